@@ -9,11 +9,13 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"runtime"
 	"sort"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"spider-golang/config"
@@ -31,22 +33,50 @@ func printUsage() {
   spider -url <URL>              # 单URL扫描
   spider -config <配置文件>      # 使用配置文件（推荐）
   spider -batch-file <文件>      # 批量扫描
+  spider -url <URL> -listen <地址> # 被动代理（手动浏览时记录分析）
+  spider -replay <输出前缀>      # 经代理重放上次运行发现的请求
 
 🎯 核心参数:
   -url string          目标URL（单URL扫描）
   -batch-file string   批量URL文件（每行一个URL）
   -config string       配置文件路径（推荐使用）
+  -listen string       被动代理监听地址 (如: 127.0.0.1:8080，需配合-url)
   -version             显示版本信息
 
 ⚙️ 常用参数:
   -depth int           最大爬取深度 (默认: 3)
   -proxy string        代理服务器 (如: http://127.0.0.1:8080)
   -log-level string    日志级别: debug/info/warn/error (默认: info)
-  -sensitive-rules     敏感信息规则文件 (默认: sensitive_rules.json)
+  -sensitive-rules     敏感信息规则文件 (默认: sensitive_rules.json，支持Gitleaks TOML/TruffleHog YAML，逗号分隔多个)
+  -js-vuln-db          前端组件漏洞库 (默认: js_vulnerabilities.json)
+  -import string       导入流量文件，自动识别Burp/HAR/ZAP/mitmproxy/原始请求（逗号分隔多个）
+  -import-burp string  导入Burp Suite导出的XML（响应参与分析，URL作为爬取种子）
+  -import-har string   导入HAR文件（同上）
+
+🔁 重放参数（-replay）:
+  -replay string       上次运行的输出前缀或文件 (如: spider_example.com_20250101_120000)
+  -proxy string        重放经过的上游代理 (默认: http://127.0.0.1:8080)
+  -replay-concurrency  重放并发数 (默认: 5)
+  -replay-hosts        只重放这些主机 (逗号分隔，支持*.example.com)
+  -replay-paths        只重放这些路径 (逗号分隔，支持/api/*)
+  -replay-methods      只重放这些方法 (逗号分隔，如: GET,POST)
 
 📋 配置文件功能:
   • Cookie认证          → anti_detection_settings.cookie_file
   • HTTPS证书跳过       → anti_detection_settings.insecure_skip_verify
+  • HTTP认证/mTLS       → auth_settings
+  • PDF/Office文档分析  → document_analysis_settings
+  • Source Map源码还原  → source_map_settings
+  • JS反混淆(沙箱)      → js_deobfuscation_settings
+  • 前端组件漏洞库      → js_vulnerability_settings
+  • OpenAPI文档导出     → openapi_settings
+  • Swagger/WSDL/WADL   → api_definition_settings
+  • GraphQL检测         → graphql_settings
+  • SOAP/gRPC-Web服务   → api_service_settings
+  • Postman/Insomnia    → collection_export_settings
+  • HAR流量导出         → har_settings
+  • 被动代理(HTTPS解密) → passive_proxy_settings
+  • 原始请求/curl导出   → request_export_settings
   • 静态资源过滤        → scope_settings.exclude_extensions
   • 黑名单设置          → blacklist_settings.domains
   • 速率控制            → rate_limit_settings
@@ -57,6 +87,8 @@ func printUsage() {
   spider -url https://example.com
   spider -config config.json
   spider -batch-file targets.txt -config my_config.json
+  spider -url https://example.com -listen 127.0.0.1:8080
+  spider -replay spider_example.com_20250101_120000 -proxy http://127.0.0.1:8080
 
 💡 提示: 配置文件功能更完整，推荐使用！
 
@@ -140,6 +172,9 @@ var (
 	sensitiveRealTime        bool
 	sensitiveRulesFile       string // 外部规则文件
 	
+	// 🆕 v4.9: 前端组件漏洞库
+	jsVulnDBFile            string // 离线漏洞库文件（retire.js格式）
+	
 	// 🆕 v2.11: 批量扫描参数
 	batchFile               string // 批量URL文件
 	batchConcurrency        int    // 批量扫描并发数
@@ -147,6 +182,21 @@ var (
 	// 🆕 v4.4: 请求日志参数
 	enableRequestLogging    bool   // 是否启用请求日志记录
 	
+	// 🆕 v4.9: 被动代理参数
+	listenAddr              string // 被动代理监听地址（为空表示主动爬取）
+	
+	// 🆕 v4.9: 流量导入参数
+	importFiles             string // 自动识别格式的流量文件（逗号分隔）
+	importBurpFile          string // Burp Suite导出的XML
+	importHARFile           string // HAR文件
+	
+	// 🆕 v4.9: 请求重放参数
+	replayInput             string // 上次运行的输出前缀或文件
+	replayConcurrency       int    // 重放并发数
+	replayHosts             string // 主机过滤（逗号分隔）
+	replayPaths             string // 路径过滤（逗号分隔）
+	replayMethods           string // 方法过滤（逗号分隔）
+	
 	// ✅ 修复2: cookieString变量已移除,改用配置文件
 )
 
@@ -228,7 +278,10 @@ func init() {
 	flag.StringVar(&sensitiveMinSeverity, "sensitive-min-severity", "LOW", "最低严重级别: LOW, MEDIUM, HIGH")
 	flag.StringVar(&sensitiveOutputFile, "sensitive-output", "", "敏感信息输出文件路径")
 	flag.BoolVar(&sensitiveRealTime, "sensitive-realtime", true, "实时输出敏感信息发现")
-	flag.StringVar(&sensitiveRulesFile, "sensitive-rules", "", "外部敏感信息规则文件（JSON格式）")
+	flag.StringVar(&sensitiveRulesFile, "sensitive-rules", "", "外部敏感信息规则文件（JSON、Gitleaks TOML、TruffleHog/RExpository YAML，逗号分隔可合并多个）")
+	
+	// 🆕 v4.9: 前端组件漏洞库参数
+	flag.StringVar(&jsVulnDBFile, "js-vuln-db", "", "前端组件离线漏洞库文件（retire.js jsrepository格式）")
 	
 	// 🆕 v2.11: 批量扫描参数
 	flag.StringVar(&batchFile, "batch-file", "", "批量扫描URL列表文件（每行一个URL）")
@@ -237,11 +290,31 @@ func init() {
 	// 🆕 v4.4: 请求日志参数
 	flag.BoolVar(&enableRequestLogging, "enable-request-logging", false, "启用请求日志记录（用于调试优化）")
 	
+	// 🆕 v4.9: 被动代理参数
+	flag.StringVar(&listenAddr, "listen", "", "被动代理监听地址（如127.0.0.1:8080），浏览器经代理手动访问目标时记录并分析流量")
+	
+	// 🆕 v4.9: 流量导入参数
+	flag.StringVar(&importFiles, "import", "", "导入流量文件，按内容自动识别格式：Burp XML、HAR、ZAP导出消息、mitmproxy flow、原始HTTP请求（sqlmap -r格式），多个文件用逗号分隔")
+	flag.StringVar(&importBurpFile, "import-burp", "", "导入Burp Suite导出的XML（Proxy历史/站点地图，响应参与分析，URL和表单作为爬取种子）")
+	flag.StringVar(&importHARFile, "import-har", "", "导入HAR文件（浏览器开发者工具/代理导出，响应参与分析，URL和表单作为爬取种子）")
+	
+	// 🆕 v4.9: 请求重放参数
+	flag.StringVar(&replayInput, "replay", "", "重放上次运行发现的请求：输出文件前缀（自动读取_crawl.har/_requests.json/_post_requests.txt/_in_scope.txt）或单个文件，经-proxy指定的代理发送")
+	flag.IntVar(&replayConcurrency, "replay-concurrency", 5, "重放并发数")
+	flag.StringVar(&replayHosts, "replay-hosts", "", "只重放这些主机的请求（逗号分隔，支持*.example.com）")
+	flag.StringVar(&replayPaths, "replay-paths", "", "只重放这些路径的请求（逗号分隔，支持/api/*前缀和*.php后缀）")
+	flag.StringVar(&replayMethods, "replay-methods", "", "只重放这些方法的请求（逗号分隔，如GET,POST）")
+	
 	// ✅ 修复2: Cookie字符串参数已移除,请在配置文件中配置 anti_detection_settings.cookie_string
 }
 
 
 func main() {
+	// 🆕 v4.9: JS沙箱工作进程（由JSSandbox以隐藏参数启动，不进入正常流程）
+	if len(os.Args) > 1 && os.Args[1] == core.JSSandboxWorkerArg {
+		os.Exit(core.RunJSSandboxWorker())
+	}
+
 	// ✅ 修复1: 设置控制台输出编码为UTF-8（修复PowerShell重定向乱码）
 	// Windows PowerShell默认使用GBK编码，这里强制使用UTF-8
 	// 这样 .\spider.exe ... >> log.log 时中文就不会乱码了
@@ -278,6 +351,12 @@ func main() {
 		handleBatchScanMode()
 		return
 	}
+	
+	// 🆕 v4.9: 处理请求重放模式
+	if replayInput != "" {
+		handleReplayMode()
+		return
+	}
 
 	// 简洁模式下不显示横幅
 	if !simpleMode {
@@ -448,6 +527,12 @@ func main() {
 	cfg.SensitiveDetectionSettings.OutputFile = sensitiveOutputFile
 	cfg.SensitiveDetectionSettings.RealTimeOutput = sensitiveRealTime
 	
+	// 🆕 v4.9: 前端组件漏洞库
+	if jsVulnDBFile != "" {
+		cfg.JSVulnerabilitySettings.Enabled = true
+		cfg.JSVulnerabilitySettings.DatabaseFile = jsVulnDBFile
+	}
+	
 	// 🆕 v4.4: 请求日志配置
 	if enableRequestLogging {
 		cfg.EnableRequestLogging = true
@@ -462,7 +547,11 @@ func main() {
 	}
 
 	// 创建爬虫实例
-	spider := core.NewSpider(cfg)
+	spider, err := core.NewSpider(cfg)
+	if err != nil {
+		fmt.Printf("创建爬虫失败: %v\n", err)
+		os.Exit(1)
+	}
 	defer spider.Close() // 确保资源清理
 	
 	// ✅ 修复2: 从配置文件加载Cookie
@@ -496,13 +585,19 @@ func main() {
 			rulesFile = cfg.SensitiveDetectionSettings.RulesFile
 		}
 		
-		// 如果有规则文件路径，尝试加载
+		// 如果有规则文件路径，尝试加载（🆕 v4.9: 逗号分隔可合并多个文件，支持Gitleaks/TruffleHog格式）
 		if rulesFile != "" {
-			if err := spider.MergeSensitiveRules(rulesFile); err != nil {
-				fmt.Printf("⚠️  警告: 加载敏感规则失败: %v\n", err)
-				fmt.Printf("💡 提示: 请使用 -sensitive-rules 参数指定规则文件，或确保默认文件 'sensitive_rules.json' 存在\n")
-			} else {
-				fmt.Printf("✅ 已加载敏感信息规则文件: %s\n", rulesFile)
+			for _, file := range strings.Split(rulesFile, ",") {
+				file = strings.TrimSpace(file)
+				if file == "" {
+					continue
+				}
+				if err := spider.MergeSensitiveRules(file); err != nil {
+					fmt.Printf("⚠️  警告: 加载敏感规则失败: %v\n", err)
+					fmt.Printf("💡 提示: 请使用 -sensitive-rules 参数指定规则文件，或确保默认文件 'sensitive_rules.json' 存在\n")
+				} else {
+					fmt.Printf("✅ 已加载敏感信息规则文件: %s\n", file)
+				}
 			}
 		} else {
 			fmt.Printf("⚠️  警告: 敏感信息检测已启用，但未指定规则文件\n")
@@ -518,8 +613,35 @@ func main() {
 	fmt.Printf("[*] 纯爬虫模式: 专注URL发现（已禁用参数爆破）\n")
 	fmt.Println()
 
+	// 🆕 v4.9: 导入的流量在起始页爬取后进入分析
+	if importBurpFile != "" {
+		spider.QueueTrafficImport(core.ImportFormatBurp, importBurpFile)
+	}
+	if importHARFile != "" {
+		spider.QueueTrafficImport(core.ImportFormatHAR, importHARFile)
+	}
+	for _, file := range strings.Split(importFiles, ",") {
+		if file = strings.TrimSpace(file); file != "" {
+			spider.QueueTrafficImport(core.ImportFormatAuto, file)
+		}
+	}
+
 	startTime := time.Now()
-	err := spider.Start(cfg.TargetURL)
+	if listenAddr != "" {
+		// 🆕 v4.9: 被动代理模式，Ctrl+C结束后继续生成报告
+		stop := make(chan struct{})
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-signals
+			signal.Stop(signals)
+			fmt.Println("\n[*] 正在停止被动代理并生成报告...")
+			close(stop)
+		}()
+		err = spider.StartPassive(cfg.TargetURL, listenAddr, stop)
+	} else {
+		err = spider.Start(cfg.TargetURL)
+	}
 	if err != nil {
 		log.Fatalf("爬取失败: %v", err)
 	}
@@ -562,6 +684,11 @@ func main() {
 			log.Printf("保存敏感信息失败: %v", err)
 		}
 		
+		// 🆕 v4.9: 按规则的扫描统计（预过滤命中、匹配次数、正则耗时）
+		if err := spider.SaveSensitiveRuleStats(baseFilename + "_sensitive_rule_stats.json"); err != nil {
+			log.Printf("保存敏感规则统计失败: %v", err)
+		}
+		
 		if sensitiveOutputFile != "" {
 			if err := spider.SaveSensitiveInfoToJSON(sensitiveOutputFile); err != nil {
 				log.Printf("保存敏感信息JSON失败: %v", err)
@@ -590,6 +717,208 @@ func main() {
 		log.Printf("保存爬取日志失败: %v", err)
 	}
 	
+	// 🆕 v4.9: 保存文档分析结果（如果启用）
+	if analyses := spider.GetDocumentAnalyses(); len(analyses) > 0 {
+		documentsFile := baseFilename + "_documents.json"
+		if err := saveDocumentAnalyses(analyses, documentsFile); err != nil {
+			log.Printf("保存文档分析结果失败: %v", err)
+		} else {
+			fmt.Printf("  - %s : %d 个文档的链接/元数据\n", documentsFile, len(analyses))
+		}
+	}
+	
+	// 🆕 v4.9: 保存Source Map还原的源码目录树和清单
+	if analyses := spider.GetSourceMapAnalyses(); len(analyses) > 0 {
+		sourcesDir, written, err := spider.SaveRecoveredSources(baseFilename + "_sourcemaps")
+		if err != nil {
+			log.Printf("保存还原源码时部分文件失败: %v", err)
+		}
+		if written > 0 {
+			fmt.Printf("  - %s/ : %d 个还原的源文件\n", sourcesDir, written)
+		}
+		sourceMapsFile := baseFilename + "_sourcemaps.json"
+		if err := saveSourceMapAnalyses(analyses, sourceMapsFile); err != nil {
+			log.Printf("保存Source Map清单失败: %v", err)
+		} else {
+			fmt.Printf("  - %s : %d 个Source Map的源文件清单\n", sourceMapsFile, len(analyses))
+		}
+	}
+	
+	// 🆕 v4.9: 保存JS反混淆结果（解密字符串和隐藏URL）
+	if reports := spider.GetDeobfuscations(); len(reports) > 0 {
+		deobfuscatedFile := baseFilename + "_deobfuscated.json"
+		if err := saveDeobfuscations(reports, deobfuscatedFile); err != nil {
+			log.Printf("保存反混淆结果失败: %v", err)
+		} else {
+			fmt.Printf("  - %s : %d 个混淆脚本的解密字符串\n", deobfuscatedFile, len(reports))
+		}
+	}
+	
+	// 🆕 v4.9: 保存前端组件识别结果（版本、CVE、严重程度）
+	if findings := spider.GetJSLibraryFindings(); len(findings) > 0 {
+		jsVulnsFile := baseFilename + "_js_vulns.json"
+		if err := saveJSLibraryFindings(findings, jsVulnsFile); err != nil {
+			log.Printf("保存前端组件漏洞失败: %v", err)
+		} else {
+			fmt.Printf("  - %s : %d 个前端组件（含已知CVE）\n", jsVulnsFile, len(findings))
+		}
+	}
+	
+	// 🆕 v4.9: 保存站点发布的API定义（Swagger/OpenAPI/WSDL/WADL）展开的端点
+	if definitions := spider.GetAPIDefinitions(); len(definitions) > 0 {
+		apiDefinitionsFile := baseFilename + "_api_definitions.json"
+		if err := saveAPIDefinitions(definitions, apiDefinitionsFile); err != nil {
+			log.Printf("保存API定义失败: %v", err)
+		} else {
+			endpointCount := 0
+			for _, def := range definitions {
+				endpointCount += len(def.Endpoints)
+			}
+			fmt.Printf("  - %s : %d 个API定义（%d 个端点）\n", apiDefinitionsFile, len(definitions), endpointCount)
+		}
+	}
+	
+	// 🆕 v4.9: 保存GraphQL端点、SDL和操作列表
+	if discovery := spider.GetGraphQLDiscovery(); discovery != nil {
+		endpoints := discovery.GetEndpoints()
+		operations := discovery.GetOperations()
+		if len(endpoints) > 0 || len(operations) > 0 {
+			graphqlFile := baseFilename + "_graphql.json"
+			if err := saveGraphQLDiscovery(endpoints, operations, graphqlFile); err != nil {
+				log.Printf("保存GraphQL结果失败: %v", err)
+			} else {
+				fmt.Printf("  - %s : %d 个GraphQL端点，%d 个操作\n", graphqlFile, len(endpoints), len(operations))
+			}
+		}
+		if sdl := discovery.GenerateSDL(); sdl != "" {
+			schemaFile := baseFilename + "_graphql_schema.graphql"
+			if err := os.WriteFile(schemaFile, []byte(sdl), 0644); err != nil {
+				log.Printf("保存GraphQL SDL失败: %v", err)
+			} else {
+				fmt.Printf("  - %s : introspection得到的SDL\n", schemaFile)
+			}
+		}
+		if len(operations) > 0 {
+			operationsFile := baseFilename + "_graphql_operations.graphql"
+			if err := os.WriteFile(operationsFile, []byte(discovery.GenerateOperationsDocument()), 0644); err != nil {
+				log.Printf("保存GraphQL操作失败: %v", err)
+			} else {
+				fmt.Printf("  - %s : GraphQL操作文档（含变量定义）\n", operationsFile)
+			}
+		}
+	}
+	
+	// 🆕 v4.9: 保存SOAP/gRPC-Web服务及其操作
+	if services := spider.GetAPIServices(); len(services) > 0 {
+		apiServicesFile := baseFilename + "_api_services.json"
+		if err := saveAPIServices(services, apiServicesFile); err != nil {
+			log.Printf("保存SOAP/gRPC-Web服务失败: %v", err)
+		} else {
+			operationCount := 0
+			for _, service := range services {
+				operationCount += len(service.Operations)
+			}
+			fmt.Printf("  - %s : %d 个SOAP/gRPC-Web服务（%d 个操作）\n", apiServicesFile, len(services), operationCount)
+		}
+	}
+	
+	// 🆕 v4.9: 由爬取结果生成OpenAPI 3文档（JSON + YAML）
+	if generator := spider.BuildOpenAPIGenerator(); generator != nil {
+		openAPIFile := baseFilename + "_openapi.json"
+		if err := generator.ExportToFile(openAPIFile); err != nil {
+			log.Printf("保存OpenAPI文档失败: %v", err)
+		} else {
+			fmt.Printf("  - %s : OpenAPI 3文档（%d 个端点）\n", openAPIFile, len(generator.GetEndpoints()))
+		}
+		openAPIYAMLFile := baseFilename + "_openapi.yaml"
+		if err := generator.ExportToYAML(openAPIYAMLFile); err != nil {
+			log.Printf("保存OpenAPI YAML失败: %v", err)
+		} else {
+			fmt.Printf("  - %s : OpenAPI 3文档（YAML）\n", openAPIYAMLFile)
+		}
+	}
+	
+	// 🆕 v4.9: 保存每个端点合并推断的JSON Schema
+	if cfg.OpenAPISettings.ExportSchemas {
+		if analyzer := spider.BuildAPIAnalyzer(); analyzer != nil {
+			if schemas := analyzer.GetEndpointSchemas(); len(schemas) > 0 {
+				schemasFile := baseFilename + "_api_schemas.json"
+				if err := saveEndpointSchemas(schemas, schemasFile); err != nil {
+					log.Printf("保存端点Schema失败: %v", err)
+				} else {
+					fmt.Printf("  - %s : %d 个端点的合并JSON Schema\n", schemasFile, len(schemas))
+				}
+			}
+		}
+	}
+	
+	// 🆕 v4.9: 导出Postman集合和Insomnia文件
+	if exporter := spider.BuildCollectionExporter(); exporter != nil {
+		requestCount := len(exporter.GetRequests())
+		if cfg.CollectionExportSettings.Postman {
+			postmanFile := baseFilename + "_postman_collection.json"
+			if err := exporter.ExportPostman(postmanFile); err != nil {
+				log.Printf("保存Postman集合失败: %v", err)
+			} else {
+				fmt.Printf("  - %s : Postman v2.1集合（%d 个请求）\n", postmanFile, requestCount)
+			}
+		}
+		if cfg.CollectionExportSettings.Insomnia {
+			insomniaFile := baseFilename + "_insomnia.json"
+			if err := exporter.ExportInsomnia(insomniaFile); err != nil {
+				log.Printf("保存Insomnia导出失败: %v", err)
+			} else {
+				fmt.Printf("  - %s : Insomnia导出（%d 个请求）\n", insomniaFile, requestCount)
+			}
+		}
+	}
+	
+	// 🆕 v4.9: 导出原始HTTP请求文件（sqlmap -r / ffuf -request）和curl脚本
+	if exporter := spider.BuildRawRequestExporter(); exporter != nil {
+		rawDir := ""
+		if cfg.RequestExportSettings.RawRequests {
+			rawDir = baseFilename + "_raw_requests"
+			if count, err := exporter.ExportRawFiles(rawDir); err != nil {
+				log.Printf("保存原始请求文件失败: %v", err)
+				rawDir = ""
+			} else {
+				fmt.Printf("  - %s/ : %d 个原始HTTP请求（sqlmap -r / ffuf -request）\n", rawDir, count)
+			}
+		}
+		if cfg.RequestExportSettings.CurlScript {
+			curlFile := baseFilename + "_curl.sh"
+			if err := exporter.ExportCurlScript(curlFile, rawDir); err != nil {
+				log.Printf("保存curl脚本失败: %v", err)
+			} else {
+				fmt.Printf("  - %s : %d 条curl命令\n", curlFile, len(exporter.GetRequests()))
+			}
+		}
+	}
+	
+	// 🆕 v4.9: 保存JS中发现的结构化端点（AST分析）
+	if endpoints := spider.GetJSEndpoints(); len(endpoints) > 0 {
+		jsEndpointsFile := baseFilename + "_js_endpoints.json"
+		if err := saveJSEndpoints(endpoints, jsEndpointsFile); err != nil {
+			log.Printf("保存JS端点失败: %v", err)
+		} else {
+			fmt.Printf("  - %s : %d 个JS端点（方法/请求头/参数/源码位置）\n", jsEndpointsFile, len(endpoints))
+		}
+	}
+	if stats := spider.GetJSASTStats(); stats.Fallbacks > 0 || stats.Modules > 0 {
+		fmt.Printf("  [JS语法树] 解析 %d 个脚本（ES模块 %d 个），%d 个无法解析已降级为正则提取\n",
+			stats.Parsed, stats.Modules, stats.Fallbacks)
+	}
+	
+	// 🆕 v4.9: 导出爬取流量（HAR 1.2，可在浏览器开发者工具、Burp、ZAP中打开）
+	if recorder := spider.GetHARRecorder(); recorder != nil && recorder.Count() > 0 {
+		harFile := baseFilename + "_crawl.har"
+		if err := recorder.ExportToFile(harFile); err != nil {
+			log.Printf("保存HAR文件失败: %v", err)
+		} else {
+			fmt.Printf("  - %s : HAR 1.2流量记录（%d 个请求）\n", harFile, recorder.Count())
+		}
+	}
+	
 	// 打印统计信息
 	if !simpleMode {
 		printStats(results, elapsed)
@@ -631,6 +960,8 @@ func main() {
 		
 		// 🆕 v4.8: 打印三大优化需求报告
 		spider.PrintJSHandlerReport()
+		spider.PrintChunkEnumerationReport() // 🆕 v4.9
+		spider.PrintJSLibraryReport()        // 🆕 v4.9
 		spider.PrintStaticResourceFilterReport()
 		spider.PrintSimilarURLDedupReport()
 		spider.PrintDOMEmbeddingReport()
@@ -896,6 +1227,90 @@ func saveAllURLs(results []*core.Result, baseFilename string) error {
 	return nil
 }
 
+// saveDocumentAnalyses 保存文档分析结果（v4.9新增）
+func saveDocumentAnalyses(analyses []*core.DocumentAnalysis, filename string) error {
+	data, err := json.MarshalIndent(analyses, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// saveSourceMapAnalyses 保存Source Map还原清单（v4.9新增）
+func saveSourceMapAnalyses(analyses []*core.SourceMapAnalysis, filename string) error {
+	data, err := json.MarshalIndent(analyses, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// saveDeobfuscations 保存JS反混淆结果（v4.9新增）
+func saveDeobfuscations(reports []*core.DeobfuscationReport, filename string) error {
+	data, err := json.MarshalIndent(reports, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// saveJSLibraryFindings 保存前端组件漏洞检测结果（v4.9新增）
+func saveJSLibraryFindings(findings []*core.JSLibraryFinding, filename string) error {
+	data, err := json.MarshalIndent(findings, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// saveAPIDefinitions 保存已解析的API定义（v4.9新增）
+func saveAPIDefinitions(definitions []*core.APIDefinition, filename string) error {
+	data, err := json.MarshalIndent(definitions, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// saveGraphQLDiscovery 保存GraphQL端点和操作（v4.9新增）
+func saveGraphQLDiscovery(endpoints []*core.GraphQLEndpoint, operations []*core.GraphQLOperation, filename string) error {
+	data, err := json.MarshalIndent(map[string]interface{}{
+		"endpoints":  endpoints,
+		"operations": operations,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// saveAPIServices 保存SOAP/gRPC-Web服务（v4.9新增）
+func saveAPIServices(services []*core.APIService, filename string) error {
+	data, err := json.MarshalIndent(services, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// saveEndpointSchemas 保存端点的合并JSON Schema（v4.9新增）
+func saveEndpointSchemas(schemas []*core.APIEndpointSchema, filename string) error {
+	data, err := json.MarshalIndent(schemas, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// saveJSEndpoints 保存JS端点分析结果（v4.9新增）
+func saveJSEndpoints(endpoints []*core.JSEndpoint, filename string) error {
+	data, err := json.MarshalIndent(endpoints, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
 // savePOSTRequests 保存POST请求到文件
 func savePOSTRequests(requests []*core.POSTRequest, filename string) error {
 	file, err := os.Create(filename)
@@ -1111,11 +1526,17 @@ func handleStdinMode() {
 		
 		// 🔧 修复：创建爬虫后立即关闭，避免资源泄漏
 		func() {
-			spider := core.NewSpider(cfg)
+			spider, err := core.NewSpider(cfg)
+			if err != nil {
+				if !simpleMode {
+					log.Printf("创建爬虫失败 %s: %v", url, err)
+				}
+				return
+			}
 			defer spider.Close() // 在匿名函数结束时立即关闭
 			
 			// 爬取
-			err := spider.Start(url)
+			err = spider.Start(url)
 			if err != nil && !simpleMode {
 				log.Printf("爬取失败 %s: %v", url, err)
 				return
@@ -1206,6 +1627,78 @@ func loadConfigFile(filename string) (*config.Config, error) {
 	return &cfg, nil
 }
 
+// handleReplayMode 处理请求重放模式（v4.9 新增）
+// 读取上次运行的输出，经上游代理（Burp/ZAP）重新发送，填充扫描器的站点地图
+func handleReplayMode() {
+	upstream := proxy
+	if upstream == "" {
+		upstream = "http://127.0.0.1:8080"
+	}
+	replayer, err := core.NewRequestReplayer(upstream, replayConcurrency)
+	if err != nil {
+		log.Fatalf("创建重放器失败: %v", err)
+	}
+	if timeout > 0 {
+		replayer.SetTimeout(time.Duration(timeout) * time.Second)
+	}
+	if err := replayer.SetFilters(strings.Split(replayHosts, ","), strings.Split(replayPaths, ","), strings.Split(replayMethods, ",")); err != nil {
+		log.Fatalf("重放过滤条件无效: %v", err)
+	}
+	
+	// 原始请求没有Cookie/请求头时，使用配置文件和命令行中的认证信息
+	configPath := configFile
+	if configPath == "" {
+		configPath = "config.json"
+	}
+	if _, statErr := os.Stat(configPath); statErr == nil {
+		if cfg, err := loadConfigFile(configPath); err == nil {
+			cookieManager := core.NewCookieManager()
+			if cfg.AntiDetectionSettings.CookieFile != "" {
+				if err := cookieManager.LoadFromFile(cfg.AntiDetectionSettings.CookieFile); err != nil {
+					fmt.Printf("⚠️  警告: 加载Cookie文件失败: %v\n", err)
+				}
+			}
+			cookieManager.LoadFromString(cfg.AntiDetectionSettings.CookieString)
+			replayer.SetCookieHeader(cookieManager.GetCookieHeader())
+			if len(cfg.AntiDetectionSettings.UserAgents) > 0 {
+				replayer.SetDefaultHeader("User-Agent", cfg.AntiDetectionSettings.UserAgents[0])
+			}
+		} else if configFile != "" {
+			log.Fatalf("加载配置文件失败: %v", err)
+		}
+	} else if configFile != "" {
+		log.Fatalf("指定的配置文件不存在: %s", configFile)
+	}
+	if customHeaders != "" {
+		var headers map[string]string
+		if err := json.Unmarshal([]byte(customHeaders), &headers); err != nil {
+			log.Fatalf("解析-headers失败（需要JSON对象）: %v", err)
+		}
+		for name, value := range headers {
+			replayer.SetDefaultHeader(name, value)
+		}
+	}
+	if userAgent != "" {
+		replayer.SetDefaultHeader("User-Agent", userAgent)
+	}
+	
+	for _, input := range strings.Split(replayInput, ",") {
+		if input = strings.TrimSpace(input); input == "" {
+			continue
+		}
+		if _, err := replayer.LoadRunOutput(input); err != nil {
+			log.Fatalf("加载重放输入失败: %v", err)
+		}
+	}
+	if len(replayer.GetRequests()) == 0 {
+		fmt.Println("没有可重放的请求")
+		return
+	}
+	
+	stats := replayer.Run()
+	stats.PrintSummary()
+}
+
 // handleBatchScanMode 处理批量扫描模式（v2.11 新增）
 func handleBatchScanMode() {
 	fmt.Printf("\n╔════════════════════════════════════════════════╗\n")
@@ -1304,7 +1797,14 @@ func handleBatchScanMode() {
 			}
 			
 			// 创建爬虫实例
-			spider := core.NewSpider(&cfg)
+			spider, err := core.NewSpider(&cfg)
+			if err != nil {
+				fmt.Printf("  ❌ 创建爬虫失败: %v\n", err)
+				mu.Lock()
+				failCount++
+				mu.Unlock()
+				return
+			}
 			defer spider.Close()
 			
 			// ✅ 优化1: 加载Cookie(如果配置文件中指定)
@@ -1322,15 +1822,18 @@ func handleBatchScanMode() {
 			// 加载敏感信息规则文件
 			if cfg.SensitiveDetectionSettings.Enabled {
 				rulesFile := cfg.SensitiveDetectionSettings.RulesFile
-				if rulesFile != "" {
-					if err := spider.MergeSensitiveRules(rulesFile); err != nil {
+				for _, file := range strings.Split(rulesFile, ",") {
+					if file = strings.TrimSpace(file); file == "" {
+						continue
+					}
+					if err := spider.MergeSensitiveRules(file); err != nil {
 						fmt.Printf("  ⚠️  警告: 加载敏感规则失败: %v\n", err)
 					}
 				}
 			}
 			
 			// 执行爬取
-			err := spider.Start(targetURL)
+			err = spider.Start(targetURL)
 			if err != nil {
 				fmt.Printf("  ❌ 爬取失败: %v\n", err)
 				mu.Lock()
@@ -1677,6 +2180,10 @@ func saveDetailedResults(results []*core.Result, spider *core.Spider, filename s
 			}
 			writer.WriteString(fmt.Sprintf("状态码: %d\n", result.StatusCode))
 			writer.WriteString(fmt.Sprintf("内容类型: %s\n", result.ContentType))
+			if result.Charset != "" && result.Charset != "utf-8" {
+				// 🆕 v4.9: 非UTF-8页面（内容已转码为UTF-8）
+				writer.WriteString(fmt.Sprintf("字符集:   %s (来源: %s，已转码)\n", result.Charset, result.CharsetSource))
+			}
 			if result.ResponseTime > 0 {
 				writer.WriteString(fmt.Sprintf("响应时间: %dms\n", result.ResponseTime))
 			}
@@ -1700,7 +2207,12 @@ func saveDetailedResults(results []*core.Result, spider *core.Spider, filename s
 		if len(result.Links) > 0 {
 			writer.WriteString(fmt.Sprintf("\n  发现的链接 (%d个):\n", len(result.Links)))
 			for _, link := range result.Links {
-				writer.WriteString(fmt.Sprintf("    • %s\n", link))
+				// 🆕 v4.9: 非HTML来源的链接标注提取器
+				if source := result.LinkSources[link]; source != "" && source != core.LinkSourceHTML {
+					writer.WriteString(fmt.Sprintf("    • %s [%s]\n", link, source))
+				} else {
+					writer.WriteString(fmt.Sprintf("    • %s\n", link))
+				}
 			}
 		}
 		
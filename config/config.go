@@ -53,6 +53,45 @@ type Config struct {
 	
 	// 🆕 v4.4: 请求日志开关
 	EnableRequestLogging bool `json:"enable_request_logging"` // 启用请求日志记录(用于调试优化)
+	
+	// 🆕 v4.9: HTTP认证设置（Basic/Digest/Bearer/OAuth2/mTLS）
+	AuthSettings AuthSettings `json:"auth_settings"` // 认证设置
+	
+	// 🆕 v4.9 文档分析（PDF/Office）
+	DocumentAnalysisSettings DocumentAnalysisSettings `json:"document_analysis_settings"` // 文档分析设置
+	
+	// 🆕 v4.9 Source Map还原
+	SourceMapSettings SourceMapSettings `json:"source_map_settings"` // Source Map设置
+	
+	// 🆕 v4.9 JS反混淆（沙箱还原字符串数组）
+	JSDeobfuscationSettings JSDeobfuscationSettings `json:"js_deobfuscation_settings"` // JS反混淆设置
+	
+	// 🆕 v4.9 前端组件漏洞检测（离线漏洞库）
+	JSVulnerabilitySettings JSVulnerabilitySettings `json:"js_vulnerability_settings"` // 前端组件漏洞设置
+	
+	// 🆕 v4.9 OpenAPI导出（由爬取结果生成API文档）
+	OpenAPISettings OpenAPISettings `json:"openapi_settings"` // OpenAPI导出设置
+	
+	// 🆕 v4.9 API定义发现（Swagger/OpenAPI/WSDL/WADL）
+	APIDefinitionSettings APIDefinitionSettings `json:"api_definition_settings"` // API定义发现设置
+	
+	// 🆕 v4.9 GraphQL端点发现、introspection和操作收集（总开关为 advanced_settings.enable_graphql_detection）
+	GraphQLSettings GraphQLSettings `json:"graphql_settings"` // GraphQL设置
+	
+	// 🆕 v4.9 SOAP/gRPC-Web服务发现
+	APIServiceSettings APIServiceSettings `json:"api_service_settings"` // 服务发现设置
+	
+	// 🆕 v4.9 Postman/Insomnia导出
+	CollectionExportSettings CollectionExportSettings `json:"collection_export_settings"` // 请求集合导出设置
+	
+	// 🆕 v4.9 HAR流量导出
+	HARSettings HARSettings `json:"har_settings"` // HAR导出设置
+	
+	// 🆕 v4.9: 被动代理设置（-listen）
+	PassiveProxySettings PassiveProxySettings `json:"passive_proxy_settings"` // 被动代理设置
+	
+	// 🆕 v4.9: 原始HTTP请求和curl脚本导出
+	RequestExportSettings RequestExportSettings `json:"request_export_settings"` // 原始请求导出设置
 }
 
 // DepthSettings 爬取深度设置
@@ -138,6 +177,8 @@ type PriorityWeights struct {
 // PerformanceConfig 性能配置
 type PerformanceConfig struct {
 	MaxConcurrentRequests int  `json:"max_concurrent_requests"` // 最大并发请求数
+	FrontierWorkers       int  `json:"frontier_workers"`        // 🆕 v4.9: 递归调度器的worker数（0=默认20）
+	MaxTotalURLs          int  `json:"max_total_urls"`          // 🆕 v4.9: 递归爬取的总URL上限（0=只受每层上限约束）
 	RequestTimeout        int  `json:"request_timeout"`         // 请求超时时间（秒）
 	MaxRetry              int  `json:"max_retry"`               // 最大重试次数
 	EnableConnectionPool  bool `json:"enable_connection_pool"`  // 启用连接池
@@ -185,6 +226,276 @@ type AntiDetectionSettings struct {
 	InsecureSkipVerify bool `json:"insecure_skip_verify"` // 是否忽略HTTPS证书错误（默认false）
 }
 
+// AuthSettings HTTP认证设置（v4.9新增）
+// 同时作用于静态爬虫、无头浏览器和辅助探测器（sitemap、隐藏路径、外部JS下载）
+type AuthSettings struct {
+	// 是否启用认证
+	Enabled bool `json:"enabled"`
+	
+	// 认证类型: basic, digest, bearer, oauth2（为空时只使用客户端证书）
+	Type string `json:"type"`
+	
+	// Basic/Digest 认证凭据
+	Username string `json:"username"`
+	Password string `json:"password"`
+	
+	// 静态Bearer Token（type=bearer）
+	BearerToken string `json:"bearer_token"`
+	
+	// OAuth2 client-credentials 配置（type=oauth2）
+	TokenURL      string   `json:"token_url"`      // Token端点
+	ClientID      string   `json:"client_id"`      // 客户端ID
+	ClientSecret  string   `json:"client_secret"`  // 客户端密钥
+	Scopes        []string `json:"scopes"`         // 申请的scope
+	RefreshBefore int      `json:"refresh_before"` // 提前多少秒刷新Token（默认60）
+	
+	// mTLS 客户端证书（PEM格式，可与任意认证类型组合）
+	ClientCertFile string `json:"client_cert_file"` // 客户端证书
+	ClientKeyFile  string `json:"client_key_file"`  // 客户端私钥
+	CABundleFile   string `json:"ca_bundle_file"`   // 自定义CA证书包
+	
+	// 凭据只发送到这些域名（为空表示只发送到目标域名）
+	Domains []string `json:"domains"`
+}
+
+// DocumentAnalysisSettings 文档分析设置（v4.9新增）
+// 下载爬取中发现的PDF/Office文档，提取内嵌链接、文本和元数据
+type DocumentAnalysisSettings struct {
+	// 是否启用文档分析
+	Enabled bool `json:"enabled"`
+	
+	// 单个文档最大下载大小（字节，超出则跳过）
+	MaxFileSize int64 `json:"max_file_size"`
+	
+	// 最多分析的文档数量
+	MaxDocuments int `json:"max_documents"`
+	
+	// 下载超时（秒）
+	Timeout int `json:"timeout"`
+	
+	// 并发下载数
+	Concurrency int `json:"concurrency"`
+	
+	// 需要分析的扩展名（不含点）
+	Extensions []string `json:"extensions"`
+	
+	// 是否对文档文本进行敏感信息扫描
+	ScanSensitive bool `json:"scan_sensitive"`
+	
+	// 是否只分析目标域名下的文档
+	InScopeOnly bool `json:"in_scope_only"`
+}
+
+// SourceMapSettings Source Map还原设置（v4.9新增）
+// 下载目标脚本的Source Map，还原原始前端源码并进行端点提取和敏感信息扫描
+type SourceMapSettings struct {
+	// 是否启用Source Map还原
+	Enabled bool `json:"enabled"`
+	
+	// 脚本未声明sourceMappingURL时是否探测 <脚本URL>.map
+	ProbeMapSuffix bool `json:"probe_map_suffix"`
+	
+	// 单个Source Map最大下载大小（字节）
+	MaxMapSize int64 `json:"max_map_size"`
+	
+	// 最多下载的Source Map数量
+	MaxMaps int `json:"max_maps"`
+	
+	// 下载超时（秒）
+	Timeout int `json:"timeout"`
+	
+	// 并发下载数
+	Concurrency int `json:"concurrency"`
+	
+	// 还原源码的保存目录（为空则使用 <输出文件前缀>_sourcemaps）
+	OutputDir string `json:"output_dir"`
+	
+	// 是否跳过第三方依赖（node_modules等）的端点提取和敏感信息扫描（仍会保存到磁盘）
+	SkipVendor bool `json:"skip_vendor"`
+	
+	// 是否对还原的源码进行敏感信息扫描
+	ScanSensitive bool `json:"scan_sensitive"`
+	
+	// 是否只处理目标域名下的脚本
+	InScopeOnly bool `json:"in_scope_only"`
+}
+
+// JSDeobfuscationSettings JS反混淆设置（v4.9新增）
+// 对分析的每个脚本检测混淆，obfuscator.io字符串数组和Packer打包在受限沙箱中还原
+type JSDeobfuscationSettings struct {
+	// 是否启用自动反混淆
+	Enabled bool `json:"enabled"`
+	
+	// 沙箱单次执行时限（毫秒）
+	SandboxTimeoutMs int `json:"sandbox_timeout_ms"`
+	
+	// 单个沙箱虚拟机允许的内存增长（MB）
+	SandboxMemoryMB int `json:"sandbox_memory_mb"`
+	
+	// 超过该大小的脚本不做反混淆（字节）
+	MaxScriptSize int `json:"max_script_size"`
+	
+	// 是否对解密出的字符串进行敏感信息扫描
+	ScanSensitive bool `json:"scan_sensitive"`
+}
+
+// JSVulnerabilitySettings 前端组件漏洞检测设置（v4.9新增）
+// 识别页面引用的JS库及版本，与离线漏洞库（retire.js格式）比对已知CVE
+type JSVulnerabilitySettings struct {
+	// 是否启用前端组件漏洞检测
+	Enabled bool `json:"enabled"`
+	
+	// 漏洞库文件路径（retire.js jsrepository 格式，可替换为更新的版本）
+	DatabaseFile string `json:"database_file"`
+}
+
+// OpenAPISettings OpenAPI导出设置（v4.9新增）
+// 爬取结束后由API链接、POST请求、查询参数和JSON响应生成OpenAPI 3文档（JSON+YAML）
+type OpenAPISettings struct {
+	// 是否导出OpenAPI文档
+	Enabled bool `json:"enabled"`
+	
+	// 是否包含JS中静态分析出的端点（未实际请求，无响应信息）
+	IncludeJSEndpoints bool `json:"include_js_endpoints"`
+	
+	// 是否只包含目标域名下的端点
+	InScopeOnly bool `json:"in_scope_only"`
+	
+	// 每个端点保留的请求/响应示例数
+	MaxExamples int `json:"max_examples"`
+	
+	// 是否导出每个端点合并推断的JSON Schema（_api_schemas.json）
+	ExportSchemas bool `json:"export_schemas"`
+	
+	// 字符串推断为枚举的最大不同取值数（0表示不推断枚举）
+	EnumMaxValues int `json:"enum_max_values"`
+	
+	// 推断枚举所需的最少取值次数
+	EnumMinSamples int `json:"enum_min_samples"`
+}
+
+// APIDefinitionSettings API定义发现设置（v4.9新增）
+// 探测并解析站点发布的Swagger/OpenAPI、WSDL、WADL文档，将其中的端点加入爬取队列
+type APIDefinitionSettings struct {
+	// 是否启用API定义发现
+	Enabled bool `json:"enabled"`
+	
+	// 是否探测常见定义路径（/swagger.json、/v2/api-docs、/openapi.json、/application.wadl等）
+	ProbeWellKnown bool `json:"probe_well_known"`
+	
+	// 额外探测的路径（相对站点根目录）
+	ExtraPaths []string `json:"extra_paths"`
+	
+	// 最多解析的定义文件数
+	MaxDefinitions int `json:"max_definitions"`
+	
+	// 每个定义最多加入的端点数
+	MaxEndpoints int `json:"max_endpoints"`
+	
+	// 是否只将目标域名下的端点加入爬取队列
+	InScopeOnly bool `json:"in_scope_only"`
+}
+
+// APIServiceSettings SOAP/gRPC-Web服务发现设置（v4.9新增）
+// 从捕获的请求（SOAPAction头、SOAP信封、application/grpc-web*）、WSDL和JS包中识别RPC风格的服务
+type APIServiceSettings struct {
+	// 是否启用服务发现
+	Enabled bool `json:"enabled"`
+	
+	// 是否扫描JS包中的gRPC-Web服务描述（MethodDescriptor、/package.Service/Method路径等）
+	ScanJS bool `json:"scan_js"`
+}
+
+// CollectionExportSettings 请求集合导出设置（v4.9新增）
+// 将发现的请求导出为Postman v2.1集合和Insomnia导出文件，按主机和路径前缀分组
+type CollectionExportSettings struct {
+	// 是否导出Postman集合（_postman_collection.json）
+	Postman bool `json:"postman"`
+	
+	// 是否导出Insomnia文件（_insomnia.json）
+	Insomnia bool `json:"insomnia"`
+	
+	// 是否包含带查询参数的GET链接（未请求的链接也会导出）
+	IncludeLinks bool `json:"include_links"`
+	
+	// 是否只导出目标域名下的请求
+	InScopeOnly bool `json:"in_scope_only"`
+	
+	// 最多导出的请求数
+	MaxRequests int `json:"max_requests"`
+}
+
+// HARSettings HAR导出设置（v4.9新增）
+// 记录静态爬虫、无头浏览器和探测请求实际收发的流量，导出为HAR 1.2（_crawl.har）
+type HARSettings struct {
+	// 是否记录并导出HAR
+	Enabled bool `json:"enabled"`
+	
+	// 是否保存响应体（文本原样保存，二进制内容base64编码）
+	IncludeBodies bool `json:"include_bodies"`
+	
+	// 每个响应体最多保存的字节数（超出部分截断并在comment中说明，0表示不截断）
+	MaxBodySize int `json:"max_body_size"`
+	
+	// 最多记录的请求数（0表示不限制）
+	MaxEntries int `json:"max_entries"`
+}
+
+// PassiveProxySettings 被动代理设置（v4.9新增）
+// 通过 -listen 启动，解密HTTPS所用的本地CA首次运行时生成，之后复用
+type PassiveProxySettings struct {
+	// CA证书文件（PEM，需导入浏览器信任）
+	CACertFile string `json:"ca_cert_file"`
+	
+	// CA私钥文件（PEM，新生成时权限为0600）
+	CAKeyFile string `json:"ca_key_file"`
+	
+	// 是否只解密目标域名的HTTPS（其余主机直接隧道转发，不受证书信任影响）
+	DecryptInScopeOnly bool `json:"decrypt_in_scope_only"`
+}
+
+// RequestExportSettings 原始HTTP请求导出设置（v4.9新增）
+// 每个去重后的POST请求和表单提交写成一个原始请求文件（_raw_requests/，可用于 sqlmap -r、ffuf -request），
+// 并生成等价的curl脚本（_curl.sh）
+type RequestExportSettings struct {
+	// 是否导出原始请求文件
+	RawRequests bool `json:"raw_requests"`
+	
+	// 是否导出curl脚本
+	CurlScript bool `json:"curl_script"`
+	
+	// 参数标记（如sqlmap的"*"、ffuf的"FUZZ"），为空表示不加
+	Placeholder string `json:"placeholder"`
+	
+	// 标记方式：append（追加在参数值之后）、replace（替换参数值）
+	PlaceholderMode string `json:"placeholder_mode"`
+	
+	// 是否只导出目标域名下的请求
+	InScopeOnly bool `json:"in_scope_only"`
+	
+	// 最多导出的请求数
+	MaxRequests int `json:"max_requests"`
+}
+
+// GraphQLSettings GraphQL设置（v4.9新增）
+// 由 AdvancedSettings.EnableGraphQLDetection 控制是否启用
+type GraphQLSettings struct {
+	// 是否对确认的端点执行introspection查询（关闭时只从JS和捕获的请求中收集操作）
+	Introspection bool `json:"introspection"`
+	
+	// 是否探测常见GraphQL路径（/graphql、/api/graphql、/v1/graphql等）
+	ProbeCommonPaths bool `json:"probe_common_paths"`
+	
+	// 额外探测的路径（相对站点根目录）
+	ExtraPaths []string `json:"extra_paths"`
+	
+	// 最多收集的操作数
+	MaxOperations int `json:"max_operations"`
+	
+	// 是否只探测目标域名下的端点（其他域名的端点只记录）
+	InScopeOnly bool `json:"in_scope_only"`
+}
+
 // DeduplicationSettings 去重设置
 type DeduplicationSettings struct {
 	// 相似度阈值
@@ -265,26 +576,26 @@ type OutputSettings struct {
 // RateLimitSettings 速率限制设置（v2.9 新增）
 type RateLimitSettings struct {
 	// 是否启用速率限制
-	Enabled bool
+	Enabled bool `json:"enabled"`
 	
 	// 每秒最大请求数
-	RequestsPerSecond int
+	RequestsPerSecond int `json:"requests_per_second"`
 	
 	// 突发请求数
-	BurstSize int
+	BurstSize int `json:"burst_size"`
 	
 	// 最小请求间隔（毫秒）
-	MinDelay int
+	MinDelay int `json:"min_delay"`
 	
 	// 最大请求间隔（毫秒）
-	MaxDelay int
+	MaxDelay int `json:"max_delay"`
 	
 	// 是否启用自适应速率
-	Adaptive bool
+	Adaptive bool `json:"adaptive"`
 	
 	// 自适应速率范围
-	AdaptiveMinRate int
-	AdaptiveMaxRate int
+	AdaptiveMinRate int `json:"adaptive_min_rate"`
+	AdaptiveMaxRate int `json:"adaptive_max_rate"`
 }
 
 // ExternalSourceSettings 外部数据源设置（v2.9 新增）
@@ -404,7 +715,7 @@ type SensitiveDetectionSettings struct {
 	// 排除的URL模式（不检测这些URL）
 	ExcludeURLPatterns []string `json:"exclude_url_patterns"`
 	
-	// 敏感信息规则文件路径
+	// 敏感信息规则文件路径（🆕 v4.9: 支持Gitleaks TOML/TruffleHog YAML，逗号分隔可合并多个）
 	RulesFile string `json:"rules_file"`
 }
 
@@ -673,6 +984,8 @@ func NewDefaultConfig() *Config {
 			},
 			PerformanceConfig: PerformanceConfig{
 				MaxConcurrentRequests: 20,   // 最大并发20
+				FrontierWorkers:       20,   // 调度器20个worker
+				MaxTotalURLs:          0,    // 总URL数只受每层上限约束
 				RequestTimeout:        30,   // 超时30秒
 				MaxRetry:              3,    // 最多重试3次
 				EnableConnectionPool:  true, // 启用连接池
@@ -715,6 +1028,111 @@ func NewDefaultConfig() *Config {
 			MinBusinessScore:     30.0,
 			HighValueThreshold:   70.0,
 		},
+		
+		// 🆕 v4.9: 认证默认配置
+		AuthSettings: AuthSettings{
+			Enabled:       false, // 默认不启用认证
+			Type:          "",
+			Scopes:        []string{},
+			RefreshBefore: 60,    // 提前60秒刷新Token
+			Domains:       []string{},
+		},
+		DocumentAnalysisSettings: DocumentAnalysisSettings{
+			Enabled:       false,            // 默认不启用（需要额外下载）
+			MaxFileSize:   10 * 1024 * 1024, // 10MB
+			MaxDocuments:  50,
+			Timeout:       30,
+			Concurrency:   5,
+			Extensions: []string{
+				"pdf", "docx", "xlsx", "pptx", "doc", "xls", "ppt",
+				"odt", "ods", "odp", "rtf",
+			},
+			ScanSensitive: true,
+			InScopeOnly:   true,
+		},
+		SourceMapSettings: SourceMapSettings{
+			Enabled:        true,             // 只请求目标脚本声明或相邻的.map文件
+			ProbeMapSuffix: true,
+			MaxMapSize:     20 * 1024 * 1024, // 20MB
+			MaxMaps:        100,
+			Timeout:        30,
+			Concurrency:    5,
+			OutputDir:      "",
+			SkipVendor:     true,
+			ScanSensitive:  true,
+			InScopeOnly:    true,
+		},
+		JSDeobfuscationSettings: JSDeobfuscationSettings{
+			Enabled:          true,
+			SandboxTimeoutMs: 2000,
+			SandboxMemoryMB:  64,
+			MaxScriptSize:    2 * 1024 * 1024, // 2MB
+			ScanSensitive:    true,
+		},
+		JSVulnerabilitySettings: JSVulnerabilitySettings{
+			Enabled:      true,
+			DatabaseFile: "js_vulnerabilities.json",
+		},
+		OpenAPISettings: OpenAPISettings{
+			Enabled:            true,
+			IncludeJSEndpoints: true,
+			InScopeOnly:        true,
+			MaxExamples:        3,
+			ExportSchemas:      true,
+			EnumMaxValues:      10,
+			EnumMinSamples:     5,
+		},
+		APIDefinitionSettings: APIDefinitionSettings{
+			Enabled:        true,
+			ProbeWellKnown: true,
+			ExtraPaths:     []string{},
+			MaxDefinitions: 20,
+			MaxEndpoints:   500,
+			InScopeOnly:    true,
+		},
+		GraphQLSettings: GraphQLSettings{
+			Introspection:    true,
+			ProbeCommonPaths: true,
+			ExtraPaths:       []string{},
+			MaxOperations:    1000,
+			InScopeOnly:      true,
+		},
+		APIServiceSettings: APIServiceSettings{
+			Enabled: true,
+			ScanJS:  true,
+		},
+		CollectionExportSettings: CollectionExportSettings{
+			Postman:      true,
+			Insomnia:     true,
+			IncludeLinks: true,
+			InScopeOnly:  true,
+			MaxRequests:  2000,
+		},
+		
+		// 🆕 v4.9: HAR导出默认配置（记录全部流量开销较大，默认关闭）
+		HARSettings: HARSettings{
+			Enabled:       false,
+			IncludeBodies: true,
+			MaxBodySize:   262144,
+			MaxEntries:    20000,
+		},
+		
+		// 🆕 v4.9: 被动代理默认配置
+		PassiveProxySettings: PassiveProxySettings{
+			CACertFile:         "spider_ca.crt",
+			CAKeyFile:          "spider_ca.key",
+			DecryptInScopeOnly: true,
+		},
+		
+		// 🆕 v4.9: 原始请求导出默认配置（默认不加参数标记）
+		RequestExportSettings: RequestExportSettings{
+			RawRequests:     true,
+			CurlScript:      true,
+			Placeholder:     "",
+			PlaceholderMode: "append",
+			InScopeOnly:     true,
+			MaxRequests:     1000,
+		},
 	}
 }
 
@@ -765,6 +1183,21 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("至少需要配置一个User-Agent")
 	}
 
+	// 验证认证设置
+	if c.AuthSettings.Enabled {
+		switch strings.ToLower(c.AuthSettings.Type) {
+		case "", "basic", "digest", "bearer", "oauth2":
+		default:
+			return fmt.Errorf("认证类型必须是 basic, digest, bearer 或 oauth2，当前值: %s", c.AuthSettings.Type)
+		}
+		if strings.EqualFold(c.AuthSettings.Type, "oauth2") && c.AuthSettings.TokenURL == "" {
+			return fmt.Errorf("oauth2认证需要配置token_url")
+		}
+		if (c.AuthSettings.ClientCertFile == "") != (c.AuthSettings.ClientKeyFile == "") {
+			return fmt.Errorf("客户端证书和私钥必须同时配置")
+		}
+	}
+
 	// 验证去重设置
 	if c.DeduplicationSettings.SimilarityThreshold < 0 || c.DeduplicationSettings.SimilarityThreshold > 1 {
 		return fmt.Errorf("相似度阈值必须在0-1之间，当前值: %.2f", c.DeduplicationSettings.SimilarityThreshold)
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 	
 	"github.com/chromedp/cdproto/network"
 	"github.com/chromedp/chromedp"
@@ -15,18 +16,41 @@ type AjaxInterceptor struct {
 	interceptedURLs []string
 	mutex           sync.Mutex
 	targetDomain    string
+
+	// 🆕 v4.9: 带请求体的请求（POST/PUT等），供SOAP/gRPC-Web等服务识别
+	capturedRequests []POSTRequest
+
+	// 🆕 v4.9: XHR/Fetch文本响应（加载完成后异步拉取响应体，供敏感信息扫描）
+	ctx               context.Context
+	requestMethods    map[network.RequestID]string
+	pendingResponses  map[network.RequestID]*CapturedResponse
+	capturedResponses []CapturedResponse
+	responseBodies    sync.WaitGroup
 }
 
+// maxCapturedRequests 最多保留的带请求体请求数
+const maxCapturedRequests = 500
+
+// 🆕 v4.9: 响应捕获上限
+const (
+	maxCapturedResponses     = 200             // 每个页面最多保留的响应数
+	maxCapturedResponseBody  = 1024 * 1024     // 单个响应体最多保留的字节数
+	capturedResponsesTimeout = 5 * time.Second // 等待响应体拉取的最长时间
+)
+
 // NewAjaxInterceptor 创建AJAX拦截器
 func NewAjaxInterceptor(targetDomain string) *AjaxInterceptor {
 	return &AjaxInterceptor{
-		interceptedURLs: make([]string, 0),
-		targetDomain:    targetDomain,
+		interceptedURLs:  make([]string, 0),
+		targetDomain:     targetDomain,
+		requestMethods:   make(map[network.RequestID]string),
+		pendingResponses: make(map[network.RequestID]*CapturedResponse),
 	}
 }
 
 // StartListening 开始监听网络请求
 func (ai *AjaxInterceptor) StartListening(ctx context.Context) {
+	ai.ctx = ctx
 	chromedp.ListenTarget(ctx, func(ev interface{}) {
 		switch ev := ev.(type) {
 		case *network.EventRequestWillBeSent:
@@ -38,12 +62,30 @@ func (ai *AjaxInterceptor) StartListening(ctx context.Context) {
 			if ai.isPotentialAjaxURL(url, method, ev.Request.Headers) {
 				ai.addURL(url)
 			}
+			if method != "GET" && method != "OPTIONS" && (ev.Request.HasPostData || ev.Request.PostData != "") {
+				ai.addRequest(ev.Request)
+			}
+			if ev.Type == network.ResourceTypeXHR || ev.Type == network.ResourceTypeFetch {
+				ai.mutex.Lock()
+				ai.requestMethods[ev.RequestID] = method
+				ai.mutex.Unlock()
+			}
 		case *network.EventResponseReceived:
 			// 也记录响应中的URL（如果看起来像API）
 			url := ev.Response.URL
 			if ai.isPotentialAjaxURL(url, "", nil) {
 				ai.addURL(url)
 			}
+			if ev.Type == network.ResourceTypeXHR || ev.Type == network.ResourceTypeFetch {
+				ai.trackResponse(ev)
+			}
+		case *network.EventLoadingFinished:
+			ai.fetchResponseBody(ev.RequestID)
+		case *network.EventLoadingFailed:
+			ai.mutex.Lock()
+			delete(ai.pendingResponses, ev.RequestID)
+			delete(ai.requestMethods, ev.RequestID)
+			ai.mutex.Unlock()
 		}
 	})
 }
@@ -146,6 +188,128 @@ func (ai *AjaxInterceptor) addURL(url string) {
 	fmt.Printf("  [AJAX拦截] 发现AJAX请求: %s\n", url)
 }
 
+// addRequest 记录带请求体的请求（线程安全）
+func (ai *AjaxInterceptor) addRequest(req *network.Request) {
+	ai.mutex.Lock()
+	defer ai.mutex.Unlock()
+
+	if ai.targetDomain != "" && !strings.Contains(req.URL, ai.targetDomain) {
+		return
+	}
+	if len(ai.capturedRequests) >= maxCapturedRequests {
+		return
+	}
+
+	headers := make(map[string]string, len(req.Headers))
+	contentType := ""
+	for name, value := range req.Headers {
+		headers[name] = fmt.Sprint(value)
+		if strings.EqualFold(name, "Content-Type") {
+			contentType = headers[name]
+		}
+	}
+	ai.capturedRequests = append(ai.capturedRequests, POSTRequest{
+		URL:         req.URL,
+		Method:      req.Method,
+		Parameters:  make(map[string]string),
+		Body:        req.PostData,
+		ContentType: contentType,
+		Headers:     headers,
+	})
+}
+
+// trackResponse 登记XHR/Fetch文本响应，等加载完成后拉取响应体（线程安全）
+func (ai *AjaxInterceptor) trackResponse(ev *network.EventResponseReceived) {
+	ai.mutex.Lock()
+	defer ai.mutex.Unlock()
+
+	method := ai.requestMethods[ev.RequestID]
+	delete(ai.requestMethods, ev.RequestID)
+	if ai.targetDomain != "" && !strings.Contains(ev.Response.URL, ai.targetDomain) {
+		return
+	}
+	if !IsTextualContentType(ev.Response.MimeType) || ev.Response.MimeType == "" {
+		return
+	}
+	if len(ai.capturedResponses)+len(ai.pendingResponses) >= maxCapturedResponses {
+		return
+	}
+	if method == "" {
+		method = "GET"
+	}
+	ai.pendingResponses[ev.RequestID] = &CapturedResponse{
+		URL:         ev.Response.URL,
+		Method:      method,
+		StatusCode:  int(ev.Response.Status),
+		ContentType: ev.Response.MimeType,
+	}
+}
+
+// fetchResponseBody 异步拉取已登记响应的响应体（不能在事件回调中同步执行CDP命令）
+func (ai *AjaxInterceptor) fetchResponseBody(requestID network.RequestID) {
+	ai.mutex.Lock()
+	response, exists := ai.pendingResponses[requestID]
+	if !exists {
+		ai.mutex.Unlock()
+		return
+	}
+	delete(ai.pendingResponses, requestID)
+	// 先登记再解锁，保证GetCapturedResponses能等到这次拉取
+	ai.responseBodies.Add(1)
+	ai.mutex.Unlock()
+
+	go func() {
+		defer ai.responseBodies.Done()
+		var body []byte
+		err := chromedp.Run(ai.ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			var err error
+			body, err = network.GetResponseBody(requestID).Do(ctx)
+			return err
+		}))
+		if err != nil || len(body) == 0 {
+			return
+		}
+		if len(body) > maxCapturedResponseBody {
+			body = body[:maxCapturedResponseBody]
+		}
+		response.Body = string(body)
+
+		ai.mutex.Lock()
+		ai.capturedResponses = append(ai.capturedResponses, *response)
+		ai.mutex.Unlock()
+	}()
+}
+
+// GetCapturedResponses 获取捕获的XHR/Fetch文本响应（等待进行中的响应体拉取，最多等待capturedResponsesTimeout）
+func (ai *AjaxInterceptor) GetCapturedResponses() []CapturedResponse {
+	done := make(chan struct{})
+	go func() {
+		ai.responseBodies.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(capturedResponsesTimeout):
+	}
+
+	ai.mutex.Lock()
+	defer ai.mutex.Unlock()
+
+	result := make([]CapturedResponse, len(ai.capturedResponses))
+	copy(result, ai.capturedResponses)
+	return result
+}
+
+// GetCapturedRequests 获取捕获的带请求体请求
+func (ai *AjaxInterceptor) GetCapturedRequests() []POSTRequest {
+	ai.mutex.Lock()
+	defer ai.mutex.Unlock()
+
+	result := make([]POSTRequest, len(ai.capturedRequests))
+	copy(result, ai.capturedRequests)
+	return result
+}
+
 // GetInterceptedURLs 获取拦截的URL
 func (ai *AjaxInterceptor) GetInterceptedURLs() []string {
 	ai.mutex.Lock()
@@ -173,5 +337,9 @@ func (ai *AjaxInterceptor) Clear() {
 	defer ai.mutex.Unlock()
 	
 	ai.interceptedURLs = make([]string, 0)
+	ai.capturedRequests = nil
+	ai.capturedResponses = nil
+	ai.pendingResponses = make(map[network.RequestID]*CapturedResponse)
+	ai.requestMethods = make(map[network.RequestID]string)
 }
 
@@ -8,6 +8,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -23,6 +24,15 @@ type APIAnalyzer struct {
 	totalRequests  int
 	successCount   int
 	failureCount   int
+	
+	// 🆕 v4.9: 被动观察（爬取结果 → OpenAPI）
+	observed       map[string]*apiObservedEndpoint // 方法 + 模板路径 → 累积状态
+	observeMutex   sync.Mutex
+	structure      *URLStructureDeduplicator // 路径变量识别
+	serverURL      string                    // 文档中的服务器地址
+	maxExamples    int
+	enumMaxValues  int // 🆕 v4.9: Schema推断中枚举的最大取值数
+	enumMinSamples int // 🆕 v4.9: 推断枚举所需的最少样本数
 }
 
 // APIEndpoint API端点详细信息
@@ -32,6 +42,7 @@ type APIEndpoint struct {
 	Parameters      []APIParameter         `json:"parameters"`
 	Headers         map[string]string      `json:"headers"`
 	RequestBody     interface{}            `json:"request_body,omitempty"`
+	RequestContentType string              `json:"request_content_type,omitempty"` // 🆕 v4.9: 请求体类型（默认JSON）
 	ResponseBody    interface{}            `json:"response_body,omitempty"`
 	StatusCodes     []int                  `json:"status_codes"`
 	ContentType     string                 `json:"content_type"`
@@ -40,10 +51,12 @@ type APIEndpoint struct {
 	Description     string                 `json:"description,omitempty"`
 	Examples        []APIExample           `json:"examples"`
 	ResponseSchema  map[string]interface{} `json:"response_schema,omitempty"`
+	ResponseSamples int                    `json:"response_samples,omitempty"` // 🆕 v4.9: 合并推断Schema的JSON响应数
 	ErrorResponses  []ErrorResponse        `json:"error_responses,omitempty"`
 	
 	// 推断的信息
 	APIType         string                 `json:"api_type"` // REST, GraphQL, gRPC, SOAP
+	Operations      []APIServiceOperation  `json:"operations,omitempty"` // 🆕 v4.9: SOAP/gRPC-Web服务的操作
 	Version         string                 `json:"version,omitempty"`
 	Deprecated      bool                   `json:"deprecated"`
 }
@@ -90,10 +103,15 @@ type RateLimit struct {
 // NewAPIAnalyzer 创建API分析器
 func NewAPIAnalyzer(targetDomain string) *APIAnalyzer {
 	return &APIAnalyzer{
-		endpoints:    make(map[string]*APIEndpoint),
-		client:       &http.Client{Timeout: 30 * time.Second},
-		targetDomain: targetDomain,
-		userAgent:    "Spider-Ultimate-API-Analyzer/2.5",
+		endpoints:      make(map[string]*APIEndpoint),
+		client:         &http.Client{Timeout: 30 * time.Second},
+		targetDomain:   targetDomain,
+		userAgent:      "Spider-Ultimate-API-Analyzer/2.5",
+		observed:       make(map[string]*apiObservedEndpoint),
+		structure:      NewURLStructureDeduplicator(),
+		maxExamples:    3,
+		enumMaxValues:  defaultSchemaMaxEnumValues,
+		enumMinSamples: defaultSchemaMinEnumSamples,
 	}
 }
 
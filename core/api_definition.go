@@ -0,0 +1,1086 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// API定义类型
+const (
+	APIDefinitionOpenAPI2 = "openapi2" // Swagger 2.0
+	APIDefinitionOpenAPI3 = "openapi3" // OpenAPI 3.x
+	APIDefinitionWSDL11   = "wsdl11"
+	APIDefinitionWSDL20   = "wsdl20"
+	APIDefinitionWADL     = "wadl"
+)
+
+// LinkSourceAPIDefinition 由API定义（Swagger/OpenAPI/WSDL/WADL）展开的链接
+const LinkSourceAPIDefinition = "api_definition"
+
+// APIDefinitionPaths 常见的API定义发布位置（相对站点根目录）
+var APIDefinitionPaths = []string{
+	"/swagger.json",
+	"/swagger.yaml",
+	"/swagger/v1/swagger.json",
+	"/swagger/doc.json",
+	"/swagger-resources",
+	"/api/swagger.json",
+	"/api/swagger.yaml",
+	"/api-docs",
+	"/api-docs.json",
+	"/v2/api-docs",
+	"/v3/api-docs",
+	"/openapi.json",
+	"/openapi.yaml",
+	"/api/openapi.json",
+	"/api/openapi.yaml",
+	"/api/v1/openapi.json",
+	"/docs/openapi.json",
+	"/application.wadl",
+	"/api/application.wadl",
+}
+
+// apiDefinitionMaxEndpoints 单个定义最多展开的端点数
+const apiDefinitionMaxEndpoints = 1000
+
+var (
+	apiDefinitionURLPattern = regexp.MustCompile(`(?i)(swagger[^/]*\.(json|ya?ml)|openapi[^/]*\.(json|ya?ml)|/api-docs\b|[?&]wsdl\b|\.wsdl$|\.wadl$|/swagger-resources$|/swagger/doc\.json$)`)
+	soapServicePattern      = regexp.MustCompile(`(?i)(\.asmx|\.svc|\.jws|/services?/[^/?]+)$`)
+	apiPathTemplatePattern  = regexp.MustCompile(`\{([^{}/]+)\}`)
+	openAPIMethods          = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+)
+
+// APIDefinition 解析后的API定义
+type APIDefinition struct {
+	URL       string                   `json:"url"`
+	Type      string                   `json:"type"`
+	Title     string                   `json:"title,omitempty"`
+	Version   string                   `json:"version,omitempty"`
+	Endpoints []*APIDefinitionEndpoint `json:"endpoints"`
+}
+
+// APIDefinitionEndpoint 定义展开后的具体端点（路径参数已填入示例值，可直接请求）
+type APIDefinitionEndpoint struct {
+	Method      string            `json:"method"`
+	URL         string            `json:"url"`
+	Path        string            `json:"path"`                // 定义中的路径模板，如 /users/{id}
+	Operation   string            `json:"operation,omitempty"` // operationId / SOAP操作名
+	Parameters  []APIParameter    `json:"parameters,omitempty"`
+	ContentType string            `json:"content_type,omitempty"` // 请求体类型
+	Body        string            `json:"body,omitempty"`         // 示例请求体（JSON/表单/SOAP信封）
+	Headers     map[string]string `json:"headers,omitempty"`      // 如 SOAPAction
+	Source      string            `json:"source"`                 // 定义文件URL
+}
+
+// BodyParams 返回请求体参数的示例值（表单和JSON请求体的顶层字段）
+func (ep *APIDefinitionEndpoint) BodyParams() map[string]string {
+	params := make(map[string]string)
+	for _, param := range ep.Parameters {
+		if param.In == "body" || param.In == "formData" {
+			params[param.Name] = fmt.Sprint(param.Example)
+		}
+	}
+	return params
+}
+
+// IsAPIDefinitionURL 判断URL是否像API定义文件
+func IsAPIDefinitionURL(rawURL string) bool {
+	return apiDefinitionURLPattern.MatchString(rawURL)
+}
+
+// WSDLCandidateURL 对疑似SOAP服务地址（.asmx/.svc/services/xxx）返回其 ?wsdl 地址
+func WSDLCandidateURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.RawQuery != "" || !soapServicePattern.MatchString(u.Path) {
+		return ""
+	}
+	u.RawQuery = "wsdl"
+	u.Fragment = ""
+	return u.String()
+}
+
+// APIDefinitionLocations 解析Swagger UI的资源列表（springfox /swagger-resources、swagger-config），返回定义地址
+func APIDefinitionLocations(body []byte, baseURL string) []string {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil
+	}
+	var raw interface{}
+	if json.Unmarshal(body, &raw) != nil {
+		return nil
+	}
+	entries := make([]interface{}, 0)
+	switch v := raw.(type) {
+	case []interface{}:
+		entries = v
+	case map[string]interface{}:
+		if urls, ok := v["urls"].([]interface{}); ok {
+			entries = urls
+		} else if _, ok := v["url"]; ok {
+			entries = append(entries, v)
+		}
+	}
+
+	locations := make([]string, 0)
+	for _, entry := range entries {
+		obj, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		location := apiString(obj["location"])
+		if location == "" {
+			location = apiString(obj["url"])
+		}
+		if location == "" {
+			continue
+		}
+		if ref, err := url.Parse(location); err == nil {
+			locations = appendUniqueString(locations, base.ResolveReference(ref).String())
+		}
+	}
+	return locations
+}
+
+// ParseAPIDefinition 识别并解析API定义（OpenAPI 2/3 的JSON或YAML、WSDL 1.1/2.0、WADL）
+func ParseAPIDefinition(body []byte, definitionURL string) (*APIDefinition, error) {
+	base, err := url.Parse(definitionURL)
+	if err != nil {
+		return nil, err
+	}
+	trimmed := bytes.TrimSpace(stripBOM(body))
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("内容为空")
+	}
+
+	if trimmed[0] == '<' {
+		root := parseAPIXMLTree(trimmed)
+		if root == nil {
+			return nil, fmt.Errorf("无法解析XML")
+		}
+		switch root.Name {
+		case "definitions":
+			return parseWSDL11(root, base), nil
+		case "description":
+			return parseWSDL20(root, base), nil
+		case "application":
+			return parseWADL(root, base), nil
+		}
+		return nil, fmt.Errorf("不是WSDL/WADL文档: <%s>", root.Name)
+	}
+
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(trimmed, &doc); err != nil {
+		return nil, fmt.Errorf("无法解析JSON/YAML: %v", err)
+	}
+	if _, ok := doc["swagger"]; ok {
+		return parseOpenAPI(doc, base, APIDefinitionOpenAPI2), nil
+	}
+	if _, ok := doc["openapi"]; ok {
+		return parseOpenAPI(doc, base, APIDefinitionOpenAPI3), nil
+	}
+	return nil, fmt.Errorf("不是Swagger/OpenAPI文档")
+}
+
+// ========== OpenAPI 2/3 ==========
+
+// openAPIParser 解析状态（保存根文档用于解析 $ref）
+type openAPIParser struct {
+	root    map[string]interface{}
+	version string
+}
+
+// parseOpenAPI 解析Swagger 2.0 / OpenAPI 3.x 文档
+func parseOpenAPI(doc map[string]interface{}, base *url.URL, version string) *APIDefinition {
+	p := &openAPIParser{root: doc, version: version}
+	def := &APIDefinition{
+		URL:       base.String(),
+		Type:      version,
+		Endpoints: make([]*APIDefinitionEndpoint, 0),
+	}
+	if info, ok := doc["info"].(map[string]interface{}); ok {
+		def.Title = apiString(info["title"])
+		def.Version = apiString(info["version"])
+	}
+
+	server := p.serverURL(base)
+	paths, _ := doc["paths"].(map[string]interface{})
+	pathNames := make([]string, 0, len(paths))
+	for path := range paths {
+		pathNames = append(pathNames, path)
+	}
+	sort.Strings(pathNames)
+
+	for _, path := range pathNames {
+		item, ok := p.resolve(paths[path]).(map[string]interface{})
+		if !ok {
+			continue
+		}
+		shared := p.parameters(item["parameters"])
+		for _, method := range openAPIMethods {
+			operation, ok := item[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if len(def.Endpoints) >= apiDefinitionMaxEndpoints {
+				return def
+			}
+			def.Endpoints = append(def.Endpoints, p.endpoint(server, path, strings.ToUpper(method), operation, shared, def.URL))
+		}
+	}
+	return def
+}
+
+// serverURL 计算API的基础地址（相对地址按定义文件地址解析）
+func (p *openAPIParser) serverURL(base *url.URL) *url.URL {
+	server := &url.URL{Scheme: base.Scheme, Host: base.Host}
+	if p.version == APIDefinitionOpenAPI2 {
+		if schemes, ok := p.root["schemes"].([]interface{}); ok && len(schemes) > 0 {
+			scheme := apiString(schemes[0])
+			// 与定义文件同协议优先
+			for _, s := range schemes {
+				if apiString(s) == base.Scheme {
+					scheme = base.Scheme
+				}
+			}
+			if scheme == "http" || scheme == "https" {
+				server.Scheme = scheme
+			}
+		}
+		if host := apiString(p.root["host"]); host != "" {
+			server.Host = host
+		}
+		server.Path = strings.TrimRight(apiString(p.root["basePath"]), "/")
+		return server
+	}
+
+	servers, _ := p.root["servers"].([]interface{})
+	if len(servers) == 0 {
+		return server
+	}
+	first, _ := servers[0].(map[string]interface{})
+	raw := apiString(first["url"])
+	variables, _ := first["variables"].(map[string]interface{})
+	raw = apiPathTemplatePattern.ReplaceAllStringFunc(raw, func(m string) string {
+		if variable, ok := variables[strings.Trim(m, "{}")].(map[string]interface{}); ok {
+			return apiString(variable["default"])
+		}
+		return ""
+	})
+	ref, err := url.Parse(raw)
+	if err != nil {
+		return server
+	}
+	resolved := base.ResolveReference(ref)
+	resolved.Path = strings.TrimRight(resolved.Path, "/")
+	resolved.RawQuery = ""
+	resolved.Fragment = ""
+	return resolved
+}
+
+// endpoint 展开单个操作
+func (p *openAPIParser) endpoint(server *url.URL, path, method string, operation map[string]interface{}, shared []APIParameter, source string) *APIDefinitionEndpoint {
+	ep := &APIDefinitionEndpoint{
+		Method:    method,
+		Path:      server.Path + path,
+		Operation: apiString(operation["operationId"]),
+		Source:    source,
+	}
+
+	// 操作级参数覆盖路径级同名参数
+	params := make([]APIParameter, 0)
+	own := p.parameters(operation["parameters"])
+	for _, param := range shared {
+		overridden := false
+		for _, o := range own {
+			if o.Name == param.Name && o.In == param.In {
+				overridden = true
+			}
+		}
+		if !overridden {
+			params = append(params, param)
+		}
+	}
+	params = append(params, own...)
+
+	// Swagger 2.0 的 in: body 参数展开为请求体字段
+	bodyParams := make([]APIParameter, 0)
+	formParams := make([]APIParameter, 0)
+	final := make([]APIParameter, 0, len(params))
+	for _, param := range params {
+		switch param.In {
+		case "body":
+			if schema, ok := param.Default.(map[string]interface{}); ok {
+				bodyParams = append(bodyParams, p.schemaFields(schema, 0)...)
+				ep.ContentType = p.consumes(operation, "application/json")
+				continue
+			}
+		case "formData":
+			formParams = append(formParams, param)
+			ep.ContentType = p.consumes(operation, "application/x-www-form-urlencoded")
+			continue
+		}
+		final = append(final, param)
+	}
+
+	// OpenAPI 3 的 requestBody
+	if requestBody, ok := p.resolve(operation["requestBody"]).(map[string]interface{}); ok {
+		if content, ok := requestBody["content"].(map[string]interface{}); ok {
+			mediaType := pickOpenAPIMediaType(content)
+			ep.ContentType = mediaType
+			if media, ok := content[mediaType].(map[string]interface{}); ok {
+				if schema, ok := p.resolve(media["schema"]).(map[string]interface{}); ok {
+					fields := p.schemaFields(schema, 0)
+					if strings.Contains(mediaType, "json") {
+						bodyParams = append(bodyParams, fields...)
+					} else {
+						formParams = append(formParams, fields...)
+					}
+				}
+			}
+		}
+	}
+
+	for i := range formParams {
+		formParams[i].In = "formData"
+	}
+	ep.Parameters = append(final, bodyParams...)
+	ep.Parameters = append(ep.Parameters, formParams...)
+
+	// 填充示例值得到可请求的URL和请求体
+	query := url.Values{}
+	concretePath := ep.Path
+	for _, param := range final {
+		value := fmt.Sprint(param.Example)
+		switch param.In {
+		case "path":
+			concretePath = strings.ReplaceAll(concretePath, "{"+param.Name+"}", url.PathEscape(value))
+		case "query":
+			query.Set(param.Name, value)
+		case "header":
+			if ep.Headers == nil {
+				ep.Headers = make(map[string]string)
+			}
+			ep.Headers[param.Name] = value
+		}
+	}
+	// 未声明的路径参数填1
+	concretePath = apiPathTemplatePattern.ReplaceAllString(concretePath, "1")
+	concrete := *server
+	concrete.Path = concretePath
+	concrete.RawQuery = query.Encode()
+	ep.URL = concrete.String()
+
+	if len(bodyParams) > 0 {
+		body := make(map[string]interface{}, len(bodyParams))
+		for _, param := range bodyParams {
+			body[param.Name] = param.Example
+		}
+		if data, err := json.Marshal(body); err == nil {
+			ep.Body = string(data)
+		}
+	} else if len(formParams) > 0 && collectionMediaType(ep.ContentType) != "multipart/form-data" {
+		// multipart请求体需要边界，只保留参数（导出时由Postman/curl生成）
+		form := url.Values{}
+		for _, param := range formParams {
+			form.Set(param.Name, fmt.Sprint(param.Example))
+		}
+		ep.Body = form.Encode()
+	}
+	return ep
+}
+
+// consumes 获取Swagger 2.0 操作（或全局）声明的请求体类型
+func (p *openAPIParser) consumes(operation map[string]interface{}, fallback string) string {
+	for _, source := range []interface{}{operation["consumes"], p.root["consumes"]} {
+		if list, ok := source.([]interface{}); ok && len(list) > 0 {
+			for _, item := range list {
+				if strings.Contains(apiString(item), strings.SplitN(fallback, "/", 2)[1]) {
+					return apiString(item)
+				}
+			}
+			return apiString(list[0])
+		}
+	}
+	return fallback
+}
+
+// parameters 解析参数列表（Swagger 2.0 body参数的schema暂存在Default中）
+func (p *openAPIParser) parameters(raw interface{}) []APIParameter {
+	list, _ := raw.([]interface{})
+	params := make([]APIParameter, 0, len(list))
+	for _, item := range list {
+		obj, ok := p.resolve(item).(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name := apiString(obj["name"])
+		in := apiString(obj["in"])
+		if name == "" || in == "" || in == "cookie" {
+			continue
+		}
+		if in == "body" {
+			schema, _ := p.resolve(obj["schema"]).(map[string]interface{})
+			params = append(params, APIParameter{Name: name, In: in, Default: schema})
+			continue
+		}
+		schema := obj
+		if s, ok := p.resolve(obj["schema"]).(map[string]interface{}); ok {
+			schema = s // OpenAPI 3 的类型在schema中
+		}
+		param := p.schemaParameter(name, in, schema)
+		param.Required = apiBool(obj["required"]) || in == "path"
+		param.Description = apiString(obj["description"])
+		if example, ok := obj["example"]; ok {
+			param.Example = example
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+// schemaFields 展开对象Schema的顶层字段（支持 allOf 合并）
+func (p *openAPIParser) schemaFields(schema map[string]interface{}, depth int) []APIParameter {
+	fields := make([]APIParameter, 0)
+	if depth > 5 {
+		return fields
+	}
+	if allOf, ok := schema["allOf"].([]interface{}); ok {
+		for _, part := range allOf {
+			if sub, ok := p.resolve(part).(map[string]interface{}); ok {
+				fields = append(fields, p.schemaFields(sub, depth+1)...)
+			}
+		}
+	}
+	properties, _ := schema["properties"].(map[string]interface{})
+	required := make(map[string]bool)
+	if list, ok := schema["required"].([]interface{}); ok {
+		for _, name := range list {
+			required[apiString(name)] = true
+		}
+	}
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop, _ := p.resolve(properties[name]).(map[string]interface{})
+		if prop == nil {
+			prop = map[string]interface{}{}
+		}
+		param := p.schemaParameter(name, "body", prop)
+		param.Required = required[name]
+		param.Description = apiString(prop["description"])
+		fields = append(fields, param)
+	}
+	return fields
+}
+
+// schemaParameter 由Schema生成参数（类型、格式、枚举、默认值和示例值）
+func (p *openAPIParser) schemaParameter(name, in string, schema map[string]interface{}) APIParameter {
+	param := APIParameter{
+		Name:   name,
+		In:     in,
+		Type:   apiString(schema["type"]),
+		Format: apiString(schema["format"]),
+	}
+	if param.Type == "" {
+		param.Type = "string"
+		if _, ok := schema["properties"]; ok {
+			param.Type = "object"
+		}
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		for _, value := range enum {
+			param.Enum = append(param.Enum, fmt.Sprint(value))
+		}
+	}
+	if def, ok := schema["default"]; ok {
+		param.Default = def
+	}
+
+	switch {
+	case schema["example"] != nil:
+		param.Example = schema["example"]
+	case param.Default != nil:
+		param.Example = param.Default
+	case len(param.Enum) > 0:
+		param.Example = param.Enum[0]
+	default:
+		param.Example = apiExampleValue(param.Type, param.Format)
+	}
+	return param
+}
+
+// resolve 解析本地 $ref（#/definitions/X、#/components/schemas/X 等）
+func (p *openAPIParser) resolve(value interface{}) interface{} {
+	for depth := 0; depth < 10; depth++ {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return value
+		}
+		ref := apiString(obj["$ref"])
+		if !strings.HasPrefix(ref, "#/") {
+			return value
+		}
+		var current interface{} = p.root
+		for _, part := range strings.Split(ref[2:], "/") {
+			part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+			m, ok := current.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			current = m[part]
+		}
+		value = current
+	}
+	return nil
+}
+
+// pickOpenAPIMediaType 选择请求体类型（JSON优先，其次表单）
+func pickOpenAPIMediaType(content map[string]interface{}) string {
+	for _, preferred := range []string{"application/json", "application/x-www-form-urlencoded", "multipart/form-data"} {
+		if _, ok := content[preferred]; ok {
+			return preferred
+		}
+	}
+	types := make([]string, 0, len(content))
+	for mediaType := range content {
+		types = append(types, mediaType)
+	}
+	sort.Strings(types)
+	if len(types) == 0 {
+		return ""
+	}
+	return types[0]
+}
+
+// apiExampleValue 按类型和格式生成示例值
+func apiExampleValue(typ, format string) interface{} {
+	switch typ {
+	case "integer":
+		return 1
+	case "number":
+		return 1.0
+	case "boolean":
+		return true
+	case "array":
+		return []interface{}{}
+	case "object":
+		return map[string]interface{}{}
+	}
+	switch format {
+	case "uuid":
+		return "00000000-0000-0000-0000-000000000000"
+	case "email":
+		return "test@example.com"
+	case "date":
+		return "2024-01-01"
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "uri", "url":
+		return "https://example.com"
+	}
+	return "test"
+}
+
+// apiString 将任意值转为字符串（nil为空字符串）
+func apiString(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprint(value)
+}
+
+// apiBool 读取布尔值
+func apiBool(value interface{}) bool {
+	b, _ := value.(bool)
+	return b
+}
+
+// ========== WSDL / WADL ==========
+
+// apiXMLNode 简化的XML节点（名称为本地名，命名空间保存在Space）
+type apiXMLNode struct {
+	Name     string
+	Space    string
+	Attrs    map[string]string
+	Children []*apiXMLNode
+}
+
+// attr 获取属性值
+func (n *apiXMLNode) attr(name string) string {
+	return n.Attrs[name]
+}
+
+// children 获取指定本地名的直接子节点
+func (n *apiXMLNode) children(name string) []*apiXMLNode {
+	matched := make([]*apiXMLNode, 0)
+	for _, child := range n.Children {
+		if child.Name == name {
+			matched = append(matched, child)
+		}
+	}
+	return matched
+}
+
+// child 获取第一个指定本地名的直接子节点
+func (n *apiXMLNode) child(name string) *apiXMLNode {
+	for _, child := range n.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// descendants 深度优先获取所有指定本地名的后代节点
+func (n *apiXMLNode) descendants(name string) []*apiXMLNode {
+	matched := make([]*apiXMLNode, 0)
+	for _, child := range n.Children {
+		if child.Name == name {
+			matched = append(matched, child)
+		}
+		matched = append(matched, child.descendants(name)...)
+	}
+	return matched
+}
+
+// parseAPIXMLTree 将XML解析为节点树
+func parseAPIXMLTree(body []byte) *apiXMLNode {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.Strict = false
+	decoder.CharsetReader = passthroughCharsetReader
+
+	var root *apiXMLNode
+	stack := make([]*apiXMLNode, 0, 16)
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			return root
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			node := &apiXMLNode{Name: t.Name.Local, Space: t.Name.Space, Attrs: make(map[string]string)}
+			for _, attr := range t.Attr {
+				node.Attrs[attr.Name.Local] = attr.Value
+			}
+			if len(stack) == 0 {
+				if root != nil {
+					return root
+				}
+				root = node
+			} else {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, node)
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+}
+
+// localName 去掉QName前缀（tns:GetUser → GetUser）
+func localName(qname string) string {
+	if i := strings.LastIndex(qname, ":"); i >= 0 {
+		return qname[i+1:]
+	}
+	return qname
+}
+
+// xsdTypeToAPIType XML Schema类型 → 参数类型
+func xsdTypeToAPIType(xsdType string) string {
+	switch localName(xsdType) {
+	case "int", "integer", "long", "short", "byte", "unsignedInt", "unsignedLong", "unsignedShort", "nonNegativeInteger", "positiveInteger":
+		return "integer"
+	case "decimal", "float", "double":
+		return "number"
+	case "boolean":
+		return "boolean"
+	}
+	return "string"
+}
+
+// wsdlSchemaFields 在 <types> 中查找元素定义的子元素（document/literal 请求参数）
+func wsdlSchemaFields(root *apiXMLNode, elementName string) []APIParameter {
+	fields := make([]APIParameter, 0)
+	for _, element := range root.descendants("element") {
+		if element.attr("name") != elementName {
+			continue
+		}
+		complexType := element.child("complexType")
+		if complexType == nil {
+			// 引用命名类型：<element name="X" type="tns:XType"/>
+			typeName := localName(element.attr("type"))
+			for _, ct := range root.descendants("complexType") {
+				if typeName != "" && ct.attr("name") == typeName {
+					complexType = ct
+				}
+			}
+		}
+		if complexType == nil {
+			continue
+		}
+		for _, field := range complexType.descendants("element") {
+			name := field.attr("name")
+			if name == "" {
+				name = localName(field.attr("ref"))
+			}
+			if name == "" {
+				continue
+			}
+			typ := xsdTypeToAPIType(field.attr("type"))
+			fields = append(fields, APIParameter{
+				Name:     name,
+				In:       "body",
+				Type:     typ,
+				Required: field.attr("minOccurs") != "0",
+				Example:  apiExampleValue(typ, ""),
+			})
+		}
+		break
+	}
+	return fields
+}
+
+// soapEnvelope 生成SOAP请求信封示例
+func soapEnvelope(version12 bool, namespace, element string, fields []APIParameter) string {
+	envNS := "http://schemas.xmlsoap.org/soap/envelope/"
+	if version12 {
+		envNS = "http://www.w3.org/2003/05/soap-envelope"
+	}
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="utf-8"?>` + "\n")
+	sb.WriteString(`<soap:Envelope xmlns:soap="` + envNS + `" xmlns:tns="` + namespace + `">` + "\n")
+	sb.WriteString("  <soap:Body>\n")
+	sb.WriteString("    <tns:" + element + ">\n")
+	for _, field := range fields {
+		sb.WriteString(fmt.Sprintf("      <tns:%s>%v</tns:%s>\n", field.Name, field.Example, field.Name))
+	}
+	sb.WriteString("    </tns:" + element + ">\n")
+	sb.WriteString("  </soap:Body>\n")
+	sb.WriteString("</soap:Envelope>")
+	return sb.String()
+}
+
+// resolveDefinitionURL 按定义文件地址解析服务地址
+func resolveDefinitionURL(base *url.URL, location string) string {
+	ref, err := url.Parse(strings.TrimSpace(location))
+	if err != nil {
+		return ""
+	}
+	return base.ResolveReference(ref).String()
+}
+
+// parseWSDL11 解析WSDL 1.1：service/port → binding → 操作（SOAPAction、请求元素和字段）
+func parseWSDL11(root *apiXMLNode, base *url.URL) *APIDefinition {
+	def := &APIDefinition{
+		URL:       base.String(),
+		Type:      APIDefinitionWSDL11,
+		Title:     root.attr("name"),
+		Endpoints: make([]*APIDefinitionEndpoint, 0),
+	}
+	namespace := root.attr("targetNamespace")
+
+	// message → 第一个part的元素名
+	messageElements := make(map[string]string)
+	for _, message := range root.children("message") {
+		for _, part := range message.children("part") {
+			element := localName(part.attr("element"))
+			if element == "" {
+				element = part.attr("name")
+			}
+			messageElements[message.attr("name")] = element
+			break
+		}
+	}
+	// portType操作 → 输入message
+	operationInputs := make(map[string]string)
+	for _, portType := range root.children("portType") {
+		for _, operation := range portType.children("operation") {
+			if input := operation.child("input"); input != nil {
+				operationInputs[portType.attr("name")+"/"+operation.attr("name")] = localName(input.attr("message"))
+			}
+		}
+	}
+
+	bindings := make(map[string]*apiXMLNode)
+	for _, binding := range root.children("binding") {
+		bindings[binding.attr("name")] = binding
+	}
+
+	for _, service := range root.children("service") {
+		if def.Title == "" {
+			def.Title = service.attr("name")
+		}
+		for _, port := range service.children("port") {
+			address := port.child("address")
+			binding := bindings[localName(port.attr("binding"))]
+			if address == nil || binding == nil {
+				continue
+			}
+			location := resolveDefinitionURL(base, address.attr("location"))
+			version12 := strings.Contains(address.Space, "soap12")
+			if binding.child("binding") == nil || location == "" {
+				continue // HTTP绑定等非SOAP绑定
+			}
+			portType := localName(binding.attr("type"))
+			for _, operation := range binding.children("operation") {
+				name := operation.attr("name")
+				action := ""
+				if soapOp := operation.child("operation"); soapOp != nil {
+					action = soapOp.attr("soapAction")
+				}
+				element := messageElements[operationInputs[portType+"/"+name]]
+				if element == "" {
+					element = name
+				}
+				def.Endpoints = append(def.Endpoints, newSOAPEndpoint(root, location, name, action, namespace, element, version12, def.URL))
+				if len(def.Endpoints) >= apiDefinitionMaxEndpoints {
+					return def
+				}
+			}
+		}
+	}
+	return def
+}
+
+// parseWSDL20 解析WSDL 2.0：service/endpoint → binding → interface操作
+func parseWSDL20(root *apiXMLNode, base *url.URL) *APIDefinition {
+	def := &APIDefinition{
+		URL:       base.String(),
+		Type:      APIDefinitionWSDL20,
+		Endpoints: make([]*APIDefinitionEndpoint, 0),
+	}
+	namespace := root.attr("targetNamespace")
+
+	interfaces := make(map[string]*apiXMLNode)
+	for _, iface := range root.children("interface") {
+		interfaces[iface.attr("name")] = iface
+	}
+	bindings := make(map[string]*apiXMLNode)
+	for _, binding := range root.children("binding") {
+		bindings[binding.attr("name")] = binding
+	}
+
+	for _, service := range root.children("service") {
+		if def.Title == "" {
+			def.Title = service.attr("name")
+		}
+		for _, endpoint := range service.children("endpoint") {
+			location := resolveDefinitionURL(base, endpoint.attr("address"))
+			binding := bindings[localName(endpoint.attr("binding"))]
+			if location == "" || binding == nil {
+				continue
+			}
+			iface := interfaces[localName(binding.attr("interface"))]
+			if iface == nil {
+				iface = interfaces[localName(service.attr("interface"))]
+			}
+			if iface == nil {
+				continue
+			}
+			isHTTP := strings.HasSuffix(binding.attr("type"), "/http")
+			actions := make(map[string]string)
+			for _, op := range binding.children("operation") {
+				actions[localName(op.attr("ref"))] = op.attr("action")
+				if m := op.attr("method"); m != "" {
+					actions[localName(op.attr("ref"))+"#method"] = m
+				}
+			}
+			for _, operation := range iface.children("operation") {
+				name := operation.attr("name")
+				element := name
+				if input := operation.child("input"); input != nil && input.attr("element") != "" {
+					element = localName(input.attr("element"))
+				}
+				if isHTTP {
+					method := strings.ToUpper(actions[name+"#method"])
+					if method == "" {
+						method = "GET"
+					}
+					def.Endpoints = append(def.Endpoints, &APIDefinitionEndpoint{
+						Method:     method,
+						URL:        location,
+						Path:       location,
+						Operation:  name,
+						Parameters: wsdlSchemaFields(root, element),
+						Source:     def.URL,
+					})
+				} else {
+					def.Endpoints = append(def.Endpoints, newSOAPEndpoint(root, location, name, actions[name], namespace, element, binding.attr("version") != "1.1", def.URL))
+				}
+				if len(def.Endpoints) >= apiDefinitionMaxEndpoints {
+					return def
+				}
+			}
+		}
+	}
+	return def
+}
+
+// newSOAPEndpoint 构造SOAP操作端点（POST + 信封请求体）
+func newSOAPEndpoint(root *apiXMLNode, location, operation, action, namespace, element string, version12 bool, source string) *APIDefinitionEndpoint {
+	fields := wsdlSchemaFields(root, element)
+	ep := &APIDefinitionEndpoint{
+		Method:     "POST",
+		URL:        location,
+		Path:       location,
+		Operation:  operation,
+		Parameters: fields,
+		Body:       soapEnvelope(version12, namespace, element, fields),
+		Headers:    make(map[string]string),
+		Source:     source,
+	}
+	if u, err := url.Parse(location); err == nil {
+		ep.Path = u.Path
+	}
+	if version12 {
+		ep.ContentType = "application/soap+xml; charset=utf-8"
+		if action != "" {
+			ep.ContentType += `; action="` + action + `"`
+		}
+	} else {
+		ep.ContentType = "text/xml; charset=utf-8"
+		ep.Headers["SOAPAction"] = `"` + action + `"`
+	}
+	return ep
+}
+
+// parseWADL 解析WADL：resources/resource（可嵌套）→ method → 参数
+func parseWADL(root *apiXMLNode, base *url.URL) *APIDefinition {
+	def := &APIDefinition{
+		URL:       base.String(),
+		Type:      APIDefinitionWADL,
+		Endpoints: make([]*APIDefinitionEndpoint, 0),
+	}
+	for _, resources := range root.children("resources") {
+		resourcesBase := resolveDefinitionURL(base, resources.attr("base"))
+		baseURL, err := url.Parse(resourcesBase)
+		if err != nil {
+			continue
+		}
+		for _, resource := range resources.children("resource") {
+			walkWADLResource(def, baseURL, "", resource, nil)
+		}
+	}
+	return def
+}
+
+// walkWADLResource 递归展开资源（子资源路径拼接，模板参数继承）
+func walkWADLResource(def *APIDefinition, base *url.URL, parentPath string, resource *apiXMLNode, inherited []APIParameter) {
+	path := strings.TrimRight(parentPath, "/") + "/" + strings.Trim(resource.attr("path"), "/")
+	params := append([]APIParameter{}, inherited...)
+	params = append(params, wadlParams(resource)...)
+
+	for _, method := range resource.children("method") {
+		if len(def.Endpoints) >= apiDefinitionMaxEndpoints {
+			return
+		}
+		ep := &APIDefinitionEndpoint{
+			Method:    strings.ToUpper(method.attr("name")),
+			Path:      strings.TrimRight(base.Path, "/") + path,
+			Operation: method.attr("id"),
+			Source:    def.URL,
+		}
+		ep.Parameters = append([]APIParameter{}, params...)
+		if request := method.child("request"); request != nil {
+			ep.Parameters = append(ep.Parameters, wadlParams(request)...)
+			for _, representation := range request.children("representation") {
+				ep.ContentType = representation.attr("mediaType")
+				ep.Parameters = append(ep.Parameters, wadlParams(representation)...)
+				break
+			}
+		}
+
+		query := url.Values{}
+		concretePath := ep.Path
+		form := url.Values{}
+		for _, param := range ep.Parameters {
+			value := fmt.Sprint(param.Example)
+			switch param.In {
+			case "path":
+				concretePath = strings.ReplaceAll(concretePath, "{"+param.Name+"}", url.PathEscape(value))
+			case "query":
+				query.Set(param.Name, value)
+			case "header":
+				if ep.Headers == nil {
+					ep.Headers = make(map[string]string)
+				}
+				ep.Headers[param.Name] = value
+			case "formData":
+				form.Set(param.Name, value)
+			}
+		}
+		concrete := *base
+		concrete.Path = apiPathTemplatePattern.ReplaceAllString(concretePath, "1")
+		concrete.RawQuery = query.Encode()
+		ep.URL = concrete.String()
+		if len(form) > 0 {
+			if ep.ContentType == "" {
+				ep.ContentType = "application/x-www-form-urlencoded"
+			}
+			// multipart请求体需要边界，只保留参数
+			if collectionMediaType(ep.ContentType) != "multipart/form-data" {
+				ep.Body = form.Encode()
+			}
+		}
+		def.Endpoints = append(def.Endpoints, ep)
+	}
+
+	for _, child := range resource.children("resource") {
+		walkWADLResource(def, base, path, child, params)
+	}
+}
+
+// wadlParams 解析 <param>（style: template/query/header/matrix/plain）
+func wadlParams(node *apiXMLNode) []APIParameter {
+	params := make([]APIParameter, 0)
+	for _, param := range node.children("param") {
+		in := map[string]string{
+			"template": "path",
+			"query":    "query",
+			"header":   "header",
+			"plain":    "formData",
+			"matrix":   "query",
+		}[param.attr("style")]
+		if in == "" || param.attr("name") == "" {
+			continue
+		}
+		typ := xsdTypeToAPIType(param.attr("type"))
+		p := APIParameter{
+			Name:     param.attr("name"),
+			In:       in,
+			Type:     typ,
+			Required: param.attr("required") == "true" || in == "path",
+			Default:  nil,
+			Example:  apiExampleValue(typ, ""),
+		}
+		if def := param.attr("default"); def != "" {
+			p.Default = def
+			p.Example = def
+		}
+		for _, option := range param.children("option") {
+			p.Enum = append(p.Enum, option.attr("value"))
+		}
+		if p.Default == nil && len(p.Enum) > 0 {
+			p.Example = p.Enum[0]
+		}
+		params = append(params, p)
+	}
+	return params
+}
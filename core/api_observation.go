@@ -0,0 +1,565 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// APIObservation 爬取过程中观察到的一次API请求（被动分析，不发送任何请求）
+// 来源包括 Result.APIs、POST请求、带查询参数的页面、JSON响应和JS端点
+type APIObservation struct {
+	Method             string               // HTTP方法（为空时按GET处理）
+	URL                string               // 完整URL（查询参数会作为query参数记录）
+	RequestContentType string               // 请求体类型
+	RequestBody        string               // 原始请求体
+	BodyParams         map[string]string    // 已解析的请求体参数（表单字段等）
+	StatusCode         int                  // 响应状态码（0表示未请求）
+	ContentType        string               // 响应类型
+	ResponseBody       string               // 响应体
+	Source             string               // 来源：result/api/post/js
+	APIType            string               // 🆕 v4.9: 已知的服务类型（SOAP/gRPC-Web），为空时按URL推断
+	Operation          *APIServiceOperation // 🆕 v4.9: 请求对应的服务操作
+}
+
+// apiObservedEndpoint 被动观察的端点累积状态（同一方法+模板化路径）
+type apiObservedEndpoint struct {
+	endpoint     *APIEndpoint
+	path         string
+	requests     int                 // 观察到的请求数（用于判断参数是否必需）
+	queryValues  map[string][]string // 查询参数 → 观察到的值
+	queryCount   map[string]int      // 查询参数 → 出现次数
+	pathValues   map[string][]string // 路径参数 → 观察到的值
+	bodyValues   map[string][]string
+	bodyCount    map[string]int
+	bodyRequests int
+	jsonBody     bool // 请求体Schema来自实际的JSON请求体
+	sources      []string
+
+	// 🆕 v4.9: 多样本合并的JSON Schema
+	requestSchema  *JSONSchemaInferrer
+	responseSchema *JSONSchemaInferrer
+}
+
+// 参数取值格式识别
+var (
+	apiUUIDPattern     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	apiEmailPattern    = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[a-zA-Z]{2,}$`)
+	apiDatePattern     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	apiVersionSegment  = regexp.MustCompile(`^[vV]\d+(\.\d+)*$`) // 版本号段（v1、v2.1）不作为路径参数
+	apiDateTimePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}(:\d{2}(\.\d+)?)?(Z|[+-]\d{2}:?\d{2})?$`)
+)
+
+// apiPathParamNames 结构化去重的路径变量 → OpenAPI路径参数名
+var apiPathParamNames = map[string]string{
+	"{num}":  "id",
+	"{uuid}": "uuid",
+	"{hash}": "hash",
+}
+
+// SetServerURL 设置文档中的服务器地址（如 https://example.com）
+func (aa *APIAnalyzer) SetServerURL(serverURL string) {
+	aa.serverURL = strings.TrimRight(serverURL, "/")
+}
+
+// SetMaxExamples 设置每个端点保留的请求/响应示例数
+func (aa *APIAnalyzer) SetMaxExamples(n int) {
+	aa.maxExamples = n
+}
+
+// SetEnumLimits 设置Schema推断中的枚举条件（最多maxValues个不同取值，至少minSamples次观察）
+func (aa *APIAnalyzer) SetEnumLimits(maxValues, minSamples int) {
+	aa.enumMaxValues = maxValues
+	aa.enumMinSamples = minSamples
+}
+
+// newSchemaInferrer 按分析器配置创建Schema推断器
+func (aa *APIAnalyzer) newSchemaInferrer() *JSONSchemaInferrer {
+	inferrer := NewJSONSchemaInferrer()
+	inferrer.SetEnumLimits(aa.enumMaxValues, aa.enumMinSamples)
+	return inferrer
+}
+
+// APIEndpointSchema 端点的合并Schema（v4.9新增）
+// 供OpenAPI、Postman等导出器按 方法+模板路径 查找请求/响应结构
+type APIEndpointSchema struct {
+	Method          string                 `json:"method"`
+	Path            string                 `json:"path"` // 模板化路径（/api/users/{id}）
+	StatusCodes     []int                  `json:"status_codes,omitempty"`
+	ContentType     string                 `json:"content_type,omitempty"`
+	ResponseSamples int                    `json:"response_samples"`
+	ResponseSchema  map[string]interface{} `json:"response_schema,omitempty"`
+	RequestSamples  int                    `json:"request_samples,omitempty"`
+	RequestSchema   map[string]interface{} `json:"request_schema,omitempty"`
+}
+
+// GetEndpointSchemas 获取所有带JSON Schema的观察端点（按路径和方法排序）
+func (aa *APIAnalyzer) GetEndpointSchemas() []*APIEndpointSchema {
+	aa.observeMutex.Lock()
+	defer aa.observeMutex.Unlock()
+
+	schemas := make([]*APIEndpointSchema, 0)
+	for _, state := range aa.observed {
+		if schema := aa.endpointSchema(state); schema != nil {
+			schemas = append(schemas, schema)
+		}
+	}
+	sort.SliceStable(schemas, func(i, j int) bool {
+		if schemas[i].Path != schemas[j].Path {
+			return schemas[i].Path < schemas[j].Path
+		}
+		return schemas[i].Method < schemas[j].Method
+	})
+	return schemas
+}
+
+// LookupEndpointSchema 按请求的方法和URL查找端点Schema（URL按同样规则模板化）；没有JSON样本时返回nil
+func (aa *APIAnalyzer) LookupEndpointSchema(method, rawURL string) *APIEndpointSchema {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+	method = strings.ToUpper(method)
+	if method == "" {
+		method = "GET"
+	}
+	path, _ := aa.templatePath(u.Path)
+
+	aa.observeMutex.Lock()
+	defer aa.observeMutex.Unlock()
+	state, exists := aa.observed[method+" "+path]
+	if !exists {
+		return nil
+	}
+	return aa.endpointSchema(state)
+}
+
+// endpointSchema 生成端点的Schema记录（调用方持有锁）
+func (aa *APIAnalyzer) endpointSchema(state *apiObservedEndpoint) *APIEndpointSchema {
+	if state.responseSchema == nil && state.requestSchema == nil {
+		return nil
+	}
+	endpoint := state.endpoint
+	schema := &APIEndpointSchema{
+		Path:        state.path,
+		StatusCodes: endpoint.StatusCodes,
+		ContentType: endpoint.ContentType,
+	}
+	if len(endpoint.Methods) > 0 {
+		schema.Method = endpoint.Methods[0]
+	}
+	if state.responseSchema != nil {
+		schema.ResponseSamples = state.responseSchema.Samples()
+		schema.ResponseSchema = state.responseSchema.Schema()
+	}
+	if state.requestSchema != nil {
+		schema.RequestSamples = state.requestSchema.Samples()
+		schema.RequestSchema = state.requestSchema.Schema()
+	}
+	return schema
+}
+
+// Observe 记录一次观察到的请求（v4.9新增）
+// 路径中的数字/UUID/哈希段按结构化去重规则模板化为 {id} 等路径参数，
+// 同一方法和模板路径的观察合并为一个端点，参数类型根据观察到的取值推断
+func (aa *APIAnalyzer) Observe(obs APIObservation) *APIEndpoint {
+	u, err := url.Parse(obs.URL)
+	if err != nil || u.Host == "" {
+		return nil
+	}
+	method := strings.ToUpper(obs.Method)
+	if method == "" {
+		method = "GET"
+	}
+
+	path, pathParams := aa.templatePath(u.Path)
+	key := method + " " + path
+
+	aa.observeMutex.Lock()
+	defer aa.observeMutex.Unlock()
+
+	state, exists := aa.observed[key]
+	if !exists {
+		state = &apiObservedEndpoint{
+			endpoint: &APIEndpoint{
+				URL:            path,
+				Methods:        []string{method},
+				Parameters:     make([]APIParameter, 0),
+				Headers:        make(map[string]string),
+				StatusCodes:    make([]int, 0),
+				Examples:       make([]APIExample, 0),
+				ErrorResponses: make([]ErrorResponse, 0),
+				APIType:        aa.detectAPIType(obs.URL),
+				Version:        aa.extractVersion(obs.URL, nil),
+			},
+			path:        path,
+			queryValues: make(map[string][]string),
+			queryCount:  make(map[string]int),
+			pathValues:  make(map[string][]string),
+			bodyValues:  make(map[string][]string),
+			bodyCount:   make(map[string]int),
+		}
+		aa.observed[key] = state
+		aa.endpoints[key] = state.endpoint
+	}
+	endpoint := state.endpoint
+	state.sources = appendUniqueString(state.sources, obs.Source)
+	if obs.APIType != "" {
+		endpoint.APIType = obs.APIType
+	}
+	if obs.Operation != nil {
+		aa.observeOperation(endpoint, obs)
+	}
+
+	// 路径参数和查询参数
+	state.requests++
+	for name, value := range pathParams {
+		state.pathValues[name] = appendLimitedValue(state.pathValues[name], value)
+	}
+	for name, values := range u.Query() {
+		state.queryCount[name]++
+		for _, value := range values {
+			state.queryValues[name] = appendLimitedValue(state.queryValues[name], value)
+		}
+	}
+
+	// 请求体
+	aa.observeRequestBody(state, obs)
+
+	// 响应
+	if obs.StatusCode > 0 {
+		aa.observeResponse(state, method, obs)
+	}
+
+	aa.rebuildParameters(state)
+	endpoint.Description = fmt.Sprintf("爬取中观察到 %d 次请求（来源: %s）", state.requests, strings.Join(state.sources, ", "))
+	return endpoint
+}
+
+// observeOperation 记录SOAP/gRPC-Web服务操作（同一URL上的多个操作按名称、SOAPAction和SOAP版本区分）
+func (aa *APIAnalyzer) observeOperation(endpoint *APIEndpoint, obs APIObservation) {
+	if endpoint.RequestContentType == "" {
+		endpoint.RequestContentType = obs.RequestContentType
+	}
+	for _, op := range endpoint.Operations {
+		if op.Name == obs.Operation.Name && op.Action == obs.Operation.Action && op.SOAPVersion == obs.Operation.SOAPVersion {
+			return
+		}
+	}
+	endpoint.Operations = append(endpoint.Operations, *obs.Operation)
+}
+
+// observeRequestBody 记录请求体：JSON请求体生成Schema，表单请求体记录字段取值
+func (aa *APIAnalyzer) observeRequestBody(state *apiObservedEndpoint, obs APIObservation) {
+	endpoint := state.endpoint
+	mediaType, _, _ := mime.ParseMediaType(obs.RequestContentType)
+	body := strings.TrimSpace(obs.RequestBody)
+
+	if strings.Contains(mediaType, "json") || (mediaType == "" && (strings.HasPrefix(body, "{") || strings.HasPrefix(body, "["))) {
+		var data interface{}
+		if json.Unmarshal([]byte(body), &data) == nil {
+			endpoint.RequestContentType = "application/json"
+			if state.requestSchema == nil {
+				state.requestSchema = aa.newSchemaInferrer()
+			}
+			state.requestSchema.AddSample(data)
+			endpoint.RequestBody = state.requestSchema.Schema()
+			state.jsonBody = true
+			return
+		}
+	}
+
+	params := obs.BodyParams
+	if len(params) == 0 && body != "" && (mediaType == "" || mediaType == "application/x-www-form-urlencoded") {
+		if values, err := url.ParseQuery(body); err == nil {
+			params = make(map[string]string)
+			for name := range values {
+				params[name] = values.Get(name)
+			}
+		}
+	}
+	if len(params) == 0 {
+		return
+	}
+	if endpoint.RequestContentType == "" {
+		endpoint.RequestContentType = mediaType
+		if endpoint.RequestContentType == "" {
+			endpoint.RequestContentType = "application/x-www-form-urlencoded"
+		}
+	}
+	state.bodyRequests++
+	for name, value := range params {
+		state.bodyCount[name]++
+		state.bodyValues[name] = appendLimitedValue(state.bodyValues[name], value)
+	}
+}
+
+// observeResponse 记录响应状态码、JSON响应Schema和示例
+func (aa *APIAnalyzer) observeResponse(state *apiObservedEndpoint, method string, obs APIObservation) {
+	endpoint := state.endpoint
+	if !containsInt(endpoint.StatusCodes, obs.StatusCode) {
+		endpoint.StatusCodes = append(endpoint.StatusCodes, obs.StatusCode)
+		sort.Ints(endpoint.StatusCodes)
+	}
+	if obs.StatusCode == 401 || obs.StatusCode == 403 {
+		endpoint.RequiresAuth = true
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(obs.ContentType)
+	var data interface{}
+	isJSON := strings.Contains(mediaType, "json") && json.Unmarshal([]byte(obs.ResponseBody), &data) == nil
+
+	if obs.StatusCode >= 400 {
+		for _, errResp := range endpoint.ErrorResponses {
+			if errResp.StatusCode == obs.StatusCode {
+				return
+			}
+		}
+		endpoint.ErrorResponses = append(endpoint.ErrorResponses, ErrorResponse{
+			StatusCode: obs.StatusCode,
+			Message:    http.StatusText(obs.StatusCode),
+			Example:    truncateString(obs.ResponseBody, 500),
+		})
+		return
+	}
+
+	if !isJSON {
+		if endpoint.ContentType == "" {
+			endpoint.ContentType = mediaType
+		}
+		return
+	}
+	endpoint.ContentType = "application/json"
+	if endpoint.ResponseBody == nil {
+		endpoint.ResponseBody = data
+	}
+	// 同一端点的所有JSON响应合并推断Schema
+	if state.responseSchema == nil {
+		state.responseSchema = aa.newSchemaInferrer()
+	}
+	state.responseSchema.AddSample(data)
+	endpoint.ResponseSchema = state.responseSchema.Schema()
+	endpoint.ResponseSamples = state.responseSchema.Samples()
+	if len(endpoint.Examples) < aa.maxExamples {
+		endpoint.Examples = append(endpoint.Examples, APIExample{
+			Method:         method,
+			URL:            obs.URL,
+			RequestBody:    truncateString(obs.RequestBody, 500),
+			ResponseStatus: obs.StatusCode,
+			ResponseBody:   truncateString(obs.ResponseBody, 2000),
+		})
+	}
+}
+
+// rebuildParameters 根据累积的取值重新生成参数列表
+func (aa *APIAnalyzer) rebuildParameters(state *apiObservedEndpoint) {
+	params := make([]APIParameter, 0)
+
+	for _, name := range sortedKeys(state.pathValues) {
+		param := inferAPIParameter(name, "path", state.pathValues[name])
+		param.Required = true // OpenAPI要求路径参数必需
+		params = append(params, param)
+	}
+	for _, name := range sortedKeys(state.queryValues) {
+		param := inferAPIParameter(name, "query", state.queryValues[name])
+		param.Required = state.requests > 1 && state.queryCount[name] == state.requests
+		params = append(params, param)
+	}
+	state.endpoint.Parameters = params
+
+	// 表单请求体：字段类型同样由取值推断
+	if len(state.bodyValues) > 0 && !state.jsonBody {
+		properties := make(map[string]interface{})
+		required := make([]string, 0)
+		for _, name := range sortedKeys(state.bodyValues) {
+			param := inferAPIParameter(name, "body", state.bodyValues[name])
+			schema := map[string]interface{}{"type": param.Type}
+			if param.Format != "" {
+				schema["format"] = param.Format
+			}
+			if param.Example != nil {
+				schema["example"] = param.Example
+			}
+			properties[name] = schema
+			if state.bodyRequests > 1 && state.bodyCount[name] == state.bodyRequests {
+				required = append(required, name)
+			}
+		}
+		schema := map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+		}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		state.endpoint.RequestBody = schema
+	}
+}
+
+// templatePath 按结构化去重规则将路径中的变量段模板化
+// /api/users/123/orders/9 → /api/users/{id}/orders/{orderId}
+func (aa *APIAnalyzer) templatePath(path string) (string, map[string]string) {
+	params := make(map[string]string)
+	if path == "" || path == "/" {
+		return "/", params
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	templated := make([]string, 0, len(segments))
+	previous := ""
+	for _, segment := range segments {
+		if segment == "" {
+			continue
+		}
+		normalized := segment
+		if !apiVersionSegment.MatchString(segment) {
+			normalized = aa.structure.normalizePathSegment(segment)
+		}
+		for placeholder, base := range apiPathParamNames {
+			index := strings.Index(normalized, placeholder)
+			if index < 0 {
+				continue
+			}
+			name := apiPathParamName(base, previous, params)
+			// 变量值：去掉模板中的固定前后缀（product-123 → 123）
+			suffix := normalized[index+len(placeholder):]
+			params[name] = strings.TrimSuffix(strings.TrimPrefix(segment, normalized[:index]), suffix)
+			normalized = normalized[:index] + "{" + name + "}" + suffix
+			break
+		}
+		templated = append(templated, normalized)
+		if !strings.Contains(normalized, "{") {
+			previous = segment
+		}
+	}
+	result := "/" + strings.Join(templated, "/")
+	if strings.HasSuffix(path, "/") && result != "/" {
+		result += "/"
+	}
+	return result, params
+}
+
+// apiPathParamName 生成不重复的路径参数名：首个为 id，之后按前一段命名（orders → orderId）
+func apiPathParamName(base, previous string, used map[string]string) string {
+	if _, exists := used[base]; !exists {
+		return base
+	}
+	if previous != "" {
+		prefix := strings.ToLower(strings.TrimSuffix(previous, "s"))
+		prefix = strings.Map(func(r rune) rune {
+			if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+				return r
+			}
+			return -1
+		}, prefix)
+		if prefix != "" {
+			name := prefix + strings.ToUpper(base[:1]) + base[1:]
+			if _, exists := used[name]; !exists {
+				return name
+			}
+		}
+	}
+	for i := 2; ; i++ {
+		name := base + strconv.Itoa(i)
+		if _, exists := used[name]; !exists {
+			return name
+		}
+	}
+}
+
+// inferAPIParameter 根据观察到的取值推断参数类型和格式
+func inferAPIParameter(name, in string, values []string) APIParameter {
+	param := APIParameter{Name: name, In: in, Type: "string"}
+	// 空值（如JS端点只知道参数名）不参与推断
+	nonEmpty := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" {
+			nonEmpty = append(nonEmpty, value)
+		}
+	}
+	values = nonEmpty
+	if len(values) == 0 {
+		return param
+	}
+	param.Example = values[0]
+
+	allInt, allNumber, allBool := true, true, true
+	format := ""
+	for i, value := range values {
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			allInt = false
+		}
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			allNumber = false
+		}
+		if value != "true" && value != "false" {
+			allBool = false
+		}
+		valueFormat := apiStringFormat(value)
+		if i == 0 {
+			format = valueFormat
+		} else if format != valueFormat {
+			format = ""
+		}
+	}
+
+	switch {
+	case allBool:
+		param.Type = "boolean"
+		param.Example = values[0] == "true"
+	case allInt:
+		param.Type = "integer"
+		if n, err := strconv.ParseInt(values[0], 10, 64); err == nil {
+			param.Example = n
+		}
+	case allNumber:
+		param.Type = "number"
+		if f, err := strconv.ParseFloat(values[0], 64); err == nil {
+			param.Example = f
+		}
+	default:
+		param.Format = format
+	}
+	return param
+}
+
+// apiStringFormat 识别字符串取值的格式（uuid/email/date/date-time/uri）
+func apiStringFormat(value string) string {
+	switch {
+	case apiUUIDPattern.MatchString(value):
+		return "uuid"
+	case apiEmailPattern.MatchString(value):
+		return "email"
+	case apiDatePattern.MatchString(value):
+		return "date"
+	case apiDateTimePattern.MatchString(value):
+		return "date-time"
+	case strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://"):
+		return "uri"
+	}
+	return ""
+}
+
+// appendLimitedValue 记录参数取值（去重，最多保留20个用于类型推断）
+func appendLimitedValue(values []string, value string) []string {
+	if len(values) >= 20 {
+		return values
+	}
+	return appendUniqueString(values, value)
+}
+
+// sortedKeys 返回排序后的键
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,445 @@
+package core
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 🆕 v4.9: SOAP与gRPC-Web服务发现
+// REST路径分析无法区分这两类RPC风格的服务：SOAP的所有操作共用一个URL（由SOAPAction和信封中的元素区分），
+// gRPC-Web则以 /包名.服务/方法 为路径、以protobuf为请求体。这里从捕获的请求、WSDL和JS包中识别服务和操作
+
+// 服务类型（APIService.Type，同时作为 APIEndpoint.APIType）
+const (
+	APIServiceSOAP    = "SOAP"
+	APIServiceGRPCWeb = "gRPC-Web"
+)
+
+// SOAP信封命名空间
+const (
+	soap11EnvelopeNS = "http://schemas.xmlsoap.org/soap/envelope/"
+	soap12EnvelopeNS = "http://www.w3.org/2003/05/soap-envelope"
+)
+
+var (
+	// 路径形如 [/前缀]/package.Service/Method
+	grpcPathPattern = regexp.MustCompile(`^(.*?)/([A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*)*)/([A-Za-z_][A-Za-z0-9_]*)$`)
+	// protoc-gen-grpc-web: new grpcWeb.MethodDescriptor('/pkg.Svc/Method', MethodType.UNARY, proto.pkg.Req, proto.pkg.Resp, ...)
+	grpcMethodDescriptorPattern = regexp.MustCompile(`MethodDescriptor\(\s*["'](/[A-Za-z_][\w.]*\.[A-Za-z_]\w*/[A-Za-z_]\w*)["']\s*,\s*[\w.$]+\s*,\s*([\w.$]+)\s*,\s*([\w.$]+)`)
+	// 生成代码中的方法路径字面量：'/pkg.Svc/Method'（要求包含包名，减少误报）
+	grpcPathLiteralPattern = regexp.MustCompile(`["'](/[a-z_][\w]*(?:\.[A-Za-z_]\w*)*\.[A-Z]\w*/[A-Z]\w*)["']`)
+	// Connect-ES / protobuf-ts 服务描述：typeName: "pkg.Svc" 或 new ServiceType("pkg.Svc", [...])
+	grpcServiceTypePattern   = regexp.MustCompile(`(?:typeName\s*:\s*|ServiceType\(\s*)["']([A-Za-z_]\w*(?:\.[A-Za-z_]\w*)+)["']`)
+	grpcServiceMethodPattern = regexp.MustCompile(`name\s*:\s*["']([A-Z]\w*)["']\s*,\s*(?:options\s*:\s*\{[^}]*\}\s*,\s*)?I\s*:\s*([\w.$]+)\s*,\s*O\s*:\s*([\w.$]+)`)
+)
+
+// APIService 发现的SOAP/gRPC-Web服务
+type APIService struct {
+	Type       string                 `json:"type"`     // SOAP / gRPC-Web
+	Name       string                 `json:"name"`     // SOAP服务名或gRPC服务全名（package.Service）
+	Endpoint   string                 `json:"endpoint"` // SOAP服务地址 / gRPC-Web服务前缀
+	Namespace  string                 `json:"namespace,omitempty"`
+	WSDL       string                 `json:"wsdl,omitempty"`
+	Operations []*APIServiceOperation `json:"operations"`
+	Sources    []string               `json:"sources"` // traffic/wsdl/js/link
+}
+
+// APIServiceOperation 服务中的一个操作
+type APIServiceOperation struct {
+	Name          string `json:"name"`
+	URL           string `json:"url"`
+	Action        string `json:"action,omitempty"`       // SOAPAction
+	SOAPVersion   string `json:"soap_version,omitempty"` // 1.1 / 1.2
+	InputMessage  string `json:"input_message,omitempty"`
+	OutputMessage string `json:"output_message,omitempty"`
+	ContentType   string `json:"content_type,omitempty"`
+	Example       string `json:"example,omitempty"` // 捕获的请求体/信封示例
+	Source        string `json:"source,omitempty"`
+}
+
+// APIServiceDetector SOAP/gRPC-Web服务检测器（并发安全）
+type APIServiceDetector struct {
+	mutex    sync.Mutex
+	services map[string]*APIService
+	order    []string
+}
+
+// NewAPIServiceDetector 创建服务检测器
+func NewAPIServiceDetector() *APIServiceDetector {
+	return &APIServiceDetector{
+		services: make(map[string]*APIService),
+		order:    make([]string, 0),
+	}
+}
+
+// ObserveRequest 分析捕获的请求：SOAPAction头、application/soap+xml、SOAP信封请求体，
+// 或 application/grpc-web* 请求；source为来源（traffic/js），识别出服务时返回true
+func (sd *APIServiceDetector) ObserveRequest(method, rawURL, contentType string, headers map[string]string, body, source string) bool {
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	mediaType = strings.ToLower(mediaType)
+
+	if strings.HasPrefix(mediaType, "application/grpc") {
+		return sd.observeGRPC(rawURL, mediaType, source)
+	}
+
+	soapAction, hasSOAPAction := "", false
+	for name, value := range headers {
+		if strings.EqualFold(name, "SOAPAction") {
+			soapAction, hasSOAPAction = strings.Trim(value, `"`), true
+		}
+	}
+	if action, ok := params["action"]; ok && mediaType == "application/soap+xml" {
+		soapAction, hasSOAPAction = action, true
+	}
+	envelope := parseSOAPEnvelope(body)
+	if envelope == nil && !hasSOAPAction && mediaType != "application/soap+xml" {
+		return false
+	}
+	if strings.EqualFold(method, "GET") && envelope == nil {
+		return false
+	}
+
+	op := &APIServiceOperation{
+		URL:         stripURLQuery(rawURL),
+		Action:      soapAction,
+		SOAPVersion: "1.1",
+		ContentType: contentType,
+		Source:      source,
+	}
+	namespace := ""
+	if mediaType == "application/soap+xml" {
+		op.SOAPVersion = "1.2"
+	}
+	if envelope != nil {
+		op.SOAPVersion = envelope.version
+		op.InputMessage = envelope.element
+		op.Name = envelope.element
+		namespace = envelope.namespace
+		op.Example = truncateString(body, 2000)
+	}
+	if op.Name == "" && soapAction != "" {
+		op.Name = soapActionName(soapAction)
+	}
+	if op.Name == "" {
+		return false
+	}
+	sd.addSOAPOperation(op.URL, namespace, "", "", op, source)
+	return true
+}
+
+// ObserveResponse 根据响应类型识别gRPC-Web调用（爬取结果的Content-Type为 application/grpc-web*）
+func (sd *APIServiceDetector) ObserveResponse(rawURL, contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if !strings.HasPrefix(strings.ToLower(mediaType), "application/grpc") {
+		return false
+	}
+	return sd.observeGRPC(rawURL, strings.ToLower(mediaType), "traffic")
+}
+
+// observeGRPC 记录gRPC-Web调用（服务和方法取自路径）
+func (sd *APIServiceDetector) observeGRPC(rawURL, mediaType, source string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	match := grpcPathPattern.FindStringSubmatch(u.Path)
+	if match == nil {
+		return false
+	}
+	base := u.Scheme + "://" + u.Host + match[1]
+	sd.addGRPCOperation(base, match[2], &APIServiceOperation{
+		Name:        match[3],
+		URL:         base + "/" + match[2] + "/" + match[3],
+		ContentType: mediaType,
+		Source:      source,
+	}, source)
+	return true
+}
+
+// AddWSDL 将WSDL定义（见api_definition.go）中的SOAP操作记录为服务
+func (sd *APIServiceDetector) AddWSDL(def *APIDefinition) {
+	if def.Type != APIDefinitionWSDL11 && def.Type != APIDefinitionWSDL20 {
+		return
+	}
+	for _, ep := range def.Endpoints {
+		if ep.Body == "" {
+			continue // WSDL 2.0 的HTTP绑定不是SOAP
+		}
+		envelope := parseSOAPEnvelope(ep.Body)
+		if envelope == nil {
+			continue
+		}
+		op := &APIServiceOperation{
+			Name:         ep.Operation,
+			URL:          ep.URL,
+			SOAPVersion:  envelope.version,
+			InputMessage: envelope.element,
+			ContentType:  ep.ContentType,
+			Example:      ep.Body,
+			Source:       def.URL,
+		}
+		if action := ep.Headers["SOAPAction"]; action != "" {
+			op.Action = strings.Trim(action, `"`)
+		} else if _, params, err := mime.ParseMediaType(ep.ContentType); err == nil {
+			op.Action = params["action"]
+		}
+		sd.addSOAPOperation(ep.URL, envelope.namespace, def.Title, def.URL, op, "wsdl")
+	}
+}
+
+// ObserveWSDLLink 记录指向WSDL的链接（未能解析WSDL时仍保留服务地址）
+func (sd *APIServiceDetector) ObserveWSDLLink(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	isWSDL := strings.EqualFold(u.RawQuery, "wsdl") || strings.HasPrefix(strings.ToLower(u.RawQuery), "wsdl=") ||
+		strings.HasSuffix(strings.ToLower(u.Path), ".wsdl")
+	if !isWSDL {
+		return false
+	}
+	endpoint := stripURLQuery(rawURL)
+	if strings.HasSuffix(strings.ToLower(u.Path), ".wsdl") {
+		endpoint = "" // 独立的WSDL文件，服务地址未知
+	}
+	sd.addSOAPOperation(endpoint, "", "", rawURL, nil, "link")
+	return true
+}
+
+// ScanScript 从JS包中识别gRPC-Web服务：protoc-gen-grpc-web的MethodDescriptor、方法路径字面量，
+// 以及Connect-ES/protobuf-ts的服务描述；baseURL为调用地址前缀（通常是站点根地址）
+func (sd *APIServiceDetector) ScanScript(code, source, baseURL string) int {
+	if !strings.Contains(code, "grpc") && !strings.Contains(code, "typeName") && !strings.Contains(code, "ServiceType") {
+		return 0
+	}
+	baseURL = strings.TrimRight(baseURL, "/")
+	found := 0
+	seen := make(map[string]bool)
+	add := func(service, method, input, output string) {
+		key := service + "/" + method
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		sd.addGRPCOperation(baseURL, service, &APIServiceOperation{
+			Name:          method,
+			URL:           baseURL + "/" + key,
+			InputMessage:  grpcMessageName(input),
+			OutputMessage: grpcMessageName(output),
+			ContentType:   "application/grpc-web+proto",
+			Source:        source,
+		}, "js")
+		found++
+	}
+
+	for _, match := range grpcMethodDescriptorPattern.FindAllStringSubmatch(code, -1) {
+		parts := strings.Split(strings.TrimPrefix(match[1], "/"), "/")
+		add(parts[0], parts[1], match[2], match[3])
+	}
+	for _, match := range grpcPathLiteralPattern.FindAllStringSubmatch(code, -1) {
+		parts := strings.Split(strings.TrimPrefix(match[1], "/"), "/")
+		add(parts[0], parts[1], "", "")
+	}
+	for _, loc := range grpcServiceTypePattern.FindAllStringSubmatchIndex(code, -1) {
+		service := code[loc[2]:loc[3]]
+		// 方法列表紧跟在服务名之后
+		end := loc[1] + 4096
+		if end > len(code) {
+			end = len(code)
+		}
+		region := code[loc[1]:end]
+		if next := grpcServiceTypePattern.FindStringIndex(region); next != nil {
+			region = region[:next[0]]
+		}
+		for _, m := range grpcServiceMethodPattern.FindAllStringSubmatch(region, -1) {
+			add(service, m[1], m[2], m[3])
+		}
+	}
+	return found
+}
+
+// addSOAPOperation 记录SOAP服务及操作（同一服务地址合并，操作按名称+版本去重）
+func (sd *APIServiceDetector) addSOAPOperation(endpoint, namespace, name, wsdl string, op *APIServiceOperation, source string) {
+	key := APIServiceSOAP + " " + endpoint
+	if endpoint == "" {
+		key += wsdl
+	}
+	sd.mutex.Lock()
+	defer sd.mutex.Unlock()
+	service := sd.service(key, APIServiceSOAP, endpoint)
+	service.Sources = appendUniqueString(service.Sources, source)
+	if service.Name == "" || name != "" {
+		if name != "" {
+			service.Name = name
+		} else if u, err := url.Parse(endpoint); err == nil && endpoint != "" {
+			service.Name = strings.TrimSuffix(u.Path[strings.LastIndex(u.Path, "/")+1:], ".asmx")
+		}
+	}
+	if service.Namespace == "" {
+		service.Namespace = namespace
+	}
+	if service.WSDL == "" {
+		service.WSDL = wsdl
+	}
+	if op == nil {
+		return
+	}
+	for _, existing := range service.Operations {
+		if existing.Name == op.Name && existing.SOAPVersion == op.SOAPVersion {
+			// 补全缺失的信息（如流量中的操作后来在WSDL中出现）
+			if existing.Action == "" {
+				existing.Action = op.Action
+			}
+			if existing.InputMessage == "" {
+				existing.InputMessage = op.InputMessage
+			}
+			if existing.Example == "" {
+				existing.Example = op.Example
+			}
+			return
+		}
+	}
+	service.Operations = append(service.Operations, op)
+}
+
+// addGRPCOperation 记录gRPC-Web服务及方法
+func (sd *APIServiceDetector) addGRPCOperation(base, serviceName string, op *APIServiceOperation, source string) {
+	key := APIServiceGRPCWeb + " " + base + "/" + serviceName
+	sd.mutex.Lock()
+	defer sd.mutex.Unlock()
+	service := sd.service(key, APIServiceGRPCWeb, base)
+	service.Name = serviceName
+	service.Sources = appendUniqueString(service.Sources, source)
+	if i := strings.LastIndex(serviceName, "."); i > 0 {
+		service.Namespace = serviceName[:i]
+	}
+	for _, existing := range service.Operations {
+		if existing.Name == op.Name {
+			if existing.InputMessage == "" {
+				existing.InputMessage = op.InputMessage
+				existing.OutputMessage = op.OutputMessage
+			}
+			return
+		}
+	}
+	service.Operations = append(service.Operations, op)
+}
+
+// service 获取或创建服务（调用方持有锁）
+func (sd *APIServiceDetector) service(key, typ, endpoint string) *APIService {
+	service, exists := sd.services[key]
+	if !exists {
+		service = &APIService{
+			Type:       typ,
+			Endpoint:   endpoint,
+			Operations: make([]*APIServiceOperation, 0),
+			Sources:    make([]string, 0),
+		}
+		sd.services[key] = service
+		sd.order = append(sd.order, key)
+	}
+	return service
+}
+
+// GetServices 获取发现的服务（按发现顺序，操作按名称排序）
+func (sd *APIServiceDetector) GetServices() []*APIService {
+	sd.mutex.Lock()
+	defer sd.mutex.Unlock()
+	services := make([]*APIService, 0, len(sd.order))
+	for _, key := range sd.order {
+		service := sd.services[key]
+		sort.SliceStable(service.Operations, func(i, j int) bool {
+			return service.Operations[i].Name < service.Operations[j].Name
+		})
+		services = append(services, service)
+	}
+	return services
+}
+
+// PrintReport 打印服务发现报告
+func (sd *APIServiceDetector) PrintReport() {
+	services := sd.GetServices()
+	if len(services) == 0 {
+		return
+	}
+	fmt.Printf("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	fmt.Printf("【SOAP/gRPC-Web服务】发现 %d 个服务\n", len(services))
+	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	for _, service := range services {
+		endpoint := service.Endpoint
+		if endpoint == "" {
+			endpoint = service.WSDL
+		}
+		fmt.Printf("  [%s] %s %s [%s] 操作: %d\n", service.Type, service.Name, endpoint, strings.Join(service.Sources, ","), len(service.Operations))
+		for i, op := range service.Operations {
+			if i >= 10 {
+				fmt.Printf("      ... 还有 %d 个操作\n", len(service.Operations)-i)
+				break
+			}
+			messages := op.InputMessage
+			if op.OutputMessage != "" {
+				messages += " → " + op.OutputMessage
+			}
+			fmt.Printf("      - %s %s\n", op.Name, messages)
+		}
+	}
+}
+
+// soapEnvelopeInfo SOAP信封中的请求信息
+type soapEnvelopeInfo struct {
+	version   string
+	element   string
+	namespace string
+}
+
+// parseSOAPEnvelope 解析SOAP信封，返回Body中第一个元素（操作/请求消息）；不是SOAP信封时返回nil
+func parseSOAPEnvelope(body string) *soapEnvelopeInfo {
+	trimmed := strings.TrimSpace(body)
+	if !strings.HasPrefix(trimmed, "<") || !strings.Contains(trimmed, "Envelope") {
+		return nil
+	}
+	root := parseAPIXMLTree([]byte(trimmed))
+	if root == nil || root.Name != "Envelope" {
+		return nil
+	}
+	info := &soapEnvelopeInfo{version: "1.1"}
+	switch root.Space {
+	case soap12EnvelopeNS:
+		info.version = "1.2"
+	case soap11EnvelopeNS:
+	default:
+		return nil
+	}
+	if soapBody := root.child("Body"); soapBody != nil && len(soapBody.Children) > 0 {
+		info.element = soapBody.Children[0].Name
+		info.namespace = soapBody.Children[0].Space
+	}
+	return info
+}
+
+// soapActionName 从SOAPAction中取操作名（http://tempuri.org/GetUser → GetUser）
+func soapActionName(action string) string {
+	action = strings.TrimRight(action, "/")
+	if i := strings.LastIndexAny(action, "/#:"); i >= 0 {
+		return action[i+1:]
+	}
+	return action
+}
+
+// grpcMessageName 规范化JS中的消息类型引用（proto.pkg.HelloRequest → pkg.HelloRequest）
+func grpcMessageName(ref string) string {
+	return strings.TrimPrefix(ref, "proto.")
+}
+
+// stripURLQuery 去掉查询参数和片段
+func stripURLQuery(rawURL string) string {
+	if i := strings.IndexAny(rawURL, "?#"); i >= 0 {
+		return rawURL[:i]
+	}
+	return rawURL
+}
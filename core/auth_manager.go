@@ -0,0 +1,699 @@
+package core
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+	"spider-golang/config"
+)
+
+// 认证类型
+const (
+	AuthTypeNone   = ""
+	AuthTypeBasic  = "basic"
+	AuthTypeDigest = "digest"
+	AuthTypeBearer = "bearer"
+	AuthTypeOAuth2 = "oauth2"
+)
+
+// AuthManager HTTP认证管理器（v4.9新增）
+// 统一处理 Basic、Digest、Bearer Token（含OAuth2自动刷新）和 mTLS 客户端证书，
+// 为静态爬虫、无头浏览器和各类辅助探测器提供同一套认证能力
+type AuthManager struct {
+	settings           config.AuthSettings
+	authType           string
+	insecureSkipVerify bool // 跳过证书验证（anti_detection_settings.insecure_skip_verify）
+
+	// mTLS
+	clientCerts []tls.Certificate
+	rootCAs     *x509.CertPool
+
+	// 凭据作用域（只向这些域名发送凭据）
+	domains []string
+
+	// OAuth2 Token缓存
+	tokenMutex    sync.Mutex
+	accessToken   string
+	tokenExpiry   time.Time
+	refreshBefore time.Duration
+	tokenClient   *http.Client
+
+	// Digest 质询缓存（按Host）
+	digestMutex      sync.Mutex
+	digestChallenges map[string]*digestChallenge
+
+	// 统计
+	statsMutex      sync.Mutex
+	tokenRefreshes  int
+	digestRetries   int
+	authorizedCount int
+}
+
+// digestChallenge Digest认证质询
+type digestChallenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       string
+	nc        int
+}
+
+// oauth2TokenResponse OAuth2 Token端点响应
+type oauth2TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// NewAuthManager 创建认证管理器，加载客户端证书和CA证书包
+// insecureSkipVerify 作用于Token端点和未指定Transport时创建的默认Transport
+func NewAuthManager(settings config.AuthSettings, insecureSkipVerify bool) (*AuthManager, error) {
+	am := &AuthManager{
+		settings:           settings,
+		insecureSkipVerify: insecureSkipVerify,
+		authType:           strings.ToLower(strings.TrimSpace(settings.Type)),
+		domains:            make([]string, 0),
+		digestChallenges:   make(map[string]*digestChallenge),
+		refreshBefore:      time.Duration(settings.RefreshBefore) * time.Second,
+	}
+	if am.refreshBefore <= 0 {
+		am.refreshBefore = 60 * time.Second
+	}
+
+	for _, d := range settings.Domains {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d != "" {
+			am.domains = append(am.domains, d)
+		}
+	}
+
+	// 加载客户端证书
+	if settings.ClientCertFile != "" || settings.ClientKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(settings.ClientCertFile, settings.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("加载客户端证书失败: %v", err)
+		}
+		am.clientCerts = []tls.Certificate{cert}
+	}
+
+	// 加载CA证书包
+	if settings.CABundleFile != "" {
+		data, err := os.ReadFile(settings.CABundleFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取CA证书包失败: %v", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("CA证书包中没有有效的PEM证书: %s", settings.CABundleFile)
+		}
+		am.rootCAs = pool
+	}
+
+	switch am.authType {
+	case AuthTypeNone, AuthTypeBasic, AuthTypeDigest, AuthTypeBearer, AuthTypeOAuth2:
+	default:
+		return nil, fmt.Errorf("不支持的认证类型: %s", settings.Type)
+	}
+
+	// Token端点使用独立客户端（同样携带客户端证书）
+	am.tokenClient = &http.Client{
+		Transport: am.NewTransport(am.insecureSkipVerify),
+		Timeout:   30 * time.Second,
+	}
+
+	return am, nil
+}
+
+// SetTargetDomain 设置目标域名（未配置domains时凭据只发送到目标域名）
+func (am *AuthManager) SetTargetDomain(domain string) {
+	if len(am.settings.Domains) > 0 {
+		return
+	}
+	host := strings.ToLower(domain)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	am.domains = []string{host}
+}
+
+// GetType 获取认证类型
+func (am *AuthManager) GetType() string {
+	return am.authType
+}
+
+// HasClientCertificate 是否配置了mTLS客户端证书
+func (am *AuthManager) HasClientCertificate() bool {
+	return len(am.clientCerts) > 0
+}
+
+// TLSConfig 生成包含客户端证书和CA证书包的TLS配置
+func (am *AuthManager) TLSConfig(insecureSkipVerify bool) *tls.Config {
+	return &tls.Config{
+		InsecureSkipVerify: insecureSkipVerify,
+		Certificates:       am.clientCerts,
+		RootCAs:            am.rootCAs,
+	}
+}
+
+// NewTransport 创建带mTLS配置的HTTP Transport（保留keep-alive连接池）
+func (am *AuthManager) NewTransport(insecureSkipVerify bool) *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:       am.TLSConfig(insecureSkipVerify),
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   20,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// WrapTransport 用认证RoundTripper包装已有Transport
+// 如果base是*http.Transport，会把客户端证书和CA合并进它的TLS配置
+func (am *AuthManager) WrapTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = am.NewTransport(am.insecureSkipVerify)
+	}
+	if t, ok := base.(*http.Transport); ok {
+		t = t.Clone()
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{}
+		}
+		if len(am.clientCerts) > 0 {
+			t.TLSClientConfig.Certificates = am.clientCerts
+		}
+		if am.rootCAs != nil {
+			t.TLSClientConfig.RootCAs = am.rootCAs
+		}
+		base = t
+	}
+	return &authRoundTripper{base: base, am: am}
+}
+
+// ApplyToClient 为已有HTTP客户端启用认证（辅助探测器使用）
+func (am *AuthManager) ApplyToClient(client *http.Client) {
+	if client == nil {
+		return
+	}
+	base := client.Transport
+	if base == nil {
+		base = am.NewTransport(am.insecureSkipVerify)
+	}
+	client.Transport = am.WrapTransport(base)
+}
+
+// inScope 判断是否应向该Host发送凭据
+func (am *AuthManager) inScope(host string) bool {
+	host = strings.ToLower(host)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if len(am.domains) == 0 {
+		return true
+	}
+	for _, d := range am.domains {
+		if strings.HasPrefix(d, "*.") {
+			suffix := d[1:]
+			if strings.HasSuffix(host, suffix) || host == d[2:] {
+				return true
+			}
+			continue
+		}
+		if host == d {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthorizationHeader 获取当前请求应携带的Authorization头（Digest除外，Digest需要质询）
+func (am *AuthManager) AuthorizationHeader() (string, error) {
+	switch am.authType {
+	case AuthTypeBasic:
+		cred := am.settings.Username + ":" + am.settings.Password
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(cred)), nil
+	case AuthTypeBearer:
+		if am.settings.BearerToken == "" {
+			return "", nil
+		}
+		return "Bearer " + am.settings.BearerToken, nil
+	case AuthTypeOAuth2:
+		token, err := am.getAccessToken(false)
+		if err != nil {
+			return "", err
+		}
+		return "Bearer " + token, nil
+	}
+	return "", nil
+}
+
+// authorize 为请求添加认证头
+func (am *AuthManager) authorize(req *http.Request) error {
+	if req.Header.Get("Authorization") != "" {
+		return nil
+	}
+
+	if am.authType == AuthTypeDigest {
+		// 已有质询时预先计算，避免每个请求都先收到401
+		am.digestMutex.Lock()
+		challenge := am.digestChallenges[req.URL.Host]
+		am.digestMutex.Unlock()
+		if challenge != nil {
+			req.Header.Set("Authorization", am.digestAuthorization(challenge, req.Method, req.URL.RequestURI()))
+		}
+		return nil
+	}
+
+	header, err := am.AuthorizationHeader()
+	if err != nil {
+		return err
+	}
+	if header != "" {
+		req.Header.Set("Authorization", header)
+		am.statsMutex.Lock()
+		am.authorizedCount++
+		am.statsMutex.Unlock()
+	}
+	return nil
+}
+
+// getAccessToken 获取OAuth2 Token，在过期前自动刷新
+func (am *AuthManager) getAccessToken(forceRefresh bool) (string, error) {
+	am.tokenMutex.Lock()
+	defer am.tokenMutex.Unlock()
+
+	if !forceRefresh && am.accessToken != "" && time.Now().Add(am.refreshBefore).Before(am.tokenExpiry) {
+		return am.accessToken, nil
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	if len(am.settings.Scopes) > 0 {
+		form.Set("scope", strings.Join(am.settings.Scopes, " "))
+	}
+
+	req, err := http.NewRequest("POST", am.settings.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("创建Token请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(am.settings.ClientID), url.QueryEscape(am.settings.ClientSecret))
+
+	resp, err := am.tokenClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("请求Token端点失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+	if err != nil {
+		return "", fmt.Errorf("读取Token响应失败: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Token端点返回 HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var tokenResp oauth2TokenResponse
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return "", fmt.Errorf("解析Token响应失败: %v", err)
+	}
+	if tokenResp.AccessToken == "" {
+		return "", fmt.Errorf("Token响应中缺少access_token")
+	}
+
+	am.accessToken = tokenResp.AccessToken
+	if tokenResp.ExpiresIn > 0 {
+		am.tokenExpiry = time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+	} else {
+		// 未返回有效期时按1小时处理
+		am.tokenExpiry = time.Now().Add(time.Hour)
+	}
+
+	am.statsMutex.Lock()
+	am.tokenRefreshes++
+	am.statsMutex.Unlock()
+
+	fmt.Printf("[认证] OAuth2 Token已刷新，有效期至 %s\n", am.tokenExpiry.Format("15:04:05"))
+	return am.accessToken, nil
+}
+
+// parseDigestChallenge 解析WWW-Authenticate中的Digest质询
+func parseDigestChallenge(header string) *digestChallenge {
+	if !strings.HasPrefix(strings.ToLower(strings.TrimSpace(header)), "digest ") {
+		return nil
+	}
+	header = strings.TrimSpace(header)[len("Digest "):]
+
+	params := make(map[string]string)
+	for _, part := range splitDigestParams(header) {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(kv[0]))
+		params[key] = strings.Trim(strings.TrimSpace(kv[1]), `"`)
+	}
+
+	if params["nonce"] == "" {
+		return nil
+	}
+
+	qop := ""
+	for _, q := range strings.Split(params["qop"], ",") {
+		if strings.TrimSpace(q) == "auth" {
+			qop = "auth"
+			break
+		}
+	}
+
+	return &digestChallenge{
+		realm:     params["realm"],
+		nonce:     params["nonce"],
+		opaque:    params["opaque"],
+		algorithm: params["algorithm"],
+		qop:       qop,
+	}
+}
+
+// splitDigestParams 按逗号拆分参数（忽略引号内的逗号）
+func splitDigestParams(s string) []string {
+	parts := make([]string, 0)
+	inQuote := false
+	start := 0
+	for i, c := range s {
+		switch c {
+		case '"':
+			inQuote = !inQuote
+		case ',':
+			if !inQuote {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, s[start:])
+	return parts
+}
+
+// digestAuthorization 根据质询计算Digest Authorization头
+func (am *AuthManager) digestAuthorization(c *digestChallenge, method, uri string) string {
+	var h func() hash.Hash = md5.New
+	algorithm := strings.ToUpper(c.algorithm)
+	if strings.HasPrefix(algorithm, "SHA-256") {
+		h = sha256.New
+	}
+	hexHash := func(s string) string {
+		hh := h()
+		hh.Write([]byte(s))
+		return hex.EncodeToString(hh.Sum(nil))
+	}
+
+	am.digestMutex.Lock()
+	c.nc++
+	nc := fmt.Sprintf("%08x", c.nc)
+	am.digestMutex.Unlock()
+
+	cnonceBytes := make([]byte, 8)
+	rand.Read(cnonceBytes)
+	cnonce := hex.EncodeToString(cnonceBytes)
+
+	ha1 := hexHash(am.settings.Username + ":" + c.realm + ":" + am.settings.Password)
+	if strings.HasSuffix(algorithm, "-SESS") {
+		ha1 = hexHash(ha1 + ":" + c.nonce + ":" + cnonce)
+	}
+	ha2 := hexHash(method + ":" + uri)
+
+	var response string
+	if c.qop == "auth" {
+		response = hexHash(ha1 + ":" + c.nonce + ":" + nc + ":" + cnonce + ":" + c.qop + ":" + ha2)
+	} else {
+		response = hexHash(ha1 + ":" + c.nonce + ":" + ha2)
+	}
+
+	parts := []string{
+		fmt.Sprintf(`username="%s"`, am.settings.Username),
+		fmt.Sprintf(`realm="%s"`, c.realm),
+		fmt.Sprintf(`nonce="%s"`, c.nonce),
+		fmt.Sprintf(`uri="%s"`, uri),
+		fmt.Sprintf(`response="%s"`, response),
+	}
+	if c.algorithm != "" {
+		parts = append(parts, "algorithm="+c.algorithm)
+	}
+	if c.opaque != "" {
+		parts = append(parts, fmt.Sprintf(`opaque="%s"`, c.opaque))
+	}
+	if c.qop != "" {
+		parts = append(parts, "qop="+c.qop, "nc="+nc, fmt.Sprintf(`cnonce="%s"`, cnonce))
+	}
+	return "Digest " + strings.Join(parts, ", ")
+}
+
+// authRoundTripper 自动添加认证信息的RoundTripper
+// 收到401时：Digest根据质询重算后重试，OAuth2强制刷新Token后重试（各一次）
+type authRoundTripper struct {
+	base http.RoundTripper
+	am   *AuthManager
+}
+
+// RoundTrip 实现http.RoundTripper接口
+func (t *authRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.am.inScope(req.URL.Host) || t.am.authType == AuthTypeNone {
+		return t.base.RoundTrip(req)
+	}
+
+	authReq := req.Clone(req.Context())
+	if err := t.am.authorize(authReq); err != nil {
+		fmt.Printf("[认证] 添加认证信息失败 %s: %v\n", req.URL, err)
+	}
+
+	resp, err := t.base.RoundTrip(authReq)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	// 请求体无法重放时不重试
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+
+	retryReq := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, bodyErr := req.GetBody()
+		if bodyErr != nil {
+			return resp, nil
+		}
+		retryReq.Body = body
+	}
+
+	switch t.am.authType {
+	case AuthTypeDigest:
+		challenge := parseDigestChallenge(resp.Header.Get("WWW-Authenticate"))
+		if challenge == nil {
+			return resp, nil
+		}
+		t.am.digestMutex.Lock()
+		t.am.digestChallenges[req.URL.Host] = challenge
+		t.am.digestMutex.Unlock()
+		retryReq.Header.Set("Authorization", t.am.digestAuthorization(challenge, req.Method, req.URL.RequestURI()))
+
+		t.am.statsMutex.Lock()
+		t.am.digestRetries++
+		t.am.statsMutex.Unlock()
+
+	case AuthTypeOAuth2:
+		token, tokenErr := t.am.getAccessToken(true)
+		if tokenErr != nil {
+			return resp, nil
+		}
+		retryReq.Header.Set("Authorization", "Bearer "+token)
+
+	default:
+		return resp, nil
+	}
+
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+	return t.base.RoundTrip(retryReq)
+}
+
+// AttachToBrowser 为无头浏览器启用认证（必须在导航前调用）
+// Basic/Digest 通过 Fetch.authRequired 响应质询；Bearer/OAuth2 在请求暂停时注入 Authorization 头；
+// 配置了客户端证书时，作用域内的请求由Go客户端代为发送（Chrome无法通过CDP加载客户端证书）
+func (am *AuthManager) AttachToBrowser(ctx context.Context, insecureSkipVerify bool) error {
+	var proxyClient *http.Client
+	if am.HasClientCertificate() {
+		proxyClient = &http.Client{
+			Transport: am.WrapTransport(am.NewTransport(insecureSkipVerify)),
+			Timeout:   60 * time.Second,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				// 重定向交给浏览器处理
+				return http.ErrUseLastResponse
+			},
+		}
+	}
+
+	chromedp.ListenTarget(ctx, func(ev interface{}) {
+		switch e := ev.(type) {
+		case *fetch.EventAuthRequired:
+			go func() {
+				resp := &fetch.AuthChallengeResponse{
+					Response: fetch.AuthChallengeResponseResponseCancelAuth,
+				}
+				if (am.authType == AuthTypeBasic || am.authType == AuthTypeDigest) && am.inScope(hostOf(e.Request.URL)) {
+					resp = &fetch.AuthChallengeResponse{
+						Response: fetch.AuthChallengeResponseResponseProvideCredentials,
+						Username: am.settings.Username,
+						Password: am.settings.Password,
+					}
+				}
+				chromedp.Run(ctx, fetch.ContinueWithAuth(e.RequestID, resp))
+			}()
+
+		case *fetch.EventRequestPaused:
+			go am.handlePausedRequest(ctx, e, proxyClient)
+		}
+	})
+
+	return chromedp.Run(ctx, fetch.Enable().
+		WithHandleAuthRequests(true).
+		WithPatterns([]*fetch.RequestPattern{{URLPattern: "*"}}))
+}
+
+// handlePausedRequest 处理浏览器中被暂停的请求
+func (am *AuthManager) handlePausedRequest(ctx context.Context, e *fetch.EventRequestPaused, proxyClient *http.Client) {
+	if !am.inScope(hostOf(e.Request.URL)) {
+		chromedp.Run(ctx, fetch.ContinueRequest(e.RequestID))
+		return
+	}
+
+	// mTLS：由Go客户端发送并回填响应
+	if proxyClient != nil {
+		if err := am.fulfillViaClient(ctx, e, proxyClient); err == nil {
+			return
+		}
+	}
+
+	// Bearer/OAuth2/Basic：注入Authorization头后继续
+	authHeader := ""
+	if am.authType == AuthTypeBearer || am.authType == AuthTypeOAuth2 || am.authType == AuthTypeBasic {
+		authHeader, _ = am.AuthorizationHeader()
+	}
+	if authHeader == "" {
+		chromedp.Run(ctx, fetch.ContinueRequest(e.RequestID))
+		return
+	}
+
+	headers := make([]*fetch.HeaderEntry, 0, len(e.Request.Headers)+1)
+	for name, value := range e.Request.Headers {
+		if strings.EqualFold(name, "Authorization") {
+			continue
+		}
+		headers = append(headers, &fetch.HeaderEntry{Name: name, Value: fmt.Sprintf("%v", value)})
+	}
+	headers = append(headers, &fetch.HeaderEntry{Name: "Authorization", Value: authHeader})
+	chromedp.Run(ctx, fetch.ContinueRequest(e.RequestID).WithHeaders(headers))
+}
+
+// fulfillViaClient 使用带客户端证书的Go客户端代发请求，并把响应交给浏览器
+func (am *AuthManager) fulfillViaClient(ctx context.Context, e *fetch.EventRequestPaused, client *http.Client) error {
+	var body io.Reader
+	if e.Request.HasPostData {
+		body = strings.NewReader(e.Request.PostData)
+	}
+	req, err := http.NewRequestWithContext(ctx, e.Request.Method, e.Request.URL, body)
+	if err != nil {
+		return err
+	}
+	for name, value := range e.Request.Headers {
+		req.Header.Set(name, fmt.Sprintf("%v", value))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		chromedp.Run(ctx, fetch.FailRequest(e.RequestID, network.ErrorReasonConnectionFailed))
+		return nil
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 20*1024*1024))
+	if err != nil {
+		chromedp.Run(ctx, fetch.FailRequest(e.RequestID, network.ErrorReasonFailed))
+		return nil
+	}
+
+	headers := make([]*fetch.HeaderEntry, 0, len(resp.Header))
+	for name, values := range resp.Header {
+		// Go客户端已经解压，避免浏览器重复解码
+		if strings.EqualFold(name, "Content-Encoding") || strings.EqualFold(name, "Content-Length") {
+			continue
+		}
+		for _, v := range values {
+			headers = append(headers, &fetch.HeaderEntry{Name: name, Value: v})
+		}
+	}
+
+	return chromedp.Run(ctx, fetch.FulfillRequest(e.RequestID, int64(resp.StatusCode)).
+		WithResponseHeaders(headers).
+		WithBody(base64.StdEncoding.EncodeToString(data)))
+}
+
+// hostOf 提取URL中的Host
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// PrintSummary 打印认证配置摘要
+func (am *AuthManager) PrintSummary() {
+	authType := am.authType
+	if authType == "" {
+		authType = "none"
+	}
+	fmt.Printf("[认证] 类型: %s | 客户端证书: %v | 自定义CA: %v | 作用域: %s\n",
+		authType, am.HasClientCertificate(), am.rootCAs != nil, strings.Join(am.domains, ", "))
+}
+
+// GetStatistics 获取认证统计
+func (am *AuthManager) GetStatistics() map[string]int {
+	am.statsMutex.Lock()
+	defer am.statsMutex.Unlock()
+	return map[string]int{
+		"authorized_requests": am.authorizedCount,
+		"token_refreshes":     am.tokenRefreshes,
+		"digest_retries":      am.digestRetries,
+	}
+}
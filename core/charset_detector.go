@@ -0,0 +1,250 @@
+package core
+
+import (
+	"bytes"
+	"mime"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/saintfish/chardet"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// 🆕 v4.9: 字符集检测与转码
+// 大量中文站点仍使用 GBK/GB2312/Big5，原始字节直接作为字符串保存会导致
+// 表单字段名、链接文本、敏感信息匹配和表单关键词匹配全部乱码。
+// 检测顺序：BOM → Content-Type响应头 → <meta>/XML声明 → 内容嗅探
+
+// 字符集来源（Result.CharsetSource）
+const (
+	CharsetSourceBOM     = "bom"
+	CharsetSourceHeader  = "header"
+	CharsetSourceMeta    = "meta"
+	CharsetSourceSniff   = "sniff"
+	CharsetSourceDefault = "default"
+	CharsetSourceBrowser = "browser" // 无头浏览器已解码（document.characterSet）
+)
+
+// charsetMetaScanSize 在响应前多少字节中查找 <meta> 声明（HTML5规范为1024，放宽以兼容）
+const charsetMetaScanSize = 4096
+
+var (
+	metaCharsetPattern = regexp.MustCompile(`(?i)<meta[^>]+charset\s*=\s*["']?\s*([A-Za-z0-9_\-:.]+)`)
+	xmlEncodingPattern = regexp.MustCompile(`(?i)^\s*<\?xml[^>]+encoding\s*=\s*["']([A-Za-z0-9_\-:.]+)["']`)
+	cssCharsetPattern  = regexp.MustCompile(`(?i)^@charset\s+["']([A-Za-z0-9_\-:.]+)["']`)
+)
+
+// charsetAliases 规范化别名：GB2312/GBK统一按超集GB18030解码
+var charsetAliases = map[string]string{
+	"gb2312":            "gb18030",
+	"gbk":               "gb18030",
+	"x-gbk":             "gb18030",
+	"gb-18030":          "gb18030",
+	"cp936":             "gb18030",
+	"windows-936":       "gb18030",
+	"euc-cn":            "gb18030",
+	"hz-gb-2312":        "gb18030",
+	"big5-hkscs":        "big5",
+	"x-big5":            "big5",
+	"cp950":             "big5",
+	"utf8":              "utf-8",
+	"unicode-1-1-utf-8": "utf-8",
+	"sjis":              "shift_jis",
+	"x-sjis":            "shift_jis",
+	"cp932":             "shift_jis",
+	"ms932":             "shift_jis",
+	"windows-31j":       "shift_jis",
+	"ks_c_5601-1987":    "euc-kr",
+	"cp949":             "euc-kr",
+}
+
+// CharsetDetection 字符集检测结果
+type CharsetDetection struct {
+	Charset string // 规范化后的字符集名称（小写）
+	Source  string // 检测来源
+}
+
+// DetectCharset 检测响应体字符集
+func DetectCharset(contentType string, body []byte) CharsetDetection {
+	// 1. BOM
+	if cs := charsetFromBOM(body); cs != "" {
+		return CharsetDetection{Charset: cs, Source: CharsetSourceBOM}
+	}
+
+	// 2. Content-Type 响应头
+	if cs := CharsetFromContentType(contentType); cs != "" && lookupEncoding(cs) != nil {
+		// 响应头声明UTF-8但内容不是合法UTF-8时不可信，继续检测
+		if !isUTF8Charset(cs) || utf8.Valid(body) {
+			return CharsetDetection{Charset: cs, Source: CharsetSourceHeader}
+		}
+	}
+
+	// 3. <meta charset> / <meta http-equiv> / XML声明 / CSS @charset
+	if cs := charsetFromDocument(body); cs != "" && lookupEncoding(cs) != nil {
+		if !isUTF8Charset(cs) || utf8.Valid(body) {
+			return CharsetDetection{Charset: cs, Source: CharsetSourceMeta}
+		}
+	}
+
+	// 4. 内容嗅探
+	if utf8.Valid(body) {
+		return CharsetDetection{Charset: "utf-8", Source: CharsetSourceDefault}
+	}
+	if cs := sniffCharset(body); cs != "" {
+		return CharsetDetection{Charset: cs, Source: CharsetSourceSniff}
+	}
+
+	// 非UTF-8且无法识别：中文站点最常见的是GBK
+	return CharsetDetection{Charset: "gb18030", Source: CharsetSourceSniff}
+}
+
+// DecodeToUTF8 检测字符集并将响应体转为UTF-8（去除BOM）
+// 转码失败时返回原始内容
+func DecodeToUTF8(contentType string, body []byte) ([]byte, CharsetDetection) {
+	detection := DetectCharset(contentType, body)
+	if len(body) == 0 {
+		return body, detection
+	}
+
+	decoded, err := TranscodeToUTF8(body, detection.Charset)
+	if err != nil {
+		return body, detection
+	}
+	return decoded, detection
+}
+
+// TranscodeToUTF8 将指定字符集的内容转为UTF-8
+func TranscodeToUTF8(body []byte, charset string) ([]byte, error) {
+	if isUTF8Charset(charset) {
+		return bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")), nil
+	}
+	enc := lookupEncoding(charset)
+	if enc == nil {
+		return body, nil
+	}
+	// UTF-16解码器会自动处理BOM
+	return enc.NewDecoder().Bytes(body)
+}
+
+// CharsetFromContentType 从Content-Type中提取charset参数（规范化）
+func CharsetFromContentType(contentType string) string {
+	if contentType == "" {
+		return ""
+	}
+	if _, params, err := mime.ParseMediaType(contentType); err == nil {
+		if cs := params["charset"]; cs != "" {
+			return NormalizeCharset(cs)
+		}
+		return ""
+	}
+	// 不规范的Content-Type（如 "text/html;charset=gbk;"）手动解析
+	lower := strings.ToLower(contentType)
+	idx := strings.Index(lower, "charset=")
+	if idx < 0 {
+		return ""
+	}
+	cs := strings.Trim(contentType[idx+len("charset="):], ` "';`)
+	if end := strings.IndexAny(cs, " ;,"); end >= 0 {
+		cs = cs[:end]
+	}
+	return NormalizeCharset(cs)
+}
+
+// NormalizeCharset 规范化字符集名称
+func NormalizeCharset(charset string) string {
+	cs := strings.ToLower(strings.Trim(strings.TrimSpace(charset), `"'`))
+	if alias, ok := charsetAliases[cs]; ok {
+		return alias
+	}
+	return cs
+}
+
+// isUTF8Charset 是否为UTF-8
+func isUTF8Charset(charset string) bool {
+	return charset == "utf-8" || charset == "utf8"
+}
+
+// lookupEncoding 按WHATWG编码标签查找解码器
+func lookupEncoding(charset string) encoding.Encoding {
+	switch charset {
+	case "utf-16", "utf-16le":
+		return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM)
+	case "utf-16be":
+		return unicode.UTF16(unicode.BigEndian, unicode.UseBOM)
+	}
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil
+	}
+	return enc
+}
+
+// charsetFromBOM 根据BOM判断字符集
+func charsetFromBOM(body []byte) string {
+	switch {
+	case bytes.HasPrefix(body, []byte("\xef\xbb\xbf")):
+		return "utf-8"
+	case bytes.HasPrefix(body, []byte("\xfe\xff")):
+		return "utf-16be"
+	case bytes.HasPrefix(body, []byte("\xff\xfe")):
+		return "utf-16le"
+	}
+	return ""
+}
+
+// charsetFromDocument 从文档头部的声明中提取字符集
+func charsetFromDocument(body []byte) string {
+	head := body
+	if len(head) > charsetMetaScanSize {
+		head = head[:charsetMetaScanSize]
+	}
+	for _, pattern := range []*regexp.Regexp{xmlEncodingPattern, cssCharsetPattern, metaCharsetPattern} {
+		if m := pattern.FindSubmatch(head); m != nil {
+			return NormalizeCharset(string(m[1]))
+		}
+	}
+	return ""
+}
+
+// sniffCharset 基于内容统计猜测字符集
+func sniffCharset(body []byte) string {
+	sample := body
+	if len(sample) > 64*1024 {
+		sample = sample[:64*1024]
+	}
+	results, err := chardet.NewTextDetector().DetectAll(sample)
+	if err != nil {
+		return ""
+	}
+	for _, r := range results {
+		if r.Confidence < 30 {
+			break
+		}
+		cs := NormalizeCharset(r.Charset)
+		if cs == "utf-8" || lookupEncoding(cs) == nil {
+			continue
+		}
+		return cs
+	}
+	return ""
+}
+
+// IsTextualContentType 是否为需要字符集处理的文本类型（空类型视为文本）
+func IsTextualContentType(contentType string) bool {
+	mediaType := parseMediaType(contentType)
+	switch {
+	case mediaType == "":
+		return true
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	case strings.HasSuffix(mediaType, "+xml"), strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "/xml"), strings.HasSuffix(mediaType, "/json"):
+		return true
+	case strings.Contains(mediaType, "javascript"), strings.Contains(mediaType, "ecmascript"):
+		return true
+	}
+	return false
+}
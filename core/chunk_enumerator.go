@@ -0,0 +1,476 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dop251/goja/ast"
+	"github.com/dop251/goja/parser"
+	"github.com/dop251/goja/token"
+)
+
+// 🆕 v4.9: Webpack/Vite 分块枚举
+// SPA 的大部分路由位于按需加载的分块中，HTML里从不引用。这里从运行时代码和构建清单中计算出全部分块URL：
+//   - webpack 运行时：__webpack_require__.u / jsonpScriptSrc 中的 chunkId → hash 映射表
+//   - Vite/Rollup：import("./x.js")、静态 import/export from、__vite__mapDeps 依赖数组
+//   - Next.js：_buildManifest.js 中的 static/chunks 路径
+//   - 构建清单：asset-manifest.json（CRA）、.vite/manifest.json、webpack-manifest-plugin 的 manifest.json
+
+// 分块来源
+const (
+	ChunkSourceWebpack  = "webpack"
+	ChunkSourceVite     = "vite"
+	ChunkSourceImport   = "import"
+	ChunkSourceNext     = "next"
+	ChunkSourceManifest = "manifest"
+)
+
+// LinkSourceChunk 分块链接来源标签（Result.LinkSources）
+const LinkSourceChunk = "chunk"
+
+// maxChunksPerScript 单个运行时最多枚举的分块数（防止异常映射表导致URL爆炸）
+const maxChunksPerScript = 2000
+
+// BuildManifestPaths 主动探测的构建清单路径
+var BuildManifestPaths = []string{
+	"/asset-manifest.json",
+	"/manifest.json",
+	"/.vite/manifest.json",
+	"/build/manifest.json",
+	"/build/.vite/manifest.json",
+}
+
+var (
+	webpackPublicPathPattern = regexp.MustCompile(`(?:__webpack_require__|\b[A-Za-z_$][\w$]?)\.p\s*=\s*["']([^"']*)["']`)
+	dynamicImportPattern     = regexp.MustCompile(`\bimport\s*\(\s*["'` + "`" + `]([^"'` + "`" + `$]+\.m?js)["'` + "`" + `]\s*\)`)
+	staticImportPattern      = regexp.MustCompile(`(?:^|[;\n}])\s*(?:import|export)\s*(?:[\w$*{}\s,]+?\s*from\s*)?["']([^"']+\.m?js)["']`)
+	viteDepsPattern          = regexp.MustCompile(`__vite__(?:mapDeps|fileDeps)[\s\S]{0,200}?\[([^\]]*)\]`)
+	viteAssetsURLPattern     = regexp.MustCompile(`assetsURL\s*=\s*(?:function\s*\(\s*[\w$]+\s*\)\s*\{\s*return|\(?\s*[\w$]+\s*\)?\s*=>)\s*["']([^"']*)["']\s*\+`)
+	quotedScriptPattern      = regexp.MustCompile(`["']([^"'\s]+\.m?js)["']`)
+	nextChunkPattern         = regexp.MustCompile(`["'](static/(?:chunks|css|[\w-]+/pages)/[^"'\s]+\.js)["']`)
+)
+
+// ChunkEnumeratorStats 分块枚举统计
+type ChunkEnumeratorStats struct {
+	ScriptsAnalyzed int
+	WebpackRuntimes int
+	ManifestsParsed int
+	ChunksBySource  map[string]int
+	TotalChunkURLs  int
+}
+
+// ChunkEnumerator 分块枚举器（并发安全）
+type ChunkEnumerator struct {
+	seen  map[string]bool // 已产出的分块URL（全局去重）
+	stats ChunkEnumeratorStats
+	mutex sync.Mutex
+}
+
+// NewChunkEnumerator 创建分块枚举器
+func NewChunkEnumerator() *ChunkEnumerator {
+	return &ChunkEnumerator{
+		seen:  make(map[string]bool),
+		stats: ChunkEnumeratorStats{ChunksBySource: make(map[string]int)},
+	}
+}
+
+// FromScript 从JS代码中枚举分块URL（返回此前未产出过的绝对URL）
+func (c *ChunkEnumerator) FromScript(jsCode string, scriptURL string) []string {
+	base, err := url.Parse(scriptURL)
+	if err != nil || base.Host == "" {
+		return nil
+	}
+
+	found := make(map[string]string) // 绝对URL → 来源
+
+	// 1. webpack 运行时（需要AST；ES模块语法无法解析时跳过，由下面的正则处理）
+	if webpackChunks := webpackChunkPaths(jsCode); len(webpackChunks) > 0 {
+		c.mutex.Lock()
+		c.stats.WebpackRuntimes++
+		c.mutex.Unlock()
+		publicPath := webpackPublicPath(jsCode)
+		for _, chunk := range webpackChunks {
+			if u := resolveChunkURL(base, publicPath, chunk); u != "" {
+				found[u] = ChunkSourceWebpack
+			}
+		}
+	}
+
+	// 2. 动态/静态 import：相对于当前模块解析
+	for _, pattern := range []*regexp.Regexp{dynamicImportPattern, staticImportPattern} {
+		for _, m := range pattern.FindAllStringSubmatch(jsCode, -1) {
+			if u := resolveModuleURL(base, m[1]); u != "" {
+				if _, ok := found[u]; !ok {
+					found[u] = ChunkSourceImport
+				}
+			}
+		}
+	}
+
+	// 3. Vite 预加载依赖表：相对于 base（assetsURL 前缀，默认 "/"）
+	if m := viteDepsPattern.FindAllStringSubmatch(jsCode, -1); len(m) > 0 {
+		prefix := "/"
+		if a := viteAssetsURLPattern.FindStringSubmatch(jsCode); a != nil {
+			prefix = a[1]
+		}
+		for _, deps := range m {
+			for _, dep := range quotedScriptPattern.FindAllStringSubmatch(deps[1], -1) {
+				if u := resolveChunkURL(base, prefix, dep[1]); u != "" {
+					found[u] = ChunkSourceVite
+				}
+			}
+		}
+	}
+
+	// 4. Next.js 构建清单（相对于 /_next/）
+	if strings.Contains(jsCode, "__BUILD_MANIFEST") || strings.Contains(jsCode, "__SSG_MANIFEST") {
+		for _, m := range nextChunkPattern.FindAllStringSubmatch(jsCode, -1) {
+			if u := resolveChunkURL(base, "/_next/", m[1]); u != "" {
+				found[u] = ChunkSourceNext
+			}
+		}
+	}
+
+	c.mutex.Lock()
+	c.stats.ScriptsAnalyzed++
+	c.mutex.Unlock()
+	return c.record(found)
+}
+
+// FromManifest 从构建清单JSON中枚举分块URL
+// 清单中的路径相对于清单所在目录（.vite/ 目录下的清单相对于其上级目录）
+func (c *ChunkEnumerator) FromManifest(body []byte, manifestURL string) []string {
+	base, err := url.Parse(manifestURL)
+	if err != nil || base.Host == "" {
+		return nil
+	}
+	var data interface{}
+	if err := json.Unmarshal(stripBOM(body), &data); err != nil {
+		return nil
+	}
+
+	dir := path.Dir(base.Path)
+	if path.Base(dir) == ".vite" {
+		dir = path.Dir(dir)
+	}
+	if !strings.HasSuffix(dir, "/") {
+		dir += "/"
+	}
+
+	found := make(map[string]string)
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch value := v.(type) {
+		case map[string]interface{}:
+			for _, child := range value {
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range value {
+				walk(child)
+			}
+		case string:
+			if isScriptPath(value) {
+				if u := resolveChunkURL(base, dir, value); u != "" {
+					found[u] = ChunkSourceManifest
+				}
+			}
+		}
+	}
+	walk(data)
+
+	if len(found) == 0 {
+		return nil // PWA manifest.json 等不含脚本的清单
+	}
+	c.mutex.Lock()
+	c.stats.ManifestsParsed++
+	c.mutex.Unlock()
+	return c.record(found)
+}
+
+// IsBuildManifestURL 是否为构建清单地址
+func IsBuildManifestURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(path.Base(parsed.Path)) {
+	case "asset-manifest.json", "manifest.json", "build-manifest.json", "mix-manifest.json":
+		return true
+	}
+	return false
+}
+
+// record 全局去重并更新统计
+func (c *ChunkEnumerator) record(found map[string]string) []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	urls := make([]string, 0, len(found))
+	for u, source := range found {
+		if c.seen[u] {
+			continue
+		}
+		c.seen[u] = true
+		c.stats.ChunksBySource[source]++
+		c.stats.TotalChunkURLs++
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// GetStatistics 获取统计
+func (c *ChunkEnumerator) GetStatistics() ChunkEnumeratorStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	stats := c.stats
+	stats.ChunksBySource = make(map[string]int, len(c.stats.ChunksBySource))
+	for source, count := range c.stats.ChunksBySource {
+		stats.ChunksBySource[source] = count
+	}
+	return stats
+}
+
+// PrintReport 打印分块枚举报告
+func (c *ChunkEnumerator) PrintReport() {
+	stats := c.GetStatistics()
+	if stats.TotalChunkURLs == 0 {
+		return
+	}
+	fmt.Println("\n【Webpack/Vite 分块枚举】")
+	fmt.Printf("  分析脚本: %d, webpack运行时: %d, 构建清单: %d\n",
+		stats.ScriptsAnalyzed, stats.WebpackRuntimes, stats.ManifestsParsed)
+	sources := make([]string, 0, len(stats.ChunksBySource))
+	for source := range stats.ChunksBySource {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	for _, source := range sources {
+		fmt.Printf("  %-10s %d 个分块\n", source, stats.ChunksBySource[source])
+	}
+	fmt.Printf("  共计 %d 个分块URL\n", stats.TotalChunkURLs)
+}
+
+// ========== webpack 运行时 ==========
+
+// webpackPublicPath 提取 __webpack_require__.p 的字符串值（"auto"或未找到时返回空）
+func webpackPublicPath(jsCode string) string {
+	if m := webpackPublicPathPattern.FindStringSubmatch(jsCode); m != nil && m[1] != "auto" {
+		return m[1]
+	}
+	return ""
+}
+
+// webpackChunkPaths 查找分块文件名函数并对映射表中的每个chunkId求值
+// 识别形如 e => "static/js/" + e + "." + {12:"a1b2"}[e] + ".chunk.js" 的单参数函数
+func webpackChunkPaths(jsCode string) []string {
+	if !strings.Contains(jsCode, ".js") || !strings.Contains(jsCode, "}[") {
+		return nil // 没有 {...}[id] 形式的映射表
+	}
+	program, err := parser.ParseFile(nil, "", jsCode, parser.IgnoreRegExpErrors, parser.WithDisableSourceMaps)
+	if err != nil {
+		return nil
+	}
+
+	paths := make([]string, 0)
+	seen := make(map[string]bool)
+	walkJSAST(program, func(node ast.Node) bool {
+		var params *ast.ParameterList
+		var body ast.Node
+		switch fn := node.(type) {
+		case *ast.FunctionLiteral:
+			params, body = fn.ParameterList, fn.Body
+		case *ast.ArrowFunctionLiteral:
+			params, body = fn.ParameterList, fn.Body
+		default:
+			return true
+		}
+		if params == nil || len(params.List) != 1 {
+			return true
+		}
+		param, ok := params.List[0].Target.(*ast.Identifier)
+		if !ok {
+			return true
+		}
+		expr := chunkFunctionReturn(body)
+		if expr == nil {
+			return true
+		}
+
+		ids := chunkIDs(expr, string(param.Name))
+		if len(ids) == 0 {
+			return true
+		}
+		for _, id := range ids {
+			if len(paths) >= maxChunksPerScript {
+				break
+			}
+			value, ok := evalChunkExpr(expr, string(param.Name), id)
+			if ok && isScriptPath(value) && !seen[value] {
+				seen[value] = true
+				paths = append(paths, value)
+			}
+		}
+		return true
+	})
+	return paths
+}
+
+// chunkFunctionReturn 函数的返回表达式（箭头函数表达式体或仅含return的函数体）
+func chunkFunctionReturn(body ast.Node) ast.Expression {
+	switch b := body.(type) {
+	case *ast.ExpressionBody:
+		return b.Expression
+	case *ast.BlockStatement:
+		if len(b.List) > 0 {
+			if ret, ok := b.List[len(b.List)-1].(*ast.ReturnStatement); ok {
+				return ret.Argument
+			}
+		}
+	}
+	return nil
+}
+
+// chunkIDs 收集 {id: ...}[param] 映射表中的全部chunkId；表达式不是字符串拼接时返回nil
+func chunkIDs(expr ast.Expression, param string) []string {
+	binary, ok := expr.(*ast.BinaryExpression)
+	if !ok || binary.Operator != token.PLUS {
+		return nil
+	}
+
+	ids := make([]string, 0)
+	seen := make(map[string]bool)
+	walkJSAST(expr, func(node ast.Node) bool {
+		bracket, ok := node.(*ast.BracketExpression)
+		if !ok {
+			return true
+		}
+		obj, ok := bracket.Left.(*ast.ObjectLiteral)
+		if !ok {
+			return true
+		}
+		if member, ok := bracket.Member.(*ast.Identifier); !ok || string(member.Name) != param {
+			return true
+		}
+		for _, prop := range obj.Value {
+			if keyed, ok := prop.(*ast.PropertyKeyed); ok {
+				if key := jsPropertyKey(keyed.Key); key != "" && !seen[key] {
+					seen[key] = true
+					ids = append(ids, key)
+				}
+			}
+		}
+		return false
+	})
+	return ids
+}
+
+// evalChunkExpr 以指定chunkId对文件名表达式求值；publicPath（.p）求值为空，由调用方拼接
+func evalChunkExpr(expr ast.Expression, param, id string) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.StringLiteral:
+		return string(e.Value), true
+	case *ast.NumberLiteral:
+		return e.Literal, true
+	case *ast.Identifier:
+		if string(e.Name) == param {
+			return id, true
+		}
+	case *ast.DotExpression:
+		if string(e.Identifier.Name) == "p" {
+			return "", true
+		}
+	case *ast.BracketExpression:
+		if obj, ok := e.Left.(*ast.ObjectLiteral); ok {
+			for _, prop := range obj.Value {
+				if keyed, ok := prop.(*ast.PropertyKeyed); ok && jsPropertyKey(keyed.Key) == id {
+					return evalChunkExpr(keyed.Value, param, id)
+				}
+			}
+			return "", true // 映射表中没有该chunkId，配合 || 回退
+		}
+	case *ast.BinaryExpression:
+		left, lok := evalChunkExpr(e.Left, param, id)
+		right, rok := evalChunkExpr(e.Right, param, id)
+		switch e.Operator {
+		case token.PLUS:
+			return left + right, lok && rok
+		case token.LOGICAL_OR:
+			if lok && left != "" {
+				return left, true
+			}
+			return right, rok
+		}
+	case *ast.ConditionalExpression:
+		// (e === 123 ? "vendors" : e)
+		if test, ok := e.Test.(*ast.BinaryExpression); ok &&
+			(test.Operator == token.STRICT_EQUAL || test.Operator == token.EQUAL) {
+			left, lok := evalChunkExpr(test.Left, param, id)
+			right, rok := evalChunkExpr(test.Right, param, id)
+			if lok && rok {
+				if left == right {
+					return evalChunkExpr(e.Consequent, param, id)
+				}
+				return evalChunkExpr(e.Alternate, param, id)
+			}
+		}
+	}
+	return "", false
+}
+
+// ========== URL 解析 ==========
+
+// isScriptPath 路径是否指向JS文件
+func isScriptPath(p string) bool {
+	if idx := strings.IndexAny(p, "?#"); idx >= 0 {
+		p = p[:idx]
+	}
+	lower := strings.ToLower(p)
+	return (strings.HasSuffix(lower, ".js") || strings.HasSuffix(lower, ".mjs")) && !strings.ContainsAny(p, " \t\n<>{}")
+}
+
+// resolveChunkURL 按 publicPath 解析分块路径
+// publicPath 未知时：带目录的路径相对站点根目录，纯文件名相对当前脚本目录
+func resolveChunkURL(base *url.URL, publicPath, chunk string) string {
+	if !isScriptPath(chunk) {
+		return ""
+	}
+	ref := chunk
+	if !jsIsAbsoluteURL(chunk) && !strings.HasPrefix(chunk, "/") && !strings.HasPrefix(chunk, ".") {
+		switch {
+		case publicPath != "":
+			ref = strings.TrimSuffix(publicPath, "/") + "/" + chunk
+		case strings.Contains(chunk, "/"):
+			ref = "/" + chunk
+		}
+		if !jsIsAbsoluteURL(ref) && !strings.HasPrefix(ref, "/") && !strings.HasPrefix(ref, ".") {
+			ref = "./" + ref
+		}
+	}
+	return resolveModuleURL(base, ref)
+}
+
+// resolveModuleURL 相对于模块URL解析（ES模块语义：裸模块说明符如 "vue" 无法由浏览器直接加载，忽略）
+func resolveModuleURL(base *url.URL, ref string) string {
+	if !jsIsAbsoluteURL(ref) && !strings.HasPrefix(ref, "/") &&
+		!strings.HasPrefix(ref, "./") && !strings.HasPrefix(ref, "../") {
+		return ""
+	}
+	parsed, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	resolved := base.ResolveReference(parsed)
+	if resolved.Scheme != "http" && resolved.Scheme != "https" {
+		return ""
+	}
+	resolved.Fragment = ""
+	return resolved.String()
+}
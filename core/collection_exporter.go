@@ -0,0 +1,690 @@
+package core
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// 🆕 v4.9: Postman / Insomnia 导出
+// 将爬取发现的请求（带参数的GET、表单提交、POST请求、捕获的API调用）导出为Postman v2.1集合和Insomnia v4导出文件，
+// 按 主机 → 路径前缀 分组；Cookie和认证类请求头提取为集合变量，请求中只引用变量
+
+// 请求来源（CollectionRequest.Source）
+const (
+	CollectionSourceLink = "link" // 带查询参数的GET链接
+	CollectionSourceAPI  = "api"  // API链接
+	CollectionSourceForm = "form" // 表单
+	CollectionSourcePOST = "post" // POST请求（表单提交、捕获的请求、API定义示例）
+)
+
+// PostmanSchemaURL Postman集合v2.1格式
+const PostmanSchemaURL = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// collectionSecretHeaders 需要提取为变量的请求头 → 变量名
+var collectionSecretHeaders = map[string]string{
+	"cookie":              "cookie",
+	"authorization":       "authorization",
+	"proxy-authorization": "proxyAuthorization",
+	"x-api-key":           "apiKey",
+	"api-key":             "apiKey",
+	"x-auth-token":        "authToken",
+	"x-access-token":      "accessToken",
+	"x-csrf-token":        "csrfToken",
+	"x-xsrf-token":        "xsrfToken",
+}
+
+// CollectionParam 有序的参数（表单字段、urlencoded请求体）
+type CollectionParam struct {
+	Name  string
+	Value string
+}
+
+// CollectionRequest 导出的一个请求
+type CollectionRequest struct {
+	Method      string
+	URL         string
+	Headers     map[string]string
+	Body        string            // 原始请求体
+	ContentType string            // 请求体类型
+	Params      []CollectionParam // 表单字段（urlencoded/multipart），优先于Body
+	Source      string
+}
+
+// collectionVariable 集合变量
+type collectionVariable struct {
+	Key   string
+	Value string
+}
+
+// collectionDefaultHeader 附加到范围内所有请求的请求头（如配置的Cookie和认证）
+type collectionDefaultHeader struct {
+	name    string
+	value   string
+	applies func(rawURL string) bool
+}
+
+// CollectionExporter Postman/Insomnia导出器
+type CollectionExporter struct {
+	name        string
+	maxRequests int
+	requests    []*CollectionRequest
+	seen        map[string]bool
+
+	variables     []collectionVariable
+	variableByKey map[string]string // 请求头名 + 值 → 变量名
+	variableNames map[string]bool
+
+	defaultHeaders []collectionDefaultHeader
+	schemaLookup   func(method, rawURL string) *APIEndpointSchema
+}
+
+// NewCollectionExporter 创建导出器；maxRequests<=0 表示不限制
+func NewCollectionExporter(name string, maxRequests int) *CollectionExporter {
+	return &CollectionExporter{
+		name:          name,
+		maxRequests:   maxRequests,
+		requests:      make([]*CollectionRequest, 0),
+		seen:          make(map[string]bool),
+		variables:     make([]collectionVariable, 0),
+		variableByKey: make(map[string]string),
+		variableNames: make(map[string]bool),
+	}
+}
+
+// SetDefaultHeader 为applies范围内的请求附加请求头（值提取为变量），用于爬取时配置的Cookie和认证头
+func (ce *CollectionExporter) SetDefaultHeader(name, value string, applies func(rawURL string) bool) {
+	if value == "" {
+		return
+	}
+	ce.defaultHeaders = append(ce.defaultHeaders, collectionDefaultHeader{name: name, value: value, applies: applies})
+}
+
+// SetSchemaLookup 设置端点Schema查找（见APIAnalyzer.LookupEndpointSchema），有JSON样本的请求在描述中附带响应Schema
+func (ce *CollectionExporter) SetSchemaLookup(lookup func(method, rawURL string) *APIEndpointSchema) {
+	ce.schemaLookup = lookup
+}
+
+// AddRequest 添加请求（按方法+URL+请求体去重），返回是否添加
+func (ce *CollectionExporter) AddRequest(req CollectionRequest) bool {
+	u, err := url.Parse(req.URL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	if ce.maxRequests > 0 && len(ce.requests) >= ce.maxRequests {
+		return false
+	}
+	req.Method = strings.ToUpper(req.Method)
+	if req.Method == "" {
+		req.Method = "GET"
+	}
+	u.Fragment = ""
+	req.URL = u.String()
+
+	key := req.Method + " " + req.URL + "\x00" + req.Body
+	for _, param := range req.Params {
+		key += "\x00" + param.Name
+	}
+	if ce.seen[key] {
+		return false
+	}
+	ce.seen[key] = true
+
+	headers := make(map[string]string, len(req.Headers))
+	for name, value := range req.Headers {
+		headers[name] = value
+	}
+	for _, header := range ce.defaultHeaders {
+		if header.applies != nil && !header.applies(req.URL) {
+			continue
+		}
+		if _, exists := lookupHeader(headers, header.name); !exists {
+			headers[header.name] = header.value
+		}
+	}
+	req.Headers = headers
+	ce.requests = append(ce.requests, &req)
+	return true
+}
+
+// GetRequests 获取已添加的请求
+func (ce *CollectionExporter) GetRequests() []*CollectionRequest {
+	return ce.requests
+}
+
+// collectionFolder 主机/路径前缀分组
+type collectionFolder struct {
+	host     string
+	prefix   string
+	requests []*CollectionRequest
+}
+
+// folders 按 主机 → 路径前缀 分组（排序后输出，保证导出稳定）
+func (ce *CollectionExporter) folders() []*collectionFolder {
+	byKey := make(map[string]*collectionFolder)
+	for _, req := range ce.requests {
+		u, _ := url.Parse(req.URL)
+		prefix := collectionPathPrefix(u.Path)
+		key := u.Host + " " + prefix
+		folder, exists := byKey[key]
+		if !exists {
+			folder = &collectionFolder{host: u.Host, prefix: prefix}
+			byKey[key] = folder
+		}
+		folder.requests = append(folder.requests, req)
+	}
+	folders := make([]*collectionFolder, 0, len(byKey))
+	for _, folder := range byKey {
+		sort.SliceStable(folder.requests, func(i, j int) bool {
+			a, b := folder.requests[i], folder.requests[j]
+			if a.URL != b.URL {
+				return a.URL < b.URL
+			}
+			return a.Method < b.Method
+		})
+		folders = append(folders, folder)
+	}
+	sort.Slice(folders, func(i, j int) bool {
+		if folders[i].host != folders[j].host {
+			return folders[i].host < folders[j].host
+		}
+		return folders[i].prefix < folders[j].prefix
+	})
+	return folders
+}
+
+// prepareHeaders 将敏感请求头的值替换为变量引用，返回排序后的请求头；format为变量引用格式（如 "{{%s}}"）
+func (ce *CollectionExporter) prepareHeaders(headers map[string]string, format string) []CollectionParam {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := make([]CollectionParam, 0, len(names))
+	for _, name := range names {
+		lower := strings.ToLower(name)
+		// 由客户端自动生成的请求头不导出
+		if lower == "content-length" || lower == "host" || strings.HasPrefix(name, ":") {
+			continue
+		}
+		value := headers[name]
+		if variable := ce.variableFor(name, value); variable != "" {
+			value = fmt.Sprintf(format, variable)
+		}
+		result = append(result, CollectionParam{Name: name, Value: value})
+	}
+	return result
+}
+
+// variableFor 敏感请求头对应的变量名（同名请求头的不同取值分配不同变量）；非敏感请求头返回空
+func (ce *CollectionExporter) variableFor(header, value string) string {
+	lower := strings.ToLower(header)
+	base, isSecret := collectionSecretHeaders[lower]
+	if !isSecret {
+		if !strings.Contains(lower, "token") && !strings.Contains(lower, "api-key") && !strings.Contains(lower, "apikey") {
+			return ""
+		}
+		base = collectionVariableName(header)
+	}
+	if value == "" {
+		return ""
+	}
+	key := lower + "\x00" + value
+	if name, exists := ce.variableByKey[key]; exists {
+		return name
+	}
+	name := base
+	for i := 2; ce.variableNames[name]; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	ce.variableNames[name] = true
+	ce.variableByKey[key] = name
+	ce.variables = append(ce.variables, collectionVariable{Key: name, Value: value})
+	return name
+}
+
+// bodyParams 请求体的有序参数（urlencoded/multipart）；其他类型返回nil
+func (req *CollectionRequest) bodyParams() []CollectionParam {
+	if len(req.Params) > 0 {
+		return req.Params
+	}
+	mediaType := collectionMediaType(req.ContentType)
+	if mediaType != "application/x-www-form-urlencoded" || req.Body == "" {
+		return nil
+	}
+	params := make([]CollectionParam, 0)
+	for _, pair := range strings.Split(req.Body, "&") {
+		if pair == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(pair, "=")
+		if decoded, err := url.QueryUnescape(name); err == nil {
+			name = decoded
+		}
+		if decoded, err := url.QueryUnescape(value); err == nil {
+			value = decoded
+		}
+		params = append(params, CollectionParam{Name: name, Value: value})
+	}
+	return params
+}
+
+// hasBody 请求是否带请求体
+func (req *CollectionRequest) hasBody() bool {
+	return req.Body != "" || len(req.Params) > 0
+}
+
+// requestName 请求名称：方法 + 路径 + 查询参数名
+func (req *CollectionRequest) requestName() string {
+	u, _ := url.Parse(req.URL)
+	name := req.Method + " " + u.EscapedPath()
+	if u.Path == "" {
+		name = req.Method + " /"
+	}
+	if query := u.Query(); len(query) > 0 {
+		keys := make([]string, 0, len(query))
+		for key := range query {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		name += "?" + strings.Join(keys, "&")
+	}
+	return name
+}
+
+// description 请求说明：来源和合并推断的响应Schema
+func (ce *CollectionExporter) description(req *CollectionRequest) string {
+	lines := []string{"来源: " + req.Source}
+	if ce.schemaLookup != nil {
+		if schema := ce.schemaLookup(req.Method, req.URL); schema != nil && schema.ResponseSchema != nil {
+			data, err := json.MarshalIndent(schema.ResponseSchema, "", "  ")
+			if err == nil {
+				lines = append(lines, fmt.Sprintf("响应Schema（%s，%d 个样本）:\n```json\n%s\n```", schema.Path, schema.ResponseSamples, data))
+			}
+		}
+	}
+	return strings.Join(lines, "\n\n")
+}
+
+// ==================== Postman v2.1 ====================
+
+type postmanCollection struct {
+	Info     postmanInfo       `json:"info"`
+	Item     []postmanItem     `json:"item"`
+	Variable []postmanKeyValue `json:"variable,omitempty"`
+}
+
+type postmanInfo struct {
+	PostmanID   string `json:"_postman_id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schema      string `json:"schema"`
+}
+
+type postmanItem struct {
+	Name     string          `json:"name"`
+	Item     []postmanItem   `json:"item,omitempty"`
+	Request  *postmanRequest `json:"request,omitempty"`
+	Response []interface{}   `json:"response,omitempty"`
+}
+
+type postmanRequest struct {
+	Method      string            `json:"method"`
+	Header      []postmanKeyValue `json:"header"`
+	Body        *postmanBody      `json:"body,omitempty"`
+	URL         postmanURL        `json:"url"`
+	Description string            `json:"description,omitempty"`
+}
+
+type postmanKeyValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Type  string `json:"type,omitempty"`
+}
+
+type postmanBody struct {
+	Mode       string                 `json:"mode"`
+	Raw        string                 `json:"raw,omitempty"`
+	URLEncoded []postmanKeyValue      `json:"urlencoded,omitempty"`
+	FormData   []postmanKeyValue      `json:"formdata,omitempty"`
+	Options    map[string]interface{} `json:"options,omitempty"`
+}
+
+type postmanURL struct {
+	Raw      string            `json:"raw"`
+	Protocol string            `json:"protocol"`
+	Host     []string          `json:"host"`
+	Port     string            `json:"port,omitempty"`
+	Path     []string          `json:"path,omitempty"`
+	Query    []postmanKeyValue `json:"query,omitempty"`
+}
+
+// buildPostman 生成Postman v2.1集合
+func (ce *CollectionExporter) buildPostman() postmanCollection {
+	id := collectionID(ce.name, 32)
+	collection := postmanCollection{
+		Info: postmanInfo{
+			PostmanID:   id[:8] + "-" + id[8:12] + "-" + id[12:16] + "-" + id[16:20] + "-" + id[20:],
+			Name:        ce.name,
+			Description: fmt.Sprintf("爬取发现的 %d 个请求（生成时间 %s）", len(ce.requests), time.Now().Format("2006-01-02 15:04:05")),
+			Schema:      PostmanSchemaURL,
+		},
+		Item: make([]postmanItem, 0),
+	}
+
+	hostIndex := make(map[string]int)
+	for _, folder := range ce.folders() {
+		index, exists := hostIndex[folder.host]
+		if !exists {
+			collection.Item = append(collection.Item, postmanItem{Name: folder.host, Item: make([]postmanItem, 0)})
+			index = len(collection.Item) - 1
+			hostIndex[folder.host] = index
+		}
+		prefixItem := postmanItem{Name: folder.prefix, Item: make([]postmanItem, 0, len(folder.requests))}
+		for _, req := range folder.requests {
+			prefixItem.Item = append(prefixItem.Item, postmanItem{
+				Name:     req.requestName(),
+				Request:  ce.postmanRequest(req),
+				Response: make([]interface{}, 0),
+			})
+		}
+		collection.Item[index].Item = append(collection.Item[index].Item, prefixItem)
+	}
+
+	// 变量在生成请求时登记，最后输出
+	for _, variable := range ce.variables {
+		collection.Variable = append(collection.Variable, postmanKeyValue{Key: variable.Key, Value: variable.Value, Type: "string"})
+	}
+	return collection
+}
+
+// postmanRequest 转换单个请求
+func (ce *CollectionExporter) postmanRequest(req *CollectionRequest) *postmanRequest {
+	u, _ := url.Parse(req.URL)
+	pmURL := postmanURL{
+		Raw:      req.URL,
+		Protocol: u.Scheme,
+		Host:     strings.Split(u.Hostname(), "."),
+		Port:     u.Port(),
+	}
+	for _, segment := range strings.Split(strings.TrimPrefix(u.EscapedPath(), "/"), "/") {
+		if segment != "" {
+			pmURL.Path = append(pmURL.Path, segment)
+		}
+	}
+	for _, pair := range strings.Split(u.RawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(pair, "=")
+		pmURL.Query = append(pmURL.Query, postmanKeyValue{Key: name, Value: value})
+	}
+
+	pmReq := &postmanRequest{
+		Method:      req.Method,
+		Header:      make([]postmanKeyValue, 0),
+		URL:         pmURL,
+		Description: ce.description(req),
+	}
+	hasContentType := false
+	for _, header := range ce.prepareHeaders(req.Headers, "{{%s}}") {
+		pmReq.Header = append(pmReq.Header, postmanKeyValue{Key: header.Name, Value: header.Value})
+		if strings.EqualFold(header.Name, "Content-Type") {
+			hasContentType = true
+		}
+	}
+	if !req.hasBody() {
+		return pmReq
+	}
+
+	mediaType := collectionMediaType(req.ContentType)
+	switch {
+	case mediaType == "multipart/form-data" && len(req.Params) > 0:
+		body := &postmanBody{Mode: "formdata"}
+		for _, param := range req.Params {
+			body.FormData = append(body.FormData, postmanKeyValue{Key: param.Name, Value: param.Value, Type: "text"})
+		}
+		pmReq.Body = body
+		return pmReq // multipart边界由Postman生成，不输出Content-Type
+	case req.bodyParams() != nil:
+		body := &postmanBody{Mode: "urlencoded"}
+		for _, param := range req.bodyParams() {
+			body.URLEncoded = append(body.URLEncoded, postmanKeyValue{Key: param.Name, Value: param.Value, Type: "text"})
+		}
+		pmReq.Body = body
+	default:
+		body := &postmanBody{Mode: "raw", Raw: req.Body}
+		if language := collectionRawLanguage(mediaType); language != "" {
+			body.Options = map[string]interface{}{"raw": map[string]string{"language": language}}
+		}
+		pmReq.Body = body
+	}
+	if !hasContentType && req.ContentType != "" {
+		pmReq.Header = append(pmReq.Header, postmanKeyValue{Key: "Content-Type", Value: req.ContentType})
+	}
+	return pmReq
+}
+
+// ==================== Insomnia v4 ====================
+
+// buildInsomnia 生成Insomnia v4导出（workspace → 主机分组 → 路径前缀分组 → 请求，变量放在基础环境中）
+func (ce *CollectionExporter) buildInsomnia() map[string]interface{} {
+	workspaceID := "wrk_" + collectionID(ce.name, 32)
+	resources := []map[string]interface{}{
+		{
+			"_id":         workspaceID,
+			"_type":       "workspace",
+			"parentId":    nil,
+			"name":        ce.name,
+			"description": fmt.Sprintf("爬取发现的 %d 个请求", len(ce.requests)),
+			"scope":       "collection",
+		},
+	}
+
+	hostIDs := make(map[string]string)
+	sortKey := 0
+	for _, folder := range ce.folders() {
+		hostID, exists := hostIDs[folder.host]
+		if !exists {
+			hostID = "fld_" + collectionID(ce.name+" "+folder.host, 32)
+			hostIDs[folder.host] = hostID
+			resources = append(resources, map[string]interface{}{
+				"_id":      hostID,
+				"_type":    "request_group",
+				"parentId": workspaceID,
+				"name":     folder.host,
+			})
+		}
+		folderID := "fld_" + collectionID(ce.name+" "+folder.host+" "+folder.prefix, 32)
+		resources = append(resources, map[string]interface{}{
+			"_id":      folderID,
+			"_type":    "request_group",
+			"parentId": hostID,
+			"name":     folder.prefix,
+		})
+		for _, req := range folder.requests {
+			sortKey++
+			resource := ce.insomniaRequest(req)
+			resource["_id"] = "req_" + collectionID(req.Method+" "+req.URL+"\x00"+req.Body, 32)
+			resource["parentId"] = folderID
+			resource["metaSortKey"] = sortKey
+			resources = append(resources, resource)
+		}
+	}
+
+	// 变量在生成请求时登记，环境资源最后加入
+	data := make(map[string]interface{}, len(ce.variables))
+	for _, variable := range ce.variables {
+		data[variable.Key] = variable.Value
+	}
+	resources = append(resources, map[string]interface{}{
+		"_id":      "env_" + collectionID(ce.name+" env", 32),
+		"_type":    "environment",
+		"parentId": workspaceID,
+		"name":     "Base Environment",
+		"data":     data,
+	})
+
+	return map[string]interface{}{
+		"_type":           "export",
+		"__export_format": 4,
+		"__export_date":   time.Now().UTC().Format(time.RFC3339),
+		"__export_source": "spider-golang",
+		"resources":       resources,
+	}
+}
+
+// insomniaRequest 转换单个请求（不含 _id/parentId）
+func (ce *CollectionExporter) insomniaRequest(req *CollectionRequest) map[string]interface{} {
+	headers := make([]map[string]string, 0)
+	hasContentType := false
+	for _, header := range ce.prepareHeaders(req.Headers, "{{ _.%s }}") {
+		headers = append(headers, map[string]string{"name": header.Name, "value": header.Value})
+		if strings.EqualFold(header.Name, "Content-Type") {
+			hasContentType = true
+		}
+	}
+
+	body := map[string]interface{}{}
+	if req.hasBody() {
+		mediaType := collectionMediaType(req.ContentType)
+		switch {
+		case mediaType == "multipart/form-data" && len(req.Params) > 0:
+			params := make([]map[string]string, 0, len(req.Params))
+			for _, param := range req.Params {
+				params = append(params, map[string]string{"name": param.Name, "value": param.Value})
+			}
+			body = map[string]interface{}{"mimeType": mediaType, "params": params}
+		case req.bodyParams() != nil:
+			params := make([]map[string]string, 0)
+			for _, param := range req.bodyParams() {
+				params = append(params, map[string]string{"name": param.Name, "value": param.Value})
+			}
+			body = map[string]interface{}{"mimeType": "application/x-www-form-urlencoded", "params": params}
+		default:
+			if mediaType == "" {
+				mediaType = "text/plain"
+			}
+			body = map[string]interface{}{"mimeType": mediaType, "text": req.Body}
+		}
+		if !hasContentType && req.ContentType != "" && mediaType != "multipart/form-data" {
+			headers = append(headers, map[string]string{"name": "Content-Type", "value": req.ContentType})
+		}
+	}
+
+	return map[string]interface{}{
+		"_type":       "request",
+		"name":        req.requestName(),
+		"description": ce.description(req),
+		"method":      req.Method,
+		"url":         req.URL,
+		"body":        body,
+		"headers":     headers,
+		"parameters":  []interface{}{},
+	}
+}
+
+// ExportPostman 导出Postman集合到文件
+func (ce *CollectionExporter) ExportPostman(filename string) error {
+	return writeCollectionJSON(ce.buildPostman(), filename)
+}
+
+// ExportInsomnia 导出Insomnia文件
+func (ce *CollectionExporter) ExportInsomnia(filename string) error {
+	return writeCollectionJSON(ce.buildInsomnia(), filename)
+}
+
+func writeCollectionJSON(data interface{}, filename string) error {
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, content, 0644)
+}
+
+// collectionPathPrefix 分组用的路径前缀（第一段目录；根目录下的文件归入 /）
+func collectionPathPrefix(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) < 2 || segments[0] == "" {
+		return "/"
+	}
+	return "/" + segments[0]
+}
+
+// collectionMediaType 规范化的请求体类型
+func collectionMediaType(contentType string) string {
+	if contentType == "" {
+		return ""
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	}
+	return mediaType
+}
+
+// collectionRawLanguage Postman raw请求体的语言标记
+func collectionRawLanguage(mediaType string) string {
+	switch {
+	case strings.Contains(mediaType, "json"):
+		return "json"
+	case strings.Contains(mediaType, "xml"):
+		return "xml"
+	case strings.Contains(mediaType, "html"):
+		return "html"
+	case strings.Contains(mediaType, "javascript"):
+		return "javascript"
+	case strings.HasPrefix(mediaType, "text/"):
+		return "text"
+	}
+	return ""
+}
+
+// collectionVariableName 请求头名转为变量名（X-Session-Token → sessionToken）
+func collectionVariableName(header string) string {
+	parts := strings.FieldsFunc(strings.ToLower(header), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(parts) > 1 && parts[0] == "x" {
+		parts = parts[1:]
+	}
+	var b strings.Builder
+	for i, part := range parts {
+		if i > 0 && part != "" {
+			part = strings.ToUpper(part[:1]) + part[1:]
+		}
+		b.WriteString(part)
+	}
+	if b.Len() == 0 {
+		return "header"
+	}
+	return b.String()
+}
+
+// collectionID 生成稳定的ID（同一输入多次导出得到相同ID，便于重复导入时覆盖）
+func collectionID(seed string, length int) string {
+	sum := sha1.Sum([]byte(seed))
+	id := hex.EncodeToString(sum[:])
+	if length < len(id) {
+		id = id[:length]
+	}
+	return id
+}
+
+// lookupHeader 不区分大小写查找请求头
+func lookupHeader(headers map[string]string, name string) (string, bool) {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return "", false
+}
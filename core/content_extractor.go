@@ -0,0 +1,761 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// 🆕 v4.9: 按Content-Type分派的链接提取器
+// HTML由goquery处理、JS由正则处理，其余类型（JSON/RSS/Atom/XML/OpenSearch/纯文本）
+// 之前不产生任何链接，这里通过可插拔的提取器注册表补全。
+
+// 链接来源标签（Result.LinkSources 的值）
+const (
+	LinkSourceHTML         = "html"
+	LinkSourceHeader       = "header"
+	LinkSourceInlineScript = "inline_script"
+	LinkSourceJSON         = "json"
+	LinkSourceFeed         = "feed"
+	LinkSourceOpenSearch   = "opensearch"
+	LinkSourceXML          = "xml"
+	LinkSourceText         = "text"
+	LinkSourceDocument     = "document"
+	LinkSourceJavaScript   = "javascript" // JS文件（AST分析）
+)
+
+// maxExtractBodySize 提取器处理的最大响应体（超出部分截断）
+const maxExtractBodySize = 5 * 1024 * 1024
+
+// ExtractedLink 提取器发现的链接
+type ExtractedLink struct {
+	URL       string // 绝对URL
+	Raw       string // 原始值
+	Extractor string // 产生该链接的提取器名称
+	Context   string // 位置信息（JSON路径 / XML元素 / 行号）
+}
+
+// ContentExtractor 内容提取器接口
+type ContentExtractor interface {
+	// Name 提取器名称（用作链接来源标签）
+	Name() string
+
+	// MatchContentType 是否处理该媒体类型（已小写、不含参数）
+	MatchContentType(mediaType string) bool
+
+	// Sniff 根据内容判断是否可处理（Content-Type缺失或不可信时使用）
+	Sniff(body []byte) bool
+
+	// Extract 提取链接，base为响应URL
+	Extract(body []byte, base *url.URL) []ExtractedLink
+}
+
+// ContentExtractorRegistry 提取器注册表
+type ContentExtractorRegistry struct {
+	extractors []ContentExtractor
+	stats      map[string]int // 提取器 → 发现的链接数
+	mutex      sync.RWMutex
+}
+
+// NewContentExtractorRegistry 创建注册表（含内置提取器）
+// 顺序即优先级：OpenSearch和Feed需要排在通用XML之前
+func NewContentExtractorRegistry() *ContentExtractorRegistry {
+	r := &ContentExtractorRegistry{
+		extractors: make([]ContentExtractor, 0),
+		stats:      make(map[string]int),
+	}
+	r.Register(&JSONExtractor{})
+	r.Register(&OpenSearchExtractor{})
+	r.Register(&FeedExtractor{})
+	r.Register(&XMLExtractor{})
+	r.Register(&TextExtractor{})
+	return r
+}
+
+// Register 注册提取器（追加到末尾）
+func (r *ContentExtractorRegistry) Register(extractor ContentExtractor) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.extractors = append(r.extractors, extractor)
+}
+
+// RegisterFirst 注册提取器并置于最高优先级
+func (r *ContentExtractorRegistry) RegisterFirst(extractor ContentExtractor) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.extractors = append([]ContentExtractor{extractor}, r.extractors...)
+}
+
+// genericMediaTypes 不可信的媒体类型，需要结合内容嗅探
+var genericMediaTypes = map[string]bool{
+	"":                         true,
+	"text/plain":               true,
+	"application/octet-stream": true,
+	"binary/octet-stream":      true,
+	"application/xml":          true,
+	"text/xml":                 true,
+}
+
+// Select 为响应选择提取器
+// 通用类型（text/plain、application/xml、缺失等）按内容嗅探依次选择；
+// 具体类型优先选择类型匹配且嗅探通过的，否则取第一个类型匹配的
+func (r *ContentExtractorRegistry) Select(contentType string, body []byte) ContentExtractor {
+	mediaType := parseMediaType(contentType)
+	if isHTMLMediaType(mediaType) || looksLikeHTML(body) {
+		return nil // HTML由goquery处理
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if genericMediaTypes[mediaType] {
+		for _, e := range r.extractors {
+			if e.Sniff(body) {
+				return e
+			}
+		}
+		return nil
+	}
+
+	var typeMatch ContentExtractor
+	for _, e := range r.extractors {
+		if !e.MatchContentType(mediaType) {
+			continue
+		}
+		if e.Sniff(body) {
+			return e
+		}
+		if typeMatch == nil {
+			typeMatch = e
+		}
+	}
+	return typeMatch
+}
+
+// Extract 选择提取器并提取链接（结果已去重）
+func (r *ContentExtractorRegistry) Extract(contentType string, body []byte, base *url.URL) []ExtractedLink {
+	if len(body) == 0 || base == nil {
+		return nil
+	}
+	if len(body) > maxExtractBodySize {
+		body = body[:maxExtractBodySize]
+	}
+
+	extractor := r.Select(contentType, body)
+	if extractor == nil {
+		return nil
+	}
+
+	links := extractor.Extract(body, base)
+	seen := make(map[string]bool, len(links))
+	unique := make([]ExtractedLink, 0, len(links))
+	for _, link := range links {
+		if link.URL == "" || seen[link.URL] {
+			continue
+		}
+		seen[link.URL] = true
+		if link.Extractor == "" {
+			link.Extractor = extractor.Name()
+		}
+		unique = append(unique, link)
+	}
+
+	if len(unique) > 0 {
+		r.mutex.Lock()
+		r.stats[extractor.Name()] += len(unique)
+		r.mutex.Unlock()
+	}
+	return unique
+}
+
+// GetStatistics 获取各提取器发现的链接数
+func (r *ContentExtractorRegistry) GetStatistics() map[string]int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	stats := make(map[string]int, len(r.stats))
+	for name, count := range r.stats {
+		stats[name] = count
+	}
+	return stats
+}
+
+// PrintStatistics 打印提取器统计
+func (r *ContentExtractorRegistry) PrintStatistics() {
+	stats := r.GetStatistics()
+	if len(stats) == 0 {
+		return
+	}
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Println("\n【内容提取器统计】")
+	for _, name := range names {
+		fmt.Printf("  %-12s %d 个链接\n", name, stats[name])
+	}
+}
+
+// ========== JSON ==========
+
+// JSONExtractor 遍历JSON值，提取URL和路径形式的字符串
+type JSONExtractor struct{}
+
+// Name 提取器名称
+func (e *JSONExtractor) Name() string { return LinkSourceJSON }
+
+// MatchContentType 匹配 application/json、*+json、text/json 等
+func (e *JSONExtractor) MatchContentType(mediaType string) bool {
+	return mediaType == "application/json" ||
+		mediaType == "text/json" ||
+		mediaType == "application/x-ndjson" ||
+		strings.HasSuffix(mediaType, "+json")
+}
+
+// Sniff 以 { 或 [ 开头且为合法JSON
+func (e *JSONExtractor) Sniff(body []byte) bool {
+	trimmed := bytes.TrimSpace(stripBOM(body))
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return false
+	}
+	return json.Valid(trimmed)
+}
+
+// jsonURLKeyHints 这些键的值即使是相对路径也视为链接
+var jsonURLKeyHints = []string{
+	"url", "uri", "href", "link", "src", "path", "endpoint", "next", "prev",
+	"previous", "self", "first", "last", "redirect", "callback", "location",
+	"action", "route", "api",
+}
+
+// Extract 提取链接
+func (e *JSONExtractor) Extract(body []byte, base *url.URL) []ExtractedLink {
+	links := make([]ExtractedLink, 0)
+	body = stripBOM(body)
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	// 支持多文档（NDJSON）
+	for i := 0; ; i++ {
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			break
+		}
+		root := "$"
+		if i > 0 {
+			root = fmt.Sprintf("$%d", i)
+		}
+		e.walk(value, root, "", base, &links)
+	}
+	return links
+}
+
+// walk 递归遍历JSON值
+func (e *JSONExtractor) walk(value interface{}, path, key string, base *url.URL, links *[]ExtractedLink) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			e.walk(child, path+"."+k, k, base, links)
+		}
+	case []interface{}:
+		for i, child := range v {
+			e.walk(child, path+"["+strconv.Itoa(i)+"]", key, base, links)
+		}
+	case string:
+		candidate := strings.TrimSpace(v)
+		if !looksLikeLink(candidate) && !(keyHintsURL(key) && looksLikeRelativePath(candidate)) {
+			return
+		}
+		if abs, ok := resolveExtractedURL(base, candidate); ok {
+			*links = append(*links, ExtractedLink{
+				URL:       abs,
+				Raw:       v,
+				Extractor: LinkSourceJSON,
+				Context:   path,
+			})
+		}
+	}
+}
+
+// keyHintsURL 判断JSON键名是否暗示为URL
+func keyHintsURL(key string) bool {
+	lower := strings.ToLower(key)
+	for _, hint := range jsonURLKeyHints {
+		if lower == hint || strings.HasSuffix(lower, "_"+hint) || strings.HasSuffix(lower, "-"+hint) {
+			return true
+		}
+		// 驼峰命名：nextUrl、avatarHref
+		if strings.HasSuffix(lower, hint) && isUpperBoundary(key, len(key)-len(hint)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isUpperBoundary 判断驼峰命名的分界（如 nextUrl 中的 U）
+func isUpperBoundary(key string, idx int) bool {
+	if idx <= 0 || idx >= len(key) {
+		return false
+	}
+	c := key[idx]
+	return c >= 'A' && c <= 'Z'
+}
+
+// ========== RSS / Atom ==========
+
+// FeedExtractor 解析RSS 2.0 / RSS 1.0 (RDF) / Atom 中的链接
+type FeedExtractor struct{}
+
+// Name 提取器名称
+func (e *FeedExtractor) Name() string { return LinkSourceFeed }
+
+// MatchContentType 匹配 rss/atom/rdf 类型及通用XML
+func (e *FeedExtractor) MatchContentType(mediaType string) bool {
+	switch mediaType {
+	case "application/rss+xml", "application/atom+xml", "application/rdf+xml",
+		"application/xml", "text/xml":
+		return true
+	}
+	return false
+}
+
+// Sniff 根元素为 rss / feed / rdf:RDF
+func (e *FeedExtractor) Sniff(body []byte) bool {
+	switch xmlRootName(body) {
+	case "rss", "feed", "RDF":
+		return true
+	}
+	return false
+}
+
+// feedTextElements 文本内容为链接的元素
+var feedTextElements = map[string]bool{
+	"link": true, "comments": true, "docs": true, "commentRss": true,
+}
+
+// Extract 提取链接
+func (e *FeedExtractor) Extract(body []byte, base *url.URL) []ExtractedLink {
+	links := make([]ExtractedLink, 0)
+	add := func(raw, context string) {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			return
+		}
+		if abs, ok := resolveExtractedURL(base, raw); ok {
+			links = append(links, ExtractedLink{URL: abs, Raw: raw, Extractor: LinkSourceFeed, Context: context})
+		}
+	}
+
+	walkXML(body, func(stack []xml.StartElement, elem xml.StartElement, text string) {
+		name := elem.Name.Local
+		if text != "" {
+			switch {
+			case feedTextElements[name]:
+				add(text, name)
+			case name == "guid" && xmlAttr(elem, "isPermaLink") != "false" && looksLikeLink(text):
+				add(text, name)
+			case name == "id" && looksLikeLink(text):
+				add(text, name) // Atom id 通常为文章URL
+			case (name == "icon" || name == "logo" || name == "url") && looksLikeLink(text):
+				add(text, name)
+			}
+		}
+		// Atom <link href>, <enclosure url>, <content src>, <media:content url>, rdf:about/resource
+		for _, attr := range elem.Attr {
+			switch attr.Name.Local {
+			case "href", "src", "url":
+				add(attr.Value, name+"@"+attr.Name.Local)
+			case "about", "resource":
+				if looksLikeLink(attr.Value) {
+					add(attr.Value, name+"@"+attr.Name.Local)
+				}
+			}
+		}
+	})
+	return links
+}
+
+// ========== OpenSearch ==========
+
+// OpenSearchExtractor 解析OpenSearch描述文档中的搜索URL模板
+type OpenSearchExtractor struct{}
+
+// Name 提取器名称
+func (e *OpenSearchExtractor) Name() string { return LinkSourceOpenSearch }
+
+// MatchContentType 匹配 opensearchdescription+xml 及通用XML
+func (e *OpenSearchExtractor) MatchContentType(mediaType string) bool {
+	return mediaType == "application/opensearchdescription+xml" ||
+		mediaType == "application/xml" || mediaType == "text/xml"
+}
+
+// Sniff 根元素为 OpenSearchDescription
+func (e *OpenSearchExtractor) Sniff(body []byte) bool {
+	return xmlRootName(body) == "OpenSearchDescription"
+}
+
+// openSearchParamPattern 模板参数 {searchTerms} / {startPage?} / {ns:param}
+var openSearchParamPattern = regexp.MustCompile(`\{([A-Za-z0-9_:.-]+)(\??)\}`)
+
+// Extract 提取链接（模板参数替换为示例值）
+func (e *OpenSearchExtractor) Extract(body []byte, base *url.URL) []ExtractedLink {
+	links := make([]ExtractedLink, 0)
+	add := func(raw, context string) {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			return
+		}
+		if abs, ok := resolveExtractedURL(base, expandOpenSearchTemplate(raw)); ok {
+			links = append(links, ExtractedLink{URL: abs, Raw: raw, Extractor: LinkSourceOpenSearch, Context: context})
+		}
+	}
+
+	walkXML(body, func(stack []xml.StartElement, elem xml.StartElement, text string) {
+		switch elem.Name.Local {
+		case "Url":
+			add(xmlAttr(elem, "template"), "Url@"+xmlAttr(elem, "type"))
+		case "Image", "SearchForm":
+			add(text, elem.Name.Local)
+		}
+	})
+	return links
+}
+
+// expandOpenSearchTemplate 替换模板参数
+func expandOpenSearchTemplate(template string) string {
+	return openSearchParamPattern.ReplaceAllStringFunc(template, func(m string) string {
+		parts := openSearchParamPattern.FindStringSubmatch(m)
+		name := parts[1]
+		if idx := strings.LastIndex(name, ":"); idx >= 0 {
+			name = name[idx+1:]
+		}
+		switch name {
+		case "searchTerms":
+			return "test"
+		case "count":
+			return "10"
+		case "startIndex", "startPage":
+			return "1"
+		case "language":
+			return "en"
+		case "inputEncoding", "outputEncoding":
+			return "UTF-8"
+		}
+		if parts[2] == "?" {
+			return "" // 可选参数留空
+		}
+		return "1"
+	})
+}
+
+// ========== 通用XML ==========
+
+// XMLExtractor 通用XML：链接类属性和链接类元素文本
+type XMLExtractor struct{}
+
+// Name 提取器名称
+func (e *XMLExtractor) Name() string { return LinkSourceXML }
+
+// MatchContentType 匹配 application/xml、text/xml、*+xml
+func (e *XMLExtractor) MatchContentType(mediaType string) bool {
+	return mediaType == "application/xml" || mediaType == "text/xml" ||
+		strings.HasSuffix(mediaType, "+xml")
+}
+
+// Sniff 以XML声明或元素开头
+func (e *XMLExtractor) Sniff(body []byte) bool {
+	return xmlRootName(body) != ""
+}
+
+// xmlLinkElements 文本内容为链接的元素（小写）
+var xmlLinkElements = map[string]bool{
+	"loc": true, "link": true, "url": true, "uri": true, "href": true,
+	"location": true, "endpoint": true, "address": true, "wsdl": true,
+}
+
+// xmlLinkAttrs 值为链接的属性（小写）
+var xmlLinkAttrs = map[string]bool{
+	"href": true, "src": true, "url": true, "uri": true, "location": true,
+	"action": true, "base": true, "schemalocation": true, "resource": true,
+}
+
+// Extract 提取链接
+func (e *XMLExtractor) Extract(body []byte, base *url.URL) []ExtractedLink {
+	links := make([]ExtractedLink, 0)
+	add := func(raw, context string) {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			return
+		}
+		if abs, ok := resolveExtractedURL(base, raw); ok {
+			links = append(links, ExtractedLink{URL: abs, Raw: raw, Extractor: LinkSourceXML, Context: context})
+		}
+	}
+
+	walkXML(body, func(stack []xml.StartElement, elem xml.StartElement, text string) {
+		name := elem.Name.Local
+		if text != "" && (xmlLinkElements[strings.ToLower(name)] || looksLikeLink(text)) {
+			add(text, name)
+		}
+		for _, attr := range elem.Attr {
+			attrName := strings.ToLower(attr.Name.Local)
+			switch {
+			case attrName == "schemalocation":
+				// "namespace location namespace location ..."
+				fields := strings.Fields(attr.Value)
+				for i := 1; i < len(fields); i += 2 {
+					add(fields[i], name+"@"+attr.Name.Local)
+				}
+			case xmlLinkAttrs[attrName]:
+				add(attr.Value, name+"@"+attr.Name.Local)
+			case looksLikeLink(attr.Value):
+				add(attr.Value, name+"@"+attr.Name.Local)
+			}
+		}
+	})
+	return links
+}
+
+// ========== 纯文本 ==========
+
+// TextExtractor 纯文本：绝对URL和根路径
+type TextExtractor struct{}
+
+// Name 提取器名称
+func (e *TextExtractor) Name() string { return LinkSourceText }
+
+// MatchContentType 匹配 text/plain 及常见文本类型
+func (e *TextExtractor) MatchContentType(mediaType string) bool {
+	switch mediaType {
+	case "text/plain", "text/csv", "text/markdown", "text/x-markdown", "text/uri-list":
+		return true
+	}
+	return false
+}
+
+// Sniff 合法UTF-8且不含NUL字符
+func (e *TextExtractor) Sniff(body []byte) bool {
+	sample := body
+	if len(sample) > 4096 {
+		sample = sample[:4096]
+	}
+	return len(sample) > 0 && utf8.Valid(trimIncompleteRune(sample)) && bytes.IndexByte(sample, 0) < 0
+}
+
+var (
+	textAbsoluteURLPattern = regexp.MustCompile(`(?i)\bhttps?://[^\s"'<>()\[\]{}\\^` + "`" + `]+`)
+	textRootPathPattern    = regexp.MustCompile(`(?:^|[\s:="'(,])(/[A-Za-z0-9_\-~%][A-Za-z0-9_\-.~%/]*(?:\?[^\s"'<>]*)?)`)
+)
+
+// Extract 提取链接
+func (e *TextExtractor) Extract(body []byte, base *url.URL) []ExtractedLink {
+	links := make([]ExtractedLink, 0)
+	lines := strings.Split(string(body), "\n")
+	for i, line := range lines {
+		context := "line " + strconv.Itoa(i+1)
+		for _, raw := range textAbsoluteURLPattern.FindAllString(line, -1) {
+			raw = strings.TrimRight(raw, ".,;:!?")
+			if abs, ok := resolveExtractedURL(base, raw); ok {
+				links = append(links, ExtractedLink{URL: abs, Raw: raw, Extractor: LinkSourceText, Context: context})
+			}
+		}
+		for _, m := range textRootPathPattern.FindAllStringSubmatch(line, -1) {
+			raw := strings.TrimRight(m[1], ".,;:!?")
+			if len(raw) < 2 || strings.HasPrefix(raw, "//") {
+				continue
+			}
+			if abs, ok := resolveExtractedURL(base, raw); ok {
+				links = append(links, ExtractedLink{URL: abs, Raw: raw, Extractor: LinkSourceText, Context: context})
+			}
+		}
+	}
+	return links
+}
+
+// ========== 辅助函数 ==========
+
+// parseMediaType 解析Content-Type中的媒体类型（小写）
+func parseMediaType(contentType string) string {
+	if contentType == "" {
+		return ""
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+	}
+	return strings.ToLower(mediaType)
+}
+
+// isHTMLMediaType 是否为HTML类型
+func isHTMLMediaType(mediaType string) bool {
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
+
+// looksLikeHTML 内容是否为HTML
+func looksLikeHTML(body []byte) bool {
+	head := body
+	if len(head) > 512 {
+		head = head[:512]
+	}
+	lower := bytes.ToLower(bytes.TrimSpace(stripBOM(head)))
+	return bytes.HasPrefix(lower, []byte("<!doctype html")) || bytes.HasPrefix(lower, []byte("<html"))
+}
+
+// stripBOM 去掉UTF-8 BOM
+func stripBOM(body []byte) []byte {
+	return bytes.TrimPrefix(body, []byte("\xef\xbb\xbf"))
+}
+
+// trimIncompleteRune 去掉截断产生的不完整UTF-8字符
+func trimIncompleteRune(b []byte) []byte {
+	for i := 0; i < utf8.UTFMax && len(b) > 0; i++ {
+		r, size := utf8.DecodeLastRune(b)
+		if r != utf8.RuneError || size > 1 {
+			return b
+		}
+		b = b[:len(b)-1]
+	}
+	return b
+}
+
+// xmlRootName 返回XML根元素的本地名（非XML返回空字符串）
+func xmlRootName(body []byte) string {
+	trimmed := bytes.TrimSpace(stripBOM(body))
+	if len(trimmed) == 0 || trimmed[0] != '<' || looksLikeHTML(trimmed) {
+		return ""
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(trimmed))
+	decoder.Strict = false
+	decoder.CharsetReader = passthroughCharsetReader
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			return ""
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name.Local
+		}
+	}
+}
+
+// walkXML 遍历XML元素，对每个元素回调（text为元素直接包含的文本）
+func walkXML(body []byte, visit func(stack []xml.StartElement, elem xml.StartElement, text string)) {
+	decoder := xml.NewDecoder(bytes.NewReader(stripBOM(body)))
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity // 不设置AutoClose：RSS的<link>有文本内容
+	decoder.CharsetReader = passthroughCharsetReader
+
+	stack := make([]xml.StartElement, 0, 16)
+	texts := make([]strings.Builder, 0, 16)
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			return
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			stack = append(stack, t.Copy())
+			texts = append(texts, strings.Builder{})
+		case xml.CharData:
+			if len(texts) > 0 {
+				texts[len(texts)-1].Write(t)
+			}
+		case xml.EndElement:
+			if len(stack) == 0 {
+				continue
+			}
+			elem := stack[len(stack)-1]
+			text := strings.TrimSpace(texts[len(texts)-1].String())
+			stack = stack[:len(stack)-1]
+			texts = texts[:len(texts)-1]
+			visit(stack, elem, text)
+		}
+	}
+}
+
+// passthroughCharsetReader 非UTF-8声明的XML按原字节解析（链接通常为ASCII）
+func passthroughCharsetReader(charset string, input io.Reader) (io.Reader, error) {
+	return input, nil
+}
+
+// xmlAttr 获取属性值（按本地名匹配）
+func xmlAttr(elem xml.StartElement, name string) string {
+	for _, attr := range elem.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// looksLikeLink 是否为绝对URL、协议相对URL或根路径
+func looksLikeLink(s string) bool {
+	if len(s) < 2 || len(s) > 2048 || strings.ContainsAny(s, " \t\r\n<>\"") {
+		return false
+	}
+	lower := strings.ToLower(s)
+	if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") {
+		return len(s) > len("https://")
+	}
+	if strings.HasPrefix(s, "//") {
+		return len(s) > 3 && strings.Contains(s[2:], ".")
+	}
+	if strings.HasPrefix(s, "/") {
+		// 排除纯符号、日期（/2024/01/）外的正常路径都可接受
+		return strings.IndexFunc(s[1:], func(r rune) bool {
+			return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+		}) >= 0
+	}
+	return false
+}
+
+// looksLikeRelativePath 是否为相对路径（仅在键名暗示为URL时使用）
+func looksLikeRelativePath(s string) bool {
+	if s == "" || len(s) > 2048 || strings.ContainsAny(s, " \t\r\n<>\"{}") {
+		return false
+	}
+	if strings.HasPrefix(s, "./") || strings.HasPrefix(s, "../") {
+		return true
+	}
+	return strings.Contains(s, "/") || strings.Contains(s, ".")
+}
+
+// resolveExtractedURL 将提取的值解析为绝对URL（仅http/https）
+func resolveExtractedURL(base *url.URL, raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || strings.HasPrefix(raw, "#") {
+		return "", false
+	}
+	lower := strings.ToLower(raw)
+	for _, prefix := range []string{"javascript:", "mailto:", "tel:", "data:", "about:", "urn:", "tag:"} {
+		if strings.HasPrefix(lower, prefix) {
+			return "", false
+		}
+	}
+
+	ref, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+	abs := ref
+	if base != nil {
+		abs = base.ResolveReference(ref)
+	}
+	if abs.Scheme != "http" && abs.Scheme != "https" || abs.Host == "" {
+		return "", false
+	}
+	abs.Fragment = ""
+	return abs.String(), true
+}
@@ -0,0 +1,287 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"spider-golang/config"
+)
+
+// defaultFrontierWorkers 未配置 frontier_workers 时调度器的worker数
+const defaultFrontierWorkers = 20
+
+// frontierWorkerCount 调度器worker数（performance_config.frontier_workers）
+func frontierWorkerCount(cfg *config.Config) int {
+	if cfg != nil && cfg.SchedulingSettings.PerformanceConfig.FrontierWorkers > 0 {
+		return cfg.SchedulingSettings.PerformanceConfig.FrontierWorkers
+	}
+	return defaultFrontierWorkers
+}
+
+// CrawlFrontier 流水线式爬取调度器（v4.9新增）
+// 替代"逐层爬取→等待整层完成→再收集下一层"的模式：
+// 每个URL完成后立即展开其链接并入队，worker无需等待整层结束。
+// 出队顺序按深度升序、同深度先进先出，保持近似BFS的遍历顺序。
+type CrawlFrontier struct {
+	workerCount int
+	maxQPS      int // 每秒请求上限
+	maxTotal    int // 总URL上限
+	maxPerDepth int // 每层URL上限
+
+	fetch    func(task Task) (*Result, error) // 爬取单个URL
+	onResult func(task Task, result *Result)  // 结果回调（保存结果）
+	expand   func(task Task, result *Result)  // 从结果中展开下一层链接（调用Push入队）
+
+	queues   map[int][]Task // 按深度分组的待爬队列
+	inFlight int            // 正在爬取的任务数
+	enqueued int            // 已入队的任务总数
+	stopped  bool
+	stats    map[int]*FrontierDepthStats
+	mutex    sync.Mutex
+	cond     *sync.Cond
+
+	expandMutex sync.Mutex   // 串行化链接展开（过滤管道非并发安全）
+	rateLimiter *time.Ticker // nil表示不限速
+	wg          sync.WaitGroup
+}
+
+// FrontierDepthStats 单层统计
+type FrontierDepthStats struct {
+	Enqueued  int
+	Completed int
+	Failed    int
+	Dropped   int // 因上限被丢弃
+}
+
+// NewCrawlFrontier 创建爬取调度器
+// maxQPS <= 0 表示不限速；maxTotal、maxPerDepth 为0表示不限制
+func NewCrawlFrontier(workerCount, maxQPS, maxTotal, maxPerDepth int) *CrawlFrontier {
+	if workerCount <= 0 {
+		workerCount = 1
+	}
+	f := &CrawlFrontier{
+		workerCount: workerCount,
+		maxQPS:      maxQPS,
+		maxTotal:    maxTotal,
+		maxPerDepth: maxPerDepth,
+		queues:      make(map[int][]Task),
+		stats:       make(map[int]*FrontierDepthStats),
+	}
+	if maxQPS > 0 {
+		f.rateLimiter = time.NewTicker(time.Second / time.Duration(maxQPS))
+	}
+	f.cond = sync.NewCond(&f.mutex)
+	return f
+}
+
+// Push 将链接加入指定深度的队列，返回实际入队的链接
+func (f *CrawlFrontier) Push(links []string, depth int, parent string) []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	stats := f.depthStats(depth)
+	accepted := make([]string, 0, len(links))
+	for _, link := range links {
+		if f.stopped ||
+			(f.maxTotal > 0 && f.enqueued >= f.maxTotal) ||
+			(f.maxPerDepth > 0 && stats.Enqueued >= f.maxPerDepth) {
+			stats.Dropped++
+			continue
+		}
+		f.queues[depth] = append(f.queues[depth], Task{URL: link, Depth: depth, Parent: parent})
+		f.enqueued++
+		stats.Enqueued++
+		accepted = append(accepted, link)
+	}
+
+	if len(accepted) > 0 {
+		f.cond.Broadcast()
+	}
+	return accepted
+}
+
+// Run 启动worker并阻塞直到队列清空且没有进行中的任务
+func (f *CrawlFrontier) Run(
+	fetch func(task Task) (*Result, error),
+	onResult func(task Task, result *Result),
+	expand func(task Task, result *Result),
+) {
+	f.fetch = fetch
+	f.onResult = onResult
+	f.expand = expand
+
+	for i := 0; i < f.workerCount; i++ {
+		f.wg.Add(1)
+		go f.worker()
+	}
+	f.wg.Wait()
+	if f.rateLimiter != nil {
+		f.rateLimiter.Stop()
+	}
+}
+
+// Stop 停止调度（已在爬取的任务会完成，队列中的任务被丢弃）
+func (f *CrawlFrontier) Stop() {
+	f.mutex.Lock()
+	f.stopped = true
+	f.cond.Broadcast()
+	f.mutex.Unlock()
+}
+
+// worker 工作协程：取任务→爬取→展开链接→入队
+func (f *CrawlFrontier) worker() {
+	defer f.wg.Done()
+
+	for {
+		task, ok := f.next()
+		if !ok {
+			return
+		}
+
+		if f.rateLimiter != nil {
+			<-f.rateLimiter.C
+		}
+		f.process(task)
+	}
+}
+
+// process 处理单个任务（带panic恢复，保证inFlight计数正确）
+func (f *CrawlFrontier) process(task Task) {
+	failed := true
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Printf("  [调度器] 任务panic %s: %v\n", task.URL, r)
+		}
+		f.done(task, failed)
+	}()
+
+	result, err := f.fetch(task)
+	if err != nil || result == nil {
+		return
+	}
+	failed = false
+
+	if f.onResult != nil {
+		f.onResult(task, result)
+	}
+
+	if f.expand != nil {
+		f.expandMutex.Lock()
+		defer f.expandMutex.Unlock()
+		f.expand(task, result)
+	}
+}
+
+// next 取出深度最小的任务；队列为空且无进行中任务时返回false
+func (f *CrawlFrontier) next() (Task, bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for {
+		if f.stopped {
+			return Task{}, false
+		}
+		if depth, ok := f.minPendingDepth(); ok {
+			queue := f.queues[depth]
+			task := queue[0]
+			if len(queue) == 1 {
+				delete(f.queues, depth)
+			} else {
+				f.queues[depth] = queue[1:]
+			}
+			f.inFlight++
+			return task, true
+		}
+		if f.inFlight == 0 {
+			// 所有任务已完成，唤醒其他等待的worker退出
+			f.cond.Broadcast()
+			return Task{}, false
+		}
+		f.cond.Wait()
+	}
+}
+
+// done 标记任务完成
+func (f *CrawlFrontier) done(task Task, failed bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.inFlight--
+	stats := f.depthStats(task.Depth)
+	stats.Completed++
+	if failed {
+		stats.Failed++
+	}
+	f.cond.Broadcast()
+}
+
+// minPendingDepth 返回有待爬任务的最小深度（调用方需持有锁）
+func (f *CrawlFrontier) minPendingDepth() (int, bool) {
+	found := false
+	minDepth := 0
+	for depth, queue := range f.queues {
+		if len(queue) == 0 {
+			continue
+		}
+		if !found || depth < minDepth {
+			minDepth = depth
+			found = true
+		}
+	}
+	return minDepth, found
+}
+
+// depthStats 获取指定深度的统计（调用方需持有锁）
+func (f *CrawlFrontier) depthStats(depth int) *FrontierDepthStats {
+	stats, ok := f.stats[depth]
+	if !ok {
+		stats = &FrontierDepthStats{}
+		f.stats[depth] = stats
+	}
+	return stats
+}
+
+// GetStats 获取各层统计
+func (f *CrawlFrontier) GetStats() map[int]FrontierDepthStats {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	stats := make(map[int]FrontierDepthStats, len(f.stats))
+	for depth, s := range f.stats {
+		stats[depth] = *s
+	}
+	return stats
+}
+
+// Describe 调度器配置的文本描述
+func (f *CrawlFrontier) Describe() string {
+	return fmt.Sprintf("%d 个worker, 限速: %s, 总URL上限: %s, 每层上限: %s",
+		f.workerCount, frontierLimitLabel(f.maxQPS, "%d 请求/秒"),
+		frontierLimitLabel(f.maxTotal, "%d"), frontierLimitLabel(f.maxPerDepth, "%d"))
+}
+
+// frontierLimitLabel 调度器限制的显示文本（0表示不限制）
+func frontierLimitLabel(limit int, format string) string {
+	if limit <= 0 {
+		return "不限"
+	}
+	return fmt.Sprintf(format, limit)
+}
+
+// PrintStats 打印各层统计
+func (f *CrawlFrontier) PrintStats() {
+	stats := f.GetStats()
+	depths := make([]int, 0, len(stats))
+	for depth := range stats {
+		depths = append(depths, depth)
+	}
+	sort.Ints(depths)
+
+	for _, depth := range depths {
+		s := stats[depth]
+		fmt.Printf("  第 %d 层 - 入队: %d, 完成: %d, 失败: %d, 超限丢弃: %d\n",
+			depth, s.Enqueued, s.Completed-s.Failed, s.Failed, s.Dropped)
+	}
+}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"net/http"
 	"net/url"
 	"spider-golang/config"
 )
@@ -10,18 +11,29 @@ type Result struct {
 	URL         string
 	StatusCode  int
 	ContentType string
+	Method      string // 🆕 v4.9: 请求方法（被动代理记录的非GET请求，空表示GET）
 	Links       []string
 	Assets      []string
 	Forms       []Form
 	APIs        []string
 	
+	// 🆕 v4.9: 链接来源（链接 → 产生该链接的提取器，如 html/json/feed/xml/text）
+	LinkSources map[string]string
+	
 	// POST请求数据
 	POSTRequests []POSTRequest // POST请求列表（包含完整参数）
 	
+	// 🆕 v4.9: 浏览器捕获的XHR/Fetch文本响应（用于敏感信息扫描）
+	CapturedResponses []CapturedResponse
+	
 	// 用于高级检测
-	HTMLContent string            // HTML内容（用于技术栈和敏感信息检测）
+	HTMLContent string            // HTML内容（用于技术栈和敏感信息检测，已转为UTF-8）
 	Headers     map[string]string // HTTP响应头
 	
+	// 🆕 v4.9: 字符集（转码前的原始编码）
+	Charset       string // 规范化的字符集名称，如 utf-8 / gb18030 / big5
+	CharsetSource string // 检测来源：bom / header / meta / sniff / default / browser
+	
 	// DOM相似度检测
 	IsSimilar    bool   // 是否与已爬取的页面相似
 	SimilarToURL string // 相似的页面URL
@@ -45,6 +57,16 @@ type POSTRequest struct {
 	Response     *POSTResponse     // POST请求的响应（如果已提交）
 	FromForm     bool              // 是否来自表单
 	FormAction   string            // 原始表单action
+	Headers      map[string]string // 🆕 v4.9: 捕获的请求头（浏览器流量，如SOAPAction）
+}
+
+// CapturedResponse 浏览器捕获的XHR/Fetch响应（🆕 v4.9）
+type CapturedResponse struct {
+	URL         string // 请求URL
+	Method      string // 请求方法
+	StatusCode  int    // 响应状态码
+	ContentType string // 响应MIME类型
+	Body        string // 响应体（超过上限的部分截断）
 }
 
 // Form 表单信息
@@ -91,10 +113,14 @@ type SpiderRecorder interface {
 	RecordStaticResource(url string, resourceType ResourceType)
 	RecordSpecialLink(url string, protocol string)
 	RecordBlacklistedURL(url string)
+	RecordJSEndpoints(endpoints []*JSEndpoint) // 🆕 v4.9: 记录JS中发现的结构化端点
+	RecordDeobfuscation(report *DeobfuscationReport) // 🆕 v4.9: 记录脚本反混淆结果
 	GetResourceClassifier() *ResourceClassifier
 	GetRequestLogger() *RequestLogger // 🆕 v4.4: 获取请求日志记录器
 	GetDuplicateHandler() *DuplicateHandler // 🆕 v4.5: 获取去重处理器（修复多实例问题）
 	GetURLPatternLimiter() *URLPatternLimiter // 🆕 v4.7: 获取URL模式限流器
+	GetChunkEnumerator() *ChunkEnumerator     // 🆕 v4.9: 获取分块枚举器
+	AdmitChunk(chunkURL string) (string, bool) // 🆕 v4.9: 分块准入检查（JS黑名单、路径拼接）
 }
 
 // StaticCrawler 静态爬虫接口
@@ -103,6 +129,9 @@ type StaticCrawler interface {
 	
 	// ParseHTML 解析HTML内容
 	ParseHTML(htmlContent string, baseURL *url.URL) (*Result, error)
+	
+	// AnalyzeResponse 分析已获取的响应（🆕 v4.9，被动代理流量）
+	AnalyzeResponse(method string, target *url.URL, resp *http.Response, body []byte) (*Result, error)
 }
 
 // DynamicCrawler 动态爬虫接口
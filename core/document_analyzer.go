@@ -0,0 +1,865 @@
+package core
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf16"
+
+	"spider-golang/config"
+)
+
+// DocumentAnalyzer 文档分析器（v4.9新增）
+// 下载爬取中发现的PDF/Office文档，提取内嵌链接、文本和元数据。
+// 纯Go实现，不依赖外部库：
+//   - PDF: 解析对象与FlateDecode流，提取 /URI 链接、文本字符串、Info字典和XMP元数据
+//   - OOXML (docx/xlsx/pptx) / ODF (odt/ods/odp): 解压后解析XML部件和关系文件
+//   - 旧版OLE (doc/xls/ppt) / RTF: 提取可打印字符串
+type DocumentAnalyzer struct {
+	settings config.DocumentAnalysisSettings
+	client   *http.Client
+	allowed  map[string]bool // 允许的扩展名
+
+	analyses []*DocumentAnalysis
+	started  int // 已开始分析的文档数（MaxDocuments对整个爬取生效）
+	mutex    sync.Mutex
+}
+
+// DocumentAnalysis 单个文档的分析结果
+type DocumentAnalysis struct {
+	URL           string           `json:"url"`
+	SourcePages   []string         `json:"source_pages"` // 引用该文档的页面
+	Type          string           `json:"type"`         // pdf / docx / xlsx / ...
+	ContentType   string           `json:"content_type"`
+	Size          int64            `json:"size"`
+	Metadata      DocumentMetadata `json:"metadata"`
+	Links         []string         `json:"links"`          // 内嵌超链接
+	Emails        []string         `json:"emails"`         // 邮箱地址
+	Hostnames     []string         `json:"hostnames"`      // 主机名（来自链接和UNC路径）
+	InternalPaths []string         `json:"internal_paths"` // 内部路径（本地/UNC/file://）
+	TextLength    int              `json:"text_length"`
+	Truncated     bool             `json:"truncated,omitempty"` // 解压数据超过文档级上限，剩余部分未解析
+	Text          string           `json:"-"`                   // 提取的文本（用于敏感信息扫描，不导出）
+	Error         string           `json:"error,omitempty"`
+}
+
+// DocumentMetadata 文档元数据
+type DocumentMetadata struct {
+	Title          string `json:"title,omitempty"`
+	Subject        string `json:"subject,omitempty"`
+	Author         string `json:"author,omitempty"`
+	LastModifiedBy string `json:"last_modified_by,omitempty"`
+	Creator        string `json:"creator,omitempty"`  // 创建工具（PDF Creator / OOXML Application）
+	Producer       string `json:"producer,omitempty"` // PDF Producer
+	Company        string `json:"company,omitempty"`
+	Template       string `json:"template,omitempty"`
+	Created        string `json:"created,omitempty"`
+	Modified       string `json:"modified,omitempty"`
+	Keywords       string `json:"keywords,omitempty"`
+}
+
+// NewDocumentAnalyzer 创建文档分析器
+func NewDocumentAnalyzer(settings config.DocumentAnalysisSettings, insecureSkipVerify bool) *DocumentAnalyzer {
+	if settings.MaxFileSize <= 0 {
+		settings.MaxFileSize = 10 * 1024 * 1024
+	}
+	if settings.MaxDocuments <= 0 {
+		settings.MaxDocuments = 50
+	}
+	if settings.Timeout <= 0 {
+		settings.Timeout = 30
+	}
+	if settings.Concurrency <= 0 {
+		settings.Concurrency = 5
+	}
+
+	allowed := make(map[string]bool)
+	for _, ext := range settings.Extensions {
+		allowed[strings.ToLower(strings.TrimPrefix(ext, "."))] = true
+	}
+
+	transport := &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: insecureSkipVerify},
+	}
+
+	return &DocumentAnalyzer{
+		settings: settings,
+		client: &http.Client{
+			Timeout:   time.Duration(settings.Timeout) * time.Second,
+			Transport: transport,
+		},
+		allowed:  allowed,
+		analyses: make([]*DocumentAnalysis, 0),
+	}
+}
+
+// SetAuthManager 设置认证管理器
+func (da *DocumentAnalyzer) SetAuthManager(am *AuthManager) {
+	if am != nil {
+		am.ApplyToClient(da.client)
+	}
+}
+
+// SetHARRecorder 设置HAR流量记录器（需在SetAuthManager之后调用）
+func (da *DocumentAnalyzer) SetHARRecorder(hr *HARRecorder) {
+	if hr != nil {
+		hr.ApplyToClient(da.client, HARSourceProbe)
+	}
+}
+
+// IsDocumentURL 判断URL是否为需要分析的文档
+func (da *DocumentAnalyzer) IsDocumentURL(rawURL string) bool {
+	return da.allowed[documentExtension(rawURL)]
+}
+
+// AnalyzeAll 并发分析文档（documents: 文档URL → 来源页面）
+// 可在爬取中多次调用，累计分析数不超过MaxDocuments
+func (da *DocumentAnalyzer) AnalyzeAll(documents map[string][]string) []*DocumentAnalysis {
+	urls := make([]string, 0, len(documents))
+	for u := range documents {
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+
+	da.mutex.Lock()
+	remaining := da.settings.MaxDocuments - da.started
+	if remaining < 0 {
+		remaining = 0
+	}
+	if len(urls) > remaining {
+		fmt.Printf("  [文档分析] 发现 %d 个文档，已达到分析上限 %d，只分析其中 %d 个\n", len(urls), da.settings.MaxDocuments, remaining)
+		urls = urls[:remaining]
+	}
+	da.started += len(urls)
+	da.mutex.Unlock()
+
+	results := make([]*DocumentAnalysis, len(urls))
+	sem := make(chan struct{}, da.settings.Concurrency)
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, u string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = da.Analyze(u, documents[u])
+		}(i, u)
+	}
+	wg.Wait()
+
+	da.mutex.Lock()
+	da.analyses = append(da.analyses, results...)
+	da.mutex.Unlock()
+	return results
+}
+
+// Analyze 下载并分析单个文档
+func (da *DocumentAnalyzer) Analyze(docURL string, sourcePages []string) *DocumentAnalysis {
+	if sourcePages == nil {
+		sourcePages = []string{}
+	}
+	analysis := &DocumentAnalysis{
+		URL:         docURL,
+		SourcePages: sourcePages,
+		Type:        documentExtension(docURL),
+	}
+
+	data, contentType, err := da.download(docURL)
+	if err != nil {
+		analysis.Error = err.Error()
+		return analysis
+	}
+	analysis.ContentType = contentType
+	analysis.Size = int64(len(data))
+
+	// 以文件内容的魔数为准（扩展名可能不可信）
+	var links []string
+	var text string
+	budget := newInflateBudget(da.settings.MaxFileSize)
+	switch {
+	case bytes.HasPrefix(data, []byte("%PDF")):
+		analysis.Type = "pdf"
+		links, text, analysis.Metadata = analyzePDF(data, budget)
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		links, text, analysis.Metadata, err = analyzeZipDocument(data, budget)
+		if err != nil {
+			analysis.Error = err.Error()
+		}
+	case bytes.HasPrefix(data, []byte("{\\rtf")):
+		analysis.Type = "rtf"
+		text = extractRTFText(data)
+	default:
+		// OLE复合文档（doc/xls/ppt）等二进制格式
+		text = extractPrintableStrings(data, 5)
+	}
+
+	analysis.Truncated = budget.exceeded
+	analysis.Text = text
+	analysis.TextLength = len(text)
+	da.collectIndicators(analysis, links, text)
+	return analysis
+}
+
+// GetAnalyses 获取所有分析结果
+func (da *DocumentAnalyzer) GetAnalyses() []*DocumentAnalysis {
+	da.mutex.Lock()
+	defer da.mutex.Unlock()
+	return da.analyses
+}
+
+// download 下载文档（限制大小）
+func (da *DocumentAnalyzer) download(docURL string) ([]byte, string, error) {
+	req, err := http.NewRequest("GET", docURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+
+	resp, err := da.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	if resp.ContentLength > da.settings.MaxFileSize {
+		return nil, "", fmt.Errorf("文件过大: %d 字节（上限 %d）", resp.ContentLength, da.settings.MaxFileSize)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, da.settings.MaxFileSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(len(data)) > da.settings.MaxFileSize {
+		return nil, "", fmt.Errorf("文件过大: 超过 %d 字节", da.settings.MaxFileSize)
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+var (
+	docURLPattern      = regexp.MustCompile(`(?i)\b(?:https?|ftp)://[^\s"'<>()\[\]{}\\]+`)
+	docFileURLPattern  = regexp.MustCompile(`(?i)\bfile:/{2,3}[^\s"'<>]+`)
+	docEmailPattern    = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	docUNCPattern      = regexp.MustCompile(`\\\\[A-Za-z0-9._\-$]+\\[^\s"'<>|*?]+`)
+	docWinPathPattern  = regexp.MustCompile(`\b[A-Za-z]:\\(?:[^\\/:*?"<>|\r\n]+\\)*[^\\/:*?"<>|\r\n]*`)
+	docUnixHomePattern = regexp.MustCompile(`(?:/home/|/Users/|/var/www/|/opt/|/srv/)[A-Za-z0-9._\-/]+`)
+)
+
+// collectIndicators 从链接和文本中整理URL、邮箱、主机名和内部路径
+func (da *DocumentAnalyzer) collectIndicators(analysis *DocumentAnalysis, links []string, text string) {
+	linkSet := newOrderedSet()
+	emailSet := newOrderedSet()
+	hostSet := newOrderedSet()
+	pathSet := newOrderedSet()
+
+	addLink := func(raw string) {
+		raw = strings.TrimRight(strings.TrimSpace(raw), ".,;:)")
+		if raw == "" {
+			return
+		}
+		lower := strings.ToLower(raw)
+		switch {
+		case strings.HasPrefix(lower, "mailto:"):
+			emailSet.add(strings.SplitN(raw[len("mailto:"):], "?", 2)[0])
+			return
+		case strings.HasPrefix(lower, "file:"):
+			pathSet.add(raw)
+			return
+		case strings.HasPrefix(raw, `\\`):
+			pathSet.add(raw)
+			return
+		}
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" {
+			return
+		}
+		linkSet.add(raw)
+		hostSet.add(strings.ToLower(u.Hostname()))
+	}
+
+	for _, link := range links {
+		addLink(link)
+	}
+	for _, m := range docURLPattern.FindAllString(text, -1) {
+		addLink(m)
+	}
+	for _, m := range docFileURLPattern.FindAllString(text, -1) {
+		pathSet.add(m)
+	}
+	for _, m := range docEmailPattern.FindAllString(text, -1) {
+		emailSet.add(m)
+	}
+
+	// 元数据中同样可能包含内部路径（如模板路径）
+	pathSources := text + "\n" + analysis.Metadata.Template + "\n" + analysis.Metadata.Title
+	for _, m := range docUNCPattern.FindAllString(pathSources, -1) {
+		pathSet.add(m)
+		if parts := strings.SplitN(strings.TrimPrefix(m, `\\`), `\`, 2); len(parts) > 0 {
+			hostSet.add(strings.ToLower(parts[0]))
+		}
+	}
+	for _, m := range docWinPathPattern.FindAllString(pathSources, -1) {
+		if len(m) > 3 {
+			pathSet.add(strings.TrimSpace(m))
+		}
+	}
+	for _, m := range docUnixHomePattern.FindAllString(pathSources, -1) {
+		pathSet.add(m)
+	}
+
+	analysis.Links = linkSet.items
+	analysis.Emails = emailSet.items
+	analysis.Hostnames = hostSet.items
+	analysis.InternalPaths = pathSet.items
+}
+
+// ========== PDF ==========
+
+var (
+	pdfStreamPattern = regexp.MustCompile(`(?s)<<(.*?)>>\s*stream\r?\n`)
+	pdfURIPattern    = regexp.MustCompile(`/URI\s*(\((?:\\.|[^\\)])*\)|<[0-9A-Fa-f\s]*>)`)
+	pdfInfoKeys      = []string{"Title", "Subject", "Author", "Creator", "Producer", "Keywords", "CreationDate", "ModDate"}
+	pdfTextOpPattern = regexp.MustCompile(`(?s)(\((?:\\.|[^\\)])*\)|<[0-9A-Fa-f\s]*>)\s*(?:Tj|'|")|\[((?:\\.|[^\]\\])*)\]\s*TJ`)
+	pdfTJPartPattern = regexp.MustCompile(`\((?:\\.|[^\\)])*\)|<[0-9A-Fa-f\s]*>`)
+)
+
+// analyzePDF 解析PDF：链接、文本、元数据
+func analyzePDF(data []byte, budget *inflateBudget) ([]string, string, DocumentMetadata) {
+	// 原始数据 + 解压后的流（对象流中也可能有 /URI 和 Info）
+	segments := [][]byte{data}
+	for _, loc := range pdfStreamPattern.FindAllSubmatchIndex(data, -1) {
+		if budget.exceeded {
+			break
+		}
+		dict := data[loc[2]:loc[3]]
+		start := loc[1]
+		end := bytes.Index(data[start:], []byte("endstream"))
+		if end < 0 {
+			continue
+		}
+		raw := data[start : start+end]
+		if bytes.Contains(dict, []byte("/FlateDecode")) {
+			if inflated, err := inflate(raw, budget.limit()); err == nil {
+				budget.consume(int64(len(inflated)))
+				segments = append(segments, inflated)
+			}
+		}
+	}
+
+	links := make([]string, 0)
+	var text strings.Builder
+	var meta DocumentMetadata
+	info := make(map[string]string)
+
+	for _, seg := range segments {
+		for _, m := range pdfURIPattern.FindAllSubmatch(seg, -1) {
+			links = append(links, decodePDFString(m[1]))
+		}
+		for _, key := range pdfInfoKeys {
+			if _, ok := info[key]; ok {
+				continue
+			}
+			if v := findPDFInfoValue(seg, key); v != "" {
+				info[key] = v
+			}
+		}
+		for _, m := range pdfTextOpPattern.FindAllSubmatch(seg, -1) {
+			if len(m[1]) > 0 {
+				text.WriteString(decodePDFString(m[1]))
+			} else {
+				for _, part := range pdfTJPartPattern.FindAll(m[2], -1) {
+					text.WriteString(decodePDFString(part))
+				}
+			}
+			text.WriteString(" ")
+		}
+		text.WriteString("\n")
+
+		// XMP元数据
+		if bytes.Contains(seg, []byte("<x:xmpmeta")) || bytes.Contains(seg, []byte("<rdf:RDF")) {
+			applyXMPMetadata(seg, &meta)
+		}
+	}
+
+	setIfEmpty(&meta.Title, info["Title"])
+	setIfEmpty(&meta.Subject, info["Subject"])
+	setIfEmpty(&meta.Author, info["Author"])
+	setIfEmpty(&meta.Creator, info["Creator"])
+	setIfEmpty(&meta.Producer, info["Producer"])
+	setIfEmpty(&meta.Keywords, info["Keywords"])
+	setIfEmpty(&meta.Created, info["CreationDate"])
+	setIfEmpty(&meta.Modified, info["ModDate"])
+
+	return links, text.String(), meta
+}
+
+// findPDFInfoValue 查找 /Key (value) 或 /Key <hex>
+func findPDFInfoValue(data []byte, key string) string {
+	marker := []byte("/" + key)
+	idx := 0
+	for {
+		pos := bytes.Index(data[idx:], marker)
+		if pos < 0 {
+			return ""
+		}
+		pos += idx + len(marker)
+		idx = pos
+		// 确保是完整的键名（如 /Creator 不匹配 /CreatorTool）
+		if pos < len(data) && isPDFNameChar(data[pos]) {
+			continue
+		}
+		rest := bytes.TrimLeft(data[pos:], " \t\r\n")
+		if len(rest) == 0 {
+			return ""
+		}
+		if rest[0] == '(' || rest[0] == '<' && len(rest) > 1 && rest[1] != '<' {
+			if end := pdfStringEnd(rest); end > 0 {
+				return strings.TrimSpace(decodePDFString(rest[:end]))
+			}
+		}
+	}
+}
+
+// isPDFNameChar 是否为PDF名称的组成字符
+func isPDFNameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// pdfStringEnd 返回PDF字符串（括号或十六进制）的结束位置
+func pdfStringEnd(data []byte) int {
+	if data[0] == '<' {
+		if end := bytes.IndexByte(data, '>'); end > 0 {
+			return end + 1
+		}
+		return -1
+	}
+	depth := 0
+	for i := 0; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
+
+// decodePDFString 解码PDF字符串（处理转义、八进制、十六进制和UTF-16BE）
+func decodePDFString(raw []byte) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var out []byte
+	if raw[0] == '<' {
+		hexStr := strings.Map(func(r rune) rune {
+			if r == ' ' || r == '\n' || r == '\r' || r == '\t' {
+				return -1
+			}
+			return r
+		}, string(bytes.Trim(raw, "<>")))
+		if len(hexStr)%2 == 1 {
+			hexStr += "0"
+		}
+		decoded, err := hex.DecodeString(hexStr)
+		if err != nil {
+			return ""
+		}
+		out = decoded
+	} else {
+		body := raw
+		if body[0] == '(' && body[len(body)-1] == ')' {
+			body = body[1 : len(body)-1]
+		}
+		out = make([]byte, 0, len(body))
+		for i := 0; i < len(body); i++ {
+			c := body[i]
+			if c != '\\' || i+1 >= len(body) {
+				out = append(out, c)
+				continue
+			}
+			i++
+			switch body[i] {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b', 'f':
+			case '\r', '\n':
+				// 续行
+			default:
+				if body[i] >= '0' && body[i] <= '7' {
+					v := 0
+					j := 0
+					for ; j < 3 && i+j < len(body) && body[i+j] >= '0' && body[i+j] <= '7'; j++ {
+						v = v*8 + int(body[i+j]-'0')
+					}
+					out = append(out, byte(v))
+					i += j - 1
+				} else {
+					out = append(out, body[i])
+				}
+			}
+		}
+	}
+
+	// UTF-16BE（带BOM）
+	if len(out) >= 2 && out[0] == 0xFE && out[1] == 0xFF {
+		u16 := make([]uint16, 0, (len(out)-2)/2)
+		for i := 2; i+1 < len(out); i += 2 {
+			u16 = append(u16, uint16(out[i])<<8|uint16(out[i+1]))
+		}
+		return string(utf16.Decode(u16))
+	}
+	return string(out)
+}
+
+// inflate 解压FlateDecode流（容忍截断，最多解压limit字节）
+func inflate(data []byte, limit int64) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	out, err := io.ReadAll(io.LimitReader(r, limit))
+	if len(out) > 0 {
+		return out, nil
+	}
+	return nil, err
+}
+
+// applyXMPMetadata 从XMP元数据中补充字段
+func applyXMPMetadata(data []byte, meta *DocumentMetadata) {
+	walkXML(data, func(stack []xml.StartElement, elem xml.StartElement, text string) {
+		if text == "" {
+			return
+		}
+		// rdf:li 的值归属于其父元素（dc:creator/dc:title的序列）
+		name := elem.Name.Local
+		if name == "li" {
+			for i := len(stack) - 1; i >= 0; i-- {
+				if n := stack[i].Name.Local; n != "Seq" && n != "Alt" && n != "Bag" {
+					name = n
+					break
+				}
+			}
+		}
+		switch name {
+		case "creator":
+			setIfEmpty(&meta.Author, text)
+		case "title":
+			setIfEmpty(&meta.Title, text)
+		case "description":
+			setIfEmpty(&meta.Subject, text)
+		case "CreatorTool":
+			setIfEmpty(&meta.Creator, text)
+		case "Producer":
+			setIfEmpty(&meta.Producer, text)
+		case "CreateDate":
+			setIfEmpty(&meta.Created, text)
+		case "ModifyDate":
+			setIfEmpty(&meta.Modified, text)
+		case "Keywords":
+			setIfEmpty(&meta.Keywords, text)
+		}
+	})
+}
+
+// ========== OOXML / ODF ==========
+
+// analyzeZipDocument 解析基于ZIP的文档（OOXML/ODF）
+func analyzeZipDocument(data []byte, budget *inflateBudget) ([]string, string, DocumentMetadata, error) {
+	var meta DocumentMetadata
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, "", meta, fmt.Errorf("无法解析ZIP文档: %v", err)
+	}
+
+	links := make([]string, 0)
+	var text strings.Builder
+
+	for _, file := range reader.File {
+		name := file.Name
+		if !isDocumentXMLPart(name) {
+			continue
+		}
+		if budget.exceeded {
+			break
+		}
+		content, err := readZipFile(file, budget.limit())
+		if err != nil {
+			continue
+		}
+		budget.consume(int64(len(content)))
+
+		switch {
+		case name == "docProps/core.xml" || name == "docProps/app.xml":
+			applyOfficeMetadata(content, &meta, false)
+		case name == "meta.xml":
+			applyOfficeMetadata(content, &meta, true)
+		case strings.HasSuffix(name, ".rels"):
+			links = append(links, externalRelationshipTargets(content)...)
+		default:
+			// 正文部件：收集文本和 xlink:href / r:href 等链接属性
+			partText, partLinks := extractXMLPartText(content)
+			text.WriteString(partText)
+			text.WriteString("\n")
+			links = append(links, partLinks...)
+		}
+	}
+	return links, text.String(), meta, nil
+}
+
+// isDocumentXMLPart 是否为需要解析的XML部件
+func isDocumentXMLPart(name string) bool {
+	if strings.HasSuffix(name, ".rels") {
+		return true
+	}
+	if !strings.HasSuffix(name, ".xml") {
+		return false
+	}
+	switch {
+	case name == "docProps/core.xml", name == "docProps/app.xml",
+		name == "meta.xml", name == "content.xml", name == "styles.xml":
+		return true
+	case strings.HasPrefix(name, "word/"), strings.HasPrefix(name, "ppt/slides/"),
+		strings.HasPrefix(name, "ppt/notesSlides/"), strings.HasPrefix(name, "xl/sharedStrings"),
+		strings.HasPrefix(name, "xl/worksheets/"), strings.HasPrefix(name, "xl/comments"):
+		return !strings.Contains(name, "/theme/") && !strings.HasSuffix(name, "fontTable.xml")
+	}
+	return false
+}
+
+// inflateBudget 单个文档的解压总量预算
+// 单个流/部件最多解压20MB，整个文档累计不超过 MaxFileSize 的固定倍数，防止多个流叠加的压缩炸弹
+type inflateBudget struct {
+	remaining int64
+	exceeded  bool
+}
+
+const (
+	maxInflatedPartSize   = 20 * 1024 * 1024 // 单个流/部件的解压上限
+	documentInflateFactor = 5                // 文档累计解压上限 = MaxFileSize × 该倍数
+)
+
+// newInflateBudget 按文档大小上限创建解压预算
+func newInflateBudget(maxFileSize int64) *inflateBudget {
+	return &inflateBudget{remaining: maxFileSize * documentInflateFactor}
+}
+
+// limit 下一个流/部件最多可解压的字节数
+func (b *inflateBudget) limit() int64 {
+	if b.remaining < maxInflatedPartSize {
+		return b.remaining
+	}
+	return maxInflatedPartSize
+}
+
+// consume 扣除已解压的字节数，预算用尽后标记exceeded
+func (b *inflateBudget) consume(n int64) {
+	b.remaining -= n
+	if b.remaining <= 0 {
+		b.remaining = 0
+		b.exceeded = true
+	}
+}
+
+// readZipFile 读取ZIP中的文件（限制大小，防止压缩炸弹）
+func readZipFile(file *zip.File, limit int64) ([]byte, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(io.LimitReader(rc, limit))
+}
+
+// applyOfficeMetadata 解析 core.xml / app.xml / ODF meta.xml
+// ODF中 dc:creator 为最后修改者，meta:initial-creator 才是作者
+func applyOfficeMetadata(data []byte, meta *DocumentMetadata, odf bool) {
+	walkXML(data, func(stack []xml.StartElement, elem xml.StartElement, text string) {
+		if text == "" {
+			return
+		}
+		switch elem.Name.Local {
+		case "title":
+			setIfEmpty(&meta.Title, text)
+		case "subject", "description":
+			setIfEmpty(&meta.Subject, text)
+		case "initial-creator":
+			setIfEmpty(&meta.Author, text)
+		case "creator":
+			if odf {
+				setIfEmpty(&meta.LastModifiedBy, text)
+			} else {
+				setIfEmpty(&meta.Author, text)
+			}
+		case "lastModifiedBy":
+			setIfEmpty(&meta.LastModifiedBy, text)
+		case "keywords", "keyword":
+			setIfEmpty(&meta.Keywords, text)
+		case "created", "creation-date":
+			setIfEmpty(&meta.Created, text)
+		case "modified", "date":
+			setIfEmpty(&meta.Modified, text)
+		case "Application", "generator":
+			setIfEmpty(&meta.Creator, text)
+		case "Company":
+			setIfEmpty(&meta.Company, text)
+		case "Template":
+			setIfEmpty(&meta.Template, text)
+		}
+	})
+}
+
+// externalRelationshipTargets 提取关系文件中的外部链接（超链接、外部数据源、附加模板）
+func externalRelationshipTargets(data []byte) []string {
+	targets := make([]string, 0)
+	walkXML(data, func(stack []xml.StartElement, elem xml.StartElement, text string) {
+		if elem.Name.Local != "Relationship" {
+			return
+		}
+		if !strings.EqualFold(xmlAttr(elem, "TargetMode"), "External") {
+			return
+		}
+		if target := strings.TrimSpace(xmlAttr(elem, "Target")); target != "" {
+			targets = append(targets, target)
+		}
+	})
+	return targets
+}
+
+// extractXMLPartText 提取XML正文部件的文本和链接属性
+func extractXMLPartText(data []byte) (string, []string) {
+	var text strings.Builder
+	links := make([]string, 0)
+	walkXML(data, func(stack []xml.StartElement, elem xml.StartElement, t string) {
+		for _, attr := range elem.Attr {
+			if attr.Name.Local == "href" && attr.Value != "" && !strings.HasPrefix(attr.Value, "#") {
+				links = append(links, attr.Value)
+			}
+		}
+		// 只取叶子文本元素（w:t / a:t / t / text:p 等），避免父元素重复
+		switch elem.Name.Local {
+		case "t", "p", "span", "h", "instrText", "v":
+			if t != "" {
+				text.WriteString(t)
+				text.WriteString(" ")
+			}
+		}
+	})
+	return text.String(), links
+}
+
+// ========== RTF / 二进制 ==========
+
+var rtfControlPattern = regexp.MustCompile(`\\[a-z]+-?\d* ?|\\'[0-9a-f]{2}|[{}]`)
+
+// extractRTFText 粗略去除RTF控制字
+func extractRTFText(data []byte) string {
+	return rtfControlPattern.ReplaceAllString(string(data), "")
+}
+
+// extractPrintableStrings 提取ASCII和UTF-16LE可打印字符串（类似 strings 命令）
+func extractPrintableStrings(data []byte, minLen int) string {
+	var out strings.Builder
+
+	// ASCII
+	start := -1
+	for i := 0; i <= len(data); i++ {
+		printable := i < len(data) && data[i] >= 0x20 && data[i] < 0x7F
+		if printable {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 && i-start >= minLen {
+			out.Write(data[start:i])
+			out.WriteString("\n")
+		}
+		start = -1
+	}
+
+	// UTF-16LE（OLE文档中的文本通常为UTF-16）
+	u16 := make([]uint16, 0, 64)
+	flush := func() {
+		if len(u16) >= minLen {
+			out.WriteString(string(utf16.Decode(u16)))
+			out.WriteString("\n")
+		}
+		u16 = u16[:0]
+	}
+	for i := 0; i+1 < len(data); i += 2 {
+		c := uint16(data[i]) | uint16(data[i+1])<<8
+		if c >= 0x20 && c < 0x7F || c >= 0x4E00 && c <= 0x9FFF {
+			u16 = append(u16, c)
+		} else {
+			flush()
+		}
+	}
+	flush()
+
+	return out.String()
+}
+
+// ========== 辅助 ==========
+
+// documentExtension 获取URL路径的小写扩展名（不含点）
+func documentExtension(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(path.Ext(u.Path)), ".")
+}
+
+// setIfEmpty 字段为空时赋值
+func setIfEmpty(field *string, value string) {
+	value = strings.TrimSpace(value)
+	if *field == "" && value != "" {
+		*field = value
+	}
+}
+
+// orderedSet 保持插入顺序的去重集合
+type orderedSet struct {
+	seen  map[string]bool
+	items []string
+}
+
+func newOrderedSet() *orderedSet {
+	return &orderedSet{seen: make(map[string]bool), items: make([]string, 0)}
+}
+
+func (s *orderedSet) add(item string) {
+	item = strings.TrimSpace(item)
+	if item == "" || s.seen[item] {
+		return
+	}
+	s.seen[item] = true
+	s.items = append(s.items, item)
+}
@@ -24,6 +24,8 @@ type DynamicCrawlerImpl struct {
 	// v4.1: 质量过滤与验证（与静态爬虫一致的双重防护）
 	urlQualityFilter *URLQualityFilter
 	urlValidator     URLValidatorInterface
+	authManager      *AuthManager // 🆕 v4.9: HTTP认证管理器
+	harRecorder      *HARRecorder // 🆕 v4.9: HAR流量记录器（nil表示不记录）
 }
 
 // NewDynamicCrawler 创建动态爬虫实例
@@ -60,6 +62,16 @@ func (d *DynamicCrawlerImpl) Configure(config *config.Config) {
 	}
 }
 
+// SetAuthManager 设置认证管理器（v4.9新增）
+func (d *DynamicCrawlerImpl) SetAuthManager(am *AuthManager) {
+	d.authManager = am
+}
+
+// SetHARRecorder 设置HAR流量记录器（v4.9新增）
+func (d *DynamicCrawlerImpl) SetHARRecorder(hr *HARRecorder) {
+	d.harRecorder = hr
+}
+
 // SetSpider 设置Spider引用（v3.7新增，实现Crawler接口）
 func (d *DynamicCrawlerImpl) SetSpider(spider SpiderRecorder) {
 	d.spider = spider
@@ -154,6 +166,20 @@ func (d *DynamicCrawlerImpl) Crawl(targetURL *url.URL) (*Result, error) {
 		}
 	}
 
+	// 🆕 v4.9: 启用HTTP认证（Basic/Digest质询、Bearer注入、mTLS代发）
+	if d.authManager != nil {
+		insecure := d.config != nil && d.config.AntiDetectionSettings.InsecureSkipVerify
+		if err := d.authManager.AttachToBrowser(chromeCtx, insecure); err != nil {
+			fmt.Printf("  [动态爬虫] ⚠️  启用认证失败: %v\n", err)
+		}
+	}
+
+	// 🆕 v4.9: 记录浏览器流量（defer在cancelChrome之前执行，仍可拉取响应体）
+	if d.harRecorder != nil {
+		harCapture := d.harRecorder.AttachToBrowser(chromeCtx)
+		defer harCapture.Finish()
+	}
+
 	// 启动AJAX拦截器
 	if d.enableAjax {
 		d.ajaxInterceptor = NewAjaxInterceptor(targetURL.Host)
@@ -467,10 +493,17 @@ func (d *DynamicCrawlerImpl) Crawl(targetURL *url.URL) (*Result, error) {
 	// 获取页面状态码和内容类型（通过JavaScript）
 	var statusCode int64
 	var contentType string
+	var characterSet string
 	err = chromedp.Run(chromeCtx,
 		chromedp.Evaluate(`window.performance.getEntriesByType('navigation')[0].responseStart`, &statusCode),
 		chromedp.Evaluate(`document.contentType`, &contentType),
 	)
+	
+	// 🆕 v4.9: 浏览器已按页面声明解码，这里只记录原始字符集
+	if csErr := chromedp.Run(chromeCtx, chromedp.Evaluate(`document.characterSet`, &characterSet)); csErr == nil && characterSet != "" {
+		result.Charset = NormalizeCharset(characterSet)
+		result.CharsetSource = CharsetSourceBrowser
+	}
 
 	// 🆕 v4.6: 标记为成功爬取并记录响应时间
 	result.Crawled = true
@@ -555,6 +588,20 @@ func (d *DynamicCrawlerImpl) Crawl(targetURL *url.URL) (*Result, error) {
 			stats := d.ajaxInterceptor.GetStatistics()
 			fmt.Printf("  [AJAX拦截] 统计: %v\n", stats)
 		}
+
+		// 🆕 v4.9: 带请求体的请求（含请求头），用于SOAP/gRPC-Web服务识别
+		captured := d.ajaxInterceptor.GetCapturedRequests()
+		if len(captured) > 0 {
+			fmt.Printf("  [AJAX拦截] 捕获到 %d 个带请求体的请求\n", len(captured))
+			result.POSTRequests = append(result.POSTRequests, captured...)
+		}
+
+		// 🆕 v4.9: XHR/Fetch文本响应（JSON接口等），交给敏感信息扫描
+		responses := d.ajaxInterceptor.GetCapturedResponses()
+		if len(responses) > 0 {
+			fmt.Printf("  [AJAX拦截] 捕获到 %d 个接口响应\n", len(responses))
+			result.CapturedResponses = append(result.CapturedResponses, responses...)
+		}
 	}
 
 	return result, nil
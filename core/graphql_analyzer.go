@@ -15,32 +15,40 @@ type GraphQLAnalyzer struct {
 	client     *http.Client
 	authHeader string
 	schema     *GraphQLSchema
+	doRequest  func(*http.Request) (*http.Response, error) // 🆕 v4.9 自定义请求函数（复用爬虫的代理/认证配置）
 }
 
 // GraphQLSchema GraphQL Schema
 type GraphQLSchema struct {
-	Types       []GraphQLType      `json:"types"`
-	Queries     []GraphQLField     `json:"queries"`
-	Mutations   []GraphQLField     `json:"mutations"`
-	Directives  []GraphQLDirective `json:"directives"`
+	QueryType        string             `json:"queryType,omitempty"`
+	MutationType     string             `json:"mutationType,omitempty"`
+	SubscriptionType string             `json:"subscriptionType,omitempty"`
+	Types            []GraphQLType      `json:"types"`
+	Queries          []GraphQLField     `json:"queries"`
+	Mutations        []GraphQLField     `json:"mutations"`
+	Subscriptions    []GraphQLField     `json:"subscriptions,omitempty"`
+	Directives       []GraphQLDirective `json:"directives"`
 }
 
 // GraphQLType GraphQL类型
 type GraphQLType struct {
-	Kind        string         `json:"kind"`
-	Name        string         `json:"name"`
-	Description string         `json:"description,omitempty"`
-	Fields      []GraphQLField `json:"fields,omitempty"`
-	InputFields []GraphQLField `json:"inputFields,omitempty"`
-	EnumValues  []string       `json:"enumValues,omitempty"`
+	Kind          string         `json:"kind"`
+	Name          string         `json:"name"`
+	Description   string         `json:"description,omitempty"`
+	Fields        []GraphQLField `json:"fields,omitempty"`
+	InputFields   []GraphQLField `json:"inputFields,omitempty"`
+	EnumValues    []string       `json:"enumValues,omitempty"`
+	Interfaces    []string       `json:"interfaces,omitempty"`
+	PossibleTypes []string       `json:"possibleTypes,omitempty"`
 }
 
 // GraphQLField GraphQL字段
 type GraphQLField struct {
-	Name        string              `json:"name"`
-	Description string              `json:"description,omitempty"`
-	Type        string              `json:"type"`
-	Args        []GraphQLArgument   `json:"args,omitempty"`
+	Name         string            `json:"name"`
+	Description  string            `json:"description,omitempty"`
+	Type         string            `json:"type"`
+	Args         []GraphQLArgument `json:"args,omitempty"`
+	DefaultValue string            `json:"defaultValue,omitempty"` // 输入字段默认值
 }
 
 // GraphQLArgument GraphQL参数
@@ -71,6 +79,16 @@ func (ga *GraphQLAnalyzer) SetAuthentication(authHeader string) {
 	ga.authHeader = authHeader
 }
 
+// SetRequestFunc 设置发送请求的函数（🆕 v4.9，如爬虫的性能优化客户端，自动带上代理和认证）
+func (ga *GraphQLAnalyzer) SetRequestFunc(do func(*http.Request) (*http.Response, error)) {
+	ga.doRequest = do
+}
+
+// GetSchema 获取Analyze得到的Schema
+func (ga *GraphQLAnalyzer) GetSchema() *GraphQLSchema {
+	return ga.schema
+}
+
 // Analyze 分析GraphQL端点
 func (ga *GraphQLAnalyzer) Analyze() (*GraphQLSchema, error) {
 	fmt.Printf("[GraphQL分析] 开始分析: %s\n", ga.endpoint)
@@ -91,86 +109,27 @@ func (ga *GraphQLAnalyzer) Analyze() (*GraphQLSchema, error) {
 
 // introspect 执行Introspection查询
 func (ga *GraphQLAnalyzer) introspect() (*GraphQLSchema, error) {
-	// GraphQL Introspection查询
+	// GraphQL Introspection查询（TypeRef展开7层，足以还原 [[Type!]!]! 等包装类型）
 	query := `
-	{
+	query IntrospectionQuery {
 		__schema {
+			queryType { name }
+			mutationType { name }
+			subscriptionType { name }
 			types {
 				kind
 				name
 				description
-				fields {
-					name
-					description
-					type {
-						name
-						kind
-						ofType {
-							name
-							kind
-						}
-					}
-					args {
-						name
-						description
-						type {
-							name
-							kind
-						}
-						defaultValue
-					}
-				}
-				inputFields {
+				fields(includeDeprecated: true) {
 					name
 					description
-					type {
-						name
-						kind
-					}
-					defaultValue
-				}
-				enumValues {
-					name
-					description
-				}
-			}
-			queryType {
-				name
-				fields {
-					name
-					description
-					type {
-						name
-						kind
-					}
-					args {
-						name
-						description
-						type {
-							name
-							kind
-						}
-					}
-				}
-			}
-			mutationType {
-				name
-				fields {
-					name
-					description
-					type {
-						name
-						kind
-					}
-					args {
-						name
-						description
-						type {
-							name
-							kind
-						}
-					}
+					args { name description type { ...TypeRef } defaultValue }
+					type { ...TypeRef }
 				}
+				inputFields { name description type { ...TypeRef } defaultValue }
+				interfaces { ...TypeRef }
+				enumValues(includeDeprecated: true) { name description }
+				possibleTypes { ...TypeRef }
 			}
 			directives {
 				name
@@ -179,6 +138,11 @@ func (ga *GraphQLAnalyzer) introspect() (*GraphQLSchema, error) {
 			}
 		}
 	}
+	fragment TypeRef on __Type {
+		kind name
+		ofType { kind name ofType { kind name ofType { kind name ofType { kind name
+			ofType { kind name ofType { kind name ofType { kind name } } } } } } }
+	}
 	`
 	
 	// 发送查询
@@ -203,7 +167,11 @@ func (ga *GraphQLAnalyzer) introspect() (*GraphQLSchema, error) {
 		req.Header.Set("Authorization", ga.authHeader)
 	}
 	
-	resp, err := ga.client.Do(req)
+	do := ga.client.Do
+	if ga.doRequest != nil {
+		do = ga.doRequest
+	}
+	resp, err := do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -220,9 +188,11 @@ func (ga *GraphQLAnalyzer) introspect() (*GraphQLSchema, error) {
 		return nil, err
 	}
 	
-	// 检查错误
+	// 检查错误（部分实现在data为null时才视为失败）
 	if errors, ok := result["errors"]; ok {
-		return nil, fmt.Errorf("GraphQL错误: %v", errors)
+		if data, _ := result["data"].(map[string]interface{}); data == nil || data["__schema"] == nil {
+			return nil, fmt.Errorf("GraphQL错误: %v", errors)
+		}
 	}
 	
 	// 提取schema
@@ -250,6 +220,17 @@ func (ga *GraphQLAnalyzer) parseIntrospectionResult(result map[string]interface{
 		return schema
 	}
 	
+	// 根类型名称
+	for key, target := range map[string]*string{
+		"queryType":        &schema.QueryType,
+		"mutationType":     &schema.MutationType,
+		"subscriptionType": &schema.SubscriptionType,
+	} {
+		if rootType, ok := schemaData[key].(map[string]interface{}); ok {
+			*target = getStringValue(rootType, "name")
+		}
+	}
+	
 	// 解析类型
 	if types, ok := schemaData["types"].([]interface{}); ok {
 		for _, t := range types {
@@ -264,25 +245,16 @@ func (ga *GraphQLAnalyzer) parseIntrospectionResult(result map[string]interface{
 		}
 	}
 	
-	// 解析查询
-	if queryType, ok := schemaData["queryType"].(map[string]interface{}); ok {
-		if fields, ok := queryType["fields"].([]interface{}); ok {
-			for _, f := range fields {
-				if fieldMap, ok := f.(map[string]interface{}); ok {
-					schema.Queries = append(schema.Queries, ga.parseField(fieldMap))
-				}
-			}
-		}
-	}
-	
-	// 解析变更
-	if mutationType, ok := schemaData["mutationType"].(map[string]interface{}); ok {
-		if fields, ok := mutationType["fields"].([]interface{}); ok {
-			for _, f := range fields {
-				if fieldMap, ok := f.(map[string]interface{}); ok {
-					schema.Mutations = append(schema.Mutations, ga.parseField(fieldMap))
-				}
-			}
+	// 根类型的字段即查询/变更/订阅
+	for _, t := range schema.Types {
+		switch t.Name {
+		case "":
+		case schema.QueryType:
+			schema.Queries = append(schema.Queries, t.Fields...)
+		case schema.MutationType:
+			schema.Mutations = append(schema.Mutations, t.Fields...)
+		case schema.SubscriptionType:
+			schema.Subscriptions = append(schema.Subscriptions, t.Fields...)
 		}
 	}
 	
@@ -350,6 +322,18 @@ func (ga *GraphQLAnalyzer) parseType(typeMap map[string]interface{}) GraphQLType
 		}
 	}
 	
+	// 解析实现的接口和联合类型成员
+	if interfaces, ok := typeMap["interfaces"].([]interface{}); ok {
+		for _, i := range interfaces {
+			gqlType.Interfaces = append(gqlType.Interfaces, ga.parseTypeRef(i))
+		}
+	}
+	if possibleTypes, ok := typeMap["possibleTypes"].([]interface{}); ok {
+		for _, pt := range possibleTypes {
+			gqlType.PossibleTypes = append(gqlType.PossibleTypes, ga.parseTypeRef(pt))
+		}
+	}
+	
 	return gqlType
 }
 
@@ -358,8 +342,9 @@ func (ga *GraphQLAnalyzer) parseField(fieldMap map[string]interface{}) GraphQLFi
 	field := GraphQLField{
 		Name:        getStringValue(fieldMap, "name"),
 		Description: getStringValue(fieldMap, "description"),
-		Type:        ga.parseTypeRef(fieldMap["type"]),
-		Args:        make([]GraphQLArgument, 0),
+		Type:         ga.parseTypeRef(fieldMap["type"]),
+		Args:         make([]GraphQLArgument, 0),
+		DefaultValue: getStringValue(fieldMap, "defaultValue"),
 	}
 	
 	// 解析参数
@@ -417,89 +402,147 @@ func (ga *GraphQLAnalyzer) GenerateSDL() (string, error) {
 	if ga.schema == nil {
 		return "", fmt.Errorf("schema未初始化，请先调用Analyze()")
 	}
-	
+	return ga.schema.SDL(), nil
+}
+
+// graphQLBuiltinScalars 内置标量（SDL中不需要声明）
+var graphQLBuiltinScalars = map[string]bool{
+	"String": true, "Int": true, "Float": true, "Boolean": true, "ID": true,
+}
+
+// graphQLBuiltinDirectives 内置指令
+var graphQLBuiltinDirectives = map[string]bool{
+	"skip": true, "include": true, "deprecated": true, "specifiedBy": true, "oneOf": true,
+}
+
+// SDL 按类型种类生成SDL（type/interface/union/enum/input/scalar）
+func (schema *GraphQLSchema) SDL() string {
 	var sb strings.Builder
 	
-	// 生成类型定义
-	sb.WriteString("# Types\n\n")
-	for _, t := range ga.schema.Types {
-		// 跳过标量类型
-		if t.Kind == "SCALAR" {
-			continue
+	// 根类型不是默认名称时需要schema声明
+	if (schema.QueryType != "" && schema.QueryType != "Query") ||
+		(schema.MutationType != "" && schema.MutationType != "Mutation") ||
+		(schema.SubscriptionType != "" && schema.SubscriptionType != "Subscription") {
+		sb.WriteString("schema {\n")
+		if schema.QueryType != "" {
+			sb.WriteString(fmt.Sprintf("  query: %s\n", schema.QueryType))
 		}
-		
-		sb.WriteString(fmt.Sprintf("type %s {\n", t.Name))
-		if t.Description != "" {
-			sb.WriteString(fmt.Sprintf("  \"\"\"%s\"\"\"\n", t.Description))
+		if schema.MutationType != "" {
+			sb.WriteString(fmt.Sprintf("  mutation: %s\n", schema.MutationType))
 		}
-		
-		for _, field := range t.Fields {
-			if field.Description != "" {
-				sb.WriteString(fmt.Sprintf("  \"\"\"%s\"\"\"\n", field.Description))
-			}
-			
-			// 字段定义
-			if len(field.Args) > 0 {
-				args := make([]string, 0)
-				for _, arg := range field.Args {
-					argDef := fmt.Sprintf("%s: %s", arg.Name, arg.Type)
-					if arg.DefaultValue != "" {
-						argDef += " = " + arg.DefaultValue
-					}
-					args = append(args, argDef)
-				}
-				sb.WriteString(fmt.Sprintf("  %s(%s): %s\n", field.Name, strings.Join(args, ", "), field.Type))
-			} else {
-				sb.WriteString(fmt.Sprintf("  %s: %s\n", field.Name, field.Type))
-			}
+		if schema.SubscriptionType != "" {
+			sb.WriteString(fmt.Sprintf("  subscription: %s\n", schema.SubscriptionType))
 		}
-		
 		sb.WriteString("}\n\n")
 	}
 	
-	// 生成Query
-	if len(ga.schema.Queries) > 0 {
-		sb.WriteString("type Query {\n")
-		for _, query := range ga.schema.Queries {
-			if query.Description != "" {
-				sb.WriteString(fmt.Sprintf("  \"\"\"%s\"\"\"\n", query.Description))
-			}
-			
-			if len(query.Args) > 0 {
-				args := make([]string, 0)
-				for _, arg := range query.Args {
-					args = append(args, fmt.Sprintf("%s: %s", arg.Name, arg.Type))
-				}
-				sb.WriteString(fmt.Sprintf("  %s(%s): %s\n", query.Name, strings.Join(args, ", "), query.Type))
-			} else {
-				sb.WriteString(fmt.Sprintf("  %s: %s\n", query.Name, query.Type))
+	for _, d := range schema.Directives {
+		if graphQLBuiltinDirectives[d.Name] {
+			continue
+		}
+		writeSDLDescription(&sb, d.Description, "")
+		sb.WriteString(fmt.Sprintf("directive @%s on %s\n\n", d.Name, strings.Join(d.Locations, " | ")))
+	}
+	
+	declared := make(map[string]bool)
+	for _, t := range schema.Types {
+		if t.Name == "" || strings.HasPrefix(t.Name, "__") || graphQLBuiltinScalars[t.Name] {
+			continue
+		}
+		declared[t.Name] = true
+		writeSDLDescription(&sb, t.Description, "")
+		switch t.Kind {
+		case "SCALAR":
+			sb.WriteString(fmt.Sprintf("scalar %s\n\n", t.Name))
+		case "UNION":
+			sb.WriteString(fmt.Sprintf("union %s = %s\n\n", t.Name, strings.Join(t.PossibleTypes, " | ")))
+		case "ENUM":
+			sb.WriteString(fmt.Sprintf("enum %s {\n", t.Name))
+			for _, value := range t.EnumValues {
+				sb.WriteString("  " + value + "\n")
 			}
+			sb.WriteString("}\n\n")
+		case "INPUT_OBJECT":
+			sb.WriteString(fmt.Sprintf("input %s {\n", t.Name))
+			writeSDLFields(&sb, t.InputFields)
+			sb.WriteString("}\n\n")
+		case "INTERFACE":
+			sb.WriteString(fmt.Sprintf("interface %s%s {\n", t.Name, sdlImplements(t.Interfaces)))
+			writeSDLFields(&sb, t.Fields)
+			sb.WriteString("}\n\n")
+		default:
+			sb.WriteString(fmt.Sprintf("type %s%s {\n", t.Name, sdlImplements(t.Interfaces)))
+			writeSDLFields(&sb, t.Fields)
+			sb.WriteString("}\n\n")
+		}
+	}
+	
+	// 类型列表缺失根类型时（非完整的introspection结果）由字段列表补全
+	for _, root := range []struct {
+		name   string
+		fields []GraphQLField
+	}{
+		{defaultString(schema.QueryType, "Query"), schema.Queries},
+		{defaultString(schema.MutationType, "Mutation"), schema.Mutations},
+		{defaultString(schema.SubscriptionType, "Subscription"), schema.Subscriptions},
+	} {
+		if declared[root.name] || len(root.fields) == 0 {
+			continue
 		}
+		sb.WriteString(fmt.Sprintf("type %s {\n", root.name))
+		writeSDLFields(&sb, root.fields)
 		sb.WriteString("}\n\n")
 	}
 	
-	// 生成Mutation
-	if len(ga.schema.Mutations) > 0 {
-		sb.WriteString("type Mutation {\n")
-		for _, mutation := range ga.schema.Mutations {
-			if mutation.Description != "" {
-				sb.WriteString(fmt.Sprintf("  \"\"\"%s\"\"\"\n", mutation.Description))
-			}
-			
-			if len(mutation.Args) > 0 {
-				args := make([]string, 0)
-				for _, arg := range mutation.Args {
-					args = append(args, fmt.Sprintf("%s: %s", arg.Name, arg.Type))
+	return sb.String()
+}
+
+// writeSDLFields 输出字段（含参数和默认值）
+func writeSDLFields(sb *strings.Builder, fields []GraphQLField) {
+	for _, field := range fields {
+		writeSDLDescription(sb, field.Description, "  ")
+		sb.WriteString("  " + field.Name)
+		if len(field.Args) > 0 {
+			args := make([]string, 0, len(field.Args))
+			for _, arg := range field.Args {
+				argDef := fmt.Sprintf("%s: %s", arg.Name, arg.Type)
+				if arg.DefaultValue != "" {
+					argDef += " = " + arg.DefaultValue
 				}
-				sb.WriteString(fmt.Sprintf("  %s(%s): %s\n", mutation.Name, strings.Join(args, ", "), mutation.Type))
-			} else {
-				sb.WriteString(fmt.Sprintf("  %s: %s\n", mutation.Name, mutation.Type))
+				args = append(args, argDef)
 			}
+			sb.WriteString("(" + strings.Join(args, ", ") + ")")
 		}
-		sb.WriteString("}\n\n")
+		sb.WriteString(": " + field.Type)
+		if field.DefaultValue != "" {
+			sb.WriteString(" = " + field.DefaultValue)
+		}
+		sb.WriteString("\n")
 	}
-	
-	return sb.String(), nil
+}
+
+// writeSDLDescription 输出描述（块字符串）
+func writeSDLDescription(sb *strings.Builder, description, indent string) {
+	if description == "" {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("%s\"\"\"%s\"\"\"\n", indent, strings.ReplaceAll(description, `"""`, `\"""`)))
+}
+
+// sdlImplements 生成 implements 子句
+func sdlImplements(interfaces []string) string {
+	if len(interfaces) == 0 {
+		return ""
+	}
+	return " implements " + strings.Join(interfaces, " & ")
+}
+
+// defaultString 为空时返回默认值
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
 }
 
 // ExportToFile 导出到文件
@@ -0,0 +1,1079 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dop251/goja/ast"
+	"github.com/dop251/goja/parser"
+)
+
+// 🆕 v4.9: GraphQL端点发现与操作收集
+// 端点来源：爬取到的链接、JS中的URL、捕获的请求（请求体含query）和常见路径探测；
+// 操作来源：introspection得到的Schema，或（introspection被禁用时）JS包中的gql模板/字符串和捕获的请求体
+
+// GraphQL端点来源（GraphQLEndpoint.Sources）
+const (
+	GraphQLSourceLink       = "link"        // 爬取到的链接/API
+	GraphQLSourceJS         = "js"          // JS中的端点URL
+	GraphQLSourceRequest    = "request"     // 捕获的请求（请求体为GraphQL查询）
+	GraphQLSourceCommonPath = "common_path" // 常见路径探测
+)
+
+// GraphQL操作来源（GraphQLOperation.Origin）
+const (
+	GraphQLOriginIntrospection = "introspection"
+	GraphQLOriginJS            = "js"
+	GraphQLOriginTraffic       = "traffic"
+	GraphQLOriginDocument      = "document" // .graphql/.gql 文件
+)
+
+// GraphQLProbeQuery 端点确认用的最小查询（任何GraphQL服务都能响应 data 或 errors）
+const GraphQLProbeQuery = `{"query":"query{__typename}"}`
+
+// GraphQLCommonPaths 常见的GraphQL端点路径
+var GraphQLCommonPaths = []string{
+	"/graphql",
+	"/api/graphql",
+	"/graphql/v1",
+	"/v1/graphql",
+	"/api/v1/graphql",
+	"/gql",
+	"/query",
+	"/graphql/api",
+	"/graphiql",
+	"/playground",
+}
+
+var (
+	graphQLURLPattern     = regexp.MustCompile(`(?i)/(graphql|graphiql|gql)(/|$|\?|\.php)`)
+	graphQLKeywordPattern = regexp.MustCompile(`\b(query|mutation|subscription)\s+[_A-Za-z][_0-9A-Za-z]*\s*[({@]|\bfragment\s+[_A-Za-z][_0-9A-Za-z]*\s+on\s`)
+	inlineScriptPattern   = regexp.MustCompile(`(?is)<script\b[^>]*>(.*?)</script>`)
+)
+
+// GraphQLEndpoint GraphQL端点
+type GraphQLEndpoint struct {
+	URL                string         `json:"url"`
+	Sources            []string       `json:"sources"`
+	Confirmed          bool           `json:"confirmed"`                     // 探测查询得到了GraphQL响应
+	Introspection      bool           `json:"introspection"`                 // introspection查询成功
+	IntrospectionError string         `json:"introspection_error,omitempty"` // introspection失败原因（多为被禁用）
+	Schema             *GraphQLSchema `json:"schema,omitempty"`
+}
+
+// GraphQLVariable 操作的变量定义
+type GraphQLVariable struct {
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	DefaultValue string `json:"default_value,omitempty"`
+}
+
+// GraphQLOperation 收集到的GraphQL操作
+type GraphQLOperation struct {
+	Type             string                 `json:"type"` // query/mutation/subscription
+	Name             string                 `json:"name,omitempty"`
+	Endpoint         string                 `json:"endpoint,omitempty"`
+	Variables        []GraphQLVariable      `json:"variables,omitempty"`
+	RootFields       []string               `json:"root_fields,omitempty"`
+	Fragments        []string               `json:"fragments,omitempty"`         // 引用的片段
+	ExampleVariables map[string]interface{} `json:"example_variables,omitempty"` // 捕获请求中的变量值
+	Document         string                 `json:"document"`
+	Origin           string                 `json:"origin"`
+	Source           string                 `json:"source,omitempty"` // 发现位置（JS文件/请求URL）
+}
+
+// GraphQLDiscovery GraphQL端点和操作收集器（并发安全）
+type GraphQLDiscovery struct {
+	mutex         sync.Mutex
+	endpoints     map[string]*GraphQLEndpoint
+	endpointOrder []string
+	operations    []*GraphQLOperation
+	seen          map[string]bool   // 操作去重键
+	fragments     map[string]string // 片段名 → 定义
+	maxOperations int
+}
+
+// NewGraphQLDiscovery 创建GraphQL收集器
+func NewGraphQLDiscovery(maxOperations int) *GraphQLDiscovery {
+	if maxOperations <= 0 {
+		maxOperations = 1000
+	}
+	return &GraphQLDiscovery{
+		endpoints:     make(map[string]*GraphQLEndpoint),
+		endpointOrder: make([]string, 0),
+		operations:    make([]*GraphQLOperation, 0),
+		seen:          make(map[string]bool),
+		fragments:     make(map[string]string),
+		maxOperations: maxOperations,
+	}
+}
+
+// IsGraphQLURL 判断URL路径是否像GraphQL端点
+func IsGraphQLURL(rawURL string) bool {
+	return graphQLURLPattern.MatchString(rawURL)
+}
+
+// IsGraphQLResponse 判断响应体是否为GraphQL响应（包含 data 或 errors 的JSON对象）
+func IsGraphQLResponse(body []byte) bool {
+	var resp map[string]json.RawMessage
+	if json.Unmarshal(body, &resp) != nil {
+		return false
+	}
+	if _, ok := resp["data"]; ok {
+		return true
+	}
+	var errors []map[string]interface{}
+	if json.Unmarshal(resp["errors"], &errors) == nil && len(errors) > 0 {
+		_, ok := errors[0]["message"]
+		return ok
+	}
+	return false
+}
+
+// AddEndpoint 记录端点（去掉查询参数和片段），返回端点
+func (gd *GraphQLDiscovery) AddEndpoint(rawURL, source string) *GraphQLEndpoint {
+	endpointURL := graphQLEndpointURL(rawURL)
+	if endpointURL == "" {
+		return nil
+	}
+	gd.mutex.Lock()
+	defer gd.mutex.Unlock()
+	endpoint, exists := gd.endpoints[endpointURL]
+	if !exists {
+		endpoint = &GraphQLEndpoint{URL: endpointURL, Sources: make([]string, 0)}
+		gd.endpoints[endpointURL] = endpoint
+		gd.endpointOrder = append(gd.endpointOrder, endpointURL)
+	}
+	endpoint.Sources = appendUniqueString(endpoint.Sources, source)
+	return endpoint
+}
+
+// GetEndpoints 获取所有端点（按发现顺序）
+func (gd *GraphQLDiscovery) GetEndpoints() []*GraphQLEndpoint {
+	gd.mutex.Lock()
+	defer gd.mutex.Unlock()
+	endpoints := make([]*GraphQLEndpoint, 0, len(gd.endpointOrder))
+	for _, endpointURL := range gd.endpointOrder {
+		endpoints = append(endpoints, gd.endpoints[endpointURL])
+	}
+	return endpoints
+}
+
+// MarkConfirmed 标记端点已确认
+func (gd *GraphQLDiscovery) MarkConfirmed(endpointURL string) {
+	gd.mutex.Lock()
+	defer gd.mutex.Unlock()
+	if endpoint := gd.endpoints[endpointURL]; endpoint != nil {
+		endpoint.Confirmed = true
+	}
+}
+
+// SetSchema 记录端点的introspection结果并生成对应的操作；err非nil表示introspection失败
+func (gd *GraphQLDiscovery) SetSchema(endpointURL string, schema *GraphQLSchema, err error) int {
+	gd.mutex.Lock()
+	endpoint := gd.endpoints[endpointURL]
+	if endpoint != nil {
+		if err != nil {
+			endpoint.IntrospectionError = err.Error()
+		} else {
+			endpoint.Confirmed = true
+			endpoint.Introspection = true
+			endpoint.Schema = schema
+		}
+	}
+	gd.mutex.Unlock()
+	if endpoint == nil || err != nil {
+		return 0
+	}
+
+	kinds := make(map[string]string, len(schema.Types))
+	for _, t := range schema.Types {
+		kinds[t.Name] = t.Kind
+	}
+	added := 0
+	for _, root := range []struct {
+		typ    string
+		fields []GraphQLField
+	}{
+		{"query", schema.Queries},
+		{"mutation", schema.Mutations},
+		{"subscription", schema.Subscriptions},
+	} {
+		for _, field := range root.fields {
+			if gd.addOperation(schemaOperation(root.typ, field, kinds, endpointURL)) {
+				added++
+			}
+		}
+	}
+	return added
+}
+
+// schemaOperation 由根字段生成可直接发送的操作（参数全部作为变量，对象类型只选择 __typename）
+func schemaOperation(typ string, field GraphQLField, kinds map[string]string, endpointURL string) *GraphQLOperation {
+	op := &GraphQLOperation{
+		Type:       typ,
+		Name:       field.Name,
+		Endpoint:   endpointURL,
+		RootFields: []string{field.Name},
+		Origin:     GraphQLOriginIntrospection,
+		Source:     endpointURL,
+	}
+	varDefs := make([]string, 0, len(field.Args))
+	args := make([]string, 0, len(field.Args))
+	for _, arg := range field.Args {
+		op.Variables = append(op.Variables, GraphQLVariable{Name: arg.Name, Type: arg.Type, DefaultValue: arg.DefaultValue})
+		def := fmt.Sprintf("$%s: %s", arg.Name, arg.Type)
+		if arg.DefaultValue != "" {
+			def += " = " + arg.DefaultValue
+		}
+		varDefs = append(varDefs, def)
+		args = append(args, fmt.Sprintf("%s: $%s", arg.Name, arg.Name))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(typ + " " + field.Name)
+	if len(varDefs) > 0 {
+		sb.WriteString("(" + strings.Join(varDefs, ", ") + ")")
+	}
+	sb.WriteString(" {\n  " + field.Name)
+	if len(args) > 0 {
+		sb.WriteString("(" + strings.Join(args, ", ") + ")")
+	}
+	switch kinds[strings.Trim(field.Type, "[]!")] {
+	case "OBJECT", "INTERFACE", "UNION":
+		sb.WriteString(" {\n    __typename\n  }")
+	}
+	sb.WriteString("\n}")
+	op.Document = sb.String()
+	return op
+}
+
+// HarvestScript 从JS代码的字符串常量和模板字符串（含 gql`...` 标签模板）中收集操作，返回新增数量
+func (gd *GraphQLDiscovery) HarvestScript(code, source string) int {
+	if !graphQLKeywordPattern.MatchString(code) {
+		return 0
+	}
+	added := 0
+	for _, literal := range graphQLScriptLiterals(code, source) {
+		added += gd.HarvestDocument(literal, source, "", GraphQLOriginJS)
+	}
+	return added
+}
+
+// HarvestPage 从HTML页面的内联脚本中收集操作
+func (gd *GraphQLDiscovery) HarvestPage(html, source string) int {
+	if !graphQLKeywordPattern.MatchString(html) {
+		return 0
+	}
+	added := 0
+	for _, match := range inlineScriptPattern.FindAllStringSubmatch(html, -1) {
+		added += gd.HarvestScript(match[1], source)
+	}
+	return added
+}
+
+// HarvestDocument 解析GraphQL文档并收集其中的操作和片段，返回新增操作数量
+func (gd *GraphQLDiscovery) HarvestDocument(document, source, endpoint, origin string) int {
+	ops, fragments := ParseGraphQLDocument(document)
+	gd.mutex.Lock()
+	for name, definition := range fragments {
+		if _, exists := gd.fragments[name]; !exists {
+			gd.fragments[name] = definition
+		}
+	}
+	gd.mutex.Unlock()
+
+	added := 0
+	for _, op := range ops {
+		op.Endpoint = endpoint
+		op.Origin = origin
+		op.Source = source
+		if gd.addOperation(op) {
+			added++
+		}
+	}
+	return added
+}
+
+// HarvestRequest 识别捕获的GraphQL请求（JSON请求体、批量请求、GET/表单的query参数），
+// 记录端点和请求中的操作及变量；不是GraphQL请求时返回false
+func (gd *GraphQLDiscovery) HarvestRequest(method, rawURL, contentType, body string) bool {
+	type payload struct {
+		query     string
+		variables map[string]interface{}
+	}
+	payloads := make([]payload, 0)
+	persisted := false
+
+	addJSON := func(obj map[string]interface{}) {
+		query, _ := obj["query"].(string)
+		variables, _ := obj["variables"].(map[string]interface{})
+		if query != "" {
+			payloads = append(payloads, payload{query, variables})
+		} else if extensions, ok := obj["extensions"].(map[string]interface{}); ok && extensions["persistedQuery"] != nil {
+			persisted = true // Automatic Persisted Queries：只有哈希，没有查询文本
+		}
+	}
+	addValues := func(values url.Values) {
+		if query := values.Get("query"); query != "" {
+			var variables map[string]interface{}
+			json.Unmarshal([]byte(values.Get("variables")), &variables)
+			payloads = append(payloads, payload{query, variables})
+		}
+		if values.Get("extensions") != "" && strings.Contains(values.Get("extensions"), "persistedQuery") {
+			persisted = true
+		}
+	}
+
+	trimmed := strings.TrimSpace(body)
+	switch {
+	case strings.HasPrefix(trimmed, "{"):
+		var obj map[string]interface{}
+		if json.Unmarshal([]byte(trimmed), &obj) == nil {
+			addJSON(obj)
+		}
+	case strings.HasPrefix(trimmed, "["):
+		var batch []map[string]interface{}
+		if json.Unmarshal([]byte(trimmed), &batch) == nil {
+			for _, obj := range batch {
+				addJSON(obj)
+			}
+		}
+	case strings.Contains(contentType, "graphql"):
+		payloads = append(payloads, payload{query: trimmed})
+	case trimmed != "":
+		if values, err := url.ParseQuery(trimmed); err == nil {
+			addValues(values)
+		}
+	}
+	if u, err := url.Parse(rawURL); err == nil {
+		addValues(u.Query())
+	}
+
+	harvested := false
+	for _, p := range payloads {
+		ops, fragments := ParseGraphQLDocument(p.query)
+		if len(ops) == 0 {
+			continue
+		}
+		harvested = true
+		gd.mutex.Lock()
+		for name, definition := range fragments {
+			gd.fragments[name] = definition
+		}
+		gd.mutex.Unlock()
+		for _, op := range ops {
+			op.Endpoint = graphQLEndpointURL(rawURL)
+			op.Origin = GraphQLOriginTraffic
+			op.Source = strings.ToUpper(method) + " " + rawURL
+			if len(p.variables) > 0 {
+				op.ExampleVariables = p.variables
+			}
+			gd.addOperation(op)
+		}
+	}
+	if harvested || (persisted && IsGraphQLURL(rawURL)) {
+		gd.AddEndpoint(rawURL, GraphQLSourceRequest)
+		return true
+	}
+	return false
+}
+
+// addOperation 去重后加入操作
+func (gd *GraphQLDiscovery) addOperation(op *GraphQLOperation) bool {
+	key := op.Type + "|" + op.Name + "|" + op.Endpoint + "|" + strings.Join(strings.Fields(op.Document), " ")
+	gd.mutex.Lock()
+	defer gd.mutex.Unlock()
+	if gd.seen[key] || len(gd.operations) >= gd.maxOperations {
+		return false
+	}
+	gd.seen[key] = true
+	gd.operations = append(gd.operations, op)
+	return true
+}
+
+// AssignDefaultEndpoint 为没有端点的操作（JS中收集的）指定端点：优先已确认的端点，其次第一个发现的端点
+func (gd *GraphQLDiscovery) AssignDefaultEndpoint() {
+	gd.mutex.Lock()
+	defer gd.mutex.Unlock()
+	defaultURL := ""
+	for _, endpointURL := range gd.endpointOrder {
+		if gd.endpoints[endpointURL].Confirmed {
+			defaultURL = endpointURL
+			break
+		}
+	}
+	if defaultURL == "" && len(gd.endpointOrder) > 0 {
+		defaultURL = gd.endpointOrder[0]
+	}
+	for _, op := range gd.operations {
+		if op.Endpoint == "" {
+			op.Endpoint = defaultURL
+		}
+	}
+}
+
+// GetOperations 获取收集到的操作
+func (gd *GraphQLDiscovery) GetOperations() []*GraphQLOperation {
+	gd.mutex.Lock()
+	defer gd.mutex.Unlock()
+	operations := make([]*GraphQLOperation, len(gd.operations))
+	copy(operations, gd.operations)
+	return operations
+}
+
+// GenerateSDL 合并所有端点introspection得到的SDL（多个端点时以注释分隔）
+func (gd *GraphQLDiscovery) GenerateSDL() string {
+	var sb strings.Builder
+	for _, endpoint := range gd.GetEndpoints() {
+		if endpoint.Schema == nil {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("# Endpoint: %s\n\n", endpoint.URL))
+		sb.WriteString(endpoint.Schema.SDL())
+	}
+	return sb.String()
+}
+
+// GenerateOperationsDocument 生成包含所有操作和被引用片段的GraphQL文档
+func (gd *GraphQLDiscovery) GenerateOperationsDocument() string {
+	operations := gd.GetOperations()
+	gd.mutex.Lock()
+	fragments := make(map[string]string, len(gd.fragments))
+	for name, definition := range gd.fragments {
+		fragments[name] = definition
+	}
+	gd.mutex.Unlock()
+
+	var sb strings.Builder
+	referenced := make(map[string]bool)
+	for _, op := range operations {
+		sb.WriteString(fmt.Sprintf("# %s | endpoint: %s | source: %s\n", op.Origin, op.Endpoint, op.Source))
+		if len(op.Variables) > 0 {
+			vars := make([]string, 0, len(op.Variables))
+			for _, v := range op.Variables {
+				vars = append(vars, "$"+v.Name+": "+v.Type)
+			}
+			sb.WriteString("# variables: " + strings.Join(vars, ", ") + "\n")
+		}
+		sb.WriteString(strings.TrimSpace(op.Document) + "\n\n")
+		for _, name := range op.Fragments {
+			if !strings.Contains(op.Document, "fragment "+name+" ") {
+				referenced[name] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(referenced))
+	for name := range referenced {
+		if _, ok := fragments[name]; ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if len(names) > 0 {
+		sb.WriteString("# Fragments\n\n")
+		for _, name := range names {
+			sb.WriteString(strings.TrimSpace(fragments[name]) + "\n\n")
+		}
+	}
+	return sb.String()
+}
+
+// GetStatistics 获取统计信息
+func (gd *GraphQLDiscovery) GetStatistics() map[string]int {
+	stats := map[string]int{"endpoints": 0, "confirmed": 0, "introspection": 0, "operations": 0}
+	for _, endpoint := range gd.GetEndpoints() {
+		stats["endpoints"]++
+		if endpoint.Confirmed {
+			stats["confirmed"]++
+		}
+		if endpoint.Introspection {
+			stats["introspection"]++
+		}
+	}
+	for _, op := range gd.GetOperations() {
+		stats["operations"]++
+		stats[op.Origin]++
+	}
+	return stats
+}
+
+// PrintReport 打印GraphQL发现报告
+func (gd *GraphQLDiscovery) PrintReport() {
+	endpoints := gd.GetEndpoints()
+	operations := gd.GetOperations()
+	if len(endpoints) == 0 && len(operations) == 0 {
+		return
+	}
+
+	fmt.Printf("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	fmt.Printf("【GraphQL】端点 %d 个，操作 %d 个\n", len(endpoints), len(operations))
+	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	for _, endpoint := range endpoints {
+		status := "未确认"
+		switch {
+		case endpoint.Introspection:
+			status = fmt.Sprintf("introspection成功（类型 %d）", len(endpoint.Schema.Types))
+		case endpoint.IntrospectionError != "":
+			status = "已确认，introspection失败: " + truncateString(endpoint.IntrospectionError, 80)
+		case endpoint.Confirmed:
+			status = "已确认"
+		}
+		fmt.Printf("  %s [%s] %s\n", endpoint.URL, strings.Join(endpoint.Sources, ","), status)
+	}
+	byType := make(map[string]int)
+	for _, op := range operations {
+		byType[op.Type]++
+	}
+	if len(operations) > 0 {
+		fmt.Printf("  操作: query %d, mutation %d, subscription %d\n", byType["query"], byType["mutation"], byType["subscription"])
+	}
+}
+
+// graphQLEndpointURL 去掉查询参数和片段
+func graphQLEndpointURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// graphQLScriptLiterals 提取JS中可能是GraphQL文档的字符串常量和模板字符串
+// 模板中的 ${...} 插值（通常是片段）替换为空白，片段定义在其他常量中单独收集
+func graphQLScriptLiterals(code, source string) []string {
+	literals := make([]string, 0)
+	consider := func(text string) {
+		if graphQLKeywordPattern.MatchString(text) {
+			literals = append(literals, text)
+		}
+	}
+	program, err := parser.ParseFile(nil, source, code, parser.IgnoreRegExpErrors, parser.WithDisableSourceMaps)
+	if err != nil {
+		// ES模块（import/export）等解析器不支持的语法：退化为逐字符扫描字符串
+		for _, literal := range scanJSStringLiterals(code) {
+			consider(literal)
+		}
+		return literals
+	}
+	walkJSAST(program, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.StringLiteral:
+			consider(string(n.Value))
+		case *ast.TemplateLiteral:
+			parts := make([]string, 0, len(n.Elements))
+			for _, elem := range n.Elements {
+				parts = append(parts, string(elem.Parsed))
+			}
+			consider(strings.Join(parts, "\n"))
+		}
+		return true
+	})
+	return literals
+}
+
+// scanJSStringLiterals 逐字符扫描JS中的字符串和模板字符串（跳过注释，模板插值替换为换行），返回反转义后的内容
+func scanJSStringLiterals(code string) []string {
+	literals := make([]string, 0)
+	for i := 0; i < len(code); i++ {
+		switch c := code[i]; {
+		case c == '/' && i+1 < len(code) && code[i+1] == '/':
+			for i < len(code) && code[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(code) && code[i+1] == '*':
+			end := strings.Index(code[i+2:], "*/")
+			if end < 0 {
+				return literals
+			}
+			i += end + 3
+		case c == '"' || c == '\'' || c == '`':
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(code) && code[j] != c; j++ {
+				switch {
+				case code[j] == '\\' && j+1 < len(code):
+					j++
+					sb.WriteString(unescapeJSChar(code, &j))
+				case c == '`' && code[j] == '$' && j+1 < len(code) && code[j+1] == '{':
+					// 跳过插值表达式
+					depth := 0
+					for ; j < len(code); j++ {
+						if code[j] == '{' {
+							depth++
+						} else if code[j] == '}' {
+							depth--
+							if depth == 0 {
+								break
+							}
+						}
+					}
+					sb.WriteByte('\n')
+				case c != '`' && code[j] == '\n':
+					j = len(code) // 未闭合的字符串（多为正则字面量中的引号），放弃
+				default:
+					sb.WriteByte(code[j])
+				}
+			}
+			if j >= len(code) {
+				// 未闭合：从下一个字符继续扫描
+				continue
+			}
+			literals = append(literals, sb.String())
+			i = j
+		}
+	}
+	return literals
+}
+
+// unescapeJSChar 反转义JS字符串中 code[*j] 处的转义字符（*j指向反斜杠后的字符，返回后指向转义序列最后一个字符）
+func unescapeJSChar(code string, j *int) string {
+	switch code[*j] {
+	case 'n':
+		return "\n"
+	case 't':
+		return "\t"
+	case 'r':
+		return "\r"
+	case 'u':
+		if *j+4 < len(code) {
+			var r rune
+			if _, err := fmt.Sscanf(code[*j+1:*j+5], "%04x", &r); err == nil {
+				*j += 4
+				return string(r)
+			}
+		}
+	case 'x':
+		if *j+2 < len(code) {
+			var b byte
+			if _, err := fmt.Sscanf(code[*j+1:*j+3], "%02x", &b); err == nil {
+				*j += 2
+				return string(rune(b))
+			}
+		}
+	case '\n':
+		return "" // 行尾续行
+	}
+	return string(code[*j])
+}
+
+// ========== GraphQL文档解析 ==========
+
+// gqlToken 词法单元
+type gqlToken struct {
+	kind  byte // 'n' 名称, 'p' 标点, 's' 字符串, 'd' 数字
+	value string
+	start int
+	end   int
+}
+
+// tokenizeGraphQL 词法分析；遇到GraphQL中不合法的字符时返回nil（过滤普通文本）
+func tokenizeGraphQL(doc string) []gqlToken {
+	tokens := make([]gqlToken, 0, len(doc)/4)
+	for i := 0; i < len(doc); {
+		c := doc[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(doc) && doc[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(doc[i:], `"""`):
+			end := strings.Index(doc[i+3:], `"""`)
+			if end < 0 {
+				return nil
+			}
+			tokens = append(tokens, gqlToken{'s', doc[i : i+end+6], i, i + end + 6})
+			i += end + 6
+		case c == '"':
+			j := i + 1
+			for j < len(doc) && doc[j] != '"' && doc[j] != '\n' {
+				if doc[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(doc) || doc[j] != '"' {
+				return nil
+			}
+			tokens = append(tokens, gqlToken{'s', doc[i : j+1], i, j + 1})
+			i = j + 1
+		case strings.HasPrefix(doc[i:], "..."):
+			tokens = append(tokens, gqlToken{'p', "...", i, i + 3})
+			i += 3
+		case strings.IndexByte("!$&()[]{}:=@|", c) >= 0:
+			tokens = append(tokens, gqlToken{'p', string(c), i, i + 1})
+			i++
+		case c == '_' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z'):
+			j := i + 1
+			for j < len(doc) && (doc[j] == '_' || (doc[j] >= 'A' && doc[j] <= 'Z') || (doc[j] >= 'a' && doc[j] <= 'z') || (doc[j] >= '0' && doc[j] <= '9')) {
+				j++
+			}
+			tokens = append(tokens, gqlToken{'n', doc[i:j], i, j})
+			i = j
+		case c == '-' || (c >= '0' && c <= '9'):
+			j := i + 1
+			for j < len(doc) && strings.IndexByte("0123456789.eE+-", doc[j]) >= 0 {
+				j++
+			}
+			tokens = append(tokens, gqlToken{'d', doc[i:j], i, j})
+			i = j
+		default:
+			return nil
+		}
+	}
+	return tokens
+}
+
+// gqlParser 语法分析状态
+type gqlParser struct {
+	doc    string
+	tokens []gqlToken
+	pos    int
+}
+
+// gqlFragment 片段定义
+type gqlFragment struct {
+	text    string
+	spreads []string
+}
+
+func (p *gqlParser) peek() *gqlToken {
+	if p.pos < len(p.tokens) {
+		return &p.tokens[p.pos]
+	}
+	return nil
+}
+
+// is 判断当前词法单元是否为指定的标点/名称
+func (p *gqlParser) is(kind byte, value string) bool {
+	t := p.peek()
+	return t != nil && t.kind == kind && (value == "" || t.value == value)
+}
+
+// expect 消费指定的词法单元
+func (p *gqlParser) expect(kind byte, value string) (*gqlToken, bool) {
+	if !p.is(kind, value) {
+		return nil, false
+	}
+	p.pos++
+	return &p.tokens[p.pos-1], true
+}
+
+// ParseGraphQLDocument 解析GraphQL文档，返回操作（文档中包含被引用的本地片段）和片段定义；
+// 文档不合法时返回空
+func ParseGraphQLDocument(doc string) ([]*GraphQLOperation, map[string]string) {
+	tokens := tokenizeGraphQL(doc)
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	p := &gqlParser{doc: doc, tokens: tokens}
+	operations := make([]*GraphQLOperation, 0)
+	fragments := make(map[string]*gqlFragment)
+	opSpreads := make([][]string, 0)
+
+	for p.peek() != nil {
+		t := p.peek()
+		switch {
+		case t.kind == 'n' && (t.value == "query" || t.value == "mutation" || t.value == "subscription"):
+			op, spreads, ok := p.parseOperation()
+			if !ok {
+				return nil, nil
+			}
+			operations = append(operations, op)
+			opSpreads = append(opSpreads, spreads)
+		case t.kind == 'p' && t.value == "{":
+			// 查询简写：{ user { id } }
+			start := t.start
+			fields, spreads, end, ok := p.parseSelectionSet(true)
+			if !ok || len(fields) == 0 {
+				return nil, nil
+			}
+			operations = append(operations, &GraphQLOperation{Type: "query", RootFields: fields, Document: doc[start:end]})
+			opSpreads = append(opSpreads, spreads)
+		case t.kind == 'n' && t.value == "fragment":
+			name, fragment, ok := p.parseFragment()
+			if !ok {
+				return nil, nil
+			}
+			fragments[name] = fragment
+		default:
+			return nil, nil
+		}
+	}
+
+	definitions := make(map[string]string, len(fragments))
+	for name, fragment := range fragments {
+		definitions[name] = fragment.text
+	}
+	for i, op := range operations {
+		// 展开片段引用（含片段间的嵌套引用）
+		used := make([]string, 0)
+		queue := append([]string{}, opSpreads[i]...)
+		for len(queue) > 0 {
+			name := queue[0]
+			queue = queue[1:]
+			if containsString(used, name) {
+				continue
+			}
+			used = append(used, name)
+			if fragment, ok := fragments[name]; ok {
+				queue = append(queue, fragment.spreads...)
+			}
+		}
+		op.Fragments = used
+		for _, name := range used {
+			if fragment, ok := fragments[name]; ok {
+				op.Document += "\n\n" + fragment.text
+			}
+		}
+	}
+	return operations, definitions
+}
+
+// parseOperation 解析 query/mutation/subscription 定义
+func (p *gqlParser) parseOperation() (*GraphQLOperation, []string, bool) {
+	keyword := p.peek()
+	p.pos++
+	op := &GraphQLOperation{Type: keyword.value, Variables: make([]GraphQLVariable, 0)}
+	if name, ok := p.expect('n', ""); ok {
+		op.Name = name.value
+	}
+	if p.is('p', "(") {
+		variables, ok := p.parseVariableDefinitions()
+		if !ok {
+			return nil, nil, false
+		}
+		op.Variables = variables
+	}
+	if !p.skipDirectives() {
+		return nil, nil, false
+	}
+	fields, spreads, end, ok := p.parseSelectionSet(true)
+	if !ok {
+		return nil, nil, false
+	}
+	op.RootFields = fields
+	op.Document = p.doc[keyword.start:end]
+	return op, spreads, true
+}
+
+// parseFragment 解析 fragment Name on Type { ... }
+func (p *gqlParser) parseFragment() (string, *gqlFragment, bool) {
+	start := p.peek().start
+	p.pos++
+	name, ok := p.expect('n', "")
+	if !ok || name.value == "on" {
+		return "", nil, false
+	}
+	if _, ok := p.expect('n', "on"); !ok {
+		return "", nil, false
+	}
+	if _, ok := p.expect('n', ""); !ok {
+		return "", nil, false
+	}
+	if !p.skipDirectives() {
+		return "", nil, false
+	}
+	_, spreads, end, ok := p.parseSelectionSet(false)
+	if !ok {
+		return "", nil, false
+	}
+	return name.value, &gqlFragment{text: p.doc[start:end], spreads: spreads}, true
+}
+
+// parseVariableDefinitions 解析 ($id: ID!, $first: Int = 10)
+func (p *gqlParser) parseVariableDefinitions() ([]GraphQLVariable, bool) {
+	p.pos++ // (
+	variables := make([]GraphQLVariable, 0)
+	for !p.is('p', ")") {
+		if _, ok := p.expect('p', "$"); !ok {
+			return nil, false
+		}
+		name, ok := p.expect('n', "")
+		if !ok {
+			return nil, false
+		}
+		if _, ok := p.expect('p', ":"); !ok {
+			return nil, false
+		}
+		typ, ok := p.parseTypeRef()
+		if !ok {
+			return nil, false
+		}
+		variable := GraphQLVariable{Name: name.value, Type: typ}
+		if p.is('p', "=") {
+			p.pos++
+			value, ok := p.parseValue()
+			if !ok {
+				return nil, false
+			}
+			variable.DefaultValue = value
+		}
+		if !p.skipDirectives() {
+			return nil, false
+		}
+		variables = append(variables, variable)
+	}
+	p.pos++ // )
+	return variables, true
+}
+
+// parseTypeRef 解析类型引用：Name、[Type]、Type!
+func (p *gqlParser) parseTypeRef() (string, bool) {
+	var typ string
+	if p.is('p', "[") {
+		p.pos++
+		inner, ok := p.parseTypeRef()
+		if !ok {
+			return "", false
+		}
+		if _, ok := p.expect('p', "]"); !ok {
+			return "", false
+		}
+		typ = "[" + inner + "]"
+	} else {
+		name, ok := p.expect('n', "")
+		if !ok {
+			return "", false
+		}
+		typ = name.value
+	}
+	if p.is('p', "!") {
+		p.pos++
+		typ += "!"
+	}
+	return typ, true
+}
+
+// parseValue 跳过一个值（标量、变量、列表或对象），返回其原文
+func (p *gqlParser) parseValue() (string, bool) {
+	first := p.peek()
+	if first == nil {
+		return "", false
+	}
+	if first.kind == 'p' && first.value == "$" {
+		p.pos++
+		name, ok := p.expect('n', "")
+		if !ok {
+			return "", false
+		}
+		return p.doc[first.start:name.end], true
+	}
+	if first.kind == 'p' && (first.value == "[" || first.value == "{") {
+		depth := 0
+		for t := p.peek(); t != nil; t = p.peek() {
+			p.pos++
+			if t.kind == 'p' && (t.value == "[" || t.value == "{") {
+				depth++
+			} else if t.kind == 'p' && (t.value == "]" || t.value == "}") {
+				depth--
+				if depth == 0 {
+					return p.doc[first.start:t.end], true
+				}
+			}
+		}
+		return "", false
+	}
+	if first.kind == 'p' {
+		return "", false
+	}
+	p.pos++
+	return first.value, true
+}
+
+// skipDirectives 跳过 @directive(args)
+func (p *gqlParser) skipDirectives() bool {
+	for p.is('p', "@") {
+		p.pos++
+		if _, ok := p.expect('n', ""); !ok {
+			return false
+		}
+		if p.is('p', "(") && !p.skipParens() {
+			return false
+		}
+	}
+	return true
+}
+
+// skipParens 跳过括号内的参数
+func (p *gqlParser) skipParens() bool {
+	depth := 0
+	for t := p.peek(); t != nil; t = p.peek() {
+		p.pos++
+		if t.kind == 'p' && t.value == "(" {
+			depth++
+		} else if t.kind == 'p' && t.value == ")" {
+			depth--
+			if depth == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseSelectionSet 解析选择集，返回第一层字段名（别名取实际字段）、引用的片段和结束位置
+func (p *gqlParser) parseSelectionSet(collectFields bool) ([]string, []string, int, bool) {
+	if !p.is('p', "{") {
+		return nil, nil, 0, false
+	}
+	fields := make([]string, 0)
+	spreads := make([]string, 0)
+	depth := 0
+	for t := p.peek(); t != nil; t = p.peek() {
+		p.pos++
+		switch {
+		case t.kind == 'p' && t.value == "{":
+			depth++
+		case t.kind == 'p' && t.value == "}":
+			depth--
+			if depth == 0 {
+				return fields, spreads, t.end, true
+			}
+		case t.kind == 'p' && t.value == "(":
+			p.pos--
+			if !p.skipParens() {
+				return nil, nil, 0, false
+			}
+		case t.kind == 'p' && t.value == "@":
+			p.pos--
+			if !p.skipDirectives() {
+				return nil, nil, 0, false
+			}
+		case t.kind == 'p' && t.value == "...":
+			if p.is('n', "on") {
+				p.pos += 2 // 内联片段：... on Type
+			} else if name, ok := p.expect('n', ""); ok {
+				spreads = appendUniqueString(spreads, name.value)
+			}
+		case t.kind == 'n' && depth == 1 && collectFields:
+			field := t.value
+			if p.is('p', ":") {
+				p.pos++
+				actual, ok := p.expect('n', "")
+				if !ok {
+					return nil, nil, 0, false
+				}
+				field = actual.value
+			}
+			fields = appendUniqueString(fields, field)
+		case t.kind == 'n':
+			if p.is('p', ":") {
+				p.pos++ // 别名
+			}
+		default:
+			return nil, nil, 0, false // 选择集中不应出现其他标点或字面量
+		}
+	}
+	return nil, nil, 0, false
+}
@@ -0,0 +1,338 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// 🆕 v4.9: 无头浏览器流量的HAR记录
+// 通过CDP网络事件还原每个请求：请求头取浏览器实际发出的版本（含Cookie），
+// 重定向的每一跳单独记录，页面加载完成后按需拉取响应体
+
+// harBrowserFinishTimeout 结束时等待响应体拉取的最长时间
+const harBrowserFinishTimeout = 5 * time.Second
+
+// HARBrowserCapture 单个浏览器上下文的流量记录
+type HARBrowserCapture struct {
+	recorder *HARRecorder
+	ctx      context.Context
+	mutex    sync.Mutex
+	pending  map[network.RequestID]*harBrowserRequest
+	bodies   sync.WaitGroup
+	finished bool
+}
+
+// harBrowserRequest 尚未完成的浏览器请求
+type harBrowserRequest struct {
+	entry     *HAREntry
+	startMono time.Time // 请求开始（单调时钟）
+	timing    *network.ResourceTiming
+}
+
+// AttachToBrowser 监听浏览器网络事件并记录流量；爬取结束前需调用Finish
+func (hr *HARRecorder) AttachToBrowser(ctx context.Context) *HARBrowserCapture {
+	capture := &HARBrowserCapture{
+		recorder: hr,
+		ctx:      ctx,
+		pending:  make(map[network.RequestID]*harBrowserRequest),
+	}
+	chromedp.ListenTarget(ctx, capture.handleEvent)
+	return capture
+}
+
+// Finish 等待响应体拉取完成（最多harBrowserFinishTimeout），并写入仍未完成的请求
+func (bc *HARBrowserCapture) Finish() {
+	done := make(chan struct{})
+	go func() {
+		bc.bodies.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(harBrowserFinishTimeout):
+	}
+
+	bc.mutex.Lock()
+	bc.finished = true
+	pending := bc.pending
+	bc.pending = make(map[network.RequestID]*harBrowserRequest)
+	bc.mutex.Unlock()
+
+	for _, request := range pending {
+		if request.entry.Response.Status == 0 {
+			request.entry.Comment = "页面结束时请求尚未完成"
+		}
+		bc.recorder.AddEntry(request.entry)
+	}
+}
+
+// handleEvent 处理CDP网络事件（在事件循环中调用，不能阻塞）
+func (bc *HARBrowserCapture) handleEvent(ev interface{}) {
+	switch e := ev.(type) {
+	case *network.EventRequestWillBeSent:
+		bc.onRequest(e)
+	case *network.EventResponseReceived:
+		bc.onResponse(e)
+	case *network.EventLoadingFinished:
+		bc.onFinished(e)
+	case *network.EventLoadingFailed:
+		bc.onFailed(e)
+	}
+}
+
+func (bc *HARBrowserCapture) onRequest(e *network.EventRequestWillBeSent) {
+	if e.Request == nil || harSkipBrowserURL(e.Request.URL) {
+		return
+	}
+	bc.mutex.Lock()
+	defer bc.mutex.Unlock()
+	if bc.finished {
+		return
+	}
+
+	// 重定向沿用同一个RequestID：先用重定向响应结束上一跳
+	if previous, exists := bc.pending[e.RequestID]; exists && e.RedirectResponse != nil {
+		delete(bc.pending, e.RequestID)
+		bc.applyResponse(previous, e.RedirectResponse)
+		previous.entry.Response.Content = harContent(e.RedirectResponse.MimeType, nil, 0, false)
+		bc.finishTimings(previous, monotonicOf(e.Timestamp))
+		bc.recorder.AddEntry(previous.entry)
+	}
+
+	req, err := http.NewRequest(e.Request.Method, e.Request.URL+e.Request.URLFragment, nil)
+	if err != nil {
+		return
+	}
+	req.Header = harHTTPHeader(e.Request.Headers)
+
+	started := time.Now()
+	if e.WallTime != nil {
+		started = e.WallTime.Time()
+	}
+	var body []byte
+	if e.Request.PostData != "" {
+		body = []byte(e.Request.PostData)
+	}
+	entry := newHAREntry(req, body, started, HARSourceHeadless)
+	if e.Request.HasPostData && e.Request.PostData == "" {
+		entry.Comment = "请求体过大，浏览器未提供内容"
+	}
+	bc.pending[e.RequestID] = &harBrowserRequest{
+		entry:     entry,
+		startMono: monotonicOf(e.Timestamp),
+	}
+}
+
+func (bc *HARBrowserCapture) onResponse(e *network.EventResponseReceived) {
+	if e.Response == nil {
+		return
+	}
+	bc.mutex.Lock()
+	defer bc.mutex.Unlock()
+	if request, exists := bc.pending[e.RequestID]; exists {
+		bc.applyResponse(request, e.Response)
+	}
+}
+
+func (bc *HARBrowserCapture) onFinished(e *network.EventLoadingFinished) {
+	bc.mutex.Lock()
+	request, exists := bc.pending[e.RequestID]
+	if !exists || bc.finished {
+		bc.mutex.Unlock()
+		return
+	}
+	delete(bc.pending, e.RequestID)
+	request.entry.Response.BodySize = int64(e.EncodedDataLength)
+	bc.finishTimings(request, monotonicOf(e.Timestamp))
+	// 先登记再解锁，保证Finish能等到这次拉取
+	fetchBody := bc.recorder.includeBodies && request.entry.Response.Status != 0 &&
+		request.entry.Response.RedirectURL == ""
+	if fetchBody {
+		bc.bodies.Add(1)
+	}
+	bc.mutex.Unlock()
+
+	if !fetchBody {
+		request.entry.Response.Content = harContent(request.entry.Response.Content.MimeType, nil, int64(e.EncodedDataLength), false)
+		bc.recorder.AddEntry(request.entry)
+		return
+	}
+
+	// 不能在事件回调中同步执行CDP命令
+	go func() {
+		defer bc.bodies.Done()
+		var body []byte
+		err := chromedp.Run(bc.ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			var err error
+			body, err = network.GetResponseBody(e.RequestID).Do(ctx)
+			return err
+		}))
+
+		mimeType := request.entry.Response.Content.MimeType
+		total := int64(len(body))
+		truncated := false
+		if limit := bc.recorder.maxBodySize; limit > 0 && len(body) > limit {
+			body, truncated = body[:limit], true
+		}
+		request.entry.Response.Content = harContent(mimeType, body, total, truncated)
+		if err != nil {
+			request.entry.Response.Content.Size = int64(e.EncodedDataLength)
+			request.entry.Response.Content.Comment = "获取响应体失败: " + err.Error()
+		}
+		bc.recorder.AddEntry(request.entry)
+	}()
+}
+
+func (bc *HARBrowserCapture) onFailed(e *network.EventLoadingFailed) {
+	bc.mutex.Lock()
+	request, exists := bc.pending[e.RequestID]
+	if !exists || bc.finished {
+		bc.mutex.Unlock()
+		return
+	}
+	delete(bc.pending, e.RequestID)
+	bc.finishTimings(request, monotonicOf(e.Timestamp))
+	bc.mutex.Unlock()
+
+	if e.Canceled {
+		request.entry.Comment = "请求被取消"
+	} else {
+		request.entry.Comment = "请求失败: " + e.ErrorText
+	}
+	if request.entry.Response.Content.MimeType == "" {
+		request.entry.Response.Content.MimeType = "x-unknown"
+	}
+	bc.recorder.AddEntry(request.entry)
+}
+
+// applyResponse 填入响应头、状态和实际发出的请求头（调用方持有锁）
+func (bc *HARBrowserCapture) applyResponse(request *harBrowserRequest, response *network.Response) {
+	entry := request.entry
+	if len(response.RequestHeaders) > 0 {
+		// 浏览器实际发出的请求头包含Cookie等在RequestWillBeSent中缺失的头
+		req, err := http.NewRequest(entry.Request.Method, entry.Request.URL, nil)
+		if err == nil {
+			req.Header = harHTTPHeader(response.RequestHeaders)
+			sent := newHAREntry(req, nil, request.entry.started, HARSourceHeadless)
+			entry.Request.Headers = sent.Request.Headers
+			entry.Request.Cookies = sent.Request.Cookies
+		}
+	}
+
+	proto := harBrowserProtocol(response.Protocol)
+	entry.Request.HTTPVersion = proto
+	resp := &http.Response{
+		StatusCode: int(response.Status),
+		Status:     fmt.Sprintf("%d %s", response.Status, response.StatusText),
+		Proto:      proto,
+		Header:     harHTTPHeader(response.Headers),
+	}
+	if requestURL, err := url.Parse(entry.Request.URL); err == nil {
+		resp.Request = &http.Request{URL: requestURL}
+	}
+	entry.Response = newHARResponse(resp)
+	if response.StatusText == "" {
+		entry.Response.StatusText = http.StatusText(int(response.Status))
+	}
+	entry.Response.Content.MimeType = response.MimeType
+	entry.ServerIPAddress = strings.Trim(response.RemoteIPAddress, "[]")
+	request.timing = response.Timing
+}
+
+// finishTimings 根据ResourceTiming计算各阶段耗时（调用方持有锁）
+func (bc *HARBrowserCapture) finishTimings(request *harBrowserRequest, end time.Time) {
+	total := -1.0
+	if !request.startMono.IsZero() && !end.IsZero() && end.After(request.startMono) {
+		total = float64(end.Sub(request.startMono).Microseconds()) / 1000
+	}
+
+	timings := HARTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1}
+	if timing := request.timing; timing != nil {
+		span := func(start, end float64) float64 {
+			if start < 0 || end < start {
+				return -1
+			}
+			return end - start
+		}
+		timings.DNS = span(timing.DNSStart, timing.DNSEnd)
+		timings.Connect = span(timing.ConnectStart, timing.ConnectEnd)
+		timings.SSL = span(timing.SslStart, timing.SslEnd)
+		switch {
+		case timing.DNSStart >= 0:
+			timings.Blocked = timing.DNSStart
+		case timing.ConnectStart >= 0:
+			timings.Blocked = timing.ConnectStart
+		default:
+			timings.Blocked = timing.SendStart
+		}
+		timings.Send = span(timing.SendStart, timing.SendEnd)
+		timings.Wait = span(timing.SendEnd, timing.ReceiveHeadersEnd)
+		if !end.IsZero() && cdp.MonotonicTimeEpoch != nil {
+			// RequestTime为秒，其余字段为相对RequestTime的毫秒
+			headersEnd := timing.RequestTime*1000 + timing.ReceiveHeadersEnd
+			finished := float64(end.Sub(*cdp.MonotonicTimeEpoch).Microseconds()) / 1000
+			timings.Receive = span(headersEnd, finished)
+		}
+	} else if total >= 0 {
+		timings.Wait = total
+	}
+	for _, phase := range []*float64{&timings.Send, &timings.Wait, &timings.Receive} {
+		if *phase < 0 {
+			*phase = 0
+		}
+	}
+
+	request.entry.Timings = timings
+	request.entry.Time = timings.total()
+	if total > request.entry.Time {
+		request.entry.Time = total
+	}
+}
+
+// harHTTPHeader 把CDP的请求头/响应头（同名多值以换行分隔）转换为http.Header
+func harHTTPHeader(headers network.Headers) http.Header {
+	header := make(http.Header, len(headers))
+	for name, value := range headers {
+		for _, line := range strings.Split(fmt.Sprint(value), "\n") {
+			header.Add(name, line)
+		}
+	}
+	return header
+}
+
+// harBrowserProtocol 把CDP协议名（http/1.1、h2、h3）转换为HAR的httpVersion
+func harBrowserProtocol(protocol string) string {
+	switch strings.ToLower(protocol) {
+	case "h2":
+		return "HTTP/2.0"
+	case "h3", "h3-29", "quic":
+		return "HTTP/3.0"
+	case "":
+		return "HTTP/1.1"
+	}
+	return strings.ToUpper(protocol)
+}
+
+// harSkipBrowserURL 跳过不经过网络的请求
+func harSkipBrowserURL(rawURL string) bool {
+	lower := strings.ToLower(rawURL)
+	return strings.HasPrefix(lower, "data:") || strings.HasPrefix(lower, "blob:") ||
+		strings.HasPrefix(lower, "about:") || strings.HasPrefix(lower, "chrome")
+}
+
+// monotonicOf 读取可能为空的单调时间
+func monotonicOf(t *cdp.MonotonicTime) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.Time()
+}
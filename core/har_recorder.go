@@ -0,0 +1,648 @@
+package core
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// 🆕 v4.9: HAR 1.2 导出
+// 记录爬取过程中实际发送和收到的所有HTTP流量（静态爬虫、无头浏览器、探测请求），
+// 包括请求头/请求体、响应头/响应体（可截断）、耗时和重定向，导出后可在浏览器开发者工具、Burp、ZAP中打开
+
+// 流量来源（HAREntry.Source，导出为自定义字段 _source）
+const (
+	HARSourceStatic   = "static"   // 静态爬虫
+	HARSourceHeadless = "headless" // 无头浏览器
+	HARSourceProbe    = "probe"    // 探测请求（API定义、GraphQL、Sitemap、文档等）
+	HARSourceProxy    = "proxy"    // 代理拦截
+)
+
+// HAR HAR文件根对象（导出和导入共用）
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+// HARLog HAR日志
+type HARLog struct {
+	Version string      `json:"version"`
+	Creator HARCreator  `json:"creator"`
+	Entries []*HAREntry `json:"entries"`
+	Comment string      `json:"comment,omitempty"`
+}
+
+// HARCreator 生成工具
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HAREntry 一次请求/响应
+type HAREntry struct {
+	StartedDateTime string                 `json:"startedDateTime"`
+	Time            float64                `json:"time"` // 总耗时（毫秒）
+	Request         HARRequest             `json:"request"`
+	Response        HARResponse            `json:"response"`
+	Cache           map[string]interface{} `json:"cache"`
+	Timings         HARTimings             `json:"timings"`
+	ServerIPAddress string                 `json:"serverIPAddress,omitempty"`
+	Comment         string                 `json:"comment,omitempty"`
+	Source          string                 `json:"_source,omitempty"`
+
+	started time.Time
+}
+
+// HARRequest 请求
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARCookie    `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+// HARResponse 响应
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARCookie    `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+// HARContent 响应体
+type HARContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// HARPostData 请求体
+type HARPostData struct {
+	MimeType string         `json:"mimeType"`
+	Params   []HARNameValue `json:"params"`
+	Text     string         `json:"text"`
+	Comment  string         `json:"comment,omitempty"`
+}
+
+// HARNameValue 名称/值对（请求头、查询参数、表单参数）
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARCookie Cookie
+type HARCookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Path     string `json:"path,omitempty"`
+	Domain   string `json:"domain,omitempty"`
+	Expires  string `json:"expires,omitempty"`
+	HTTPOnly bool   `json:"httpOnly,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+}
+
+// HARTimings 各阶段耗时（毫秒，-1表示不适用）
+type HARTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	SSL     float64 `json:"ssl"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// HARRecorder HAR流量记录器（并发安全）
+type HARRecorder struct {
+	mutex         sync.Mutex
+	entries       []*HAREntry
+	maxEntries    int
+	includeBodies bool
+	maxBodySize   int
+	dropped       int
+}
+
+// NewHARRecorder 创建HAR记录器
+// maxEntries<=0 表示不限制条数；maxBodySize<=0 表示不截断响应体
+func NewHARRecorder(maxEntries int, includeBodies bool, maxBodySize int) *HARRecorder {
+	return &HARRecorder{
+		entries:       make([]*HAREntry, 0),
+		maxEntries:    maxEntries,
+		includeBodies: includeBodies,
+		maxBodySize:   maxBodySize,
+	}
+}
+
+// AddEntry 添加一条记录（超过上限时丢弃并计数）
+func (hr *HARRecorder) AddEntry(entry *HAREntry) {
+	if entry.Cache == nil {
+		entry.Cache = map[string]interface{}{}
+	}
+	if entry.StartedDateTime == "" {
+		if entry.started.IsZero() {
+			entry.started = time.Now()
+		}
+		entry.StartedDateTime = entry.started.Format(time.RFC3339Nano)
+	}
+
+	hr.mutex.Lock()
+	defer hr.mutex.Unlock()
+	if hr.maxEntries > 0 && len(hr.entries) >= hr.maxEntries {
+		hr.dropped++
+		return
+	}
+	hr.entries = append(hr.entries, entry)
+}
+
+// Count 已记录的条数
+func (hr *HARRecorder) Count() int {
+	hr.mutex.Lock()
+	defer hr.mutex.Unlock()
+	return len(hr.entries)
+}
+
+// GetEntries 获取记录（按开始时间排序）
+func (hr *HARRecorder) GetEntries() []*HAREntry {
+	hr.mutex.Lock()
+	entries := make([]*HAREntry, len(hr.entries))
+	copy(entries, hr.entries)
+	hr.mutex.Unlock()
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].started.Before(entries[j].started)
+	})
+	return entries
+}
+
+// Build 生成HAR文档
+func (hr *HARRecorder) Build() *HAR {
+	archive := &HAR{
+		Log: HARLog{
+			Version: "1.2",
+			Creator: HARCreator{Name: "spider-golang", Version: "4.9"},
+			Entries: hr.GetEntries(),
+		},
+	}
+	hr.mutex.Lock()
+	if hr.dropped > 0 {
+		archive.Log.Comment = fmt.Sprintf("超过记录上限，另有 %d 个请求未记录", hr.dropped)
+	}
+	hr.mutex.Unlock()
+	return archive
+}
+
+// ExportToFile 导出HAR文件
+func (hr *HARRecorder) ExportToFile(filename string) error {
+	data, err := json.MarshalIndent(hr.Build(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// WrapTransport 用记录流量的RoundTripper包装已有Transport（放在认证RoundTripper外层，
+// 请求头取自实际发出的请求，因此包含认证头）
+func (hr *HARRecorder) WrapTransport(base http.RoundTripper, source string) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &harRoundTripper{base: base, recorder: hr, source: source}
+}
+
+// ApplyToClient 为已有HTTP客户端启用流量记录
+func (hr *HARRecorder) ApplyToClient(client *http.Client, source string) {
+	if client == nil {
+		return
+	}
+	client.Transport = hr.WrapTransport(client.Transport, source)
+}
+
+// harRoundTripper 记录经过的请求和响应
+type harRoundTripper struct {
+	base     http.RoundTripper
+	recorder *HARRecorder
+	source   string
+}
+
+// RoundTrip 实现http.RoundTripper接口
+func (t *harRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+
+	// 读取请求体后换成可重放的副本
+	var requestBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		requestBody = body
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	trace := &harTrace{start: start}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace.clientTrace()))
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		entry := newHAREntry(req, requestBody, start, t.source)
+		entry.Response = HARResponse{
+			Cookies:     []HARCookie{},
+			Headers:     []HARNameValue{},
+			Content:     HARContent{Size: 0, MimeType: "x-unknown"},
+			HeadersSize: -1,
+			BodySize:    -1,
+		}
+		entry.Comment = "请求失败: " + err.Error()
+		entry.Timings = trace.timings(time.Now())
+		entry.Time = entry.Timings.total()
+		t.recorder.AddEntry(entry)
+		return resp, err
+	}
+
+	// 响应中的请求是实际发出的请求（包含内层RoundTripper添加的认证头）
+	sent := req
+	if resp.Request != nil {
+		sent = resp.Request
+	}
+	entry := newHAREntry(sent, requestBody, start, t.source)
+	entry.Request.HTTPVersion = resp.Proto
+	entry.Response = newHARResponse(resp)
+	trace.mutex.Lock()
+	entry.ServerIPAddress = trace.remoteAddr
+	trace.mutex.Unlock()
+
+	resp.Body = &harBodyRecorder{
+		body:    resp.Body,
+		capture: t.recorder.includeBodies,
+		limit:   t.recorder.maxBodySize,
+		finish: func(body []byte, total int64, truncated bool) {
+			entry.Response.Content = harContent(resp.Header.Get("Content-Type"), body, total, truncated)
+			if !resp.Uncompressed && total >= 0 {
+				entry.Response.BodySize = total
+			}
+			entry.Timings = trace.timings(time.Now())
+			entry.Time = entry.Timings.total()
+			t.recorder.AddEntry(entry)
+		},
+	}
+	return resp, nil
+}
+
+// harBodyRecorder 在响应体被读取时捕获内容，读完或关闭时写入记录
+type harBodyRecorder struct {
+	body      io.ReadCloser
+	buffer    bytes.Buffer
+	capture   bool
+	limit     int
+	total     int64
+	truncated bool
+	once      sync.Once
+	finish    func(body []byte, total int64, truncated bool)
+}
+
+func (b *harBodyRecorder) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	if n > 0 {
+		b.total += int64(n)
+		if b.capture {
+			keep := n
+			if b.limit > 0 && b.buffer.Len()+keep > b.limit {
+				keep = b.limit - b.buffer.Len()
+				b.truncated = true
+			}
+			if keep > 0 {
+				b.buffer.Write(p[:keep])
+			}
+		}
+	}
+	if err == io.EOF {
+		b.done()
+	}
+	return n, err
+}
+
+func (b *harBodyRecorder) Close() error {
+	b.done()
+	return b.body.Close()
+}
+
+func (b *harBodyRecorder) done() {
+	b.once.Do(func() {
+		var body []byte
+		if b.capture {
+			body = b.buffer.Bytes()
+		}
+		b.finish(body, b.total, b.truncated)
+	})
+}
+
+// harTrace 通过httptrace记录连接各阶段的时间点
+type harTrace struct {
+	mutex        sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	gotConn      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	remoteAddr   string
+}
+
+func (tr *harTrace) clientTrace() *httptrace.ClientTrace {
+	set := func(field *time.Time) {
+		tr.mutex.Lock()
+		if field.IsZero() {
+			*field = time.Now()
+		}
+		tr.mutex.Unlock()
+	}
+	return &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { set(&tr.dnsStart) },
+		DNSDone:           func(httptrace.DNSDoneInfo) { set(&tr.dnsDone) },
+		ConnectStart:      func(string, string) { set(&tr.connectStart) },
+		ConnectDone:       func(string, string, error) { set(&tr.connectDone) },
+		TLSHandshakeStart: func() { set(&tr.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { set(&tr.tlsDone) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			set(&tr.gotConn)
+			if info.Conn != nil {
+				if host, _, err := net.SplitHostPort(info.Conn.RemoteAddr().String()); err == nil {
+					tr.mutex.Lock()
+					tr.remoteAddr = host
+					tr.mutex.Unlock()
+				}
+			}
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { set(&tr.wroteRequest) },
+		GotFirstResponseByte: func() { set(&tr.firstByte) },
+	}
+}
+
+// timings 计算HAR各阶段耗时（ssl包含在connect内，与HAR规范一致）
+func (tr *harTrace) timings(end time.Time) HARTimings {
+	tr.mutex.Lock()
+	defer tr.mutex.Unlock()
+
+	span := func(from, to time.Time) float64 {
+		if from.IsZero() || to.IsZero() || to.Before(from) {
+			return -1
+		}
+		return float64(to.Sub(from).Microseconds()) / 1000
+	}
+	timings := HARTimings{
+		DNS:     span(tr.dnsStart, tr.dnsDone),
+		Connect: span(tr.connectStart, tr.tlsDone),
+		SSL:     span(tr.tlsStart, tr.tlsDone),
+		Send:    span(tr.gotConn, tr.wroteRequest),
+		Wait:    span(tr.wroteRequest, tr.firstByte),
+		Receive: span(tr.firstByte, end),
+	}
+	if timings.Connect < 0 {
+		timings.Connect = span(tr.connectStart, tr.connectDone)
+	}
+	// 等待连接的时间（连接池排队）
+	blocked := span(tr.start, tr.gotConn)
+	if blocked >= 0 {
+		for _, phase := range []float64{timings.DNS, timings.Connect} {
+			if phase > 0 {
+				blocked -= phase
+			}
+		}
+		if blocked < 0 {
+			blocked = 0
+		}
+	}
+	timings.Blocked = blocked
+	// HAR要求send/wait/receive非负
+	for _, phase := range []*float64{&timings.Send, &timings.Wait, &timings.Receive} {
+		if *phase < 0 {
+			*phase = 0
+		}
+	}
+	if tr.firstByte.IsZero() && !tr.start.IsZero() {
+		timings.Wait = span(tr.start, end)
+	}
+	return timings
+}
+
+// total 总耗时（各阶段之和，不重复计算ssl）
+func (t HARTimings) total() float64 {
+	total := 0.0
+	for _, phase := range []float64{t.Blocked, t.DNS, t.Connect, t.Send, t.Wait, t.Receive} {
+		if phase > 0 {
+			total += phase
+		}
+	}
+	return total
+}
+
+// newHAREntry 由Go请求生成记录（不含响应）
+func newHAREntry(req *http.Request, body []byte, start time.Time, source string) *HAREntry {
+	headers := harHeaders(req.Header)
+	host := req.Host
+	if host == "" && req.URL != nil {
+		host = req.URL.Host
+	}
+	if host != "" && req.Header.Get("Host") == "" {
+		headers = append([]HARNameValue{{Name: "Host", Value: host}}, headers...)
+	}
+	cookies := make([]HARCookie, 0)
+	for _, cookie := range req.Cookies() {
+		cookies = append(cookies, HARCookie{Name: cookie.Name, Value: cookie.Value})
+	}
+	entry := &HAREntry{
+		started:         start,
+		StartedDateTime: start.Format(time.RFC3339Nano),
+		Request: HARRequest{
+			Method:      req.Method,
+			URL:         req.URL.String(),
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     cookies,
+			Headers:     headers,
+			QueryString: harQueryString(req.URL),
+			HeadersSize: -1,
+			BodySize:    int64(len(body)),
+		},
+		Cache:  map[string]interface{}{},
+		Source: source,
+	}
+	if len(body) > 0 {
+		entry.Request.PostData = harPostData(req.Header.Get("Content-Type"), body)
+	}
+	return entry
+}
+
+// newHARResponse 由Go响应生成记录（响应体在读取完成后填入）
+func newHARResponse(resp *http.Response) HARResponse {
+	cookies := make([]HARCookie, 0)
+	for _, cookie := range resp.Cookies() {
+		harCookie := HARCookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Path:     cookie.Path,
+			Domain:   cookie.Domain,
+			HTTPOnly: cookie.HttpOnly,
+			Secure:   cookie.Secure,
+		}
+		if !cookie.Expires.IsZero() {
+			harCookie.Expires = cookie.Expires.Format(time.RFC3339)
+		}
+		cookies = append(cookies, harCookie)
+	}
+	redirectURL := ""
+	if location := resp.Header.Get("Location"); location != "" {
+		redirectURL = location
+		if resp.Request != nil {
+			if resolved, err := resp.Request.URL.Parse(location); err == nil {
+				redirectURL = resolved.String()
+			}
+		}
+	}
+	statusText := http.StatusText(resp.StatusCode)
+	if _, text, found := strings.Cut(resp.Status, " "); found {
+		statusText = text
+	}
+	return HARResponse{
+		Status:      resp.StatusCode,
+		StatusText:  statusText,
+		HTTPVersion: resp.Proto,
+		Cookies:     cookies,
+		Headers:     harHeaders(resp.Header),
+		Content:     HARContent{Size: 0, MimeType: resp.Header.Get("Content-Type")},
+		RedirectURL: redirectURL,
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+}
+
+// harHeaders 转换请求头/响应头（按名称排序，多值拆分为多条）
+func harHeaders(header http.Header) []HARNameValue {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	headers := make([]HARNameValue, 0, len(names))
+	for _, name := range names {
+		for _, value := range header[name] {
+			headers = append(headers, HARNameValue{Name: name, Value: value})
+		}
+	}
+	return headers
+}
+
+// harHeadersFromMap 转换map形式的请求头（浏览器事件、代理记录）
+func harHeadersFromMap(header map[string]string) []HARNameValue {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	headers := make([]HARNameValue, 0, len(names))
+	for _, name := range names {
+		// 浏览器把同名请求头合并为换行分隔的一个值
+		for _, value := range strings.Split(header[name], "\n") {
+			headers = append(headers, HARNameValue{Name: name, Value: value})
+		}
+	}
+	return headers
+}
+
+// harQueryString 查询参数
+func harQueryString(u *url.URL) []HARNameValue {
+	params := make([]HARNameValue, 0)
+	if u == nil || u.RawQuery == "" {
+		return params
+	}
+	for _, pair := range strings.Split(u.RawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(pair, "=")
+		if decoded, err := url.QueryUnescape(name); err == nil {
+			name = decoded
+		}
+		if decoded, err := url.QueryUnescape(value); err == nil {
+			value = decoded
+		}
+		params = append(params, HARNameValue{Name: name, Value: value})
+	}
+	return params
+}
+
+// harPostData 请求体（表单请求体同时给出参数列表）
+func harPostData(contentType string, body []byte) *HARPostData {
+	postData := &HARPostData{MimeType: contentType, Params: []HARNameValue{}}
+	if !utf8.Valid(body) {
+		postData.Text = base64.StdEncoding.EncodeToString(body)
+		postData.Comment = "二进制请求体（base64编码）"
+		return postData
+	}
+	postData.Text = string(body)
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == "application/x-www-form-urlencoded" {
+		postData.Params = harQueryString(&url.URL{RawQuery: postData.Text})
+	}
+	return postData
+}
+
+// harContent 响应体：文本直接保存，二进制内容base64编码；截断时在comment中说明
+func harContent(contentType string, body []byte, total int64, truncated bool) HARContent {
+	content := HARContent{Size: total, MimeType: contentType}
+	if content.MimeType == "" {
+		content.MimeType = "x-unknown"
+	}
+	if len(body) > 0 {
+		// 截断可能落在多字节字符中间，文本类型仍按文本保存
+		if utf8.Valid(body) || (truncated && harIsTextType(contentType)) {
+			content.Text = strings.ToValidUTF8(string(body), "")
+		} else {
+			content.Text = base64.StdEncoding.EncodeToString(body)
+			content.Encoding = "base64"
+		}
+	}
+	if truncated {
+		content.Comment = fmt.Sprintf("响应体已截断：保留前 %d 字节，共 %d 字节", len(body), total)
+	}
+	return content
+}
+
+// harIsTextType 判断响应类型是否为文本
+func harIsTextType(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return strings.HasPrefix(mediaType, "text/") || strings.Contains(mediaType, "json") ||
+		strings.Contains(mediaType, "xml") || strings.Contains(mediaType, "javascript")
+}
@@ -33,6 +33,20 @@ func NewHiddenPathDiscovery(baseURL, userAgent string) *HiddenPathDiscovery {
 	}
 }
 
+// SetAuthManager 设置认证管理器（v4.9新增）
+func (hpd *HiddenPathDiscovery) SetAuthManager(am *AuthManager) {
+	if am != nil {
+		am.ApplyToClient(hpd.client)
+	}
+}
+
+// SetHARRecorder 设置HAR流量记录器（v4.9新增）（需在SetAuthManager之后调用）
+func (hpd *HiddenPathDiscovery) SetHARRecorder(hr *HARRecorder) {
+	if hr != nil {
+		hr.ApplyToClient(hpd.client, HARSourceProbe)
+	}
+}
+
 // DiscoverAllHiddenPaths 发现所有隐藏路径
 func (hpd *HiddenPathDiscovery) DiscoverAllHiddenPaths() []string {
 	var wg sync.WaitGroup
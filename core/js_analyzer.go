@@ -4,16 +4,150 @@ import (
 	"encoding/base64"
 	"regexp"
 	"strings"
+	"sync"
 )
 
 // JSAnalyzer JS分析器
+// 🆕 v4.9: 优先使用AST分析（JSASTAnalyzer），代码无法解析时降级为正则提取
 type JSAnalyzer struct {
 	targetDomain string // 目标域名，用于拼接相对路径
+	
+	astAnalyzer *JSASTAnalyzer // 🆕 v4.9: 语法树分析器
+	endpoints   []*JSEndpoint  // 🆕 v4.9: 已发现的结构化端点
+	
+	// 🆕 v4.9: 自动反混淆（sandbox为nil时不启用）
+	sandbox        *JSSandbox
+	maxDeobfuscate int // 超过该大小的脚本不做反混淆
+	deobfuscations []*DeobfuscationReport
+	
+	mutex sync.Mutex
 }
 
 // NewJSAnalyzer 创建JS分析器实例
 func NewJSAnalyzer() *JSAnalyzer {
-	return &JSAnalyzer{}
+	return &JSAnalyzer{
+		astAnalyzer: NewJSASTAnalyzer(),
+	}
+}
+
+// AnalyzeEndpoints 使用AST提取结构化端点并记录（🆕 v4.9）
+// sourceURL 为JS文件地址（内联脚本为页面地址），用于定位端点出处
+func (j *JSAnalyzer) AnalyzeEndpoints(jsContent string, sourceURL string) ([]*JSEndpoint, error) {
+	endpoints, err := j.astAnalyzer.Analyze(jsContent, sourceURL)
+	if err != nil {
+		return nil, err
+	}
+	j.RecordEndpoints(endpoints)
+	return endpoints, nil
+}
+
+// ASTAnalyzer 获取语法树分析器（供静态爬虫共享，解析统计合并在一起）
+func (j *JSAnalyzer) ASTAnalyzer() *JSASTAnalyzer {
+	return j.astAnalyzer
+}
+
+// EnableDeobfuscation 启用自动反混淆（🆕 v4.9）
+func (j *JSAnalyzer) EnableDeobfuscation(sandbox *JSSandbox, maxScriptSize int) {
+	j.sandbox = sandbox
+	j.maxDeobfuscate = maxScriptSize
+}
+
+// Deobfuscate 检测混淆并在沙箱中还原，结果被记录（未启用、脚本过大或未混淆时返回nil）
+func (j *JSAnalyzer) Deobfuscate(jsContent string, sourceURL string) *DeobfuscationReport {
+	if j.sandbox == nil || (j.maxDeobfuscate > 0 && len(jsContent) > j.maxDeobfuscate) {
+		return nil
+	}
+	report := DeobfuscateScript(jsContent, sourceURL, j.sandbox)
+	j.RecordDeobfuscation(report)
+	return report
+}
+
+// RecordDeobfuscation 记录反混淆结果（包括静态爬虫分析的脚本）
+func (j *JSAnalyzer) RecordDeobfuscation(report *DeobfuscationReport) {
+	if report == nil {
+		return
+	}
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.deobfuscations = append(j.deobfuscations, report)
+}
+
+// GetDeobfuscations 获取所有反混淆结果
+func (j *JSAnalyzer) GetDeobfuscations() []*DeobfuscationReport {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	reports := make([]*DeobfuscationReport, len(j.deobfuscations))
+	copy(reports, j.deobfuscations)
+	return reports
+}
+
+// RecordEndpoints 记录其他组件（如静态爬虫的内联脚本分析）发现的端点
+func (j *JSAnalyzer) RecordEndpoints(endpoints []*JSEndpoint) {
+	if len(endpoints) == 0 {
+		return
+	}
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.endpoints = append(j.endpoints, endpoints...)
+}
+
+// GetEndpoints 获取所有已发现的结构化端点
+func (j *JSAnalyzer) GetEndpoints() []*JSEndpoint {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	endpoints := make([]*JSEndpoint, len(j.endpoints))
+	copy(endpoints, j.endpoints)
+	return endpoints
+}
+
+// endpointURLs 将端点转为URL列表；callsOnly 时只保留请求调用点（排除字面量）
+func (j *JSAnalyzer) endpointURLs(endpoints []*JSEndpoint, callsOnly bool) []string {
+	urls := make([]string, 0, len(endpoints))
+	seen := make(map[string]bool)
+	for _, ep := range endpoints {
+		if callsOnly && ep.Kind == JSEndpointLiteral {
+			continue
+		}
+		u := ep.CrawlURL()
+		if u == "" {
+			continue
+		}
+		if strings.HasPrefix(u, "/") && !strings.HasPrefix(u, "//") {
+			if !j.isValidPath(strings.SplitN(u, "?", 2)[0]) {
+				continue
+			}
+			u = j.absoluteURL(u)
+		}
+		if !seen[u] {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// endpointParams 汇总端点的查询参数和请求体字段名
+func endpointParams(endpoints []*JSEndpoint) []string {
+	params := make([]string, 0)
+	for _, ep := range endpoints {
+		params = mergeUniqueStrings(params, ep.QueryParams)
+		params = mergeUniqueStrings(params, ep.BodyKeys)
+	}
+	return params
+}
+
+// absoluteURL 将以/开头的路径与目标域名拼接（未设置目标域名时原样返回）
+func (j *JSAnalyzer) absoluteURL(path string) string {
+	if j.targetDomain == "" {
+		return path
+	}
+	scheme := "http://"
+	if strings.Contains(j.targetDomain, "https") {
+		scheme = "https://"
+	}
+	cleanDomain := strings.TrimPrefix(j.targetDomain, "http://")
+	cleanDomain = strings.TrimPrefix(cleanDomain, "https://")
+	return scheme + strings.TrimSuffix(cleanDomain, "/") + path
 }
 
 // SetTargetDomain 设置目标域名
@@ -22,7 +156,16 @@ func (j *JSAnalyzer) SetTargetDomain(domain string) {
 }
 
 // Analyze 分析JavaScript内容，提取API端点、参数和隐藏链接
+// 🆕 v4.9: 优先使用AST分析，语法错误时降级为正则
 func (j *JSAnalyzer) Analyze(jsContent string) ([]string, []string, []string) {
+	if endpoints, err := j.astAnalyzer.Analyze(jsContent, ""); err == nil {
+		return j.analyzeFromEndpoints(endpoints)
+	}
+	return j.analyzeByRegex(jsContent)
+}
+
+// analyzeByRegex 正则提取API端点、参数和隐藏链接（AST解析失败时使用）
+func (j *JSAnalyzer) analyzeByRegex(jsContent string) ([]string, []string, []string) {
 	apis := make([]string, 0)
 	params := make([]string, 0)
 	links := make([]string, 0)
@@ -39,6 +182,25 @@ func (j *JSAnalyzer) Analyze(jsContent string) ([]string, []string, []string) {
 	return apis, params, links
 }
 
+// analyzeFromEndpoints 由AST端点生成 Analyze 的三类结果
+// 请求调用点和拼接出的路径视为API，带协议的字面量视为链接
+func (j *JSAnalyzer) analyzeFromEndpoints(endpoints []*JSEndpoint) ([]string, []string, []string) {
+	apis := make([]string, 0)
+	links := make([]string, 0)
+	for _, ep := range endpoints {
+		u := ep.CrawlURL()
+		if u == "" {
+			continue
+		}
+		if ep.Kind == JSEndpointLiteral && jsIsAbsoluteURL(u) {
+			links = appendUniqueString(links, u)
+		} else {
+			apis = appendUniqueString(apis, u)
+		}
+	}
+	return apis, endpointParams(endpoints), links
+}
+
 // extractAPIs 从JavaScript中提取API端点
 func (j *JSAnalyzer) extractAPIs(jsContent string) []string {
 	apis := make([]string, 0)
@@ -322,7 +484,16 @@ func (j *JSAnalyzer) AnalyzeExternalJS(jsContent string, sourceURL string) map[s
 }
 
 // ExtractFromJSObjects 从JavaScript对象和配置中提取URL（Phase 3增强）
+// 🆕 v4.9: 优先使用AST（常量、对象属性和拼接均已求值），语法错误时降级为正则
 func (j *JSAnalyzer) ExtractFromJSObjects(jsContent string) []string {
+	if endpoints, err := j.astAnalyzer.Analyze(jsContent, ""); err == nil {
+		return j.endpointURLs(endpoints, false)
+	}
+	return j.extractFromJSObjectsByRegex(jsContent)
+}
+
+// extractFromJSObjectsByRegex 正则提取配置对象中的URL（AST解析失败时使用）
+func (j *JSAnalyzer) extractFromJSObjectsByRegex(jsContent string) []string {
 	urls := make([]string, 0)
 	seen := make(map[string]bool)
 	
@@ -381,7 +552,16 @@ func (j *JSAnalyzer) ExtractFromJSObjects(jsContent string) []string {
 }
 
 // ExtractAjaxURLs 专门提取AJAX请求URL（Phase 3增强）
+// 🆕 v4.9: 优先使用AST识别 fetch/axios/XHR/jQuery 调用点，语法错误时降级为正则
 func (j *JSAnalyzer) ExtractAjaxURLs(jsContent string) []string {
+	if endpoints, err := j.astAnalyzer.Analyze(jsContent, ""); err == nil {
+		return j.endpointURLs(endpoints, true)
+	}
+	return j.extractAjaxURLsByRegex(jsContent)
+}
+
+// extractAjaxURLsByRegex 正则提取AJAX请求URL（AST解析失败时使用）
+func (j *JSAnalyzer) extractAjaxURLsByRegex(jsContent string) []string {
 	urls := make([]string, 0)
 	seen := make(map[string]bool)
 	
@@ -503,10 +683,37 @@ func (j *JSAnalyzer) AnalyzeRouterConfig(jsContent string) []string {
 
 // EnhancedAnalyze 增强的综合分析（Phase 3集成方法）
 func (j *JSAnalyzer) EnhancedAnalyze(jsContent string) map[string][]string {
+	return j.EnhancedAnalyzeSource(jsContent, "")
+}
+
+// EnhancedAnalyzeSource 增强的综合分析，记录端点来源文件（🆕 v4.9）
+// 检测到混淆时先还原解码调用，再分析还原后的代码（隐藏URL归入 deobfuscated_urls）
+func (j *JSAnalyzer) EnhancedAnalyzeSource(jsContent string, sourceURL string) map[string][]string {
+	report := j.Deobfuscate(jsContent, sourceURL)
+	if report == nil {
+		return j.enhancedAnalyze(jsContent, sourceURL)
+	}
+	result := j.enhancedAnalyze(report.Code, sourceURL)
+	result["deobfuscated_urls"] = report.HiddenURLs
+	return result
+}
+
+// enhancedAnalyze AST解析成功时以语法树结果替代正则类别（basic_*、relative_urls、object_urls、ajax_urls），
+// 仍保留路由配置和Base64解码；解析失败时完全使用正则（每个脚本只解析一次，各类别共享结果）
+func (j *JSAnalyzer) enhancedAnalyze(jsContent string, sourceURL string) map[string][]string {
 	result := make(map[string][]string)
 	
+	if endpoints, err := j.AnalyzeEndpoints(jsContent, sourceURL); err == nil {
+		result["ast_requests"] = j.endpointURLs(endpoints, true)
+		result["ast_endpoints"] = j.endpointURLs(endpoints, false)
+		result["basic_params"] = endpointParams(endpoints)
+		result["router_urls"] = j.AnalyzeRouterConfig(jsContent)
+		result["base64_urls"] = j.ExtractBase64URLs(jsContent)
+		return result
+	}
+	
 	// 基础分析
-	apis, params, links := j.Analyze(jsContent)
+	apis, params, links := j.analyzeByRegex(jsContent)
 	result["basic_apis"] = apis
 	result["basic_params"] = params
 	result["basic_links"] = links
@@ -515,10 +722,10 @@ func (j *JSAnalyzer) EnhancedAnalyze(jsContent string) map[string][]string {
 	result["relative_urls"] = j.ExtractRelativeURLs(jsContent)
 	
 	// JavaScript对象中的URL
-	result["object_urls"] = j.ExtractFromJSObjects(jsContent)
+	result["object_urls"] = j.extractFromJSObjectsByRegex(jsContent)
 	
 	// AJAX URL
-	result["ajax_urls"] = j.ExtractAjaxURLs(jsContent)
+	result["ajax_urls"] = j.extractAjaxURLsByRegex(jsContent)
 	
 	// 路由配置
 	result["router_urls"] = j.AnalyzeRouterConfig(jsContent)
@@ -0,0 +1,965 @@
+package core
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/dop251/goja/ast"
+	"github.com/dop251/goja/file"
+	"github.com/dop251/goja/parser"
+	"github.com/dop251/goja/token"
+)
+
+// 🆕 v4.9: 基于AST的JavaScript分析
+// 正则提取器无法处理模板字符串、跨变量拼接以及 axios/fetch 的配置对象，且误报较多。
+// 这里使用 ECMAScript 解析器（goja）构建语法树：
+//   1. 收集常量绑定（var/let/const、对象属性、axios.create 的 baseURL、FormData.append）
+//   2. 求值字符串常量、模板字符串和 "+" 拼接，无法解析的部分以 {name} 占位
+//   3. 识别请求调用点，输出结构化端点（URL、方法、请求头、请求体字段、查询参数、源文件和行号）
+
+// 端点类型（JSEndpoint.Kind）
+const (
+	JSEndpointFetch       = "fetch"
+	JSEndpointAxios       = "axios"
+	JSEndpointXHR         = "xhr"
+	JSEndpointJQuery      = "jquery"
+	JSEndpointWebSocket   = "websocket"
+	JSEndpointEventSource = "eventsource"
+	JSEndpointBeacon      = "beacon"
+	JSEndpointRequest     = "request" // 通用请求封装 request({url, method, data})
+	JSEndpointLiteral     = "literal" // 代码中出现的路径/URL字面量（非调用点）
+)
+
+// jsMaxEvalDepth 常量求值的最大递归深度（防止循环引用）
+const jsMaxEvalDepth = 10
+
+// JSEndpoint JS中发现的结构化端点
+type JSEndpoint struct {
+	URL         string            `json:"url"`                    // 解析后的URL，无法解析的部分为 {name}
+	Method      string            `json:"method,omitempty"`       // HTTP方法（字面量端点为空）
+	Headers     map[string]string `json:"headers,omitempty"`      // 请求头
+	BodyKeys    []string          `json:"body_keys,omitempty"`    // 请求体字段名
+	QueryParams []string          `json:"query_params,omitempty"` // 查询参数名
+	Kind        string            `json:"kind"`                   // 调用类型：fetch/axios/xhr/jquery/...
+	Source      string            `json:"source,omitempty"`       // 源文件URL
+	Line        int               `json:"line"`
+	Column      int               `json:"column"`
+	Dynamic     bool              `json:"dynamic,omitempty"` // URL包含无法静态解析的部分
+}
+
+// CrawlURL 返回可用于爬取的URL：占位符替换为1，开头的未知前缀（如 {baseURL}）去除
+func (e *JSEndpoint) CrawlURL() string {
+	u := e.URL
+	if strings.HasPrefix(u, "{") {
+		if end := strings.Index(u, "}"); end > 0 {
+			u = u[end+1:]
+		}
+	}
+	u = jsPlaceholderPattern.ReplaceAllString(u, "1")
+	if u == "" || u == "/" {
+		return ""
+	}
+	return u
+}
+
+var (
+	jsPlaceholderPattern = regexp.MustCompile(`\{[^{}/?&=]*\}`)
+	jsHTTPMethods        = map[string]bool{
+		"GET": true, "POST": true, "PUT": true, "DELETE": true, "PATCH": true, "HEAD": true, "OPTIONS": true,
+	}
+	// 常见的HTTP客户端实例名（axios实例、Vue $http 等），调用 .get/.post 等方法时视为请求
+	jsClientNames = map[string]bool{
+		"axios": true, "$http": true, "$axios": true, "http": true, "request": true, "service": true,
+		"instance": true, "api": true, "client": true, "httpClient": true, "ajax": true, "superagent": true,
+	}
+	jsStaticPathPattern = regexp.MustCompile(`^/[A-Za-z0-9_\-.~%{}$:@!*+,;=/]*[A-Za-z]`)
+)
+
+// JSASTAnalyzer 基于语法树的JS端点分析器（只保存统计计数，可并发使用）
+type JSASTAnalyzer struct {
+	parsed    int64 // 解析成功的脚本数
+	modules   int64 // 其中经过ES模块语法改写的脚本数
+	fallbacks int64 // 解析失败（调用方降级为正则）的脚本数
+}
+
+// JSASTStats AST分析统计
+type JSASTStats struct {
+	Parsed    int64
+	Modules   int64
+	Fallbacks int64
+}
+
+// NewJSASTAnalyzer 创建AST分析器
+func NewJSASTAnalyzer() *JSASTAnalyzer {
+	return &JSASTAnalyzer{}
+}
+
+// jsAnalysis 单次分析的上下文
+type jsAnalysis struct {
+	file      *file.File
+	source    string
+	bindings  map[string]ast.Expression // 名称（含 a.b 路径） → 初始值
+	ambiguous map[string]bool           // 存在多个不同赋值的名称（压缩代码中常见），不参与求值
+	clients   map[string]string         // axios实例 → baseURL
+	formKeys  map[string][]string       // FormData/URLSearchParams变量 → append的字段名
+	endpoints []*JSEndpoint
+	seen      map[string]bool
+}
+
+// Analyze 解析JS代码并提取端点；语法错误时返回error，调用方应降级为正则提取
+func (a *JSASTAnalyzer) Analyze(code string, source string) (endpoints []*JSEndpoint, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			atomic.AddInt64(&a.fallbacks, 1)
+			endpoints, err = nil, fmt.Errorf("AST分析异常: %v", r)
+		}
+	}()
+
+	// 🆕 v4.9: 顶层 import/export 改写为脚本语法（偏移不变）
+	code, isModule := rewriteJSModuleSyntax(code)
+	program, err := parser.ParseFile(nil, source, code, parser.IgnoreRegExpErrors, parser.WithDisableSourceMaps)
+	if err != nil {
+		atomic.AddInt64(&a.fallbacks, 1)
+		return nil, err
+	}
+	atomic.AddInt64(&a.parsed, 1)
+	if isModule {
+		atomic.AddInt64(&a.modules, 1)
+	}
+
+	ctx := &jsAnalysis{
+		file:      program.File,
+		source:    source,
+		bindings:  make(map[string]ast.Expression),
+		ambiguous: make(map[string]bool),
+		clients:   make(map[string]string),
+		formKeys:  make(map[string][]string),
+		seen:      make(map[string]bool),
+	}
+
+	// 第一遍：收集绑定
+	walkJSAST(program, func(node ast.Node) bool {
+		ctx.collect(node)
+		return true
+	})
+
+	// 第二遍：识别调用点和字面量
+	ctx.walk(program)
+
+	return ctx.result(), nil
+}
+
+// GetStats 获取解析统计
+func (a *JSASTAnalyzer) GetStats() JSASTStats {
+	return JSASTStats{
+		Parsed:    atomic.LoadInt64(&a.parsed),
+		Modules:   atomic.LoadInt64(&a.modules),
+		Fallbacks: atomic.LoadInt64(&a.fallbacks),
+	}
+}
+
+// ========== 语法树遍历 ==========
+
+var jsNodeType = reflect.TypeOf((*ast.Node)(nil)).Elem()
+
+// walkJSAST 通用遍历（goja未提供Walk，基于反射访问所有子节点）
+// visit返回false时不再深入该节点
+func walkJSAST(node ast.Node, visit func(ast.Node) bool) {
+	if node == nil || reflect.ValueOf(node).IsNil() {
+		return
+	}
+	if !visit(node) {
+		return
+	}
+	walkJSChildren(reflect.ValueOf(node), visit)
+}
+
+// walkJSChildren 遍历节点的子节点
+func walkJSChildren(v reflect.Value, visit func(ast.Node) bool) {
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		// DeclarationList 与函数体中的声明语句重复
+		if t.Field(i).Name == "DeclarationList" || !t.Field(i).IsExported() {
+			continue
+		}
+		walkJSValue(v.Field(i), visit)
+	}
+}
+
+// walkJSValue 遍历字段值（节点、节点切片或内嵌结构体）
+func walkJSValue(field reflect.Value, visit func(ast.Node) bool) {
+	switch field.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if field.IsNil() {
+			return
+		}
+		if field.Type().Implements(jsNodeType) {
+			walkJSAST(field.Interface().(ast.Node), visit)
+		} else if field.Kind() == reflect.Ptr && field.Elem().Kind() == reflect.Struct &&
+			field.Elem().Type().PkgPath() == jsNodeType.PkgPath() {
+			// 非Node的AST结构（如 *ast.Binding、*ast.ParameterList）
+			walkJSChildren(field, visit)
+		}
+	case reflect.Slice:
+		for i := 0; i < field.Len(); i++ {
+			walkJSValue(field.Index(i), visit)
+		}
+	case reflect.Struct:
+		if field.Type().PkgPath() == jsNodeType.PkgPath() {
+			if field.CanAddr() && field.Addr().Type().Implements(jsNodeType) {
+				walkJSAST(field.Addr().Interface().(ast.Node), visit)
+			} else {
+				walkJSChildren(field, visit)
+			}
+		}
+	}
+}
+
+// ========== 第一遍：绑定收集 ==========
+
+// collect 记录变量绑定、对象属性和赋值
+func (c *jsAnalysis) collect(node ast.Node) {
+	switch n := node.(type) {
+	case *ast.Binding:
+		if id, ok := n.Target.(*ast.Identifier); ok && n.Initializer != nil {
+			c.bind(string(id.Name), n.Initializer)
+		}
+	case *ast.AssignExpression:
+		if n.Operator == token.ASSIGN {
+			if name := jsMemberPath(n.Left); name != "" {
+				c.bind(name, n.Right)
+			}
+		}
+	case *ast.CallExpression:
+		// fd.append("key", value) / params.set("key", value)
+		if dot, ok := n.Callee.(*ast.DotExpression); ok && len(n.ArgumentList) > 0 {
+			method := string(dot.Identifier.Name)
+			if method == "append" || method == "set" {
+				if owner := jsMemberPath(dot.Left); owner != "" {
+					if key, ok := n.ArgumentList[0].(*ast.StringLiteral); ok {
+						c.formKeys[owner] = appendUniqueString(c.formKeys[owner], string(key.Value))
+					}
+				}
+			}
+		}
+	}
+}
+
+// bind 记录绑定；对象字面量同时展开为 name.key 路径
+func (c *jsAnalysis) bind(name string, value ast.Expression) {
+	if prev, ok := c.bindings[name]; ok && prev != value {
+		if !c.ambiguous[name] {
+			prevValue, _ := c.eval(prev, 0)
+			newValue, _ := c.eval(value, 0)
+			if prevValue != newValue {
+				c.ambiguous[name] = true
+			}
+		}
+	} else {
+		c.bindings[name] = value
+	}
+
+	switch v := value.(type) {
+	case *ast.ObjectLiteral:
+		for _, prop := range v.Value {
+			if keyed, ok := prop.(*ast.PropertyKeyed); ok && !keyed.Computed {
+				if key := jsPropertyKey(keyed.Key); key != "" {
+					c.bind(name+"."+key, keyed.Value)
+				}
+			}
+		}
+	case *ast.CallExpression:
+		// const api = axios.create({ baseURL: "/api" })
+		if callee := jsMemberPath(v.Callee); strings.HasSuffix(callee, ".create") && len(v.ArgumentList) > 0 {
+			base := ""
+			if obj, ok := v.ArgumentList[0].(*ast.ObjectLiteral); ok {
+				if baseExpr := jsObjectProperty(obj, "baseURL"); baseExpr != nil {
+					base, _ = c.eval(baseExpr, 0)
+				}
+			}
+			c.clients[name] = base
+		}
+	}
+
+	// axios.defaults.baseURL = "..."
+	if strings.HasSuffix(name, ".defaults.baseURL") {
+		base, _ := c.eval(value, 0)
+		c.clients[strings.TrimSuffix(name, ".defaults.baseURL")] = base
+	}
+}
+
+// ========== 常量求值 ==========
+
+// eval 将表达式求值为字符串；返回值中无法解析的部分以 {name} 占位，第二个返回值表示是否完全解析
+func (c *jsAnalysis) eval(expr ast.Expression, depth int) (string, bool) {
+	if expr == nil || depth > jsMaxEvalDepth {
+		return "{}", false
+	}
+
+	switch e := expr.(type) {
+	case *ast.StringLiteral:
+		return string(e.Value), true
+	case *ast.NumberLiteral:
+		return e.Literal, true
+	case *ast.TemplateLiteral:
+		if e.Tag != nil {
+			return "{" + jsMemberPath(e.Tag) + "}", false
+		}
+		var b strings.Builder
+		resolved := true
+		for i, elem := range e.Elements {
+			b.WriteString(string(elem.Parsed))
+			if i < len(e.Expressions) {
+				value, ok := c.eval(e.Expressions[i], depth+1)
+				b.WriteString(value)
+				resolved = resolved && ok
+			}
+		}
+		return b.String(), resolved
+	case *ast.BinaryExpression:
+		if e.Operator != token.PLUS {
+			return "{}", false
+		}
+		left, lok := c.eval(e.Left, depth+1)
+		right, rok := c.eval(e.Right, depth+1)
+		return left + right, lok && rok
+	case *ast.Identifier, *ast.DotExpression:
+		name := jsMemberPath(e)
+		if name == "" {
+			return "{}", false
+		}
+		if value, ok := c.bindings[name]; ok && !c.ambiguous[name] {
+			switch value.(type) {
+			case *ast.StringLiteral, *ast.TemplateLiteral, *ast.BinaryExpression, *ast.NumberLiteral,
+				*ast.Identifier, *ast.DotExpression, *ast.ConditionalExpression:
+				return c.eval(value, depth+1)
+			}
+		}
+		return "{" + jsShortName(name) + "}", false
+	case *ast.ConditionalExpression:
+		// 取第一个分支，标记为动态
+		value, _ := c.eval(e.Consequent, depth+1)
+		return value, false
+	case *ast.CallExpression:
+		callee := jsMemberPath(e.Callee)
+		switch {
+		case strings.HasSuffix(callee, ".concat"):
+			if dot, ok := e.Callee.(*ast.DotExpression); ok {
+				value, resolved := c.eval(dot.Left, depth+1)
+				for _, arg := range e.ArgumentList {
+					part, ok := c.eval(arg, depth+1)
+					value += part
+					resolved = resolved && ok
+				}
+				return value, resolved
+			}
+		case callee == "encodeURIComponent" || callee == "encodeURI" || callee == "String":
+			if len(e.ArgumentList) == 1 {
+				return c.eval(e.ArgumentList[0], depth+1)
+			}
+		}
+		return "{" + jsShortName(callee) + "}", false
+	}
+	return "{}", false
+}
+
+// ========== 第二遍：调用点识别 ==========
+
+// walk 遍历语法树，识别请求调用和字面量
+func (c *jsAnalysis) walk(root ast.Node) {
+	walkJSAST(root, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.CallExpression:
+			c.inspectCall(n)
+		case *ast.NewExpression:
+			c.inspectNew(n)
+		case *ast.BinaryExpression:
+			if n.Operator == token.PLUS {
+				c.literal(n)
+				c.walkConcatOperands(n)
+				return false
+			}
+		case *ast.TemplateLiteral:
+			if n.Tag == nil {
+				c.literal(n)
+				for _, e := range n.Expressions {
+					c.walk(e)
+				}
+				return false
+			}
+		case *ast.StringLiteral:
+			c.literal(n)
+		}
+		return true
+	})
+}
+
+// walkConcatOperands 拼接表达式整体已作为字面量处理，只遍历其中的非字符串部分
+func (c *jsAnalysis) walkConcatOperands(expr ast.Expression) {
+	switch e := expr.(type) {
+	case *ast.BinaryExpression:
+		if e.Operator == token.PLUS {
+			c.walkConcatOperands(e.Left)
+			c.walkConcatOperands(e.Right)
+			return
+		}
+	case *ast.StringLiteral, *ast.TemplateLiteral:
+		return
+	}
+	c.walk(expr)
+}
+
+// inspectCall 识别请求调用
+func (c *jsAnalysis) inspectCall(call *ast.CallExpression) {
+	callee := jsMemberPath(call.Callee)
+	if callee == "" {
+		return
+	}
+	args := call.ArgumentList
+	method := callee
+	owner := ""
+	if idx := strings.LastIndex(callee, "."); idx >= 0 {
+		owner, method = callee[:idx], callee[idx+1:]
+	}
+	ownerName := jsShortName(owner)
+
+	switch {
+	// fetch(url, {method, headers, body})
+	case method == "fetch" && (owner == "" || owner == "window" || owner == "self" || owner == "globalThis"):
+		if len(args) > 0 {
+			ep := c.endpoint(call, JSEndpointFetch, args[0], "GET")
+			if ep != nil && len(args) > 1 {
+				c.applyOptions(ep, args[1], "method", "body")
+			}
+			c.add(ep)
+		}
+
+	// $.ajax(url?, {url, type, data, headers}) / $.get(url, data) / $.post / $.getJSON
+	case (owner == "$" || owner == "jQuery") && (method == "ajax" || method == "get" || method == "post" || method == "getJSON"):
+		c.inspectJQuery(call, method, args)
+
+	// xhr.open("POST", url)
+	case method == "open" && len(args) >= 2:
+		if verb, ok := args[0].(*ast.StringLiteral); ok && jsHTTPMethods[strings.ToUpper(string(verb.Value))] {
+			c.add(c.endpoint(call, JSEndpointXHR, args[1], strings.ToUpper(string(verb.Value))))
+		}
+
+	// navigator.sendBeacon(url, data)
+	case method == "sendBeacon" && len(args) > 0:
+		ep := c.endpoint(call, JSEndpointBeacon, args[0], "POST")
+		if ep != nil && len(args) > 1 {
+			ep.BodyKeys = c.bodyKeys(args[1])
+		}
+		c.add(ep)
+
+	// axios(config) / axios(url, config) / request({url, method, data, params})
+	case owner == "" && (jsClientNames[method] || c.isClient(method)):
+		c.inspectConfigCall(call, method, args)
+
+	// axios.get(url, config) / api.post(url, data, config) / this.$http.put(...)
+	case owner != "" && (jsClientNames[ownerName] || c.isClient(owner)):
+		c.inspectClientMethod(call, owner, method, args)
+	}
+}
+
+// inspectNew 识别 new Request/WebSocket/EventSource
+func (c *jsAnalysis) inspectNew(expr *ast.NewExpression) {
+	if len(expr.ArgumentList) == 0 {
+		return
+	}
+	switch jsShortName(jsMemberPath(expr.Callee)) {
+	case "Request":
+		ep := c.endpoint(expr, JSEndpointFetch, expr.ArgumentList[0], "GET")
+		if ep != nil && len(expr.ArgumentList) > 1 {
+			c.applyOptions(ep, expr.ArgumentList[1], "method", "body")
+		}
+		c.add(ep)
+	case "WebSocket":
+		c.add(c.endpoint(expr, JSEndpointWebSocket, expr.ArgumentList[0], "GET"))
+	case "EventSource":
+		c.add(c.endpoint(expr, JSEndpointEventSource, expr.ArgumentList[0], "GET"))
+	}
+}
+
+// inspectJQuery 处理jQuery请求
+func (c *jsAnalysis) inspectJQuery(call *ast.CallExpression, method string, args []ast.Expression) {
+	if len(args) == 0 {
+		return
+	}
+	if method != "ajax" {
+		httpMethod := "GET"
+		if method == "post" {
+			httpMethod = "POST"
+		}
+		ep := c.endpoint(call, JSEndpointJQuery, args[0], httpMethod)
+		if ep != nil && len(args) > 1 {
+			keys := c.bodyKeys(args[1])
+			if httpMethod == "GET" {
+				ep.QueryParams = mergeUniqueStrings(ep.QueryParams, keys)
+			} else {
+				ep.BodyKeys = keys
+			}
+		}
+		c.add(ep)
+		return
+	}
+
+	// $.ajax(url, settings) 或 $.ajax(settings)
+	urlExpr, settings := args[0], ast.Expression(nil)
+	if obj, ok := args[0].(*ast.ObjectLiteral); ok {
+		settings = obj
+		urlExpr = jsObjectProperty(obj, "url")
+	} else if len(args) > 1 {
+		settings = args[1]
+	}
+	if urlExpr == nil {
+		return
+	}
+	ep := c.endpoint(call, JSEndpointJQuery, urlExpr, "GET")
+	if ep != nil && settings != nil {
+		c.applyOptions(ep, settings, "type", "data")
+		c.applyOptions(ep, settings, "method", "")
+		if ep.Method == "GET" && len(ep.BodyKeys) > 0 {
+			// jQuery的GET请求data会拼接到查询串
+			ep.QueryParams = mergeUniqueStrings(ep.QueryParams, ep.BodyKeys)
+			ep.BodyKeys = nil
+		}
+	}
+	c.add(ep)
+}
+
+// inspectConfigCall 处理 axios(config) / axios(url, config) / request(config)
+func (c *jsAnalysis) inspectConfigCall(call *ast.CallExpression, client string, args []ast.Expression) {
+	if len(args) == 0 {
+		return
+	}
+	urlExpr, config := args[0], ast.Expression(nil)
+	if obj, ok := args[0].(*ast.ObjectLiteral); ok {
+		config = obj
+		urlExpr = jsObjectProperty(obj, "url")
+	} else if len(args) > 1 {
+		config = args[1]
+	}
+	if urlExpr == nil {
+		return
+	}
+
+	kind := JSEndpointRequest
+	if client == "axios" || c.isClient(client) {
+		kind = JSEndpointAxios
+	}
+	ep := c.endpoint(call, kind, urlExpr, "GET")
+	if ep == nil {
+		return
+	}
+	ep.URL = c.withBaseURL(client, ep.URL)
+	if config != nil {
+		c.applyOptions(ep, config, "method", "data")
+	}
+	if kind == JSEndpointRequest && !jsLooksLikeEndpoint(ep.URL, true) {
+		return // 名称泛化的封装函数，要求URL看起来像端点
+	}
+	c.add(ep)
+}
+
+// inspectClientMethod 处理 client.get(url, config) / client.post(url, data, config)
+func (c *jsAnalysis) inspectClientMethod(call *ast.CallExpression, owner, method string, args []ast.Expression) {
+	httpMethod := strings.ToUpper(method)
+	if method == "request" {
+		c.inspectConfigCall(call, owner, args)
+		return
+	}
+	if !jsHTTPMethods[httpMethod] || len(args) == 0 {
+		return
+	}
+
+	ep := c.endpoint(call, JSEndpointAxios, args[0], httpMethod)
+	if ep == nil {
+		return
+	}
+	ep.URL = c.withBaseURL(owner, ep.URL)
+	if !c.isClient(owner) && jsShortName(owner) != "axios" && !jsLooksLikeEndpoint(ep.URL, true) {
+		return // http.get("key") 等非请求用法
+	}
+
+	configIdx := 1
+	if httpMethod == "POST" || httpMethod == "PUT" || httpMethod == "PATCH" {
+		if len(args) > 1 {
+			ep.BodyKeys = c.bodyKeys(args[1])
+		}
+		configIdx = 2
+	}
+	if len(args) > configIdx {
+		c.applyOptions(ep, args[configIdx], "", "")
+	}
+	c.add(ep)
+}
+
+// applyOptions 从请求配置对象中读取方法、请求头、请求体和params
+func (c *jsAnalysis) applyOptions(ep *JSEndpoint, options ast.Expression, methodKey, bodyKey string) {
+	obj := c.resolveObject(options, 0)
+	if obj == nil {
+		return
+	}
+	if methodKey != "" {
+		if expr := jsObjectProperty(obj, methodKey); expr != nil {
+			if value, ok := c.eval(expr, 0); ok && jsHTTPMethods[strings.ToUpper(value)] {
+				ep.Method = strings.ToUpper(value)
+			}
+		}
+	}
+	if bodyKey != "" {
+		if expr := jsObjectProperty(obj, bodyKey); expr != nil {
+			ep.BodyKeys = mergeUniqueStrings(ep.BodyKeys, c.bodyKeys(expr))
+		}
+	}
+	if expr := jsObjectProperty(obj, "params"); expr != nil {
+		ep.QueryParams = mergeUniqueStrings(ep.QueryParams, c.bodyKeys(expr))
+	}
+	if expr := jsObjectProperty(obj, "headers"); expr != nil {
+		if headers := c.resolveObject(expr, 0); headers != nil {
+			for _, prop := range headers.Value {
+				keyed, ok := prop.(*ast.PropertyKeyed)
+				if !ok || keyed.Computed {
+					continue
+				}
+				name := jsPropertyKey(keyed.Key)
+				if name == "" {
+					continue
+				}
+				if ep.Headers == nil {
+					ep.Headers = make(map[string]string)
+				}
+				ep.Headers[name], _ = c.eval(keyed.Value, 0)
+			}
+		}
+	}
+}
+
+// bodyKeys 提取请求体字段名：对象字面量、JSON.stringify(obj)、qs.stringify(obj)、FormData变量、"a=1&b=2"
+func (c *jsAnalysis) bodyKeys(expr ast.Expression) []string {
+	switch e := expr.(type) {
+	case *ast.CallExpression:
+		callee := jsMemberPath(e.Callee)
+		if strings.HasSuffix(callee, "stringify") && len(e.ArgumentList) > 0 {
+			return c.bodyKeys(e.ArgumentList[0])
+		}
+		return nil
+	case *ast.NewExpression:
+		if len(e.ArgumentList) > 0 {
+			return c.bodyKeys(e.ArgumentList[0])
+		}
+		return nil
+	case *ast.StringLiteral, *ast.TemplateLiteral, *ast.BinaryExpression:
+		value, _ := c.eval(e, 0)
+		return jsQueryKeys(value)
+	case *ast.Identifier, *ast.DotExpression:
+		name := jsMemberPath(e)
+		if keys, ok := c.formKeys[name]; ok {
+			return keys
+		}
+	}
+
+	obj := c.resolveObject(expr, 0)
+	if obj == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(obj.Value))
+	for _, prop := range obj.Value {
+		switch p := prop.(type) {
+		case *ast.PropertyKeyed:
+			if key := jsPropertyKey(p.Key); key != "" && !p.Computed {
+				keys = appendUniqueString(keys, key)
+			}
+		case *ast.PropertyShort:
+			keys = appendUniqueString(keys, string(p.Name.Name))
+		}
+	}
+	return keys
+}
+
+// resolveObject 将表达式解析为对象字面量（支持变量引用）
+func (c *jsAnalysis) resolveObject(expr ast.Expression, depth int) *ast.ObjectLiteral {
+	if depth > jsMaxEvalDepth {
+		return nil
+	}
+	switch e := expr.(type) {
+	case *ast.ObjectLiteral:
+		return e
+	case *ast.Identifier, *ast.DotExpression:
+		name := jsMemberPath(e)
+		if value, ok := c.bindings[name]; ok && !c.ambiguous[name] {
+			return c.resolveObject(value, depth+1)
+		}
+	}
+	return nil
+}
+
+// endpoint 以URL表达式创建端点；URL无法构成有效端点时返回nil
+func (c *jsAnalysis) endpoint(node ast.Node, kind string, urlExpr ast.Expression, method string) *JSEndpoint {
+	value, resolved := c.eval(urlExpr, 0)
+	value = strings.TrimSpace(value)
+	if value == "" || value == "{}" || strings.ContainsAny(value, " \t\r\n<>\"'") {
+		return nil
+	}
+
+	pos := c.position(node)
+	return &JSEndpoint{
+		URL:         value,
+		Method:      method,
+		QueryParams: jsQueryKeys(value),
+		Kind:        kind,
+		Source:      c.source,
+		Line:        pos.Line,
+		Column:      pos.Column,
+		Dynamic:     !resolved,
+	}
+}
+
+// literal 记录看起来像路径/URL的字符串字面量或拼接结果
+func (c *jsAnalysis) literal(expr ast.Expression) {
+	value, resolved := c.eval(expr, 0)
+	if !jsLooksLikeEndpoint(value, false) {
+		return
+	}
+	pos := c.position(expr)
+	c.add(&JSEndpoint{
+		URL:         value,
+		QueryParams: jsQueryKeys(value),
+		Kind:        JSEndpointLiteral,
+		Source:      c.source,
+		Line:        pos.Line,
+		Column:      pos.Column,
+		Dynamic:     !resolved,
+	})
+}
+
+// add 去重后记录端点
+func (c *jsAnalysis) add(ep *JSEndpoint) {
+	if ep == nil {
+		return
+	}
+	key := fmt.Sprintf("%s|%s|%s|%d", ep.Kind, ep.Method, ep.URL, ep.Line)
+	if c.seen[key] {
+		return
+	}
+	c.seen[key] = true
+	c.endpoints = append(c.endpoints, ep)
+}
+
+// result 输出端点：已在调用点出现的URL不再重复作为字面量输出
+func (c *jsAnalysis) result() []*JSEndpoint {
+	called := make(map[string]bool)
+	for _, ep := range c.endpoints {
+		if ep.Kind != JSEndpointLiteral {
+			called[ep.URL] = true
+		}
+	}
+
+	endpoints := make([]*JSEndpoint, 0, len(c.endpoints))
+	literals := make(map[string]bool)
+	for _, ep := range c.endpoints {
+		if ep.Kind == JSEndpointLiteral {
+			if called[ep.URL] || literals[ep.URL] {
+				continue
+			}
+			literals[ep.URL] = true
+		}
+		sort.Strings(ep.QueryParams)
+		endpoints = append(endpoints, ep)
+	}
+	sort.SliceStable(endpoints, func(i, k int) bool {
+		if endpoints[i].Line != endpoints[k].Line {
+			return endpoints[i].Line < endpoints[k].Line
+		}
+		return endpoints[i].Column < endpoints[k].Column
+	})
+	return endpoints
+}
+
+// position 节点在源文件中的行列号
+func (c *jsAnalysis) position(node ast.Node) file.Position {
+	if c.file == nil {
+		return file.Position{}
+	}
+	return c.file.Position(int(node.Idx0()) - c.file.Base())
+}
+
+// isClient 是否为已知的axios实例
+func (c *jsAnalysis) isClient(name string) bool {
+	_, ok := c.clients[name]
+	return ok
+}
+
+// withBaseURL 为axios实例的相对URL拼接baseURL
+func (c *jsAnalysis) withBaseURL(client, rawURL string) string {
+	base := c.clients[client]
+	if base == "" || jsIsAbsoluteURL(rawURL) || strings.HasPrefix(rawURL, base) {
+		return rawURL
+	}
+	return strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(rawURL, "/")
+}
+
+// ========== 辅助函数 ==========
+
+// jsMemberPath 将标识符/成员访问表达式转为点分路径（this.$http.get → this.$http.get）
+func jsMemberPath(expr ast.Expression) string {
+	switch e := expr.(type) {
+	case *ast.Identifier:
+		return string(e.Name)
+	case *ast.ThisExpression:
+		return "this"
+	case *ast.DotExpression:
+		left := jsMemberPath(e.Left)
+		if left == "" {
+			return ""
+		}
+		return left + "." + string(e.Identifier.Name)
+	case *ast.BracketExpression:
+		if key, ok := e.Member.(*ast.StringLiteral); ok {
+			if left := jsMemberPath(e.Left); left != "" {
+				return left + "." + string(key.Value)
+			}
+		}
+	case *ast.Optional:
+		return jsMemberPath(e.Expression)
+	case *ast.OptionalChain:
+		return jsMemberPath(e.Expression)
+	}
+	return ""
+}
+
+// jsShortName 点分路径的最后一段
+func jsShortName(path string) string {
+	if idx := strings.LastIndex(path, "."); idx >= 0 {
+		return path[idx+1:]
+	}
+	return path
+}
+
+// jsPropertyKey 对象属性名
+func jsPropertyKey(key ast.Expression) string {
+	switch k := key.(type) {
+	case *ast.StringLiteral:
+		return string(k.Value)
+	case *ast.Identifier:
+		return string(k.Name)
+	case *ast.NumberLiteral:
+		return k.Literal
+	}
+	return ""
+}
+
+// jsObjectProperty 查找对象字面量中的属性值（简写属性返回对应标识符）
+func jsObjectProperty(obj *ast.ObjectLiteral, name string) ast.Expression {
+	for _, prop := range obj.Value {
+		switch p := prop.(type) {
+		case *ast.PropertyKeyed:
+			if !p.Computed && jsPropertyKey(p.Key) == name {
+				return p.Value
+			}
+		case *ast.PropertyShort:
+			if string(p.Name.Name) == name {
+				id := p.Name
+				return &id
+			}
+		}
+	}
+	return nil
+}
+
+// jsQueryKeys 提取查询串（或 a=1&b=2 形式请求体）中的参数名
+func jsQueryKeys(raw string) []string {
+	query := raw
+	if idx := strings.Index(raw, "?"); idx >= 0 {
+		query = raw[idx+1:]
+	} else if !strings.Contains(raw, "=") || strings.Contains(raw, "/") {
+		return nil
+	}
+	if idx := strings.Index(query, "#"); idx >= 0 {
+		query = query[:idx]
+	}
+
+	keys := make([]string, 0)
+	for _, pair := range strings.Split(query, "&") {
+		key := pair
+		if idx := strings.Index(pair, "="); idx >= 0 {
+			key = pair[:idx]
+		}
+		if decoded, err := url.QueryUnescape(key); err == nil {
+			key = decoded
+		}
+		if key != "" && !strings.HasPrefix(key, "{") {
+			keys = appendUniqueString(keys, key)
+		}
+	}
+	return keys
+}
+
+// isJavaScriptMediaType 是否为JavaScript媒体类型
+func isJavaScriptMediaType(mediaType string) bool {
+	return strings.Contains(mediaType, "javascript") || strings.Contains(mediaType, "ecmascript")
+}
+
+// jsIsAbsoluteURL 是否为带协议的URL
+func jsIsAbsoluteURL(raw string) bool {
+	lower := strings.ToLower(raw)
+	for _, prefix := range []string{"http://", "https://", "ws://", "wss://", "//"} {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsLooksLikeEndpoint 判断字符串是否像端点；callSite为true时（已在请求调用中）放宽为允许相对路径
+func jsLooksLikeEndpoint(value string, callSite bool) bool {
+	if value == "" || len(value) > 2048 || strings.ContainsAny(value, " \t\r\n<>\"'\\") {
+		return false
+	}
+	if jsIsAbsoluteURL(value) {
+		host := value[strings.Index(value, "//")+2:]
+		return strings.Contains(host, ".") || strings.HasPrefix(host, "localhost")
+	}
+	if strings.HasPrefix(value, "{") {
+		// {baseURL}/api/user
+		rest := value[strings.Index(value, "}")+1:]
+		return strings.HasPrefix(rest, "/") && jsStaticPathPattern.MatchString(rest)
+	}
+	if strings.HasPrefix(value, "/") {
+		return !strings.HasPrefix(value, "//") && jsStaticPathPattern.MatchString(value)
+	}
+	if callSite {
+		return strings.Contains(value, "/") || strings.Contains(value, ".") || strings.Contains(value, "?")
+	}
+	return false
+}
+
+// appendUniqueString 追加不重复的字符串
+func appendUniqueString(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
+
+// mergeUniqueStrings 合并去重
+func mergeUniqueStrings(list []string, values []string) []string {
+	for _, v := range values {
+		list = appendUniqueString(list, v)
+	}
+	return list
+}
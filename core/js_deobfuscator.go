@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 // JSDeobfuscator JavaScript反混淆器
@@ -544,8 +546,8 @@ func (jd *JSDeobfuscator) ExtractAPIEndpoints() []string {
 	
 	// 1. 查找API相关模式
 	apiPatterns := []string{
-		`['"]/(api|API)/[a-zA-Z0-9/_-]+['"]`,
-		`['"]/(v\d+)/[a-zA-Z0-9/_-]+['"]`,
+		`['"]/(?:api|API)/[a-zA-Z0-9/_-]+['"]`,
+		`['"]/(?:v\d+)/[a-zA-Z0-9/_-]+['"]`,
 		`baseURL\s*[+=]\s*['"]([^'"]+)['"]`,
 		`apiUrl\s*[+=]\s*['"]([^'"]+)['"]`,
 		`endpoint\s*[+=]\s*['"]([^'"]+)['"]`,
@@ -624,3 +626,71 @@ func (jd *JSDeobfuscator) isPrintable(s string) bool {
 func (jd *JSDeobfuscator) SaveToFile(filename string) error {
 	return os.WriteFile(filename, []byte(jd.code), 0644)
 }
+
+// DetectObfuscation 检测脚本是否经过混淆（🆕 v4.9），返回混淆类型
+func (jd *JSDeobfuscator) DetectObfuscation() (string, bool) {
+	if strings.TrimSpace(jd.code) == "" {
+		return "", false
+	}
+	obfuscationType := jd.detectObfuscationType()
+	return obfuscationType, obfuscationType != "Light/Unknown"
+}
+
+// DeobfuscationReport 单个脚本的反混淆结果（🆕 v4.9）
+type DeobfuscationReport struct {
+	Source         string   `json:"source"`
+	Type           string   `json:"type"`            // 混淆类型
+	DecodedStrings []string `json:"decoded_strings"` // 解密得到的字符串
+	HiddenURLs     []string `json:"hidden_urls"`     // 反混淆后发现的URL/API
+	SandboxDecoded int      `json:"sandbox_decoded"` // 沙箱还原的解码调用数
+	SandboxError   string   `json:"sandbox_error,omitempty"`
+	Code           string   `json:"-"` // 解码调用替换为字面量后的代码（仍是合法JS）
+}
+
+// DeobfuscateScript 检测混淆并自动反混淆（🆕 v4.9）
+// 未检测到混淆时返回nil。字符串数组/Packer在沙箱中还原（sandbox为nil时跳过），
+// 之后对还原结果做静态解码（Base64/Hex/Unicode/数组下标）以提取隐藏URL
+func DeobfuscateScript(jsCode, source string, sandbox *JSSandbox) *DeobfuscationReport {
+	jd := NewJSDeobfuscator(jsCode)
+	obfuscationType, obfuscated := jd.DetectObfuscation()
+	if !obfuscated {
+		return nil
+	}
+
+	report := &DeobfuscationReport{
+		Source: source,
+		Type:   obfuscationType,
+	}
+	if sandbox != nil {
+		decoded, err := jd.DecodeWithSandbox(sandbox)
+		report.SandboxDecoded = decoded
+		if err != nil {
+			report.SandboxError = err.Error()
+		}
+	}
+	report.Code = jd.code
+
+	// 静态解码会破坏语法，只用于提取，不影响 report.Code
+	jd.decodeBase64Strings()
+	jd.decodeHexStrings()
+	jd.decodeUnicodeStrings()
+	jd.decryptArrays()
+
+	unique := make(map[string]bool, len(jd.decodedStrings))
+	report.DecodedStrings = make([]string, 0, len(jd.decodedStrings))
+	for _, decoded := range jd.decodedStrings {
+		if !unique[decoded] && utf8.ValidString(decoded) && jd.isPrintable(decoded) {
+			unique[decoded] = true
+			report.DecodedStrings = append(report.DecodedStrings, decoded)
+		}
+	}
+	sort.Strings(report.DecodedStrings)
+	report.HiddenURLs = make([]string, 0)
+	for _, candidate := range mergeUniqueStrings(jd.ExtractHiddenURLs(), jd.ExtractAPIEndpoints()) {
+		if jsLooksLikeEndpoint(candidate, false) {
+			report.HiddenURLs = append(report.HiddenURLs, candidate)
+		}
+	}
+	sort.Strings(report.HiddenURLs)
+	return report
+}
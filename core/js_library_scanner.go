@@ -0,0 +1,508 @@
+package core
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 组件版本的识别方式
+const (
+	JSLibraryDetectionURI       = "uri"         // CDN路径，如 /ajax/libs/jquery/1.8.2/jquery.min.js
+	JSLibraryDetectionFilename  = "filename"    // 文件名，如 jquery-1.8.2.min.js
+	JSLibraryDetectionContent   = "filecontent" // 文件头部的版权横幅等
+	JSLibraryDetectionHash      = "hash"        // 文件内容的SHA1
+	JSLibraryDetectionTechStack = "techstack"   // 技术栈检测器给出的版本
+)
+
+// jsLibraryVersionPattern 漏洞库中 §§version§§ 占位符对应的版本号正则（与retire.js一致）
+const jsLibraryVersionPattern = `[0-9][0-9.a-z_\-]+`
+
+// jsLibrarySeverityRank 严重程度排序
+var jsLibrarySeverityRank = map[string]int{"LOW": 1, "MEDIUM": 2, "HIGH": 3, "CRITICAL": 4}
+
+// JSLibraryScanner 前端组件漏洞扫描器（v4.9新增）
+// 通过脚本URL、文件名、版权横幅和内容哈希识别组件版本，
+// 与离线漏洞库（retire.js jsrepository 格式的JSON）比对
+type JSLibraryScanner struct {
+	libraries []*jsLibraryDefinition
+	findings  []*JSLibraryFinding
+	seen      map[string]bool // 库@版本@脚本URL
+	scanned   int
+	skipped   int // 无法编译的提取规则数（如使用了Go不支持的反向断言）
+	mutex     sync.Mutex
+}
+
+// jsLibraryDefinition 编译后的组件定义
+type jsLibraryDefinition struct {
+	name            string
+	uris            []*regexp.Regexp
+	filenames       []*regexp.Regexp
+	contents        []*regexp.Regexp
+	hashes          map[string]string
+	vulnerabilities []JSLibraryVulnerability
+}
+
+// jsVulnDatabase 漏洞库文件（libraries 为空时整个文件按retire.js原始格式解析）
+type jsVulnDatabase struct {
+	Description string                        `json:"description"`
+	Version     string                        `json:"version"`
+	Libraries   map[string]*jsRepositoryEntry `json:"libraries"`
+}
+
+// jsRepositoryEntry retire.js jsrepository.json 中的单个组件
+type jsRepositoryEntry struct {
+	Vulnerabilities []JSLibraryVulnerability `json:"vulnerabilities"`
+	Extractors      struct {
+		URI         []string          `json:"uri"`
+		Filename    []string          `json:"filename"`
+		FileContent []string          `json:"filecontent"`
+		Hashes      map[string]string `json:"hashes"`
+	} `json:"extractors"`
+}
+
+// JSLibraryVulnerability 漏洞条目（影响范围：atOrAbove <= 版本 < below）
+type JSLibraryVulnerability struct {
+	AtOrAbove   string `json:"atOrAbove,omitempty"`
+	Below       string `json:"below"`
+	Severity    string `json:"severity"`
+	Identifiers struct {
+		CVE      []string `json:"CVE,omitempty"`
+		Summary  string   `json:"summary,omitempty"`
+		GithubID string   `json:"githubID,omitempty"`
+	} `json:"identifiers"`
+	Info []string `json:"info,omitempty"`
+}
+
+// JSLibraryFinding 识别出的组件及其已知漏洞
+type JSLibraryFinding struct {
+	Library         string                   `json:"library"`
+	Version         string                   `json:"version"`
+	ScriptURL       string                   `json:"script_url"`
+	Detection       string                   `json:"detection"`
+	Vulnerable      bool                     `json:"vulnerable"`
+	Severity        string                   `json:"severity,omitempty"` // 最高严重程度
+	CVEs            []string                 `json:"cves,omitempty"`
+	Vulnerabilities []JSLibraryVulnerability `json:"vulnerabilities,omitempty"`
+}
+
+// NewJSLibraryScanner 创建前端组件漏洞扫描器（需调用LoadDatabase加载漏洞库）
+func NewJSLibraryScanner() *JSLibraryScanner {
+	return &JSLibraryScanner{
+		libraries: make([]*jsLibraryDefinition, 0),
+		findings:  make([]*JSLibraryFinding, 0),
+		seen:      make(map[string]bool),
+	}
+}
+
+// LoadDatabase 从JSON文件加载漏洞库（替换已加载的组件定义）
+// 支持本项目的 {"libraries": {...}} 格式，也可直接使用retire.js的 jsrepository.json
+func (jls *JSLibraryScanner) LoadDatabase(filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("读取漏洞库失败: %v", err)
+	}
+
+	var db jsVulnDatabase
+	if err := json.Unmarshal(data, &db); err != nil {
+		return fmt.Errorf("解析漏洞库失败: %v", err)
+	}
+	entries := db.Libraries
+	if len(entries) == 0 {
+		// retire.js 原始格式：顶层即为 组件名 → 定义
+		raw := make(map[string]json.RawMessage)
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("解析漏洞库失败: %v", err)
+		}
+		entries = make(map[string]*jsRepositoryEntry)
+		for name, msg := range raw {
+			var entry jsRepositoryEntry
+			if json.Unmarshal(msg, &entry) == nil && len(entry.Vulnerabilities) > 0 {
+				entries[name] = &entry
+			}
+		}
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("漏洞库中没有组件定义: %s", filename)
+	}
+
+	libraries := make([]*jsLibraryDefinition, 0, len(entries))
+	skipped := 0
+	for name, entry := range entries {
+		def := &jsLibraryDefinition{
+			name:            name,
+			hashes:          entry.Extractors.Hashes,
+			vulnerabilities: entry.Vulnerabilities,
+		}
+		var n int
+		def.uris, n = compileJSLibraryPatterns(entry.Extractors.URI)
+		skipped += n
+		def.filenames, n = compileJSLibraryPatterns(entry.Extractors.Filename)
+		skipped += n
+		def.contents, n = compileJSLibraryPatterns(entry.Extractors.FileContent)
+		skipped += n
+		libraries = append(libraries, def)
+	}
+	sort.Slice(libraries, func(i, j int) bool { return libraries[i].name < libraries[j].name })
+
+	jls.mutex.Lock()
+	jls.libraries = libraries
+	jls.skipped = skipped
+	jls.mutex.Unlock()
+
+	fmt.Printf("[组件漏洞] 已加载漏洞库 %s: %d 个组件", filename, len(libraries))
+	if skipped > 0 {
+		fmt.Printf("（跳过 %d 条不兼容的提取规则）", skipped)
+	}
+	fmt.Println()
+	return nil
+}
+
+// compileJSLibraryPatterns 编译提取规则，返回成功的正则和跳过的数量
+func compileJSLibraryPatterns(patterns []string) ([]*regexp.Regexp, int) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	skipped := 0
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(strings.ReplaceAll(pattern, "§§version§§", jsLibraryVersionPattern))
+		if err != nil {
+			skipped++
+			continue
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, skipped
+}
+
+// ScanURL 从脚本URL（CDN路径和文件名）识别组件版本
+func (jls *JSLibraryScanner) ScanURL(scriptURL string) []*JSLibraryFinding {
+	u, err := url.Parse(scriptURL)
+	if err != nil {
+		return nil
+	}
+	filename := path.Base(u.Path)
+
+	results := make([]*JSLibraryFinding, 0)
+	for _, lib := range jls.getLibraries() {
+		if version := matchJSLibraryVersion(lib.uris, u.Path); version != "" {
+			results = appendFinding(results, jls.record(lib, version, scriptURL, JSLibraryDetectionURI))
+		} else if version := matchJSLibraryVersion(lib.filenames, filename); version != "" {
+			results = appendFinding(results, jls.record(lib, version, scriptURL, JSLibraryDetectionFilename))
+		}
+	}
+	return results
+}
+
+// ScanContent 从脚本内容（内容哈希和版权横幅）识别组件版本
+func (jls *JSLibraryScanner) ScanContent(scriptURL string, content string) []*JSLibraryFinding {
+	jls.mutex.Lock()
+	jls.scanned++
+	jls.mutex.Unlock()
+
+	sum := sha1.Sum([]byte(content))
+	hash := hex.EncodeToString(sum[:])
+
+	// 横幅通常在文件开头，打包后的bundle中可能出现在任意位置，限制扫描范围
+	const maxContentScan = 2 * 1024 * 1024
+	if len(content) > maxContentScan {
+		content = content[:maxContentScan]
+	}
+
+	results := make([]*JSLibraryFinding, 0)
+	for _, lib := range jls.getLibraries() {
+		if version, ok := lib.hashes[hash]; ok {
+			results = appendFinding(results, jls.record(lib, version, scriptURL, JSLibraryDetectionHash))
+			continue
+		}
+		for _, re := range lib.contents {
+			for _, match := range re.FindAllStringSubmatch(content, 5) {
+				if version := jsLibraryVersionFromMatch(match); version != "" {
+					results = appendFinding(results, jls.record(lib, version, scriptURL, JSLibraryDetectionContent))
+				}
+			}
+		}
+	}
+	return results
+}
+
+// ScanComponent 检查已知名称和版本的组件（如技术栈检测结果）
+// 名称不区分大小写，也会尝试去掉 .js 后缀（Vue.js → vue）
+func (jls *JSLibraryScanner) ScanComponent(name, version, sourceURL string) *JSLibraryFinding {
+	version = normalizeJSLibraryVersion(version)
+	if version == "" {
+		return nil
+	}
+	key := strings.ToLower(strings.TrimSpace(name))
+	for _, lib := range jls.getLibraries() {
+		if lib.name == key || lib.name == strings.TrimSuffix(key, ".js") {
+			return jls.record(lib, version, sourceURL, JSLibraryDetectionTechStack)
+		}
+	}
+	return nil
+}
+
+// record 记录组件（同一脚本中相同的库和版本只记录一次），返回新记录的发现
+func (jls *JSLibraryScanner) record(lib *jsLibraryDefinition, version, scriptURL, detection string) *JSLibraryFinding {
+	key := lib.name + "@" + version + "@" + scriptURL
+	jls.mutex.Lock()
+	defer jls.mutex.Unlock()
+	if jls.seen[key] {
+		return nil
+	}
+	jls.seen[key] = true
+
+	finding := &JSLibraryFinding{
+		Library:   lib.name,
+		Version:   version,
+		ScriptURL: scriptURL,
+		Detection: detection,
+	}
+	for _, vuln := range lib.vulnerabilities {
+		if !jsLibraryVersionAffected(version, vuln) {
+			continue
+		}
+		vuln.Severity = strings.ToUpper(vuln.Severity)
+		finding.Vulnerabilities = append(finding.Vulnerabilities, vuln)
+		if jsLibrarySeverityRank[vuln.Severity] > jsLibrarySeverityRank[finding.Severity] {
+			finding.Severity = vuln.Severity
+		}
+		for _, cve := range vuln.Identifiers.CVE {
+			finding.CVEs = appendUniqueString(finding.CVEs, cve)
+		}
+	}
+	finding.Vulnerable = len(finding.Vulnerabilities) > 0
+	jls.findings = append(jls.findings, finding)
+	return finding
+}
+
+// getLibraries 获取已加载的组件定义
+func (jls *JSLibraryScanner) getLibraries() []*jsLibraryDefinition {
+	jls.mutex.Lock()
+	defer jls.mutex.Unlock()
+	return jls.libraries
+}
+
+// GetFindings 获取识别出的所有组件（含无已知漏洞的组件）
+func (jls *JSLibraryScanner) GetFindings() []*JSLibraryFinding {
+	jls.mutex.Lock()
+	defer jls.mutex.Unlock()
+	findings := make([]*JSLibraryFinding, len(jls.findings))
+	copy(findings, jls.findings)
+	return findings
+}
+
+// GetVulnerableFindings 获取存在已知漏洞的组件，按严重程度排序
+func (jls *JSLibraryScanner) GetVulnerableFindings() []*JSLibraryFinding {
+	vulnerable := make([]*JSLibraryFinding, 0)
+	for _, finding := range jls.GetFindings() {
+		if finding.Vulnerable {
+			vulnerable = append(vulnerable, finding)
+		}
+	}
+	sort.SliceStable(vulnerable, func(i, j int) bool {
+		return jsLibrarySeverityRank[vulnerable[i].Severity] > jsLibrarySeverityRank[vulnerable[j].Severity]
+	})
+	return vulnerable
+}
+
+// GetStatistics 获取统计信息
+func (jls *JSLibraryScanner) GetStatistics() map[string]int {
+	findings := jls.GetFindings()
+	stats := map[string]int{
+		"components": len(findings),
+		"vulnerable": 0,
+		"cves":       0,
+	}
+	cves := make(map[string]bool)
+	for _, finding := range findings {
+		if finding.Vulnerable {
+			stats["vulnerable"]++
+		}
+		for _, cve := range finding.CVEs {
+			cves[cve] = true
+		}
+	}
+	stats["cves"] = len(cves)
+
+	jls.mutex.Lock()
+	stats["scripts_scanned"] = jls.scanned
+	stats["libraries_loaded"] = len(jls.libraries)
+	jls.mutex.Unlock()
+	return stats
+}
+
+// PrintReport 打印组件漏洞报告
+func (jls *JSLibraryScanner) PrintReport() {
+	stats := jls.GetStatistics()
+	if stats["components"] == 0 {
+		return
+	}
+
+	fmt.Println("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Println("【前端组件漏洞】")
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Printf("识别组件: %d | 存在漏洞: %d | 涉及CVE: %d\n",
+		stats["components"], stats["vulnerable"], stats["cves"])
+
+	for _, finding := range jls.GetVulnerableFindings() {
+		fmt.Printf("  [%s] %s %s (%s)\n", finding.Severity, finding.Library, finding.Version, finding.Detection)
+		fmt.Printf("      脚本: %s\n", finding.ScriptURL)
+		for _, vuln := range finding.Vulnerabilities {
+			ids := strings.Join(vuln.Identifiers.CVE, ", ")
+			if ids == "" {
+				ids = vuln.Identifiers.GithubID
+			}
+			fmt.Printf("      - %s [%s] %s\n", ids, vuln.Severity, vuln.Identifiers.Summary)
+		}
+	}
+}
+
+// appendFinding 追加非空的发现
+func appendFinding(results []*JSLibraryFinding, finding *JSLibraryFinding) []*JSLibraryFinding {
+	if finding == nil {
+		return results
+	}
+	return append(results, finding)
+}
+
+// matchJSLibraryVersion 用一组提取规则匹配版本号
+func matchJSLibraryVersion(patterns []*regexp.Regexp, value string) string {
+	for _, re := range patterns {
+		if version := jsLibraryVersionFromMatch(re.FindStringSubmatch(value)); version != "" {
+			return version
+		}
+	}
+	return ""
+}
+
+// jsLibraryVersionFromMatch 从匹配结果中取版本号（第一个以数字开头的捕获组）
+func jsLibraryVersionFromMatch(match []string) string {
+	for i, group := range match {
+		if i > 0 && group != "" && group[0] >= '0' && group[0] <= '9' {
+			return normalizeJSLibraryVersion(group)
+		}
+	}
+	return ""
+}
+
+// normalizeJSLibraryVersion 去掉贪婪匹配带入的 .min / .slim 等文件名后缀
+func normalizeJSLibraryVersion(version string) string {
+	version = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(version), "v"))
+	for changed := true; changed; {
+		changed = false
+		for _, suffix := range []string{".js", ".min", "-min", ".slim", ".prod", ".production", ".bundle", ".custom", ".debug", ".runtime", "."} {
+			if strings.HasSuffix(version, suffix) {
+				version = strings.TrimSuffix(version, suffix)
+				changed = true
+			}
+		}
+	}
+	return version
+}
+
+// jsLibraryVersionAffected 判断版本是否在漏洞影响范围内
+func jsLibraryVersionAffected(version string, vuln JSLibraryVulnerability) bool {
+	if vuln.Below != "" && compareJSLibraryVersions(version, vuln.Below) >= 0 {
+		return false
+	}
+	if vuln.AtOrAbove != "" && compareJSLibraryVersions(version, vuln.AtOrAbove) < 0 {
+		return false
+	}
+	return vuln.Below != "" || vuln.AtOrAbove != ""
+}
+
+// compareJSLibraryVersions 比较版本号（数字段按数值比较，预发布版本低于正式版本）
+func compareJSLibraryVersions(a, b string) int {
+	pa, pb := splitJSLibraryVersion(a), splitJSLibraryVersion(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y string
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x == y {
+			continue
+		}
+		// 缺失的段：数字视为0，预发布标识（alpha/beta/rc）低于正式版本
+		if x == "" {
+			if _, err := strconv.Atoi(y); err == nil {
+				x = "0"
+			} else {
+				return 1
+			}
+		}
+		if y == "" {
+			if _, err := strconv.Atoi(x); err == nil {
+				y = "0"
+			} else {
+				return -1
+			}
+		}
+		nx, errX := strconv.Atoi(x)
+		ny, errY := strconv.Atoi(y)
+		switch {
+		case errX == nil && errY == nil:
+			if nx != ny {
+				if nx < ny {
+					return -1
+				}
+				return 1
+			}
+		case errX == nil:
+			return 1 // 数字段高于预发布标识
+		case errY == nil:
+			return -1
+		default:
+			if x != y {
+				if x < y {
+					return -1
+				}
+				return 1
+			}
+		}
+	}
+	return 0
+}
+
+// splitJSLibraryVersion 将版本号拆分为数字段和标识段（3.0.0-beta1 → 3 0 0 beta 1）
+func splitJSLibraryVersion(version string) []string {
+	parts := make([]string, 0)
+	current := strings.Builder{}
+	digit := false
+	flush := func() {
+		if current.Len() > 0 {
+			parts = append(parts, current.String())
+			current.Reset()
+		}
+	}
+	for _, r := range strings.ToLower(version) {
+		switch {
+		case r == '.' || r == '-' || r == '_' || r == '+':
+			flush()
+		case r >= '0' && r <= '9':
+			if !digit {
+				flush()
+			}
+			digit = true
+			current.WriteRune(r)
+		default:
+			if digit {
+				flush()
+			}
+			digit = false
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return parts
+}
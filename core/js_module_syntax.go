@@ -0,0 +1,339 @@
+package core
+
+import "strings"
+
+// 🆕 v4.9: ES模块语法改写
+// goja解析器只接受脚本语法，Vite/Rollup产物和 type="module" 脚本中的顶层 import/export 会导致整个文件解析失败。
+// 解析前将顶层模块声明改写为等价的脚本语法，改写只替换字符、不增删字符（换行保留），
+// 语法树中的偏移、行号与原代码一致：
+//   - import 声明、export {...}、export * from 整体替换为空格
+//   - export default 替换为等长的 "var _default ="
+//   - export const/let/var/function/class 去掉 export 关键字
+//   - 动态 import() 和 import.meta（任意位置）中的 import 替换为等长的标识符
+
+const (
+	jsModuleDefaultBinding = "var _default =" // export default 的改写结果（与 "export default" 等长）
+	jsModuleImportName     = "$jsimp"         // import() / import.meta 中 import 的替换标识符
+)
+
+// rewriteJSModuleSyntax 改写顶层模块声明，返回改写后的代码和是否发生了改写
+func rewriteJSModuleSyntax(code string) (string, bool) {
+	if !strings.Contains(code, "import") && !strings.Contains(code, "export") {
+		return code, false
+	}
+
+	var out []byte // 首次改写时才复制
+	blank := func(from, to int) {
+		if out == nil {
+			out = []byte(code)
+		}
+		for k := from; k < to; k++ {
+			if out[k] != '\n' && out[k] != '\r' {
+				out[k] = ' '
+			}
+		}
+	}
+
+	depth := 0
+	var prev byte   // 上一个有效字符（0表示文件开头）
+	prevWord := ""  // 上一个有效记号为标识符时的内容
+	newline := true // 上一个有效字符之后是否出现过换行（自动分号插入）
+	for i := 0; i < len(code); {
+		c := code[i]
+		switch {
+		case c == '\n':
+			newline = true
+			i++
+			continue
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+			continue
+		case c == '/' && i+1 < len(code) && (code[i+1] == '/' || code[i+1] == '*'):
+			end := skipJSComment(code, i)
+			if strings.Contains(code[i:end], "\n") {
+				newline = true
+			}
+			i = end
+			continue
+		case c == '\'' || c == '"':
+			i = skipJSString(code, i)
+		case c == '`':
+			i = skipJSTemplate(code, i)
+		case c == '/' && jsRegexAllowed(prev, prevWord):
+			i = skipJSRegex(code, i)
+		case isJSIdentByte(c) && !(c >= '0' && c <= '9'):
+			end := i
+			for end < len(code) && isJSIdentByte(code[end]) {
+				end++
+			}
+			word := code[i:end]
+			if word == "import" && prev != '.' {
+				if k := skipJSSpace(code, end); k < len(code) && (code[k] == '(' || code[k] == '.') {
+					blank(i, end)
+					copy(out[i:], jsModuleImportName)
+					prev, prevWord, newline = code[end-1], word, false
+					i = end
+					continue
+				}
+			}
+			if depth == 0 && (word == "import" || word == "export") && (prev == 0 || prev == ';' || prev == '}' || newline) {
+				if next, ok := rewriteJSModuleDecl(code, i, end, blank, &out); ok {
+					// 整体替换的声明相当于一条以分号结束的语句
+					if next > end {
+						prev, prevWord, newline = ';', "", false
+						i = next
+						continue
+					}
+				}
+			}
+			prev, prevWord, newline = code[end-1], word, false
+			i = end
+			continue
+		case c == '{' || c == '(' || c == '[':
+			depth++
+			i++
+		case c == '}' || c == ')' || c == ']':
+			if depth > 0 {
+				depth--
+			}
+			i++
+		default:
+			i++
+		}
+		prev, prevWord, newline = c, "", false
+	}
+
+	if out == nil {
+		return code, false
+	}
+	return string(out), true
+}
+
+// rewriteJSModuleDecl 改写 start 处的 import/export 声明（wordEnd 为关键字结束位置）
+// 返回继续扫描的位置：整体替换时为声明结束位置，只改写关键字时为 wordEnd
+func rewriteJSModuleDecl(code string, start, wordEnd int, blank func(from, to int), out *[]byte) (int, bool) {
+	k := skipJSSpace(code, wordEnd)
+	if k >= len(code) {
+		return 0, false
+	}
+
+	if code[start] == 'i' {
+		end := jsModuleSpecifierEnd(code, k)
+		if end < 0 {
+			return 0, false
+		}
+		end = jsModuleClauseEnd(code, end)
+		blank(start, end)
+		return end, true
+	}
+
+	switch {
+	case code[k] == '{':
+		end := strings.IndexByte(code[k:], '}')
+		if end < 0 {
+			return 0, false
+		}
+		end += k + 1
+		if j := skipJSSpace(code, end); jsWordAt(code, j) == "from" {
+			if end = jsModuleSpecifierEnd(code, j+len("from")); end < 0 {
+				return 0, false
+			}
+		}
+		end = jsModuleClauseEnd(code, end)
+		blank(start, end)
+		return end, true
+	case code[k] == '*':
+		end := jsModuleSpecifierEnd(code, k)
+		if end < 0 {
+			return 0, false
+		}
+		end = jsModuleClauseEnd(code, end)
+		blank(start, end)
+		return end, true
+	case jsWordAt(code, k) == "default":
+		end := k + len("default")
+		if strings.ContainsAny(code[start:end], "\r\n") {
+			return 0, false
+		}
+		blank(start, end)
+		copy((*out)[start:], jsModuleDefaultBinding)
+		return end, true
+	default:
+		blank(start, wordEnd)
+		return wordEnd, true
+	}
+}
+
+// jsModuleSpecifierEnd 从 i 开始查找模块说明符字符串，返回其后的位置（先遇到分号时返回-1）
+func jsModuleSpecifierEnd(code string, i int) int {
+	for i < len(code) {
+		switch c := code[i]; {
+		case c == '\'' || c == '"':
+			return skipJSString(code, i)
+		case c == ';':
+			return -1
+		case c == '/' && i+1 < len(code) && (code[i+1] == '/' || code[i+1] == '*'):
+			i = skipJSComment(code, i)
+		default:
+			i++
+		}
+	}
+	return -1
+}
+
+// jsModuleClauseEnd 跳过说明符之后的导入属性（with/assert {...}）和结尾分号
+func jsModuleClauseEnd(code string, end int) int {
+	j := skipJSSpace(code, end)
+	if w := jsWordAt(code, j); w == "with" || w == "assert" {
+		if k := skipJSSpace(code, j+len(w)); k < len(code) && code[k] == '{' {
+			end = skipJSBraces(code, k)
+			j = skipJSSpace(code, end)
+		}
+	}
+	if j < len(code) && code[j] == ';' {
+		end = j + 1
+	}
+	return end
+}
+
+// jsRegexAllowed 根据上一个记号判断 "/" 是否开始正则字面量（否则为除号）
+func jsRegexAllowed(prev byte, prevWord string) bool {
+	if prevWord != "" {
+		switch prevWord {
+		case "return", "typeof", "case", "do", "else", "in", "of", "new", "delete", "void", "throw", "instanceof", "yield", "await":
+			return true
+		}
+		return false
+	}
+	return prev == 0 || strings.IndexByte("(,=:[!&|?{};+-*%<>~^", prev) >= 0
+}
+
+// isJSIdentByte 标识符字符（非ASCII字节一律视为标识符的一部分）
+func isJSIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// jsWordAt 返回 i 处的标识符（不是标识符时返回空串）
+func jsWordAt(code string, i int) string {
+	end := i
+	for end < len(code) && isJSIdentByte(code[end]) {
+		end++
+	}
+	return code[i:end]
+}
+
+// skipJSSpace 跳过空白和注释
+func skipJSSpace(code string, i int) int {
+	for i < len(code) {
+		switch c := code[i]; {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case c == '/' && i+1 < len(code) && (code[i+1] == '/' || code[i+1] == '*'):
+			i = skipJSComment(code, i)
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// skipJSComment 跳过 i 处的注释（行注释停在换行符上）
+func skipJSComment(code string, i int) int {
+	if code[i+1] == '/' {
+		if end := strings.IndexByte(code[i:], '\n'); end >= 0 {
+			return i + end
+		}
+		return len(code)
+	}
+	if end := strings.Index(code[i+2:], "*/"); end >= 0 {
+		return i + 2 + end + 2
+	}
+	return len(code)
+}
+
+// skipJSString 跳过 i 处的单/双引号字符串
+func skipJSString(code string, i int) int {
+	quote := code[i]
+	for j := i + 1; j < len(code); j++ {
+		switch code[j] {
+		case '\\':
+			j++
+		case quote:
+			return j + 1
+		case '\n':
+			return j
+		}
+	}
+	return len(code)
+}
+
+// skipJSTemplate 跳过 i 处的模板字符串（包括 ${...} 中嵌套的代码）
+func skipJSTemplate(code string, i int) int {
+	for j := i + 1; j < len(code); j++ {
+		switch code[j] {
+		case '\\':
+			j++
+		case '`':
+			return j + 1
+		case '$':
+			if j+1 < len(code) && code[j+1] == '{' {
+				j = skipJSBraces(code, j+1) - 1
+			}
+		}
+	}
+	return len(code)
+}
+
+// skipJSBraces 跳过 i 处以 { 开始的平衡括号块
+func skipJSBraces(code string, i int) int {
+	depth := 0
+	for j := i; j < len(code); {
+		switch c := code[j]; {
+		case c == '\'' || c == '"':
+			j = skipJSString(code, j)
+			continue
+		case c == '`':
+			j = skipJSTemplate(code, j)
+			continue
+		case c == '/' && j+1 < len(code) && (code[j+1] == '/' || code[j+1] == '*'):
+			j = skipJSComment(code, j)
+			continue
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return j + 1
+			}
+		}
+		j++
+	}
+	return len(code)
+}
+
+// skipJSRegex 跳过 i 处的正则字面量（包括标志）
+func skipJSRegex(code string, i int) int {
+	inClass := false
+	for j := i + 1; j < len(code); j++ {
+		switch code[j] {
+		case '\\':
+			j++
+		case '\n':
+			return j
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '/':
+			if !inClass {
+				j++
+				for j < len(code) && isJSIdentByte(code[j]) {
+					j++
+				}
+				return j
+			}
+		}
+	}
+	return len(code)
+}
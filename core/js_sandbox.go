@@ -0,0 +1,366 @@
+package core
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"runtime/debug"
+	"runtime/metrics"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+// jsHeapMetric 堆上存活对象的字节数（读取无需STW）
+const jsHeapMetric = "/memory/classes/heap/objects:bytes"
+
+// JSSandboxWorkerArg 以该参数启动的进程作为沙箱工作进程运行
+// 使用JSSandbox的程序须在main开头检查该参数并调用RunJSSandboxWorker（见cmd/spider/main.go）
+const JSSandboxWorkerArg = "__js-sandbox-worker"
+
+// jsSandboxKillGrace 工作进程超过时限后的强制结束宽限期
+const jsSandboxKillGrace = 2 * time.Second
+
+// JSSandbox 受限的JavaScript执行沙箱（v4.9新增）
+// 基于goja纯Go解释器，不暴露任何文件、网络或进程接口；
+// 执行在独立的工作进程中进行，一个工作进程同一时间只运行一个虚拟机，堆统计只属于该虚拟机；
+// 超时或内存超限时中断，工作进程失控时由父进程强制结束，Unix上工作进程还受RLIMIT_DATA硬性限制。
+// 工作进程执行完毕后放回空闲池复用，超时或异常退出的进程直接丢弃；
+// 可并发调用，同时执行的请求数受slots限制
+type JSSandbox struct {
+	timeout      time.Duration
+	memoryLimit  uint64 // 单个虚拟机允许的堆增长（字节）
+	maxCallStack int
+
+	slots  chan struct{}          // 同时执行的请求数
+	idle   chan *jsSandboxProcess // 空闲的工作进程
+	mutex  sync.Mutex
+	closed bool
+}
+
+// jsSandboxProcess 一个工作进程（请求和结果以JSON逐行传递）
+type jsSandboxProcess struct {
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	encoder  *json.Encoder
+	decoder  *json.Decoder
+	killOnce sync.Once
+}
+
+// jsSandboxRequest 发送给工作进程的执行请求
+type jsSandboxRequest struct {
+	Setup        string        `json:"setup"`
+	Expressions  []string      `json:"expressions"`
+	Timeout      time.Duration `json:"timeout"`
+	MemoryLimit  uint64        `json:"memory_limit"`
+	MaxCallStack int           `json:"max_call_stack"`
+}
+
+// jsSandboxResponse 工作进程返回的执行结果
+type jsSandboxResponse struct {
+	Results map[string]string `json:"results"`
+	Error   string            `json:"error,omitempty"`
+}
+
+// NewJSSandbox 创建JS沙箱（timeout: 单次执行总时限；memoryLimit: 单个虚拟机堆增长上限，字节）
+func NewJSSandbox(timeout time.Duration, memoryLimit uint64) *JSSandbox {
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	if memoryLimit == 0 {
+		memoryLimit = 64 * 1024 * 1024
+	}
+	return &JSSandbox{
+		timeout:      timeout,
+		memoryLimit:  memoryLimit,
+		maxCallStack: 1024,
+		slots:        make(chan struct{}, runtime.NumCPU()),
+		idle:         make(chan *jsSandboxProcess, runtime.NumCPU()),
+	}
+}
+
+// Evaluate 先执行setup脚本，再依次对expressions求值，返回结果为字符串的表达式
+// 单个表达式抛出异常时跳过；超时或超出内存时中断并返回已得到的结果和错误
+func (sb *JSSandbox) Evaluate(setup string, expressions []string) (map[string]string, error) {
+	sb.slots <- struct{}{}
+	defer func() { <-sb.slots }()
+
+	results := make(map[string]string)
+	process, err := sb.acquire()
+	if err != nil {
+		return results, fmt.Errorf("无法启动沙箱进程: %v", err)
+	}
+	response, err := process.call(jsSandboxRequest{
+		Setup:        setup,
+		Expressions:  expressions,
+		Timeout:      sb.timeout,
+		MemoryLimit:  sb.memoryLimit,
+		MaxCallStack: sb.maxCallStack,
+	}, sb.timeout+jsSandboxKillGrace)
+	if err != nil {
+		process.kill()
+		return results, err
+	}
+	sb.release(process)
+
+	if response.Results != nil {
+		results = response.Results
+	}
+	if response.Error != "" {
+		return results, errors.New(response.Error)
+	}
+	return results, nil
+}
+
+// Close 结束所有空闲的工作进程（之后的执行仍可进行，但进程不再复用）
+func (sb *JSSandbox) Close() {
+	sb.mutex.Lock()
+	sb.closed = true
+	sb.mutex.Unlock()
+	for {
+		select {
+		case process := <-sb.idle:
+			process.kill()
+		default:
+			return
+		}
+	}
+}
+
+// acquire 取一个空闲的工作进程，没有时启动新进程
+func (sb *JSSandbox) acquire() (*jsSandboxProcess, error) {
+	select {
+	case process := <-sb.idle:
+		return process, nil
+	default:
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(executable, JSSandboxWorkerArg)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &jsSandboxProcess{
+		cmd:     cmd,
+		stdin:   stdin,
+		encoder: json.NewEncoder(stdin),
+		decoder: json.NewDecoder(stdout),
+	}, nil
+}
+
+// release 执行成功的工作进程放回空闲池（已关闭或空闲池已满时结束）
+func (sb *JSSandbox) release(process *jsSandboxProcess) {
+	sb.mutex.Lock()
+	closed := sb.closed
+	sb.mutex.Unlock()
+	if !closed {
+		select {
+		case sb.idle <- process:
+			return
+		default:
+		}
+	}
+	process.kill()
+}
+
+// call 发送请求并等待结果，超过limit时强制结束进程
+func (p *jsSandboxProcess) call(request jsSandboxRequest, limit time.Duration) (*jsSandboxResponse, error) {
+	done := make(chan error, 1)
+	var response jsSandboxResponse
+	go func() {
+		if err := p.encoder.Encode(request); err != nil {
+			done <- err
+			return
+		}
+		done <- p.decoder.Decode(&response)
+	}()
+
+	timer := time.NewTimer(limit)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		if err != nil {
+			// 超出RLIMIT_DATA时Go运行时直接终止进程
+			return nil, fmt.Errorf("沙箱进程异常退出（可能超出内存上限）: %v", err)
+		}
+		return &response, nil
+	case <-timer.C:
+		p.kill()
+		<-done
+		return nil, fmt.Errorf("沙箱进程超时被终止（%v）", request.Timeout)
+	}
+}
+
+// kill 结束工作进程并回收
+func (p *jsSandboxProcess) kill() {
+	p.killOnce.Do(func() {
+		p.stdin.Close()
+		p.cmd.Process.Kill()
+		p.cmd.Wait()
+	})
+}
+
+// RunJSSandboxWorker 工作进程入口：从stdin逐个读取请求，执行后把结果写到stdout，stdin关闭时返回0
+func RunJSSandboxWorker() int {
+	decoder := json.NewDecoder(os.Stdin)
+	encoder := json.NewEncoder(os.Stdout)
+	limited := false
+	for {
+		var request jsSandboxRequest
+		if err := decoder.Decode(&request); err != nil {
+			if err == io.EOF {
+				return 0
+			}
+			return 2
+		}
+		if !limited {
+			// 进程内同一时间只有一个虚拟机：软内存上限让GC在接近预算时更积极地回收，
+			// 硬性上限防止单次大分配在看门狗检测到之前耗尽内存
+			debug.SetMemoryLimit(int64(request.MemoryLimit) * 2)
+			if err := limitJSSandboxMemory(request.MemoryLimit); err != nil {
+				fmt.Fprintf(os.Stderr, "设置沙箱内存上限失败: %v\n", err)
+			}
+			limited = true
+		}
+
+		results, err := evaluateInVM(request)
+		response := jsSandboxResponse{Results: results}
+		if err != nil {
+			response.Error = err.Error()
+		}
+		if err := encoder.Encode(response); err != nil {
+			return 2
+		}
+		// 回收上一个虚拟机，下一个请求的堆基线从干净状态开始
+		runtime.GC()
+	}
+}
+
+// evaluateInVM 在新建的虚拟机中执行请求（只在工作进程中调用）
+func evaluateInVM(request jsSandboxRequest) (map[string]string, error) {
+	results := make(map[string]string)
+	vm := goja.New()
+	vm.SetMaxCallStackSize(request.MaxCallStack)
+	installSandboxGlobals(vm)
+
+	stop := watchJSVM(vm, request.Timeout, request.MemoryLimit)
+	defer stop()
+
+	if _, err := runJS(vm, request.Setup); err != nil {
+		return results, err
+	}
+	for _, expr := range request.Expressions {
+		value, err := runJS(vm, expr)
+		if err != nil {
+			if _, interrupted := err.(*goja.InterruptedError); interrupted {
+				return results, err
+			}
+			continue
+		}
+		if value == nil {
+			continue
+		}
+		if s, ok := value.Export().(string); ok {
+			results[expr] = s
+		}
+	}
+	return results, nil
+}
+
+// runJS 执行一段脚本，将Go层panic（如栈溢出）转换为错误
+func runJS(vm *goja.Runtime, code string) (value goja.Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("沙箱执行异常: %v", r)
+		}
+	}()
+	return vm.RunString(code)
+}
+
+// watchJSVM 启动看门狗：超时或堆增长超限时中断虚拟机，返回停止函数
+// 工作进程中只运行一个虚拟机，进程的堆增长即该虚拟机的内存占用
+func watchJSVM(vm *goja.Runtime, timeout time.Duration, memoryLimit uint64) func() {
+	done := make(chan struct{})
+	baseline := jsHeapBytes()
+	go func() {
+		deadline := time.NewTimer(timeout)
+		defer deadline.Stop()
+		ticker := time.NewTicker(5 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-deadline.C:
+				vm.Interrupt(fmt.Sprintf("执行超时（%v）", timeout))
+				return
+			case <-ticker.C:
+				if current := jsHeapBytes(); current > baseline && current-baseline > memoryLimit {
+					vm.Interrupt(fmt.Sprintf("内存超限（%d 字节）", memoryLimit))
+					return
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// jsHeapBytes 读取当前堆上存活对象字节数
+func jsHeapBytes() uint64 {
+	sample := []metrics.Sample{{Name: jsHeapMetric}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
+
+// installSandboxGlobals 补充解码函数常用的浏览器全局对象（atob/btoa/window）
+func installSandboxGlobals(vm *goja.Runtime) {
+	vm.Set("atob", func(s string) string {
+		s = strings.TrimRight(strings.Map(func(r rune) rune {
+			if r == ' ' || r == '\n' || r == '\r' || r == '\t' {
+				return -1
+			}
+			return r
+		}, s), "=")
+		data, err := base64.RawStdEncoding.DecodeString(s)
+		if err != nil {
+			panic(vm.NewTypeError("atob: 无效的Base64"))
+		}
+		// 与浏览器一致：每个字节对应一个Latin-1字符
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	})
+	vm.Set("btoa", func(s string) string {
+		data := make([]byte, 0, len(s))
+		for _, r := range s {
+			data = append(data, byte(r))
+		}
+		return base64.StdEncoding.EncodeToString(data)
+	})
+	global := vm.GlobalObject()
+	vm.Set("window", global)
+	vm.Set("self", global)
+	vm.Set("globalThis", global)
+}
@@ -0,0 +1,48 @@
+//go:build linux
+
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// jsSandboxMemoryHeadroom 硬性上限在软上限之外预留的空间（运行时和解释器自身的开销）
+const jsSandboxMemoryHeadroom = 64 * 1024 * 1024
+
+// limitJSSandboxMemory 为工作进程设置RLIMIT_DATA硬性上限（Linux 4.7起包含匿名mmap，即Go堆）
+// 上限 = 当前数据段 + 2倍虚拟机预算 + 预留空间；超出时分配失败，Go运行时终止进程，父进程按异常退出处理
+func limitJSSandboxMemory(memoryLimit uint64) error {
+	current, err := jsProcessDataBytes()
+	if err != nil {
+		return err
+	}
+	limit := current + 2*memoryLimit + jsSandboxMemoryHeadroom
+	return syscall.Setrlimit(syscall.RLIMIT_DATA, &syscall.Rlimit{Cur: limit, Max: limit})
+}
+
+// jsProcessDataBytes 读取进程当前的数据段大小（/proc/self/status 的 VmData）
+func jsProcessDataBytes() (uint64, error) {
+	file, err := os.Open("/proc/self/status")
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "VmData:" {
+			kb, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return 0, err
+			}
+			return kb * 1024, nil
+		}
+	}
+	return 0, fmt.Errorf("/proc/self/status 中没有VmData")
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package core
+
+// limitJSSandboxMemory 非Linux平台不设置硬性上限：
+// macOS等系统的RLIMIT_DATA不约束mmap分配，Windows没有对应的进程级限制。
+// 这些平台上只靠软内存上限（GC目标）和看门狗中断虚拟机，单次大分配可能在中断前超出预算
+func limitJSSandboxMemory(memoryLimit uint64) error {
+	return nil
+}
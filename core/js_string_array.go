@@ -0,0 +1,468 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dop251/goja/ast"
+	"github.com/dop251/goja/parser"
+)
+
+// 字符串数组还原的限制
+const (
+	minStringArrayLength = 3     // 字符串数组至少包含的元素数
+	maxSandboxCalls      = 20000 // 单个脚本最多求值的解码调用数
+)
+
+// stringArrayPlan 从语法树中识别出的字符串数组、解码函数和旋转代码
+type stringArrayPlan struct {
+	arrays   map[string]bool // 字符串数组（变量或返回数组的函数）
+	decoders map[string]bool // 解码函数及包装函数
+	aliases  map[string]string
+	setup    []string // 需要在沙箱中执行的顶层语句源码（按原顺序）
+}
+
+// stringArrayCall 一处可在沙箱中求值的解码调用
+type stringArrayCall struct {
+	start, end int
+	expr       string
+}
+
+// DecodeWithSandbox 在沙箱中还原Packer打包和字符串数组解码调用（🆕 v4.9）
+// 只执行数组定义、旋转IIFE和解码函数本身，不执行业务代码；
+// 解码调用被替换为字符串字面量，jd.code 仍是合法的JS，返回还原的字符串数量
+func (jd *JSDeobfuscator) DecodeWithSandbox(sandbox *JSSandbox) (int, error) {
+	total := 0
+	unpacked, err := jd.unpackPacker(sandbox)
+	total += unpacked
+	if err != nil {
+		return total, err
+	}
+	decoded, err := jd.decodeStringArrayCalls(sandbox)
+	total += decoded
+	return total, err
+}
+
+// unpackPacker 还原 eval(function(p,a,c,k,e,d){...}(...)) 形式的Packer打包代码
+func (jd *JSDeobfuscator) unpackPacker(sandbox *JSSandbox) (int, error) {
+	program, err := parser.ParseFile(nil, "", jd.code, parser.IgnoreRegExpErrors, parser.WithDisableSourceMaps)
+	if err != nil {
+		return 0, nil
+	}
+	base := program.File.Base()
+
+	calls := make([]stringArrayCall, 0)
+	for _, stmt := range program.Body {
+		walkJSAST(stmt, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpression)
+			if !ok || jsMemberPath(call.Callee) != "eval" || len(call.ArgumentList) != 1 {
+				return true
+			}
+			inner, ok := call.ArgumentList[0].(*ast.CallExpression)
+			if !ok {
+				return true
+			}
+			if _, ok := inner.Callee.(*ast.FunctionLiteral); !ok {
+				return true
+			}
+			calls = append(calls, stringArrayCall{
+				start: int(call.Idx0()) - base,
+				end:   int(call.Idx1()) - base,
+				expr:  "(" + jd.code[int(inner.Idx0())-base:int(inner.Idx1())-base] + ")",
+			})
+			return false
+		})
+	}
+	if len(calls) == 0 {
+		return 0, nil
+	}
+
+	expressions := make([]string, len(calls))
+	for i, call := range calls {
+		expressions[i] = call.expr
+	}
+	values, err := sandbox.Evaluate("", expressions)
+
+	unpacked := 0
+	for i := len(calls) - 1; i >= 0; i-- {
+		code, ok := values[calls[i].expr]
+		if !ok {
+			continue
+		}
+		jd.code = jd.code[:calls[i].start] + code + jd.code[calls[i].end:]
+		unpacked++
+	}
+	jd.statistics["unpacked_scripts"] += unpacked
+	return unpacked, err
+}
+
+// decodeStringArrayCalls 识别字符串数组（obfuscator.io等），在沙箱中执行解码并替换调用
+func (jd *JSDeobfuscator) decodeStringArrayCalls(sandbox *JSSandbox) (int, error) {
+	program, err := parser.ParseFile(nil, "", jd.code, parser.IgnoreRegExpErrors, parser.WithDisableSourceMaps)
+	if err != nil {
+		return 0, nil
+	}
+
+	plan := jd.planStringArrays(program)
+	if len(plan.decoders) == 0 {
+		return 0, nil
+	}
+	calls := jd.findDecoderCalls(program, plan)
+	if len(calls) == 0 {
+		return 0, nil
+	}
+
+	expressions := make([]string, 0, len(calls))
+	seen := make(map[string]bool)
+	for _, call := range calls {
+		if !seen[call.expr] {
+			seen[call.expr] = true
+			expressions = append(expressions, call.expr)
+		}
+	}
+	values, err := sandbox.Evaluate(strings.Join(plan.setup, "\n"), expressions)
+
+	// 从后往前替换，保持前面调用的偏移量有效
+	sort.Slice(calls, func(a, b int) bool { return calls[a].start > calls[b].start })
+	decoded := 0
+	lastStart := len(jd.code) + 1
+	for _, call := range calls {
+		value, ok := values[call.expr]
+		if !ok || call.end > lastStart {
+			continue
+		}
+		original := jd.code[call.start:call.end]
+		jd.code = jd.code[:call.start] + jsQuote(value) + jd.code[call.end:]
+		jd.decodedStrings[original] = value
+		lastStart = call.start
+		decoded++
+	}
+	jd.statistics["decoded_strings"] += decoded
+	jd.statistics["sandbox_decoders"] = len(plan.decoders)
+	return decoded, err
+}
+
+// planStringArrays 在顶层语句中识别字符串数组、解码函数、包装函数和数组旋转代码
+func (jd *JSDeobfuscator) planStringArrays(program *ast.Program) *stringArrayPlan {
+	plan := &stringArrayPlan{
+		arrays:   make(map[string]bool),
+		decoders: make(map[string]bool),
+		aliases:  make(map[string]string),
+	}
+
+	// 1. 字符串数组：var a = ['..', ...] 或 function a(){ var b = ['..', ...]; ... }
+	for _, stmt := range program.Body {
+		switch s := stmt.(type) {
+		case *ast.FunctionDeclaration:
+			if s.Function.Name != nil && containsStringArray(s.Function.Body) {
+				plan.arrays[s.Function.Name.Name.String()] = true
+			}
+		case *ast.VariableStatement, *ast.LexicalDeclaration:
+			for _, binding := range statementBindings(s) {
+				if name := bindingName(binding); name != "" && isStringArrayLiteral(binding.Initializer) {
+					plan.arrays[name] = true
+				}
+			}
+		}
+	}
+	if len(plan.arrays) == 0 {
+		return plan
+	}
+
+	// 2. 解码函数：引用字符串数组的顶层函数；包装函数：只返回解码调用的顶层函数
+	for changed := true; changed; {
+		changed = false
+		for _, stmt := range program.Body {
+			for name, fn := range topLevelFunctions(stmt) {
+				if plan.arrays[name] || plan.decoders[name] {
+					continue
+				}
+				if referencesAny(fn.Body, plan.arrays) || isDecoderWrapper(fn, plan.decoders) {
+					plan.decoders[name] = true
+					changed = true
+				}
+			}
+		}
+	}
+	if len(plan.decoders) == 0 {
+		return plan
+	}
+
+	// 3. 按原顺序收集需要执行的语句：数组、解码函数和引用数组的顶层表达式（旋转IIFE）
+	for _, stmt := range program.Body {
+		include := false
+		switch s := stmt.(type) {
+		case *ast.ExpressionStatement:
+			include = referencesAny(s, plan.arrays)
+		default:
+			for name := range topLevelFunctions(stmt) {
+				include = include || plan.decoders[name]
+			}
+			if fd, ok := stmt.(*ast.FunctionDeclaration); ok && fd.Function.Name != nil {
+				include = include || plan.arrays[fd.Function.Name.Name.String()]
+			}
+			for _, binding := range statementBindings(stmt) {
+				include = include || plan.arrays[bindingName(binding)]
+			}
+		}
+		if include {
+			if src := jd.statementSource(program, stmt); src != "" {
+				plan.setup = append(plan.setup, src)
+			}
+		}
+	}
+	return plan
+}
+
+// findDecoderCalls 查找参数全为常量的解码调用（含 const x = decoder 形式的别名）
+func (jd *JSDeobfuscator) findDecoderCalls(program *ast.Program, plan *stringArrayPlan) []stringArrayCall {
+	// 别名可能层层传递，迭代到不再变化
+	for round := 0; round < 5; round++ {
+		added := false
+		for _, stmt := range program.Body {
+			walkJSAST(stmt, func(node ast.Node) bool {
+				switch n := node.(type) {
+				case *ast.VariableStatement, *ast.LexicalDeclaration:
+					for _, binding := range statementBindings(n.(ast.Statement)) {
+						added = plan.addAlias(bindingName(binding), binding.Initializer) || added
+					}
+				case *ast.AssignExpression:
+					if id, ok := n.Left.(*ast.Identifier); ok {
+						added = plan.addAlias(id.Name.String(), n.Right) || added
+					}
+				}
+				return true
+			})
+		}
+		if !added {
+			break
+		}
+	}
+
+	base := program.File.Base()
+	calls := make([]stringArrayCall, 0)
+	for _, stmt := range program.Body {
+		walkJSAST(stmt, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpression)
+			if !ok || len(calls) >= maxSandboxCalls {
+				return true
+			}
+			callee, ok := call.Callee.(*ast.Identifier)
+			if !ok || len(call.ArgumentList) == 0 {
+				return true
+			}
+			root := plan.resolve(callee.Name.String())
+			if root == "" {
+				return true
+			}
+			for _, arg := range call.ArgumentList {
+				if !isConstantExpression(arg) {
+					return true
+				}
+			}
+			args := jd.code[int(call.LeftParenthesis)-base+1 : int(call.RightParenthesis)-base]
+			calls = append(calls, stringArrayCall{
+				start: int(call.Idx0()) - base,
+				end:   int(call.Idx1()) - base,
+				expr:  root + "(" + args + ")",
+			})
+			return false
+		})
+	}
+	return calls
+}
+
+// addAlias 记录 target = 解码函数（或其别名）形式的别名，返回是否新增
+func (p *stringArrayPlan) addAlias(target string, value ast.Expression) bool {
+	id, ok := value.(*ast.Identifier)
+	if target == "" || !ok || p.decoders[target] || p.aliases[target] != "" {
+		return false
+	}
+	root := p.resolve(id.Name.String())
+	if root == "" || root == target {
+		return false
+	}
+	p.aliases[target] = root
+	return true
+}
+
+// resolve 将解码函数名或其别名解析为解码函数名（不是解码函数时返回空）
+func (p *stringArrayPlan) resolve(name string) string {
+	if p.decoders[name] {
+		return name
+	}
+	return p.aliases[name]
+}
+
+// statementSource 取顶层语句的源码；表达式语句两侧的括号不在节点范围内，
+// 依次尝试向外扩展括号，直到得到可独立解析的代码
+func (jd *JSDeobfuscator) statementSource(program *ast.Program, stmt ast.Statement) string {
+	base := program.File.Base()
+	start, end := int(stmt.Idx0())-base, int(stmt.Idx1())-base
+	if start < 0 || end > len(jd.code) || start >= end {
+		return ""
+	}
+	if _, ok := stmt.(*ast.ExpressionStatement); !ok {
+		return jd.code[start:end] + ";"
+	}
+
+	// 节点外侧紧邻的左/右括号位置
+	opens := []int{start}
+	for i := start - 1; i >= 0; i-- {
+		c := jd.code[i]
+		if c == '(' {
+			opens = append(opens, i)
+		} else if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+			break
+		}
+	}
+	closes := []int{end}
+	for i := end; i < len(jd.code); i++ {
+		c := jd.code[i]
+		if c == ')' {
+			closes = append(closes, i+1)
+		} else if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+			break
+		}
+	}
+	for _, s := range opens {
+		for _, e := range closes {
+			candidate := jd.code[s:e] + ";"
+			if _, err := parser.ParseFile(nil, "", candidate, parser.IgnoreRegExpErrors, parser.WithDisableSourceMaps); err == nil {
+				return candidate
+			}
+		}
+	}
+	return ""
+}
+
+// topLevelFunctions 顶层语句声明的函数（function f(){} / var f = function(){}）
+func topLevelFunctions(stmt ast.Statement) map[string]*ast.FunctionLiteral {
+	functions := make(map[string]*ast.FunctionLiteral)
+	if fd, ok := stmt.(*ast.FunctionDeclaration); ok {
+		if fd.Function.Name != nil {
+			functions[fd.Function.Name.Name.String()] = fd.Function
+		}
+		return functions
+	}
+	for _, binding := range statementBindings(stmt) {
+		if fn, ok := binding.Initializer.(*ast.FunctionLiteral); ok {
+			if name := bindingName(binding); name != "" {
+				functions[name] = fn
+			}
+		}
+	}
+	return functions
+}
+
+// statementBindings 变量声明语句中的绑定
+func statementBindings(stmt ast.Statement) []*ast.Binding {
+	switch s := stmt.(type) {
+	case *ast.VariableStatement:
+		return s.List
+	case *ast.LexicalDeclaration:
+		return s.List
+	}
+	return nil
+}
+
+// bindingName 简单绑定的变量名（解构绑定返回空）
+func bindingName(binding *ast.Binding) string {
+	if binding == nil {
+		return ""
+	}
+	if id, ok := binding.Target.(*ast.Identifier); ok {
+		return id.Name.String()
+	}
+	return ""
+}
+
+// isStringArrayLiteral 判断表达式是否为全部由字符串组成的数组字面量
+func isStringArrayLiteral(expr ast.Expression) bool {
+	array, ok := expr.(*ast.ArrayLiteral)
+	if !ok || len(array.Value) < minStringArrayLength {
+		return false
+	}
+	for _, element := range array.Value {
+		if _, ok := element.(*ast.StringLiteral); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// containsStringArray 判断函数体内是否定义了字符串数组
+func containsStringArray(body *ast.BlockStatement) bool {
+	found := false
+	walkJSAST(body, func(node ast.Node) bool {
+		if found {
+			return false
+		}
+		if expr, ok := node.(ast.Expression); ok && isStringArrayLiteral(expr) {
+			found = true
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// referencesAny 判断节点内是否引用了names中的标识符
+func referencesAny(node ast.Node, names map[string]bool) bool {
+	found := false
+	walkJSAST(node, func(n ast.Node) bool {
+		if found {
+			return false
+		}
+		if id, ok := n.(*ast.Identifier); ok && names[id.Name.String()] {
+			found = true
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// isDecoderWrapper 判断函数是否只是转调解码函数（obfuscator.io 的 stringArrayWrappers）
+func isDecoderWrapper(fn *ast.FunctionLiteral, decoders map[string]bool) bool {
+	if fn.Body == nil || len(fn.Body.List) != 1 {
+		return false
+	}
+	ret, ok := fn.Body.List[0].(*ast.ReturnStatement)
+	if !ok {
+		return false
+	}
+	call, ok := ret.Argument.(*ast.CallExpression)
+	if !ok {
+		return false
+	}
+	callee, ok := call.Callee.(*ast.Identifier)
+	return ok && decoders[callee.Name.String()]
+}
+
+// isConstantExpression 判断表达式是否只由字面量和运算组成
+func isConstantExpression(expr ast.Expression) bool {
+	switch e := expr.(type) {
+	case *ast.NumberLiteral, *ast.StringLiteral, *ast.BooleanLiteral:
+		return true
+	case *ast.UnaryExpression:
+		return !e.Postfix && isConstantExpression(e.Operand)
+	case *ast.BinaryExpression:
+		return isConstantExpression(e.Left) && isConstantExpression(e.Right)
+	}
+	return false
+}
+
+// jsQuote 将字符串转为JS字符串字面量
+func jsQuote(s string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(s); err != nil {
+		return fmt.Sprintf("%q", s)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,330 @@
+package core
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// 🆕 v4.9: 多样本JSON Schema推断
+// generateJSONSchema 只根据单个响应生成Schema；这里合并同一端点观察到的所有JSON样本：
+// 未在所有样本中出现的字段为可选，出现过null的字段标记nullable，取值较少的字符串推断为枚举，
+// 并识别uuid/email/date-time等字符串格式和整数ID
+
+// JSON Schema推断的默认参数
+const (
+	defaultSchemaMaxEnumValues  = 10  // 枚举最多包含的取值数
+	defaultSchemaMinEnumSamples = 5   // 至少观察到多少次取值才推断枚举
+	schemaMaxTrackedStrings     = 32  // 每个字段最多跟踪的不同字符串数
+	schemaMaxArrayItems         = 100 // 每个数组最多合并的元素数
+	schemaMaxEnumValueLength    = 64  // 枚举取值的最大长度
+)
+
+// JSONSchemaInferrer 合并多个JSON样本推断Schema（非并发安全，由调用方加锁）
+type JSONSchemaInferrer struct {
+	root           *jsonSchemaNode
+	samples        int
+	maxEnumValues  int
+	minEnumSamples int
+}
+
+// jsonSchemaNode Schema树中的一个位置，同时累积该位置出现过的所有类型
+type jsonSchemaNode struct {
+	count     int            // 该位置出现的次数（含null）
+	nulls     int            // null出现次数
+	kinds     map[string]int // object/array/string/integer/number/boolean → 次数
+	kindOrder []string
+
+	// object
+	objects       int
+	properties    map[string]*jsonSchemaNode
+	propertyOrder []string
+
+	// array
+	items *jsonSchemaNode
+
+	// string
+	strings        map[string]int // 取值 → 次数（最多跟踪schemaMaxTrackedStrings个）
+	stringOverflow bool           // 不同取值超过跟踪上限
+	stringCount    int
+	format         string // 所有取值一致的格式
+	formatSeen     bool   // 已出现过非空取值（格式以第一个非空取值为准）
+	formatMixed    bool
+	allDigits      bool // 所有字符串取值都是数字（字符串形式的ID）
+	example        interface{}
+
+	// number
+	minNumber float64
+	maxNumber float64
+}
+
+// NewJSONSchemaInferrer 创建Schema推断器
+func NewJSONSchemaInferrer() *JSONSchemaInferrer {
+	return &JSONSchemaInferrer{
+		maxEnumValues:  defaultSchemaMaxEnumValues,
+		minEnumSamples: defaultSchemaMinEnumSamples,
+	}
+}
+
+// SetEnumLimits 设置枚举推断条件：最多maxValues个不同取值，且至少观察到minSamples次
+func (si *JSONSchemaInferrer) SetEnumLimits(maxValues, minSamples int) {
+	si.maxEnumValues = maxValues
+	si.minEnumSamples = minSamples
+}
+
+// AddSample 合并一个已解析的JSON样本（json.Unmarshal到interface{}的结果）
+func (si *JSONSchemaInferrer) AddSample(data interface{}) {
+	if si.root == nil {
+		si.root = newJSONSchemaNode()
+	}
+	si.root.add(data)
+	si.samples++
+}
+
+// Samples 已合并的样本数
+func (si *JSONSchemaInferrer) Samples() int {
+	return si.samples
+}
+
+// Schema 生成合并后的Schema（OpenAPI 3.0风格：nullable/enum/format/required）
+func (si *JSONSchemaInferrer) Schema() map[string]interface{} {
+	if si.root == nil {
+		return nil
+	}
+	return si.root.schema(si, "")
+}
+
+func newJSONSchemaNode() *jsonSchemaNode {
+	return &jsonSchemaNode{
+		kinds:     make(map[string]int),
+		allDigits: true,
+	}
+}
+
+// add 合并一个取值
+func (n *jsonSchemaNode) add(value interface{}) {
+	n.count++
+	switch v := value.(type) {
+	case nil:
+		n.nulls++
+	case map[string]interface{}:
+		n.addKind("object")
+		n.objects++
+		if n.properties == nil {
+			n.properties = make(map[string]*jsonSchemaNode)
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child, exists := n.properties[key]
+			if !exists {
+				child = newJSONSchemaNode()
+				n.properties[key] = child
+				n.propertyOrder = append(n.propertyOrder, key)
+			}
+			child.add(v[key])
+		}
+	case []interface{}:
+		n.addKind("array")
+		if n.items == nil {
+			n.items = newJSONSchemaNode()
+		}
+		for i, item := range v {
+			if i >= schemaMaxArrayItems {
+				break
+			}
+			n.items.add(item)
+		}
+	case string:
+		n.addKind("string")
+		n.addString(v)
+	case float64:
+		kind := "number"
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			kind = "integer"
+		}
+		n.addKind(kind)
+		if n.kinds["integer"]+n.kinds["number"] == 1 {
+			n.minNumber, n.maxNumber = v, v
+		} else {
+			n.minNumber = math.Min(n.minNumber, v)
+			n.maxNumber = math.Max(n.maxNumber, v)
+		}
+		if n.example == nil {
+			n.example = v
+		}
+	case bool:
+		n.addKind("boolean")
+	}
+}
+
+func (n *jsonSchemaNode) addKind(kind string) {
+	if n.kinds[kind] == 0 {
+		n.kindOrder = append(n.kindOrder, kind)
+	}
+	n.kinds[kind]++
+}
+
+// addString 记录字符串取值（用于格式和枚举推断）
+func (n *jsonSchemaNode) addString(value string) {
+	n.stringCount++
+	if n.example == nil && value != "" {
+		n.example = value
+	}
+	if n.strings == nil {
+		n.strings = make(map[string]int)
+	}
+	if _, tracked := n.strings[value]; tracked || len(n.strings) < schemaMaxTrackedStrings {
+		n.strings[value]++
+	} else {
+		n.stringOverflow = true
+	}
+
+	if value == "" {
+		return // 空字符串不参与格式判断
+	}
+	if strings.Trim(value, "0123456789") != "" {
+		n.allDigits = false
+	}
+	format := apiStringFormat(value)
+	switch {
+	case n.formatMixed:
+	case !n.formatSeen:
+		n.format, n.formatSeen = format, true
+	case n.format != format:
+		n.format, n.formatMixed = "", true
+	}
+}
+
+// schema 生成该位置的Schema；name为字段名（用于识别ID字段）
+func (n *jsonSchemaNode) schema(si *JSONSchemaInferrer, name string) map[string]interface{} {
+	kinds := make([]string, 0, len(n.kindOrder))
+	for _, kind := range n.kindOrder {
+		// 整数和小数混合时合并为number
+		if kind == "integer" && n.kinds["number"] > 0 {
+			continue
+		}
+		kinds = append(kinds, kind)
+	}
+
+	var schema map[string]interface{}
+	switch len(kinds) {
+	case 0:
+		// 只出现过null
+		schema = map[string]interface{}{}
+	case 1:
+		schema = n.kindSchema(si, kinds[0], name)
+	default:
+		variants := make([]interface{}, 0, len(kinds))
+		for _, kind := range kinds {
+			variants = append(variants, n.kindSchema(si, kind, name))
+		}
+		schema = map[string]interface{}{"oneOf": variants}
+	}
+	if n.nulls > 0 {
+		schema["nullable"] = true
+	}
+	return schema
+}
+
+// kindSchema 生成指定类型的Schema
+func (n *jsonSchemaNode) kindSchema(si *JSONSchemaInferrer, kind, name string) map[string]interface{} {
+	schema := map[string]interface{}{"type": kind}
+	switch kind {
+	case "object":
+		properties := make(map[string]interface{}, len(n.properties))
+		required := make([]string, 0)
+		for _, key := range n.propertyOrder {
+			child := n.properties[key]
+			properties[key] = child.schema(si, key)
+			// 在所有对象样本中都出现的字段为必需字段
+			if child.count == n.objects {
+				required = append(required, key)
+			}
+		}
+		schema["properties"] = properties
+		if len(required) > 0 {
+			sort.Strings(required)
+			schema["required"] = required
+		}
+	case "array":
+		if n.items != nil && n.items.count > 0 {
+			schema["items"] = n.items.schema(si, singularFieldName(name))
+		} else {
+			schema["items"] = map[string]interface{}{}
+		}
+	case "string":
+		n.stringSchema(si, schema, name)
+	case "integer":
+		if isIDFieldName(name) || n.minNumber < math.MinInt32 || n.maxNumber > math.MaxInt32 {
+			schema["format"] = "int64"
+		} else {
+			schema["format"] = "int32"
+		}
+		if example, ok := n.example.(float64); ok {
+			schema["example"] = int64(example)
+		}
+	case "number":
+		if example, ok := n.example.(float64); ok {
+			schema["example"] = example
+		}
+	}
+	return schema
+}
+
+// stringSchema 字符串的格式、枚举和示例
+func (n *jsonSchemaNode) stringSchema(si *JSONSchemaInferrer, schema map[string]interface{}, name string) {
+	if example, ok := n.example.(string); ok {
+		schema["example"] = example
+	}
+	if n.format != "" && !n.formatMixed {
+		schema["format"] = n.format
+		return
+	}
+	if n.allDigits && len(n.strings) > 0 && isIDFieldName(name) {
+		// 字符串形式的数字ID（如 "12345"）
+		schema["pattern"] = "^[0-9]+$"
+		return
+	}
+
+	// 取值较少且重复出现的字符串视为枚举（如 status、type、role）
+	if n.stringOverflow || si.maxEnumValues <= 0 || n.stringCount < si.minEnumSamples {
+		return
+	}
+	values := make([]string, 0, len(n.strings))
+	for value := range n.strings {
+		if value == "" || len(value) > schemaMaxEnumValueLength {
+			return
+		}
+		values = append(values, value)
+	}
+	if len(values) == 0 || len(values) > si.maxEnumValues || len(values)*2 > n.stringCount {
+		return
+	}
+	sort.Strings(values)
+	enum := make([]interface{}, len(values))
+	for i, value := range values {
+		enum[i] = value
+	}
+	schema["enum"] = enum
+}
+
+// isIDFieldName 判断字段名是否表示ID（id、userId、user_id、ID）
+func isIDFieldName(name string) bool {
+	if name == "" {
+		return false
+	}
+	lower := strings.ToLower(name)
+	return lower == "id" || strings.HasSuffix(lower, "_id") || strings.HasSuffix(name, "Id") || strings.HasSuffix(name, "ID")
+}
+
+// singularFieldName 数组字段名对应的元素名（ids → id，用于识别ID数组）
+func singularFieldName(name string) string {
+	if strings.HasSuffix(name, "s") && len(name) > 1 {
+		return name[:len(name)-1]
+	}
+	return name
+}
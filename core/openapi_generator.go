@@ -6,6 +6,8 @@ import (
 	"io/ioutil"
 	"strings"
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 // OpenAPIGenerator OpenAPI/Swagger文档生成器
@@ -57,6 +59,11 @@ func NewOpenAPIGenerator(analyzer *APIAnalyzer) *OpenAPIGenerator {
 	}
 }
 
+// GetEndpoints 获取将写入文档的端点
+func (og *OpenAPIGenerator) GetEndpoints() []*APIEndpoint {
+	return og.analyzer.GetAllEndpoints()
+}
+
 // Generate 生成OpenAPI文档
 func (og *OpenAPIGenerator) Generate() (*OpenAPISpec, error) {
 	endpoints := og.analyzer.GetAllEndpoints()
@@ -64,6 +71,11 @@ func (og *OpenAPIGenerator) Generate() (*OpenAPISpec, error) {
 		return nil, fmt.Errorf("没有可用的API端点")
 	}
 	
+	serverURL := fmt.Sprintf("https://%s", og.analyzer.targetDomain)
+	if og.analyzer.serverURL != "" {
+		serverURL = og.analyzer.serverURL
+	}
+	
 	spec := &OpenAPISpec{
 		OpenAPI: "3.0.0",
 		Info: OpenAPIInfo{
@@ -73,7 +85,7 @@ func (og *OpenAPIGenerator) Generate() (*OpenAPISpec, error) {
 		},
 		Servers: []OpenAPIServer{
 			{
-				URL:         fmt.Sprintf("https://%s", og.analyzer.targetDomain),
+				URL:         serverURL,
 				Description: "Production server",
 			},
 		},
@@ -118,11 +130,21 @@ func (og *OpenAPIGenerator) Generate() (*OpenAPISpec, error) {
 			path = "/"
 		}
 		
-		spec.Paths[path] = pathItem
+		// 🆕 v4.9: 同一路径的不同方法（被动观察按方法分别记录）合并到同一PathItem
+		if existing, ok := spec.Paths[path].(map[string]interface{}); ok {
+			for method, operation := range pathItem {
+				existing[method] = operation
+			}
+		} else {
+			spec.Paths[path] = pathItem
+		}
 		
 		// 添加响应Schema到components
 		if endpoint.ResponseSchema != nil {
 			schemaName := og.generateSchemaName(path)
+			if _, exists := spec.Components.Schemas[schemaName]; exists && len(endpoint.Methods) > 0 {
+				schemaName = strings.Title(strings.ToLower(endpoint.Methods[0])) + schemaName
+			}
 			spec.Components.Schemas[schemaName] = endpoint.ResponseSchema
 		}
 	}
@@ -163,10 +185,14 @@ func (og *OpenAPIGenerator) createOperation(endpoint *APIEndpoint, method string
 	// 如果有请求体（POST/PUT/PATCH）
 	if method == "POST" || method == "PUT" || method == "PATCH" {
 		if endpoint.RequestBody != nil {
+			contentType := "application/json"
+			if endpoint.RequestContentType != "" {
+				contentType = endpoint.RequestContentType
+			}
 			operation["requestBody"] = map[string]interface{}{
 				"required": true,
 				"content": map[string]interface{}{
-					"application/json": map[string]interface{}{
+					contentType: map[string]interface{}{
 						"schema": endpoint.RequestBody,
 					},
 				},
@@ -174,6 +200,11 @@ func (og *OpenAPIGenerator) createOperation(endpoint *APIEndpoint, method string
 		}
 	}
 	
+	// 🆕 v4.9: SOAP/gRPC-Web服务的操作（同一URL上的多个操作和消息名）
+	if len(endpoint.Operations) > 0 {
+		og.addServiceOperations(operation, endpoint, method)
+	}
+	
 	// 添加标签（从URL提取）
 	tags := og.extractTags(endpoint.URL)
 	if len(tags) > 0 {
@@ -183,6 +214,54 @@ func (og *OpenAPIGenerator) createOperation(endpoint *APIEndpoint, method string
 	return operation
 }
 
+// addServiceOperations 将SOAP/gRPC-Web操作写入扩展字段 x-api-type / x-operations，
+// 并为没有JSON Schema的请求体生成原始类型的requestBody（SOAP附带信封示例）
+func (og *OpenAPIGenerator) addServiceOperations(operation map[string]interface{}, endpoint *APIEndpoint, method string) {
+	operation["x-api-type"] = endpoint.APIType
+	names := make([]string, 0, len(endpoint.Operations))
+	operations := make([]map[string]interface{}, 0, len(endpoint.Operations))
+	for _, op := range endpoint.Operations {
+		item := map[string]interface{}{"name": op.Name}
+		if op.Action != "" {
+			item["action"] = op.Action
+		}
+		if op.SOAPVersion != "" {
+			item["soap_version"] = op.SOAPVersion
+		}
+		if op.InputMessage != "" {
+			item["input"] = op.InputMessage
+		}
+		if op.OutputMessage != "" {
+			item["output"] = op.OutputMessage
+		}
+		operations = append(operations, item)
+		names = append(names, op.Name)
+	}
+	operation["x-operations"] = operations
+	operation["summary"] = fmt.Sprintf("%s %s (%s: %s)", method, endpoint.URL, endpoint.APIType, strings.Join(names, ", "))
+
+	if _, exists := operation["requestBody"]; exists || method != "POST" {
+		return
+	}
+	contentType := endpoint.RequestContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	media := map[string]interface{}{
+		"schema": map[string]interface{}{"type": "string"},
+	}
+	if endpoint.APIType == APIServiceGRPCWeb {
+		media["schema"] = map[string]interface{}{"type": "string", "format": "binary"}
+	}
+	if example := endpoint.Operations[0].Example; example != "" {
+		media["example"] = example
+	}
+	operation["requestBody"] = map[string]interface{}{
+		"required": true,
+		"content":  map[string]interface{}{contentType: media},
+	}
+}
+
 // convertParameters 转换参数
 func (og *OpenAPIGenerator) convertParameters(params []APIParameter) []map[string]interface{} {
 	result := make([]map[string]interface{}, 0)
@@ -270,14 +349,17 @@ func (og *OpenAPIGenerator) createResponses(endpoint *APIEndpoint) map[string]in
 	
 	// 错误响应
 	for _, errResp := range endpoint.ErrorResponses {
-		responses[fmt.Sprintf("%d", errResp.StatusCode)] = map[string]interface{}{
+		response := map[string]interface{}{
 			"description": errResp.Message,
-			"content": map[string]interface{}{
+		}
+		if errResp.Example != "" {
+			response["content"] = map[string]interface{}{
 				"application/json": map[string]interface{}{
 					"example": errResp.Example,
 				},
-			},
+			}
 		}
+		responses[fmt.Sprintf("%d", errResp.StatusCode)] = response
 	}
 	
 	// 默认响应（如果没有任何响应）
@@ -312,6 +394,8 @@ func (og *OpenAPIGenerator) generateOperationID(url, method string) string {
 	operationID = strings.ReplaceAll(operationID, "-", "")
 	operationID = strings.ReplaceAll(operationID, "_", "")
 	operationID = strings.ReplaceAll(operationID, ".", "")
+	operationID = strings.ReplaceAll(operationID, "{", "")
+	operationID = strings.ReplaceAll(operationID, "}", "")
 	
 	return operationID
 }
@@ -327,8 +411,8 @@ func (og *OpenAPIGenerator) extractTags(url string) []string {
 			// 移除query参数
 			part = strings.Split(part, "?")[0]
 			
-			// 过滤掉数字ID和常见模式
-			if !isNumericString(part) && part != "v1" && part != "v2" && part != "api" {
+			// 过滤掉数字ID、路径参数和常见模式
+			if !isNumericString(part) && !strings.HasPrefix(part, "{") && part != "v1" && part != "v2" && part != "api" {
 				tags = append(tags, strings.Title(part))
 			}
 		}
@@ -352,7 +436,10 @@ func (og *OpenAPIGenerator) generateSchemaName(path string) string {
 	// 过滤并首字母大写
 	nameParts := make([]string, 0)
 	for _, part := range parts {
-		if part != "" && !isNumericString(part) {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			// 路径参数：/users/{id} → UsersById
+			nameParts = append(nameParts, "By"+strings.Title(strings.Trim(part, "{}")))
+		} else if part != "" && !isNumericString(part) && !strings.Contains(part, "{") {
 			nameParts = append(nameParts, strings.Title(part))
 		}
 	}
@@ -384,9 +471,39 @@ func (og *OpenAPIGenerator) ExportToFile(filename string) error {
 
 // ExportToYAML 导出为YAML格式
 func (og *OpenAPIGenerator) ExportToYAML(filename string) error {
-	// TODO: 实现YAML导出
-	// 需要引入yaml库: gopkg.in/yaml.v3
-	return fmt.Errorf("YAML导出尚未实现，请使用JSON格式")
+	spec, err := og.Generate()
+	if err != nil {
+		return err
+	}
+	
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	
+	// JSON是YAML的子集：解析为节点树可保留字段顺序，再改为块样式输出
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	clearYAMLStyle(&node)
+	
+	var out strings.Builder
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return err
+	}
+	encoder.Close()
+	return ioutil.WriteFile(filename, []byte(out.String()), 0644)
+}
+
+// clearYAMLStyle 清除节点的流样式和引号样式（需要时编码器会自动加引号）
+func clearYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearYAMLStyle(child)
+	}
 }
 
 // GenerateMarkdownDoc 生成Markdown文档
@@ -1,11 +1,20 @@
 package core
 
 import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/base64"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net/http"
+	"net/textproto"
 	"net/url"
+	"strconv"
 	"strings"
 )
 
@@ -15,9 +24,23 @@ type PassiveCrawler struct {
 	importedURLs  []string
 	importedForms []*Form
 	importedAPIs  []string
+	exchanges     []*ImportedExchange // 🆕 v4.9: 完整的请求/响应（用于分析响应内容）
+	defaultScheme string              // 🆕 v4.9: 原始请求文件的默认协议
 	statistics    *PassiveStats
 }
 
+// ImportedExchange 导入的一次请求及其响应（v4.9新增）
+type ImportedExchange struct {
+	Method          string
+	URL             string
+	RequestHeaders  http.Header
+	RequestBody     []byte
+	StatusCode      int // 0表示导入文件中没有响应
+	ResponseHeaders http.Header
+	ResponseBody    []byte // 已解压
+	Form            *Form  // 从请求体还原的表单（如果有）
+}
+
 // PassiveStats 被动爬取统计
 type PassiveStats struct {
 	ImportedRequests int
@@ -36,88 +59,32 @@ type BurpItem struct {
 	Protocol string   `xml:"protocol"`
 	Method   string   `xml:"method"`
 	Path     string   `xml:"path"`
-	Request  string   `xml:"request"`
-	Response string   `xml:"response"`
+	Request  BurpData `xml:"request"`
+	Response BurpData `xml:"response"`
 	Status   string   `xml:"status"`
 }
 
-// BurpItems Burp XML根节点
-type BurpItems struct {
-	XMLName xml.Name    `xml:"items"`
-	Items   []BurpItem  `xml:"item"`
-}
-
-// HAREntry HAR文件entry结构
-type HAREntry struct {
-	Request  HARRequest  `json:"request"`
-	Response HARResponse `json:"response"`
-}
-
-// HARRequest HAR请求
-type HARRequest struct {
-	Method      string            `json:"method"`
-	URL         string            `json:"url"`
-	HTTPVersion string            `json:"httpVersion"`
-	Headers     []HARHeader       `json:"headers"`
-	QueryString []HARQueryString  `json:"queryString"`
-	PostData    *HARPostData      `json:"postData"`
-}
-
-// HARResponse HAR响应
-type HARResponse struct {
-	Status  int         `json:"status"`
-	Headers []HARHeader `json:"headers"`
-	Content HARContent  `json:"content"`
-}
-
-// HARHeader HAR头
-type HARHeader struct {
-	Name  string `json:"name"`
-	Value string `json:"value"`
-}
-
-// HARQueryString HAR查询字符串
-type HARQueryString struct {
-	Name  string `json:"name"`
-	Value string `json:"value"`
-}
-
-// HARPostData HAR POST数据
-type HARPostData struct {
-	MimeType string     `json:"mimeType"`
-	Params   []HARParam `json:"params"`
-	Text     string     `json:"text"`
-}
-
-// HARParam HAR参数
-type HARParam struct {
-	Name  string `json:"name"`
-	Value string `json:"value"`
-}
-
-// HARContent HAR内容
-type HARContent struct {
-	Size     int    `json:"size"`
-	MimeType string `json:"mimeType"`
-	Text     string `json:"text"`
-}
-
-// HAR HAR文件根结构
-type HAR struct {
-	Log HARLog `json:"log"`
+// BurpData Burp导出的原始请求/响应（勾选"Base64-encode requests and responses"时为base64）
+type BurpData struct {
+	Base64 bool   `xml:"base64,attr"`
+	Value  string `xml:",chardata"`
 }
 
-// HARLog HAR日志
-type HARLog struct {
-	Version string     `json:"version"`
-	Creator HARCreator `json:"creator"`
-	Entries []HAREntry `json:"entries"`
+// Bytes 原始报文
+func (d BurpData) Bytes() []byte {
+	if d.Base64 {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(d.Value))
+		if err == nil {
+			return decoded
+		}
+	}
+	return []byte(d.Value)
 }
 
-// HARCreator HAR创建者
-type HARCreator struct {
-	Name    string `json:"name"`
-	Version string `json:"version"`
+// BurpItems Burp XML根节点
+type BurpItems struct {
+	XMLName xml.Name    `xml:"items"`
+	Items   []BurpItem  `xml:"item"`
 }
 
 // NewPassiveCrawler 创建被动爬取器
@@ -127,6 +94,7 @@ func NewPassiveCrawler(mode string) *PassiveCrawler {
 		importedURLs:  make([]string, 0),
 		importedForms: make([]*Form, 0),
 		importedAPIs:  make([]string, 0),
+		exchanges:     make([]*ImportedExchange, 0),
 		statistics:    &PassiveStats{},
 	}
 }
@@ -164,13 +132,33 @@ func (pc *PassiveCrawler) LoadFromBurp(filename string) error {
 		}
 		
 		// 如果是POST请求，尝试提取表单
+		var form *Form
 		if item.Method == "POST" {
-			form := pc.extractFormFromBurpRequest(item)
+			form = pc.extractFormFromBurpRequest(item)
 			if form != nil {
 				pc.importedForms = append(pc.importedForms, form)
 				pc.statistics.ExtractedForms++
 			}
 		}
+		
+		// 🆕 v4.9: 保留完整的请求和响应
+		if fullURL != "" {
+			exchange := &ImportedExchange{
+				Method: strings.ToUpper(item.Method),
+				URL:    fullURL,
+				Form:   form,
+			}
+			exchange.RequestHeaders, exchange.RequestBody = parseRawHTTPRequest(item.Request.Bytes())
+			if rawResponse := item.Response.Bytes(); len(rawResponse) > 0 {
+				exchange.StatusCode, exchange.ResponseHeaders, exchange.ResponseBody = parseRawHTTPResponse(rawResponse)
+			}
+			if exchange.StatusCode == 0 {
+				// 响应缺失或无法解析时按未响应处理
+				exchange.ResponseHeaders = nil
+				exchange.ResponseBody = nil
+			}
+			pc.exchanges = append(pc.exchanges, exchange)
+		}
 	}
 	
 	fmt.Printf("从Burp Suite导入: %d个请求, %d个URL, %d个表单, %d个API\n",
@@ -199,6 +187,9 @@ func (pc *PassiveCrawler) LoadFromHAR(filename string) error {
 	
 	// 处理每个entry
 	for _, entry := range har.Log.Entries {
+		if entry == nil {
+			continue
+		}
 		pc.statistics.ImportedRequests++
 		
 		// 提取URL
@@ -214,13 +205,19 @@ func (pc *PassiveCrawler) LoadFromHAR(filename string) error {
 		}
 		
 		// 如果是POST请求，提取表单
+		var form *Form
 		if entry.Request.Method == "POST" && entry.Request.PostData != nil {
-			form := pc.extractFormFromHARRequest(entry.Request)
+			form = pc.extractFormFromHARRequest(entry.Request)
 			if form != nil {
 				pc.importedForms = append(pc.importedForms, form)
 				pc.statistics.ExtractedForms++
 			}
 		}
+		
+		// 🆕 v4.9: 保留完整的请求和响应
+		if entry.Request.URL != "" {
+			pc.exchanges = append(pc.exchanges, harExchange(entry, form))
+		}
 	}
 	
 	fmt.Printf("从HAR文件导入: %d个请求, %d个URL, %d个表单, %d个API\n",
@@ -242,9 +239,10 @@ func (pc *PassiveCrawler) extractFormFromBurpRequest(item BurpItem) *Form {
 	}
 	
 	// 解析请求体（简化处理）
-	if strings.Contains(item.Request, "Content-Type: application/x-www-form-urlencoded") {
+	request := string(item.Request.Bytes())
+	if strings.Contains(request, "Content-Type: application/x-www-form-urlencoded") {
 		// 查找请求体
-		parts := strings.Split(item.Request, "\r\n\r\n")
+		parts := strings.Split(request, "\r\n\r\n")
 		if len(parts) > 1 {
 			body := parts[1]
 			params := strings.Split(body, "&")
@@ -334,6 +332,11 @@ func (pc *PassiveCrawler) GetImportedAPIs() []string {
 	return pc.importedAPIs
 }
 
+// GetExchanges 获取导入的完整请求/响应（v4.9新增）
+func (pc *PassiveCrawler) GetExchanges() []*ImportedExchange {
+	return pc.exchanges
+}
+
 // GetStatistics 获取统计信息
 func (pc *PassiveCrawler) GetStatistics() *PassiveStats {
 	return pc.statistics
@@ -411,3 +414,172 @@ func (pc *PassiveCrawler) GenerateImportReport() string {
 	return report.String()
 }
 
+// harExchange 把HAR条目转换为导入的请求/响应（v4.9新增）
+func harExchange(entry *HAREntry, form *Form) *ImportedExchange {
+	exchange := &ImportedExchange{
+		Method:         strings.ToUpper(entry.Request.Method),
+		URL:            entry.Request.URL,
+		RequestHeaders: harHeaderMap(entry.Request.Headers),
+		Form:           form,
+	}
+	if postData := entry.Request.PostData; postData != nil {
+		if postData.Text != "" {
+			exchange.RequestBody = []byte(postData.Text)
+		} else if len(postData.Params) > 0 {
+			values := url.Values{}
+			for _, param := range postData.Params {
+				values.Add(param.Name, param.Value)
+			}
+			exchange.RequestBody = []byte(values.Encode())
+		}
+		if exchange.RequestHeaders.Get("Content-Type") == "" && postData.MimeType != "" {
+			exchange.RequestHeaders.Set("Content-Type", postData.MimeType)
+		}
+	}
+	
+	// 状态码0表示请求失败或被浏览器取消
+	if entry.Response.Status > 0 {
+		exchange.StatusCode = entry.Response.Status
+		exchange.ResponseHeaders = harHeaderMap(entry.Response.Headers)
+		// HAR中的响应体已解压
+		exchange.ResponseHeaders.Del("Content-Encoding")
+		content := entry.Response.Content
+		if content.Encoding == "base64" {
+			if decoded, err := base64.StdEncoding.DecodeString(content.Text); err == nil {
+				exchange.ResponseBody = decoded
+			}
+		} else {
+			exchange.ResponseBody = []byte(content.Text)
+		}
+		if exchange.ResponseHeaders.Get("Content-Type") == "" && content.MimeType != "" {
+			exchange.ResponseHeaders.Set("Content-Type", content.MimeType)
+		}
+	}
+	return exchange
+}
+
+// harHeaderMap 把HAR的名称/值列表转换为http.Header（跳过HTTP/2伪头）
+func harHeaderMap(pairs []HARNameValue) http.Header {
+	header := make(http.Header, len(pairs))
+	for _, pair := range pairs {
+		if pair.Name == "" || strings.HasPrefix(pair.Name, ":") {
+			continue
+		}
+		header.Add(pair.Name, pair.Value)
+	}
+	return header
+}
+
+// parseRawHTTPRequest 解析原始HTTP请求报文，返回请求头和请求体（v4.9新增）
+func parseRawHTTPRequest(raw []byte) (http.Header, []byte) {
+	if len(raw) == 0 {
+		return make(http.Header), nil
+	}
+	if req, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(normalizeRawHTTPVersion(raw)))); err == nil {
+		body, err := io.ReadAll(req.Body)
+		if err == nil {
+			return req.Header, body
+		}
+	}
+	// 报文不完整（如Content-Length与实际长度不符）时按空行切分
+	headerBlock, body := splitRawHTTPMessage(raw)
+	return parseRawHeaderLines(headerBlock), body
+}
+
+// parseRawHTTPResponse 解析原始HTTP响应报文，返回状态码、响应头和解压后的响应体（v4.9新增）
+func parseRawHTTPResponse(raw []byte) (int, http.Header, []byte) {
+	var status int
+	var header http.Header
+	var body []byte
+	
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(normalizeRawHTTPVersion(raw))), nil)
+	if err == nil {
+		body, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+	if err == nil {
+		status, header = resp.StatusCode, resp.Header
+	} else {
+		// 报文不完整时按空行切分，状态码取自状态行
+		headerBlock, rest := splitRawHTTPMessage(raw)
+		lines := strings.SplitN(headerBlock, "\n", 2)
+		fields := strings.Fields(lines[0])
+		if len(fields) < 2 || !strings.HasPrefix(fields[0], "HTTP/") {
+			return 0, nil, nil
+		}
+		if status, err = strconv.Atoi(fields[1]); err != nil {
+			return 0, nil, nil
+		}
+		if len(lines) > 1 {
+			header = parseRawHeaderLines(lines[1])
+		} else {
+			header = make(http.Header)
+		}
+		body = rest
+	}
+	
+	return status, header, decodeContentEncoding(header, body)
+}
+
+// decodeContentEncoding 按Content-Encoding解压响应体，成功时删除该响应头（不支持的编码原样返回）
+func decodeContentEncoding(header http.Header, body []byte) []byte {
+	switch strings.ToLower(strings.TrimSpace(header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		if reader, err := gzip.NewReader(bytes.NewReader(body)); err == nil {
+			if decoded, err := io.ReadAll(reader); err == nil || len(decoded) > 0 {
+				header.Del("Content-Encoding")
+				return decoded
+			}
+		}
+	case "deflate":
+		if decoded, err := io.ReadAll(flate.NewReader(bytes.NewReader(body))); err == nil || len(decoded) > 0 {
+			header.Del("Content-Encoding")
+			return decoded
+		}
+	}
+	return body
+}
+
+// normalizeRawHTTPVersion 把Burp显示的HTTP/2报文（"HTTP/2"）改为标准库可解析的版本号
+func normalizeRawHTTPVersion(raw []byte) []byte {
+	end := bytes.IndexByte(raw, '\n')
+	if end < 0 {
+		end = len(raw)
+	}
+	firstLine := string(raw[:end])
+	line := strings.TrimRight(firstLine, "\r")
+	switch {
+	case strings.HasPrefix(line, "HTTP/2 "): // 状态行
+		line = "HTTP/1.1 " + strings.TrimPrefix(line, "HTTP/2 ")
+	case strings.HasSuffix(line, " HTTP/2"): // 请求行
+		line = strings.TrimSuffix(line, " HTTP/2") + " HTTP/1.1"
+	default:
+		return raw
+	}
+	return append([]byte(line+"\r"), raw[end:]...)
+}
+
+// splitRawHTTPMessage 在第一个空行处切分报文头和报文体
+func splitRawHTTPMessage(raw []byte) (string, []byte) {
+	if index := bytes.Index(raw, []byte("\r\n\r\n")); index >= 0 {
+		return string(raw[:index]), raw[index+4:]
+	}
+	if index := bytes.Index(raw, []byte("\n\n")); index >= 0 {
+		return string(raw[:index]), raw[index+2:]
+	}
+	return string(raw), nil
+}
+
+// parseRawHeaderLines 解析"名称: 值"形式的头部行（跳过请求行和无法解析的行）
+func parseRawHeaderLines(block string) http.Header {
+	header := make(http.Header)
+	for _, line := range strings.Split(block, "\n") {
+		line = strings.TrimRight(line, "\r")
+		colon := strings.Index(line, ":")
+		if colon <= 0 || strings.Contains(line[:colon], " ") {
+			continue
+		}
+		header.Add(textproto.TrimString(line[:colon]), textproto.TrimString(line[colon+1:]))
+	}
+	return header
+}
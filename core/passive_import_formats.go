@@ -0,0 +1,462 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 🆕 v4.9: 更多流量导入格式
+// OWASP ZAP导出的消息文本、mitmproxy的flow文件（tnetstring）、sqlmap -r使用的原始请求文件，
+// 与Burp/HAR一样转换为ImportedExchange；LoadFile按文件内容自动识别格式
+
+// 导入格式
+const (
+	ImportFormatAuto      = "auto"
+	ImportFormatBurp      = "burp"
+	ImportFormatHAR       = "har"
+	ImportFormatZAP       = "zap"
+	ImportFormatMitmproxy = "mitmproxy"
+	ImportFormatRaw       = "raw"
+)
+
+var (
+	// ZAP导出消息的分隔行，如 "==== 12 =========="
+	zapMessageSeparator = regexp.MustCompile(`(?m)^==== \d+ ==========\r?$`)
+	// 响应状态行
+	rawStatusLinePattern = regexp.MustCompile(`(?m)^HTTP/\d(?:\.\d)? \d{3}`)
+	// 请求行
+	rawRequestLinePattern = regexp.MustCompile(`(?m)^[A-Z]+ \S+ HTTP/\d(?:\.\d)?\r?$`)
+	// tnetstring开头（长度:）
+	tnetstringPrefix = regexp.MustCompile(`^\d{1,10}:`)
+)
+
+// SetDefaultScheme 设置原始请求文件的默认协议（请求中没有完整URL且端口不是443时使用）
+func (pc *PassiveCrawler) SetDefaultScheme(scheme string) {
+	pc.defaultScheme = scheme
+}
+
+// LoadFile 加载流量文件，format为空或auto时按内容识别格式，返回实际使用的格式
+func (pc *PassiveCrawler) LoadFile(filename, format string) (string, error) {
+	if format == "" || format == ImportFormatAuto {
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return "", fmt.Errorf("读取导入文件失败: %v", err)
+		}
+		format = DetectImportFormat(data)
+		if format == "" {
+			return "", fmt.Errorf("无法识别导入文件格式: %s（支持Burp XML、HAR、ZAP消息、mitmproxy flow、原始HTTP请求）", filename)
+		}
+	}
+	pc.mode = format
+
+	var err error
+	switch format {
+	case ImportFormatBurp:
+		err = pc.LoadFromBurp(filename)
+	case ImportFormatHAR:
+		err = pc.LoadFromHAR(filename)
+	case ImportFormatZAP:
+		err = pc.LoadFromZAP(filename)
+	case ImportFormatMitmproxy:
+		err = pc.LoadFromMitmproxy(filename)
+	case ImportFormatRaw:
+		err = pc.LoadFromRawRequest(filename)
+	default:
+		err = fmt.Errorf("不支持的导入格式: %s", format)
+	}
+	return format, err
+}
+
+// DetectImportFormat 根据文件内容识别导入格式（无法识别时返回空字符串）
+func DetectImportFormat(data []byte) string {
+	trimmed := bytes.TrimLeft(data, "\xef\xbb\xbf \t\r\n")
+	head := trimmed
+	if len(head) > 4096 {
+		head = head[:4096]
+	}
+
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		if bytes.Contains(head, []byte("<items")) {
+			return ImportFormatBurp
+		}
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		if bytes.Contains(head, []byte(`"log"`)) {
+			return ImportFormatHAR
+		}
+	case zapMessageSeparator.Match(firstLine(trimmed)):
+		return ImportFormatZAP
+	case tnetstringPrefix.Match(trimmed):
+		if _, _, err := parseTNetString(trimmed); err == nil {
+			return ImportFormatMitmproxy
+		}
+	case rawRequestLinePattern.Match(firstLine(trimmed)):
+		return ImportFormatRaw
+	}
+	return ""
+}
+
+// firstLine 第一行（不含换行符）
+func firstLine(data []byte) []byte {
+	if index := bytes.IndexByte(data, '\n'); index >= 0 {
+		return data[:index]
+	}
+	return data
+}
+
+// LoadFromZAP 加载OWASP ZAP导出的消息（Export Messages to File）
+// 每条消息以 "==== 编号 ==========" 开头，之后依次为请求头、请求体、响应头、响应体
+func (pc *PassiveCrawler) LoadFromZAP(filename string) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("读取ZAP文件失败: %v", err)
+	}
+
+	separators := zapMessageSeparator.FindAllIndex(data, -1)
+	for i, separator := range separators {
+		end := len(data)
+		if i+1 < len(separators) {
+			end = separators[i+1][0]
+		}
+		message := bytes.TrimLeft(data[separator[1]:end], "\r\n")
+		if exchange := parseZAPMessage(message); exchange != nil {
+			pc.addExchange(exchange)
+		}
+	}
+
+	pc.printImportSummary("ZAP")
+	return nil
+}
+
+// parseZAPMessage 解析一条ZAP消息
+func parseZAPMessage(message []byte) *ImportedExchange {
+	headerBlock, rest := splitRawHTTPMessage(message)
+	lines := strings.SplitN(headerBlock, "\n", 2)
+	fields := strings.Fields(lines[0])
+	if len(fields) < 2 {
+		return nil
+	}
+	exchange := &ImportedExchange{
+		Method: strings.ToUpper(fields[0]),
+		URL:    fields[1], // ZAP记录的请求行使用完整URL
+	}
+	if len(lines) > 1 {
+		exchange.RequestHeaders = parseRawHeaderLines(lines[1])
+	} else {
+		exchange.RequestHeaders = make(http.Header)
+	}
+
+	// 响应从下一个状态行开始，之前的内容是请求体
+	requestBody := rest
+	var response []byte
+	if loc := rawStatusLinePattern.FindIndex(rest); loc != nil {
+		requestBody, response = rest[:loc[0]], rest[loc[0]:]
+	}
+	if length, err := strconv.Atoi(exchange.RequestHeaders.Get("Content-Length")); err == nil && length >= 0 && length <= len(requestBody) {
+		requestBody = requestBody[:length]
+	} else {
+		requestBody = bytes.TrimRight(requestBody, "\r\n")
+	}
+	if len(requestBody) > 0 {
+		exchange.RequestBody = requestBody
+	}
+
+	if len(response) > 0 {
+		// 导出时每条消息末尾追加了换行
+		response = bytes.TrimSuffix(response, []byte("\r\n"))
+		exchange.StatusCode, exchange.ResponseHeaders, exchange.ResponseBody = parseRawHTTPResponse(response)
+	}
+	return exchange
+}
+
+// LoadFromMitmproxy 加载mitmproxy保存的flow文件（mitmdump -w，tnetstring序列化）
+func (pc *PassiveCrawler) LoadFromMitmproxy(filename string) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("读取mitmproxy文件失败: %v", err)
+	}
+
+	for len(bytes.TrimSpace(data)) > 0 {
+		value, rest, err := parseTNetString(data)
+		if err != nil {
+			return fmt.Errorf("解析mitmproxy flow失败: %v", err)
+		}
+		data = rest
+		flow, ok := value.(map[string]interface{})
+		if !ok || tnetText(flow["type"]) != "http" {
+			continue // 只处理HTTP flow（跳过TCP/UDP/DNS等）
+		}
+		if exchange := mitmproxyExchange(flow); exchange != nil {
+			pc.addExchange(exchange)
+		}
+	}
+
+	pc.printImportSummary("mitmproxy")
+	return nil
+}
+
+// mitmproxyExchange 把mitmproxy的HTTP flow转换为导入的请求/响应
+func mitmproxyExchange(flow map[string]interface{}) *ImportedExchange {
+	request, ok := flow["request"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	scheme := tnetText(request["scheme"])
+	if scheme == "" {
+		scheme = "http"
+	}
+	host := tnetText(request["host"])
+	if host == "" {
+		return nil
+	}
+	port, _ := request["port"].(int64)
+	if port > 0 && !(scheme == "http" && port == 80) && !(scheme == "https" && port == 443) {
+		host = net.JoinHostPort(host, strconv.FormatInt(port, 10))
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]" // IPv6
+	}
+
+	exchange := &ImportedExchange{
+		Method:         strings.ToUpper(tnetText(request["method"])),
+		URL:            scheme + "://" + host + tnetText(request["path"]),
+		RequestHeaders: mitmproxyHeaders(request["headers"]),
+		RequestBody:    tnetBytes(request["content"]),
+	}
+	// 请求体按Content-Encoding保存（很少见），同样解压
+	exchange.RequestBody = decodeContentEncoding(exchange.RequestHeaders, exchange.RequestBody)
+
+	if response, ok := flow["response"].(map[string]interface{}); ok {
+		status, _ := response["status_code"].(int64)
+		if status > 0 {
+			exchange.StatusCode = int(status)
+			exchange.ResponseHeaders = mitmproxyHeaders(response["headers"])
+			// mitmproxy保存的是未解压的原始响应体
+			exchange.ResponseBody = decodeContentEncoding(exchange.ResponseHeaders, tnetBytes(response["content"]))
+		}
+	}
+	return exchange
+}
+
+// mitmproxyHeaders 把[[名称, 值], ...]转换为http.Header
+func mitmproxyHeaders(value interface{}) http.Header {
+	header := make(http.Header)
+	pairs, _ := value.([]interface{})
+	for _, item := range pairs {
+		pair, ok := item.([]interface{})
+		if !ok || len(pair) != 2 {
+			continue
+		}
+		if name := tnetText(pair[0]); name != "" && !strings.HasPrefix(name, ":") {
+			header.Add(name, tnetText(pair[1]))
+		}
+	}
+	return header
+}
+
+// tnetText 读取tnetstring中的字节串或文本
+func tnetText(value interface{}) string {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	}
+	return ""
+}
+
+// tnetBytes 读取tnetstring中的字节串（null返回nil）
+func tnetBytes(value interface{}) []byte {
+	switch v := value.(type) {
+	case []byte:
+		return v
+	case string:
+		return []byte(v)
+	}
+	return nil
+}
+
+// parseTNetString 解析一个tnetstring值，返回值和剩余数据
+// 类型标记：, 字节串  ; 文本  # 整数  ^ 浮点数  ! 布尔  ~ null  ] 列表  } 字典
+func parseTNetString(data []byte) (interface{}, []byte, error) {
+	colon := bytes.IndexByte(data, ':')
+	if colon <= 0 || colon > 10 {
+		return nil, nil, fmt.Errorf("无效的tnetstring长度")
+	}
+	length, err := strconv.Atoi(string(data[:colon]))
+	if err != nil || length < 0 || colon+1+length >= len(data) {
+		return nil, nil, fmt.Errorf("无效的tnetstring长度")
+	}
+	payload := data[colon+1 : colon+1+length]
+	tag := data[colon+1+length]
+	rest := data[colon+2+length:]
+
+	switch tag {
+	case ',':
+		return payload, rest, nil
+	case ';':
+		return string(payload), rest, nil
+	case '#':
+		number, err := strconv.ParseInt(string(payload), 10, 64)
+		return number, rest, err
+	case '^':
+		number, err := strconv.ParseFloat(string(payload), 64)
+		return number, rest, err
+	case '!':
+		return string(payload) == "true", rest, nil
+	case '~':
+		return nil, rest, nil
+	case ']':
+		list := make([]interface{}, 0)
+		for len(payload) > 0 {
+			item, remaining, err := parseTNetString(payload)
+			if err != nil {
+				return nil, nil, err
+			}
+			list = append(list, item)
+			payload = remaining
+		}
+		return list, rest, nil
+	case '}':
+		dict := make(map[string]interface{})
+		for len(payload) > 0 {
+			key, remaining, err := parseTNetString(payload)
+			if err != nil {
+				return nil, nil, err
+			}
+			value, remaining, err := parseTNetString(remaining)
+			if err != nil {
+				return nil, nil, err
+			}
+			dict[tnetText(key)] = value
+			payload = remaining
+		}
+		return dict, rest, nil
+	}
+	return nil, nil, fmt.Errorf("未知的tnetstring类型: %q", tag)
+}
+
+// LoadFromRawRequest 加载原始HTTP请求文件（sqlmap -r格式，也支持以空行分隔的多个请求）
+func (pc *PassiveCrawler) LoadFromRawRequest(filename string) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("读取请求文件失败: %v", err)
+	}
+	data = bytes.TrimLeft(data, "\xef\xbb\xbf \t\r\n")
+
+	for len(data) > 0 {
+		exchange, rest := pc.parseRawRequestFile(data)
+		if exchange == nil {
+			break
+		}
+		pc.addExchange(exchange)
+		data = bytes.TrimLeft(rest, " \t\r\n")
+	}
+
+	pc.printImportSummary("原始请求")
+	return nil
+}
+
+// parseRawRequestFile 解析文件开头的一个请求，返回请求和剩余内容
+func (pc *PassiveCrawler) parseRawRequestFile(data []byte) (*ImportedExchange, []byte) {
+	headerBlock, rest := splitRawHTTPMessage(data)
+	lines := strings.SplitN(headerBlock, "\n", 2)
+	requestLine := strings.TrimRight(lines[0], "\r")
+	if !rawRequestLinePattern.MatchString(requestLine) {
+		return nil, nil
+	}
+	fields := strings.Fields(requestLine)
+	header := make(http.Header)
+	if len(lines) > 1 {
+		header = parseRawHeaderLines(lines[1])
+	}
+
+	// 请求体：有Content-Length时按长度截取，否则到下一个请求行（或文件末尾）
+	body := rest
+	rest = nil
+	if length, err := strconv.Atoi(header.Get("Content-Length")); err == nil && length >= 0 && length <= len(body) {
+		body, rest = body[:length], body[length:]
+	} else if loc := rawRequestLinePattern.FindIndex(body); loc != nil {
+		body, rest = body[:loc[0]], body[loc[0]:]
+	}
+	body = bytes.TrimRight(body, "\r\n")
+
+	exchange := &ImportedExchange{
+		Method:         strings.ToUpper(fields[0]),
+		URL:            pc.rawRequestURL(fields[1], header.Get("Host")),
+		RequestHeaders: header,
+	}
+	if len(body) > 0 {
+		exchange.RequestBody = body
+	}
+	return exchange, rest
+}
+
+// rawRequestURL 由请求行和Host头还原完整URL（端口443或默认协议为https时使用https）
+func (pc *PassiveCrawler) rawRequestURL(target, host string) string {
+	if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+		return target
+	}
+	scheme := "http"
+	if pc.defaultScheme != "" {
+		scheme = pc.defaultScheme
+	}
+	if _, port, err := net.SplitHostPort(host); err == nil {
+		switch port {
+		case "443":
+			scheme = "https"
+			host = strings.TrimSuffix(host, ":443")
+		case "80":
+			scheme = "http"
+			host = strings.TrimSuffix(host, ":80")
+		}
+	}
+	return (&url.URL{Scheme: scheme, Host: host}).String() + target
+}
+
+// addExchange 记录一次导入的请求（统计URL、API和表单）
+func (pc *PassiveCrawler) addExchange(exchange *ImportedExchange) {
+	if exchange.URL == "" {
+		return
+	}
+	pc.statistics.ImportedRequests++
+	pc.importedURLs = append(pc.importedURLs, exchange.URL)
+	pc.statistics.ExtractedURLs++
+	if pc.isAPI(exchange.URL) {
+		pc.importedAPIs = append(pc.importedAPIs, exchange.URL)
+		pc.statistics.ExtractedAPIs++
+	}
+
+	if exchange.Form == nil && exchange.Method == http.MethodPost && exchange.RequestHeaders != nil &&
+		strings.HasPrefix(strings.ToLower(exchange.RequestHeaders.Get("Content-Type")), "application/x-www-form-urlencoded") {
+		if values, err := url.ParseQuery(string(exchange.RequestBody)); err == nil && len(values) > 0 {
+			form := &Form{Action: exchange.URL, Method: exchange.Method, Fields: make([]FormField, 0, len(values))}
+			for name := range values {
+				form.Fields = append(form.Fields, FormField{Name: name, Type: "text", Value: values.Get(name)})
+			}
+			exchange.Form = form
+		}
+	}
+	if exchange.Form != nil {
+		pc.importedForms = append(pc.importedForms, exchange.Form)
+		pc.statistics.ExtractedForms++
+	}
+
+	pc.exchanges = append(pc.exchanges, exchange)
+}
+
+// printImportSummary 输出导入统计
+func (pc *PassiveCrawler) printImportSummary(source string) {
+	fmt.Printf("从%s导入: %d个请求, %d个URL, %d个表单, %d个API\n",
+		source,
+		pc.statistics.ImportedRequests,
+		pc.statistics.ExtractedURLs,
+		pc.statistics.ExtractedForms,
+		pc.statistics.ExtractedAPIs)
+}
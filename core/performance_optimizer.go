@@ -112,6 +112,21 @@ func (po *PerformanceOptimizer) createOptimizedHTTPClient() *http.Client {
 	}
 }
 
+// SetAuthManager 设置认证管理器（v4.9新增）
+// 凭据只会发送到认证作用域内的域名，跨域CDN请求不受影响
+func (po *PerformanceOptimizer) SetAuthManager(am *AuthManager) {
+	if am != nil {
+		am.ApplyToClient(po.httpClient)
+	}
+}
+
+// SetHARRecorder 设置HAR流量记录器（v4.9新增）（需在SetAuthManager之后调用）
+func (po *PerformanceOptimizer) SetHARRecorder(hr *HARRecorder) {
+	if hr != nil {
+		hr.ApplyToClient(po.httpClient, HARSourceProbe)
+	}
+}
+
 // GetBuffer 从对象池获取Buffer
 func (po *PerformanceOptimizer) GetBuffer() *bytes.Buffer {
 	po.stats.mutex.Lock()
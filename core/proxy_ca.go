@@ -0,0 +1,225 @@
+package core
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 🆕 v4.9: 被动代理的本地CA
+// 首次使用时生成根证书并保存到磁盘，之后复用同一证书（浏览器只需信任一次）；
+// 代理为每个访问的主机即时签发叶子证书，用于解密HTTPS流量
+
+// 证书有效期
+const (
+	proxyCAValidity   = 10 * 365 * 24 * time.Hour
+	proxyLeafValidity = 397 * 24 * time.Hour // 浏览器接受的叶子证书最长有效期约为398天
+)
+
+// ProxyCA 代理根证书及叶子证书缓存
+type ProxyCA struct {
+	cert    *x509.Certificate
+	certDER []byte
+	key     crypto.Signer
+	leafKey *ecdsa.PrivateKey // 所有叶子证书共用一个密钥，避免每个主机都生成密钥
+	cache   map[string]*tls.Certificate
+	mutex   sync.Mutex
+}
+
+// LoadOrCreateProxyCA 从文件加载CA，文件不存在时生成新的CA并保存
+// 返回的created表示本次新生成了证书（需要导入浏览器信任）
+func LoadOrCreateProxyCA(certFile, keyFile string) (ca *ProxyCA, created bool, err error) {
+	_, certErr := os.Stat(certFile)
+	_, keyErr := os.Stat(keyFile)
+	switch {
+	case certErr == nil && keyErr == nil:
+		ca, err = loadProxyCA(certFile, keyFile)
+		return ca, false, err
+	case os.IsNotExist(certErr) && os.IsNotExist(keyErr):
+		ca, err = createProxyCA(certFile, keyFile)
+		return ca, err == nil, err
+	case certErr == nil:
+		return nil, false, fmt.Errorf("CA证书存在但私钥不存在: %s", keyFile)
+	case keyErr == nil:
+		return nil, false, fmt.Errorf("CA私钥存在但证书不存在: %s", certFile)
+	}
+	if certErr != nil {
+		return nil, false, certErr
+	}
+	return nil, false, keyErr
+}
+
+// loadProxyCA 加载PEM格式的CA证书和私钥（支持PKCS#8、EC和PKCS#1 RSA私钥，可使用Burp等工具导出的CA）
+func loadProxyCA(certFile, keyFile string) (*ProxyCA, error) {
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("加载CA证书失败: %v", err)
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("解析CA证书失败: %v", err)
+	}
+	if !cert.IsCA {
+		return nil, fmt.Errorf("证书不是CA证书: %s", certFile)
+	}
+	key, ok := pair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("不支持的CA私钥类型: %T", pair.PrivateKey)
+	}
+	return newProxyCA(cert, pair.Certificate[0], key)
+}
+
+// createProxyCA 生成新的CA并保存（私钥文件权限0600）
+func createProxyCA(certFile, keyFile string) (*ProxyCA, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := randomSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			CommonName:   "GogoSpider Passive Proxy CA",
+			Organization: []string{"GogoSpider"},
+		},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(proxyCAValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("生成CA证书失败: %v", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range []string{certFile, keyFile} {
+		if dir := filepath.Dir(file); dir != "" {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		return nil, fmt.Errorf("保存CA证书失败: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		return nil, fmt.Errorf("保存CA私钥失败: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return newProxyCA(cert, der, key)
+}
+
+func newProxyCA(cert *x509.Certificate, der []byte, key crypto.Signer) (*ProxyCA, error) {
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &ProxyCA{
+		cert:    cert,
+		certDER: der,
+		key:     key,
+		leafKey: leafKey,
+		cache:   make(map[string]*tls.Certificate),
+	}, nil
+}
+
+// Subject CA证书名称
+func (ca *ProxyCA) Subject() string {
+	return ca.cert.Subject.CommonName
+}
+
+// Fingerprint CA证书的SHA-256指纹（用于确认浏览器信任的是同一证书）
+func (ca *ProxyCA) Fingerprint() string {
+	sum := sha256.Sum256(ca.certDER)
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// CertificateFor 获取主机的叶子证书（首次访问时签发并缓存）
+func (ca *ProxyCA) CertificateFor(host string) (*tls.Certificate, error) {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if host == "" {
+		return nil, fmt.Errorf("缺少主机名，无法签发证书")
+	}
+
+	ca.mutex.Lock()
+	defer ca.mutex.Unlock()
+	if cert, exists := ca.cache[host]; exists && time.Now().Before(cert.Leaf.NotAfter) {
+		return cert, nil
+	}
+
+	serial, err := randomSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	notAfter := now.Add(proxyLeafValidity)
+	if notAfter.After(ca.cert.NotAfter) {
+		notAfter = ca.cert.NotAfter
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: host},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		template.IPAddresses = []net.IP{ip}
+	} else {
+		template.DNSNames = []string{host}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &ca.leafKey.PublicKey, ca.key)
+	if err != nil {
+		return nil, fmt.Errorf("签发证书失败 %s: %v", host, err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	cert := &tls.Certificate{
+		Certificate: [][]byte{der, ca.certDER},
+		PrivateKey:  ca.leafKey,
+		Leaf:        leaf,
+	}
+	ca.cache[host] = cert
+	return cert, nil
+}
+
+// randomSerialNumber 随机128位证书序列号
+func randomSerialNumber() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
@@ -37,6 +37,14 @@ type ProxyServer struct {
 	server   *http.Server
 	running  bool
 	stopChan chan struct{}
+	
+	// 🆕 v4.9: HTTPS解密（中间人）
+	ca                *ProxyCA // 为nil时CONNECT只做隧道转发
+	decryptScopeOnly  bool     // 只解密目标域名的HTTPS，其余主机直接隧道转发
+	
+	// 🆕 v4.9: 复用连接的上游客户端
+	transport *http.Transport
+	client    *http.Client
 }
 
 // InterceptedRequest 拦截的请求
@@ -73,7 +81,27 @@ type ProxyStats struct {
 
 // NewProxyServer 创建代理服务器
 func NewProxyServer(listenAddr string, targetDomain string) *ProxyServer {
+	// 上游连接不走环境变量代理（避免浏览器代理指向自身时形成回环）
+	transport := &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:   true,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+	
 	return &ProxyServer{
+		transport: transport,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   30 * time.Second,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse // 不自动跟随重定向，交给浏览器处理
+			},
+		},
 		listenAddr:           listenAddr,
 		targetDomain:         targetDomain,
 		interceptedRequests:  make([]*InterceptedRequest, 0),
@@ -94,6 +122,29 @@ func (ps *ProxyServer) SetSpider(spider *Spider) {
 	ps.spider = spider
 }
 
+// EnableMITM 启用HTTPS解密（v4.9新增）
+// scopeOnly为true时只解密目标域名，其余主机（含证书固定的应用）保持隧道转发
+func (ps *ProxyServer) EnableMITM(ca *ProxyCA, scopeOnly bool) {
+	ps.ca = ca
+	ps.decryptScopeOnly = scopeOnly
+}
+
+// SetInsecureSkipVerify 设置是否跳过上游HTTPS证书验证（v4.9新增）
+func (ps *ProxyServer) SetInsecureSkipVerify(skip bool) {
+	if skip {
+		ps.transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	} else {
+		ps.transport.TLSClientConfig = nil
+	}
+}
+
+// SetHARRecorder 记录代理转发的流量（v4.9新增，需在SetInsecureSkipVerify之后调用）
+func (ps *ProxyServer) SetHARRecorder(hr *HARRecorder) {
+	if hr != nil {
+		hr.ApplyToClient(ps.client, HARSourceProxy)
+	}
+}
+
 // AddFilter 添加URL过滤规则
 func (ps *ProxyServer) AddFilter(filter string) {
 	ps.filters = append(ps.filters, filter)
@@ -105,6 +156,12 @@ func (ps *ProxyServer) Start() error {
 		return fmt.Errorf("代理服务器已在运行")
 	}
 	
+	// 🆕 v4.9: 先同步监听，端口被占用等错误直接返回
+	listener, err := net.Listen("tcp", ps.listenAddr)
+	if err != nil {
+		return fmt.Errorf("代理监听失败: %v", err)
+	}
+	
 	ps.running = true
 	
 	// 创建HTTP服务器
@@ -118,10 +175,13 @@ func (ps *ProxyServer) Start() error {
 	log.Printf("代理服务器启动在: %s", ps.listenAddr)
 	log.Printf("目标域名: %s", ps.targetDomain)
 	log.Printf("配置浏览器代理为: http://%s", ps.listenAddr)
+	if ps.ca != nil {
+		log.Printf("HTTPS解密已启用（CA: %s）", ps.ca.Subject())
+	}
 	
 	// 在goroutine中启动服务器
 	go func() {
-		if err := ps.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := ps.server.Serve(listener); err != nil && err != http.ErrServerClosed {
 			log.Printf("代理服务器错误: %v", err)
 		}
 	}()
@@ -190,11 +250,6 @@ func (ps *ProxyServer) handleHTTPRequest(w http.ResponseWriter, r *http.Request)
 	// 记录响应
 	ps.recordResponse(r.URL.String(), resp, bodyBytes)
 	
-	// 实时分析（如果配置了爬虫）
-	if ps.spider != nil && resp.StatusCode == http.StatusOK {
-		ps.analyzeResponse(r.URL.String(), resp, string(bodyBytes))
-	}
-	
 	// 转发响应给客户端
 	for key, values := range resp.Header {
 		for _, value := range values {
@@ -210,12 +265,22 @@ func (ps *ProxyServer) handleHTTPRequest(w http.ResponseWriter, r *http.Request)
 		interceptedReq.URL, 
 		resp.StatusCode, 
 		len(bodyBytes))
+	
+	// 实时分析（如果配置了爬虫，在响应返回浏览器之后进行，不拖慢浏览）
+	if ps.spider != nil {
+		ps.spider.AnalyzePassiveTraffic(interceptedReq, resp, bodyBytes)
+	}
 }
 
 // handleHTTPSConnect 处理HTTPS CONNECT隧道
 func (ps *ProxyServer) handleHTTPSConnect(w http.ResponseWriter, r *http.Request) {
-	// 对于HTTPS，我们只能建立隧道，无法看到内容（除非做中间人）
-	// 这里实现简单的隧道转发
+	// 🆕 v4.9: 启用CA时解密HTTPS（中间人），否则只能建立隧道，无法看到内容
+	if ps.ca != nil && (!ps.decryptScopeOnly || ps.shouldIntercept("https://"+r.Host+"/")) {
+		ps.handleMITM(w, r)
+		return
+	}
+	
+	// 隧道转发
 	
 	targetConn, err := net.DialTimeout("tcp", r.Host, 10*time.Second)
 	if err != nil {
@@ -248,14 +313,118 @@ func (ps *ProxyServer) handleHTTPSConnect(w http.ResponseWriter, r *http.Request
 	log.Printf("[HTTPS隧道] %s", r.Host)
 }
 
+// handleMITM 解密HTTPS隧道（v4.9新增）
+// 用CA为目标主机签发的证书与浏览器完成TLS握手，隧道内的请求按普通HTTP请求拦截和转发
+func (ps *ProxyServer) handleMITM(w http.ResponseWriter, r *http.Request) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "不支持劫持", http.StatusInternalServerError)
+		return
+	}
+	
+	clientConn, _, err := hijacker.Hijack()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("劫持失败: %v", err), http.StatusInternalServerError)
+		return
+	}
+	clientConn.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n"))
+	
+	connectHost := r.Host
+	tlsConn := tls.Server(clientConn, &tls.Config{
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			if hello.ServerName != "" {
+				return ps.ca.CertificateFor(hello.ServerName)
+			}
+			return ps.ca.CertificateFor(connectHost)
+		},
+		NextProtos: []string{"http/1.1"}, // 隧道内只支持HTTP/1.1
+	})
+	tlsConn.SetDeadline(time.Now().Add(10 * time.Second))
+	if err := tlsConn.Handshake(); err != nil {
+		// 通常是浏览器尚未信任CA
+		log.Printf("[HTTPS解密] %s 握手失败: %v", connectHost, err)
+		tlsConn.Close()
+		return
+	}
+	tlsConn.SetDeadline(time.Time{})
+	
+	listener := newSingleConnListener(tlsConn)
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			// 隧道内的请求只有路径，补全为绝对URL
+			req.URL.Scheme = "https"
+			req.URL.Host = req.Host
+			if req.URL.Host == "" {
+				req.URL.Host = connectHost
+			}
+			ps.updateStats(req)
+			ps.handleHTTPRequest(w, req)
+		}),
+		ConnState: func(conn net.Conn, state http.ConnState) {
+			if state == http.StateClosed || state == http.StateHijacked {
+				listener.Close()
+			}
+		},
+		IdleTimeout: 60 * time.Second,
+		ErrorLog:    log.New(io.Discard, "", 0),
+	}
+	
+	// 代理停止时关闭隧道
+	go func() {
+		select {
+		case <-ps.stopChan:
+			tlsConn.Close()
+		case <-listener.done:
+		}
+	}()
+	
+	server.Serve(listener)
+}
+
+// singleConnListener 只返回一个连接的Listener，连接关闭后Accept返回错误
+type singleConnListener struct {
+	conn      net.Conn
+	accepted  bool
+	mutex     sync.Mutex
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newSingleConnListener(conn net.Conn) *singleConnListener {
+	return &singleConnListener{conn: conn, done: make(chan struct{})}
+}
+
+func (l *singleConnListener) Accept() (net.Conn, error) {
+	l.mutex.Lock()
+	if !l.accepted {
+		l.accepted = true
+		l.mutex.Unlock()
+		return l.conn, nil
+	}
+	l.mutex.Unlock()
+	<-l.done
+	return nil, net.ErrClosed
+}
+
+func (l *singleConnListener) Close() error {
+	l.closeOnce.Do(func() { close(l.done) })
+	return nil
+}
+
+func (l *singleConnListener) Addr() net.Addr {
+	return l.conn.LocalAddr()
+}
+
 // sendRequest 发送代理请求
 func (ps *ProxyServer) sendRequest(r *http.Request) (*http.Response, error) {
 	// 创建新的请求
 	outReq := &http.Request{
-		Method: r.Method,
-		URL:    r.URL,
-		Header: r.Header.Clone(),
-		Body:   r.Body,
+		Method:        r.Method,
+		URL:           r.URL,
+		Header:        r.Header.Clone(),
+		Body:          r.Body,
+		ContentLength: r.ContentLength,
+		Host:          r.Host,
 	}
 	
 	// 移除代理相关的头
@@ -263,15 +432,10 @@ func (ps *ProxyServer) sendRequest(r *http.Request) (*http.Response, error) {
 	outReq.Header.Del("Proxy-Authenticate")
 	outReq.Header.Del("Proxy-Authorization")
 	
-	// 创建HTTP客户端
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			return http.ErrUseLastResponse // 不自动跟随重定向
-		},
-	}
+	// 🆕 v4.9: 由Transport协商压缩并自动解压，拦截记录和分析的都是明文
+	outReq.Header.Del("Accept-Encoding")
 	
-	return client.Do(outReq)
+	return ps.client.Do(outReq)
 }
 
 // forwardRequest 直接转发请求（不记录）
@@ -306,8 +470,12 @@ func (ps *ProxyServer) shouldIntercept(urlStr string) bool {
 		return false
 	}
 	
-	// 检查是否匹配目标域名
-	if !strings.Contains(parsedURL.Host, ps.targetDomain) {
+	// 检查是否匹配目标域名（🆕 v4.9: 关联爬虫时使用爬虫的范围判断）
+	if ps.spider != nil {
+		if !ps.spider.isInTargetDomain(urlStr) {
+			return false
+		}
+	} else if !strings.Contains(parsedURL.Host, ps.targetDomain) {
 		return false
 	}
 	
@@ -385,20 +553,6 @@ func (ps *ProxyServer) recordResponse(urlStr string, resp *http.Response, body [
 	ps.stats.mutex.Unlock()
 }
 
-// analyzeResponse 实时分析响应
-func (ps *ProxyServer) analyzeResponse(urlStr string, resp *http.Response, body string) {
-	// 如果是HTML内容，进行分析
-	contentType := resp.Header.Get("Content-Type")
-	if strings.Contains(contentType, "text/html") {
-		// 提取链接、表单等
-		// 这里可以调用爬虫的分析功能
-		log.Printf("[分析] HTML页面: %s (%d字节)", urlStr, len(body))
-		
-		// TODO: 集成到爬虫的分析流程
-		// ps.spider.analyzeHTML(urlStr, body)
-	}
-}
-
 // updateStats 更新统计信息
 func (ps *ProxyServer) updateStats(r *http.Request) {
 	ps.stats.mutex.Lock()
@@ -454,9 +608,65 @@ func (ps *ProxyServer) GetStatistics() map[string]interface{} {
 }
 
 // ExportToHAR 导出为HAR格式（用于分析）
+// 🆕 v4.9: 按URL和时间顺序把拦截的请求与响应配对，未收到响应的请求同样导出
 func (ps *ProxyServer) ExportToHAR(filename string) error {
-	// TODO: 实现HAR导出
-	return fmt.Errorf("HAR导出功能待实现")
+	ps.mutex.RLock()
+	requests := make([]*InterceptedRequest, len(ps.interceptedRequests))
+	copy(requests, ps.interceptedRequests)
+	responses := make([]*InterceptedResponse, len(ps.interceptedResponses))
+	copy(responses, ps.interceptedResponses)
+	ps.mutex.RUnlock()
+	
+	recorder := NewHARRecorder(0, true, 0)
+	used := make([]bool, len(responses))
+	for _, intercepted := range requests {
+		req, err := http.NewRequest(intercepted.Method, intercepted.URL, nil)
+		if err != nil {
+			continue
+		}
+		for name, value := range intercepted.Headers {
+			req.Header.Set(name, value)
+		}
+		entry := newHAREntry(req, []byte(intercepted.Body), intercepted.Timestamp, HARSourceProxy)
+		entry.Response = HARResponse{
+			Cookies:     []HARCookie{},
+			Headers:     []HARNameValue{},
+			Content:     HARContent{MimeType: "x-unknown"},
+			HeadersSize: -1,
+			BodySize:    -1,
+		}
+		entry.Timings = HARTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1}
+		
+		// 同一URL的第一个尚未配对、且不早于请求的响应
+		for i, response := range responses {
+			if used[i] || response.URL != intercepted.URL || response.Timestamp.Before(intercepted.Timestamp) {
+				continue
+			}
+			used[i] = true
+			resp := &http.Response{
+				StatusCode: response.StatusCode,
+				Status:     fmt.Sprintf("%d %s", response.StatusCode, http.StatusText(response.StatusCode)),
+				Proto:      "HTTP/1.1",
+				Header:     make(http.Header),
+				Request:    req,
+			}
+			for name, value := range response.Headers {
+				resp.Header.Set(name, value)
+			}
+			entry.Response = newHARResponse(resp)
+			entry.Response.Content = harContent(response.ContentType, []byte(response.Body), response.Size, false)
+			entry.Response.BodySize = response.Size
+			entry.Timings.Wait = float64(response.Timestamp.Sub(intercepted.Timestamp).Microseconds()) / 1000
+			entry.Time = entry.Timings.Wait
+			break
+		}
+		if entry.Response.Status == 0 {
+			entry.Comment = "未记录到响应"
+		}
+		recorder.AddEntry(entry)
+	}
+	
+	return recorder.ExportToFile(filename)
 }
 
 // Clear 清除所有拦截记录
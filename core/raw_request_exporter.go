@@ -0,0 +1,420 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 🆕 v4.9: 原始HTTP请求导出
+// 每个去重后的POST请求/表单提交写成一个原始请求文件（可直接用于 sqlmap -r、ffuf -request），
+// 并生成等价的curl脚本；可选在每个参数值上加注入标记（sqlmap的*、ffuf的FUZZ）
+
+// 导出的multipart请求体使用固定边界，保证多次导出结果一致
+const rawRequestMultipartBoundary = "----GogoSpiderBoundary7MA4YWxkTrZu0gW"
+
+// 文件名中不安全的字符
+var rawRequestUnsafeName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// RawRequestExporter 原始HTTP请求导出器
+type RawRequestExporter struct {
+	maxRequests    int
+	placeholder    string // 参数标记（空表示不加）
+	replaceValues  bool   // true: 用标记替换参数值；false: 追加在参数值之后
+	requests       []*CollectionRequest
+	seen           map[string]bool
+	defaultHeaders []collectionDefaultHeader
+}
+
+// preparedRawRequest 已加标记、计算好请求头和请求体的请求
+type preparedRawRequest struct {
+	fileName string
+	scheme   string
+	method   string
+	url      string
+	target   string // 请求行中的路径和查询
+	host     string
+	headers  []CollectionParam // 不含Content-Type和Content-Length
+	mimeType string            // 完整的Content-Type（multipart含边界）
+	body     []byte
+	fields   []CollectionParam // multipart字段（curl用--form-string发送）
+}
+
+// NewRawRequestExporter 创建导出器；maxRequests<=0 表示不限制
+func NewRawRequestExporter(maxRequests int) *RawRequestExporter {
+	return &RawRequestExporter{
+		maxRequests: maxRequests,
+		requests:    make([]*CollectionRequest, 0),
+		seen:        make(map[string]bool),
+	}
+}
+
+// SetPlaceholder 设置参数标记；replace为true时标记替换参数值（ffuf的FUZZ），否则追加在值之后（sqlmap的*）
+func (re *RawRequestExporter) SetPlaceholder(marker string, replace bool) {
+	re.placeholder = marker
+	re.replaceValues = replace
+}
+
+// SetDefaultHeader 为applies范围内的请求附加请求头（如爬取时配置的Cookie和认证头）
+func (re *RawRequestExporter) SetDefaultHeader(name, value string, applies func(rawURL string) bool) {
+	if value == "" {
+		return
+	}
+	re.defaultHeaders = append(re.defaultHeaders, collectionDefaultHeader{name: name, value: value, applies: applies})
+}
+
+// AddRequest 添加请求，返回是否添加
+// 按 方法 + 路径 + 查询参数名 + 请求体参数名 去重，同一接口不同参数值只导出一次
+func (re *RawRequestExporter) AddRequest(req CollectionRequest) bool {
+	u, err := url.Parse(req.URL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	if re.maxRequests > 0 && len(re.requests) >= re.maxRequests {
+		return false
+	}
+	req.Method = strings.ToUpper(req.Method)
+	if req.Method == "" {
+		req.Method = "POST"
+	}
+	u.Fragment = ""
+	req.URL = u.String()
+	if req.ContentType == "" {
+		req.ContentType, _ = lookupHeader(req.Headers, "Content-Type")
+	}
+
+	key := rawRequestKey(&req, u)
+	if re.seen[key] {
+		return false
+	}
+	re.seen[key] = true
+
+	headers := make(map[string]string, len(req.Headers))
+	for name, value := range req.Headers {
+		headers[name] = value
+	}
+	for _, header := range re.defaultHeaders {
+		if header.applies != nil && !header.applies(req.URL) {
+			continue
+		}
+		if _, exists := lookupHeader(headers, header.name); !exists {
+			headers[header.name] = header.value
+		}
+	}
+	req.Headers = headers
+	re.requests = append(re.requests, &req)
+	return true
+}
+
+// GetRequests 获取已添加的请求
+func (re *RawRequestExporter) GetRequests() []*CollectionRequest {
+	return re.requests
+}
+
+// rawRequestKey 去重键：方法、主机路径、排序后的查询参数名和请求体参数名（无法解析参数时用原始请求体）
+func rawRequestKey(req *CollectionRequest, u *url.URL) string {
+	queryNames := make([]string, 0)
+	for name := range u.Query() {
+		queryNames = append(queryNames, name)
+	}
+	sort.Strings(queryNames)
+
+	bodyKey := req.Body
+	if params := req.bodyParams(); params != nil {
+		names := make([]string, 0, len(params))
+		for _, param := range params {
+			names = append(names, param.Name)
+		}
+		sort.Strings(names)
+		bodyKey = strings.Join(names, "&")
+	} else {
+		var object map[string]interface{}
+		if json.Unmarshal([]byte(req.Body), &object) == nil {
+			names := make([]string, 0, len(object))
+			for name := range object {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			bodyKey = "json:" + strings.Join(names, ",")
+		}
+	}
+	return req.Method + " " + u.Scheme + "://" + u.Host + u.Path + "?" + strings.Join(queryNames, "&") + "\x00" + bodyKey
+}
+
+// mark 给参数值加标记
+func (re *RawRequestExporter) mark(value string) string {
+	if re.placeholder == "" {
+		return value
+	}
+	if re.replaceValues {
+		return re.placeholder
+	}
+	return value + re.placeholder
+}
+
+// markEncodedPairs 给 a=1&b=2 形式（已编码）的每个参数值加标记，保持原有顺序
+func (re *RawRequestExporter) markEncodedPairs(encoded string) string {
+	if re.placeholder == "" || encoded == "" {
+		return encoded
+	}
+	pairs := strings.Split(encoded, "&")
+	for i, pair := range pairs {
+		if pair == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(pair, "=")
+		pairs[i] = name + "=" + re.mark(value)
+	}
+	return strings.Join(pairs, "&")
+}
+
+// markJSON 给JSON请求体的每个标量值加标记；无法解析时原样返回
+// 数字和布尔值的标记不加引号（与sqlmap对JSON参数的标记方式一致）
+func (re *RawRequestExporter) markJSON(body string) string {
+	if re.placeholder == "" {
+		return body
+	}
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return body
+	}
+
+	// 先用占位符替换叶子节点，序列化后再替换为带标记的文本
+	replacements := make(map[string]string)
+	var walk func(node interface{}) interface{}
+	walk = func(node interface{}) interface{} {
+		switch typed := node.(type) {
+		case map[string]interface{}:
+			for key, child := range typed {
+				typed[key] = walk(child)
+			}
+			return typed
+		case []interface{}:
+			for i, child := range typed {
+				typed[i] = walk(child)
+			}
+			return typed
+		case nil:
+			return nil
+		}
+		token := fmt.Sprintf("__gogospider_leaf_%d__", len(replacements))
+		switch typed := node.(type) {
+		case string:
+			quoted, _ := json.Marshal(re.mark(typed))
+			replacements[strconv.Quote(token)] = string(quoted)
+		default:
+			replacements[strconv.Quote(token)] = re.mark(fmt.Sprint(typed))
+		}
+		return token
+	}
+	value = walk(value)
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return body
+	}
+	result := strings.TrimRight(buffer.String(), "\n")
+	for token, replacement := range replacements {
+		result = strings.Replace(result, token, replacement, 1)
+	}
+	return result
+}
+
+// prepare 生成带标记的请求行、请求头和请求体
+func (re *RawRequestExporter) prepare(req *CollectionRequest, index int) *preparedRawRequest {
+	u, _ := url.Parse(req.URL)
+	u.RawQuery = re.markEncodedPairs(u.RawQuery)
+
+	prepared := &preparedRawRequest{
+		scheme: u.Scheme,
+		method: req.Method,
+		url:    u.String(),
+		target: u.RequestURI(),
+		host:   u.Host,
+	}
+
+	pathName := strings.Trim(rawRequestUnsafeName.ReplaceAllString(u.Host+u.Path, "_"), "_")
+	if len(pathName) > 80 {
+		pathName = pathName[:80]
+	}
+	prepared.fileName = fmt.Sprintf("%03d_%s_%s.txt", index+1, req.Method, pathName)
+
+	mediaType := collectionMediaType(req.ContentType)
+	switch {
+	case len(req.Params) > 0 && mediaType == "multipart/form-data":
+		prepared.mimeType = "multipart/form-data; boundary=" + rawRequestMultipartBoundary
+		var body strings.Builder
+		for _, param := range req.Params {
+			value := re.mark(param.Value)
+			prepared.fields = append(prepared.fields, CollectionParam{Name: param.Name, Value: value})
+			body.WriteString("--" + rawRequestMultipartBoundary + "\r\n")
+			body.WriteString(fmt.Sprintf("Content-Disposition: form-data; name=\"%s\"\r\n\r\n", param.Name))
+			body.WriteString(value + "\r\n")
+		}
+		body.WriteString("--" + rawRequestMultipartBoundary + "--\r\n")
+		prepared.body = []byte(body.String())
+	case len(req.Params) > 0:
+		prepared.mimeType = "application/x-www-form-urlencoded"
+		pairs := make([]string, 0, len(req.Params))
+		for _, param := range req.Params {
+			pairs = append(pairs, url.QueryEscape(param.Name)+"="+re.mark(url.QueryEscape(param.Value)))
+		}
+		prepared.body = []byte(strings.Join(pairs, "&"))
+	case req.Body != "":
+		prepared.mimeType = req.ContentType
+		switch {
+		case mediaType == "application/x-www-form-urlencoded":
+			prepared.body = []byte(re.markEncodedPairs(req.Body))
+		case strings.Contains(mediaType, "json"):
+			prepared.body = []byte(re.markJSON(req.Body))
+		default:
+			prepared.body = []byte(req.Body)
+		}
+	}
+
+	names := make([]string, 0, len(req.Headers))
+	for name := range req.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		lower := strings.ToLower(name)
+		if lower == "host" || lower == "content-length" || lower == "content-type" || strings.HasPrefix(name, ":") {
+			continue
+		}
+		prepared.headers = append(prepared.headers, CollectionParam{Name: name, Value: req.Headers[name]})
+	}
+	return prepared
+}
+
+// prepareAll 按URL和方法排序后逐个生成
+func (re *RawRequestExporter) prepareAll() []*preparedRawRequest {
+	requests := make([]*CollectionRequest, len(re.requests))
+	copy(requests, re.requests)
+	sort.SliceStable(requests, func(i, j int) bool {
+		if requests[i].URL != requests[j].URL {
+			return requests[i].URL < requests[j].URL
+		}
+		return requests[i].Method < requests[j].Method
+	})
+
+	prepared := make([]*preparedRawRequest, 0, len(requests))
+	for i, req := range requests {
+		prepared = append(prepared, re.prepare(req, i))
+	}
+	return prepared
+}
+
+// raw 原始请求报文（CRLF换行）
+func (p *preparedRawRequest) raw() []byte {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s %s HTTP/1.1\r\n", p.method, p.target))
+	buffer.WriteString("Host: " + p.host + "\r\n")
+	for _, header := range p.headers {
+		buffer.WriteString(header.Name + ": " + header.Value + "\r\n")
+	}
+	if p.mimeType != "" {
+		buffer.WriteString("Content-Type: " + p.mimeType + "\r\n")
+	}
+	if len(p.body) > 0 || p.method == "POST" || p.method == "PUT" || p.method == "PATCH" {
+		buffer.WriteString(fmt.Sprintf("Content-Length: %d\r\n", len(p.body)))
+	}
+	buffer.WriteString("\r\n")
+	buffer.Write(p.body)
+	return buffer.Bytes()
+}
+
+// ExportRawFiles 每个请求写一个原始请求文件到dir目录，返回写入的文件数
+func (re *RawRequestExporter) ExportRawFiles(dir string) (int, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, prepared := range re.prepareAll() {
+		if err := os.WriteFile(filepath.Join(dir, prepared.fileName), prepared.raw(), 0644); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// ExportCurlScript 导出等价的curl命令脚本；rawDir非空时在每条命令前注明对应的原始请求文件
+func (re *RawRequestExporter) ExportCurlScript(filename, rawDir string) error {
+	var script strings.Builder
+	script.WriteString("#!/bin/sh\n")
+	script.WriteString("# GogoSpider 导出的请求（curl）\n")
+	if re.placeholder != "" {
+		mode := "追加在参数值之后"
+		if re.replaceValues {
+			mode = "替换参数值"
+		}
+		script.WriteString(fmt.Sprintf("# 参数标记: %s（%s）\n", re.placeholder, mode))
+	}
+
+	for _, prepared := range re.prepareAll() {
+		script.WriteString("\n")
+		if rawDir != "" {
+			note := ""
+			if prepared.scheme == "https" {
+				note = "（HTTPS：sqlmap加--force-ssl，ffuf加-request-proto https）"
+			}
+			script.WriteString(fmt.Sprintf("# %s%s\n", filepath.Join(rawDir, prepared.fileName), note))
+		}
+		script.WriteString(fmt.Sprintf("curl -sS -i -k -X %s %s", prepared.method, shellQuote(prepared.url)))
+		for _, header := range prepared.headers {
+			script.WriteString(" \\\n  -H " + shellQuote(header.Name+": "+header.Value))
+		}
+		if len(prepared.fields) > 0 {
+			// multipart由curl生成边界
+			for _, field := range prepared.fields {
+				script.WriteString(" \\\n  --form-string " + shellQuote(field.Name+"="+field.Value))
+			}
+		} else if len(prepared.body) > 0 {
+			if prepared.mimeType != "" {
+				script.WriteString(" \\\n  -H " + shellQuote("Content-Type: "+prepared.mimeType))
+			}
+			script.WriteString(" \\\n  --data-binary " + shellQuote(string(prepared.body)))
+		}
+		script.WriteString("\n")
+	}
+
+	return os.WriteFile(filename, []byte(script.String()), 0755)
+}
+
+// rawPOSTParams 请求体缺失或是没有边界的multipart时，用解析出的参数重建请求体（按参数名排序）
+func rawPOSTParams(post POSTRequest) []CollectionParam {
+	if len(post.Parameters) == 0 {
+		return nil
+	}
+	multipart := collectionMediaType(post.ContentType) == "multipart/form-data"
+	if post.Body != "" && !(multipart && !strings.Contains(post.ContentType, "boundary=")) {
+		return nil
+	}
+	names := make([]string, 0, len(post.Parameters))
+	for name := range post.Parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	params := make([]CollectionParam, 0, len(names))
+	for _, name := range names {
+		params = append(params, CollectionParam{Name: name, Value: post.Parameters[name]})
+	}
+	return params
+}
+
+// shellQuote 用单引号包裹（POSIX shell）
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,566 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 🆕 v4.9: 请求重放
+// 读取上一次爬取的输出（HAR流量、请求日志、POST请求、范围内链接），
+// 经指定的上游代理（Burp/ZAP等）重新发送，让扫描器的站点地图自动填充
+
+// 按优先级排列的运行输出文件：信息更完整的来源先加载，重复请求以先加载的为准
+var replayRunOutputSuffixes = []string{
+	"_crawl.har",
+	"_requests.json",
+	"_post_requests.txt",
+	"_in_scope.txt",
+}
+
+// 重放时不复制的请求头（由Transport按实际连接重新生成）
+var replaySkipHeaders = []string{
+	"Host",
+	"Content-Length",
+	"Connection",
+	"Proxy-Connection",
+	"Keep-Alive",
+	"Transfer-Encoding",
+	"Upgrade",
+	"Te",
+}
+
+// ReplayRequest 待重放的请求
+type ReplayRequest struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte
+	Source string // 来源文件
+}
+
+// ReplayStats 重放统计
+type ReplayStats struct {
+	Loaded      int         // 加载的请求数（去重后）
+	Filtered    int         // 被主机/路径/方法过滤掉的请求数
+	Sent        int         // 成功收到响应的请求数
+	Failed      int         // 发送失败的请求数
+	StatusCodes map[int]int // 状态码分布
+	Errors      []string    // 失败原因（最多保留20条）
+	Duration    time.Duration
+}
+
+// RequestReplayer 请求重放器
+type RequestReplayer struct {
+	proxyURL       *url.URL
+	concurrency    int
+	timeout        time.Duration
+	scope          *ScopeController
+	methods        map[string]bool
+	defaultHeaders map[string]string
+	cookieHeader   string
+	requests       []*ReplayRequest
+	seen           map[string]bool
+}
+
+// NewRequestReplayer 创建请求重放器，proxyURL为上游代理地址（如 http://127.0.0.1:8080）
+func NewRequestReplayer(proxyURL string, concurrency int) (*RequestReplayer, error) {
+	if proxyURL == "" {
+		return nil, fmt.Errorf("未指定上游代理")
+	}
+	if !strings.Contains(proxyURL, "://") {
+		proxyURL = "http://" + proxyURL
+	}
+	parsed, err := url.Parse(proxyURL)
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("无效的代理地址: %s", proxyURL)
+	}
+	if concurrency <= 0 {
+		concurrency = 5
+	}
+
+	return &RequestReplayer{
+		proxyURL:       parsed,
+		concurrency:    concurrency,
+		timeout:        30 * time.Second,
+		methods:        make(map[string]bool),
+		defaultHeaders: make(map[string]string),
+		requests:       make([]*ReplayRequest, 0),
+		seen:           make(map[string]bool),
+	}, nil
+}
+
+// SetTimeout 设置单个请求的超时时间
+func (rr *RequestReplayer) SetTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		rr.timeout = timeout
+	}
+}
+
+// SetFilters 设置过滤条件（为空表示不限制）
+// hosts支持*.example.com，paths支持/api/*前缀和*.php后缀
+func (rr *RequestReplayer) SetFilters(hosts, paths, methods []string) error {
+	scope, err := NewScopeController(ScopeConfig{
+		IncludeDomains: trimNonEmpty(hosts),
+		IncludePaths:   trimNonEmpty(paths),
+		AllowHTTP:      true,
+		AllowHTTPS:     true,
+	})
+	if err != nil {
+		return err
+	}
+	rr.scope = scope
+
+	rr.methods = make(map[string]bool)
+	for _, method := range trimNonEmpty(methods) {
+		rr.methods[strings.ToUpper(method)] = true
+	}
+	return nil
+}
+
+// SetDefaultHeader 设置默认请求头（仅在原始请求没有该头时补充）
+func (rr *RequestReplayer) SetDefaultHeader(name, value string) {
+	rr.defaultHeaders[name] = value
+}
+
+// SetCookieHeader 设置默认Cookie（仅在原始请求没有Cookie时补充）
+func (rr *RequestReplayer) SetCookieHeader(cookie string) {
+	rr.cookieHeader = cookie
+}
+
+// AddRequest 添加待重放的请求（方法+URL+请求体相同的视为重复），返回是否添加
+func (rr *RequestReplayer) AddRequest(req *ReplayRequest) bool {
+	if req == nil || req.URL == "" {
+		return false
+	}
+	if parsed, err := url.Parse(req.URL); err != nil || parsed.Host == "" ||
+		(parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return false
+	}
+	req.Method = strings.ToUpper(req.Method)
+	if req.Method == "" {
+		req.Method = "GET"
+	}
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+
+	key := req.Method + " " + req.URL + "\n" + string(req.Body)
+	if rr.seen[key] {
+		return false
+	}
+	rr.seen[key] = true
+	rr.requests = append(rr.requests, req)
+	return true
+}
+
+// GetRequests 获取已加载的请求
+func (rr *RequestReplayer) GetRequests() []*ReplayRequest {
+	return rr.requests
+}
+
+// LoadRunOutput 加载上一次运行的输出
+// path可以是输出文件前缀（如 spider_example.com_20250101_120000，自动查找同前缀的各输出文件），
+// 也可以是单个文件（HAR、请求日志JSON、POST请求列表、URL列表，或-import支持的流量文件）
+func (rr *RequestReplayer) LoadRunOutput(path string) (int, error) {
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		return rr.loadFile(path)
+	}
+
+	total := 0
+	found := 0
+	for _, suffix := range replayRunOutputSuffixes {
+		filename := path + suffix
+		if _, err := os.Stat(filename); err != nil {
+			continue
+		}
+		found++
+		count, err := rr.loadFile(filename)
+		if err != nil {
+			fmt.Printf("[Replay] 跳过 %s: %v\n", filename, err)
+			continue
+		}
+		total += count
+	}
+	if found == 0 {
+		return 0, fmt.Errorf("没有找到运行输出: %s（需要 %s 等文件）", path, strings.Join(replayRunOutputSuffixes, "、"))
+	}
+	return total, nil
+}
+
+// loadFile 按文件名和内容选择解析方式，返回新增的请求数
+func (rr *RequestReplayer) loadFile(filename string) (int, error) {
+	var requests []*ReplayRequest
+	var err error
+
+	switch {
+	case strings.HasSuffix(filename, "_requests.json"):
+		requests, err = loadReplayRequestLog(filename)
+	case strings.HasSuffix(filename, "_post_requests.txt"):
+		requests, err = loadReplayPOSTRequests(filename)
+	default:
+		data, readErr := ioutil.ReadFile(filename)
+		if readErr != nil {
+			return 0, readErr
+		}
+		if DetectImportFormat(data) != "" {
+			requests, err = loadReplayTraffic(filename)
+		} else {
+			requests = parseReplayURLList(data, filename)
+			if len(requests) == 0 {
+				err = fmt.Errorf("文件中没有可重放的请求")
+			}
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	added := 0
+	for _, req := range requests {
+		if rr.AddRequest(req) {
+			added++
+		}
+	}
+	fmt.Printf("[Replay] %s: %d 个请求（新增 %d）\n", filename, len(requests), added)
+	return added, nil
+}
+
+// loadReplayTraffic 复用流量导入加载HAR/Burp/ZAP等文件
+func loadReplayTraffic(filename string) ([]*ReplayRequest, error) {
+	pc := NewPassiveCrawler("replay")
+	if _, err := pc.LoadFile(filename, ImportFormatAuto); err != nil {
+		return nil, err
+	}
+
+	requests := make([]*ReplayRequest, 0, len(pc.GetExchanges()))
+	for _, exchange := range pc.GetExchanges() {
+		requests = append(requests, &ReplayRequest{
+			Method: exchange.Method,
+			URL:    exchange.URL,
+			Header: exchange.RequestHeaders,
+			Body:   exchange.RequestBody,
+			Source: filename,
+		})
+	}
+	return requests, nil
+}
+
+// loadReplayRequestLog 加载请求日志（_requests.json）
+func loadReplayRequestLog(filename string) ([]*ReplayRequest, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var output struct {
+		Logs []RequestLog `json:"logs"`
+	}
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("解析请求日志失败: %v", err)
+	}
+
+	requests := make([]*ReplayRequest, 0, len(output.Logs))
+	for _, entry := range output.Logs {
+		header := make(http.Header, len(entry.Headers))
+		for name, value := range entry.Headers {
+			header.Set(name, value)
+		}
+		requests = append(requests, &ReplayRequest{
+			Method: entry.Method,
+			URL:    entry.URL,
+			Header: header,
+			Body:   []byte(entry.Body),
+			Source: filename,
+		})
+	}
+	return requests, nil
+}
+
+// loadReplayPOSTRequests 加载POST请求列表（_post_requests.txt）
+// 格式为 "METHOD URL" 开头，之后缩进的Content-Type、Parameters、Body等行
+func loadReplayPOSTRequests(filename string) ([]*ReplayRequest, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	requests := make([]*ReplayRequest, 0)
+	var current *ReplayRequest
+	var params [][2]string
+	inParams := false
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		// Body超过200字符时被截断（以...结尾），用参数重建
+		body := string(current.Body)
+		if (body == "" || strings.HasSuffix(body, "...")) && len(params) > 0 {
+			current.Body = buildReplayBody(current.Header.Get("Content-Type"), params)
+		}
+		requests = append(requests, current)
+		current = nil
+		params = nil
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(line, " ") {
+			flush()
+			parts := strings.SplitN(line, " ", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			current = &ReplayRequest{
+				Method: parts[0],
+				URL:    strings.TrimSpace(parts[1]),
+				Header: make(http.Header),
+				Source: filename,
+			}
+			inParams = false
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "    ") && inParams:
+			if name, value, ok := strings.Cut(trimmed, "="); ok {
+				params = append(params, [2]string{name, value})
+			}
+		case strings.HasPrefix(trimmed, "Content-Type: "):
+			current.Header.Set("Content-Type", strings.TrimPrefix(trimmed, "Content-Type: "))
+			inParams = false
+		case trimmed == "Parameters:":
+			inParams = true
+		case strings.HasPrefix(trimmed, "Body: "):
+			current.Body = []byte(strings.TrimPrefix(trimmed, "Body: "))
+			inParams = false
+		default:
+			inParams = false
+		}
+	}
+	flush()
+	return requests, scanner.Err()
+}
+
+// buildReplayBody 按Content-Type用参数重建请求体
+func buildReplayBody(contentType string, params [][2]string) []byte {
+	if strings.Contains(strings.ToLower(contentType), "json") {
+		object := make(map[string]string, len(params))
+		for _, param := range params {
+			object[param[0]] = param[1]
+		}
+		data, _ := json.Marshal(object)
+		return data
+	}
+
+	values := url.Values{}
+	for _, param := range params {
+		values.Add(param[0], param[1])
+	}
+	return []byte(values.Encode())
+}
+
+// parseReplayURLList 解析URL列表（跳过说明行，每个URL按GET重放）
+func parseReplayURLList(data []byte, source string) []*ReplayRequest {
+	requests := make([]*ReplayRequest, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "http://") && !strings.HasPrefix(line, "https://") {
+			continue
+		}
+		if fields := strings.Fields(line); len(fields) > 0 {
+			line = fields[0]
+		}
+		requests = append(requests, &ReplayRequest{
+			Method: "GET",
+			URL:    line,
+			Header: make(http.Header),
+			Source: source,
+		})
+	}
+	return requests
+}
+
+// filtered 返回通过过滤条件的请求
+func (rr *RequestReplayer) filtered() []*ReplayRequest {
+	if rr.scope == nil && len(rr.methods) == 0 {
+		return rr.requests
+	}
+
+	result := make([]*ReplayRequest, 0, len(rr.requests))
+	for _, req := range rr.requests {
+		if len(rr.methods) > 0 && !rr.methods[req.Method] {
+			continue
+		}
+		if rr.scope != nil && !rr.scope.IsInScope(req.URL) {
+			continue
+		}
+		result = append(result, req)
+	}
+	return result
+}
+
+// Run 经上游代理重放所有通过过滤的请求
+func (rr *RequestReplayer) Run() *ReplayStats {
+	startTime := time.Now()
+	requests := rr.filtered()
+	stats := &ReplayStats{
+		Loaded:      len(rr.requests),
+		Filtered:    len(rr.requests) - len(requests),
+		StatusCodes: make(map[int]int),
+	}
+
+	client := &http.Client{
+		Timeout: rr.timeout,
+		Transport: &http.Transport{
+			Proxy: http.ProxyURL(rr.proxyURL),
+			// 拦截代理会用自己的CA重新签发证书
+			TLSClientConfig:     &tls.Config{InsecureSkipVerify: true},
+			MaxIdleConnsPerHost: rr.concurrency,
+			DisableCompression:  true,
+		},
+		// 不跟随重定向：只发送原始请求，重定向响应由代理记录
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	fmt.Printf("[Replay] 经代理 %s 重放 %d 个请求（过滤 %d 个，并发 %d）\n",
+		rr.proxyURL.String(), len(requests), stats.Filtered, rr.concurrency)
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	jobs := make(chan int)
+	for i := 0; i < rr.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				req := requests[index]
+				statusCode, err := rr.send(client, req)
+
+				mutex.Lock()
+				if err != nil {
+					stats.Failed++
+					if len(stats.Errors) < 20 {
+						stats.Errors = append(stats.Errors, fmt.Sprintf("%s %s: %v", req.Method, req.URL, err))
+					}
+					fmt.Printf("  [%d/%d] ✗ %s %s: %v\n", index+1, len(requests), req.Method, req.URL, err)
+				} else {
+					stats.Sent++
+					stats.StatusCodes[statusCode]++
+					fmt.Printf("  [%d/%d] %d %s %s\n", index+1, len(requests), statusCode, req.Method, req.URL)
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	for i := range requests {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	stats.Duration = time.Since(startTime)
+	return stats
+}
+
+// send 发送单个请求，读完响应体以便代理记录完整响应
+func (rr *RequestReplayer) send(client *http.Client, req *ReplayRequest) (int, error) {
+	var body io.Reader
+	if len(req.Body) > 0 {
+		body = bytes.NewReader(req.Body)
+	}
+	httpReq, err := http.NewRequest(req.Method, req.URL, body)
+	if err != nil {
+		return 0, err
+	}
+
+	httpReq.Header = req.Header.Clone()
+	if host := httpReq.Header.Get("Host"); host != "" {
+		httpReq.Host = host
+	}
+	for _, name := range replaySkipHeaders {
+		httpReq.Header.Del(name)
+	}
+	for name, value := range rr.defaultHeaders {
+		if httpReq.Header.Get(name) == "" {
+			httpReq.Header.Set(name, value)
+		}
+	}
+	if rr.cookieHeader != "" && httpReq.Header.Get("Cookie") == "" {
+		httpReq.Header.Set("Cookie", rr.cookieHeader)
+	}
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+// PrintSummary 打印重放统计
+func (stats *ReplayStats) PrintSummary() {
+	fmt.Println("\n══════════════ 重放统计 ══════════════")
+	fmt.Printf("加载请求: %d\n", stats.Loaded)
+	fmt.Printf("过滤掉:   %d\n", stats.Filtered)
+	fmt.Printf("已发送:   %d\n", stats.Sent)
+	fmt.Printf("失败:     %d\n", stats.Failed)
+	fmt.Printf("耗时:     %.2f 秒\n", stats.Duration.Seconds())
+
+	if len(stats.StatusCodes) > 0 {
+		codes := make([]int, 0, len(stats.StatusCodes))
+		for code := range stats.StatusCodes {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		fmt.Println("状态码分布:")
+		for _, code := range codes {
+			fmt.Printf("  %d: %d\n", code, stats.StatusCodes[code])
+		}
+	}
+	if len(stats.Errors) > 0 {
+		fmt.Println("失败示例:")
+		for _, msg := range stats.Errors {
+			fmt.Printf("  %s\n", msg)
+		}
+	}
+	fmt.Println("══════════════════════════════════════")
+}
+
+// trimNonEmpty 去除空白并丢弃空字符串
+func trimNonEmpty(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
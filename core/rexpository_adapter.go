@@ -185,9 +185,12 @@ func (adapter *RExRepositoryAdapter) LoadIntoDetector(detector *SensitiveInfoDet
 	}
 
 	// 合并规则到检测器
+	detector.mutex.Lock()
+	defer detector.mutex.Unlock()
 	for name, pattern := range rules {
 		detector.patterns[name] = pattern
 	}
+	detector.prefilter = nil
 
 	return nil
 }
@@ -5,9 +5,16 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 )
 
+// sensitiveSnippetRadius 上下文片段在匹配值前后各保留的字节数（🆕 v4.9）
+const sensitiveSnippetRadius = 60
+
 // SensitiveInfo 敏感信息
 type SensitiveInfo struct {
 	Type       string // 类型
@@ -17,6 +24,11 @@ type SensitiveInfo struct {
 	Severity   string // 严重程度: HIGH/MEDIUM/LOW
 	SourceURL  string // 来源URL
 	LineNumber int    // 行号
+	
+	// 🆕 v4.9: 精确位置（压缩后的JS包通常只有一行，仅靠行号无法定位）
+	ContentType string // 来源内容类型（如 application/javascript、application/json）
+	Column      int    // 列号（从1开始，按字符计）
+	Snippet     string // 匹配值前后的上下文（需要脱敏的值同样脱敏）
 }
 
 // SensitiveInfoDetector 敏感信息检测器
@@ -25,6 +37,13 @@ type SensitiveInfoDetector struct {
 	findings      []*SensitiveInfo
 	totalScanned  int
 	totalFindings int
+	
+	// 🆕 v4.9: 关键词预过滤（规则变化时置nil，下次扫描时重建）和按规则统计
+	prefilter *keywordPrefilter
+	ruleStats map[string]*SensitiveRuleStats
+	
+	// 🆕 v4.9: 保护规则、发现和统计；正则匹配本身不持锁，可并发扫描
+	mutex sync.Mutex
 }
 
 // SensitivePattern 敏感信息模式
@@ -34,6 +53,32 @@ type SensitivePattern struct {
 	Severity    string
 	Mask        bool   // 是否需要脱敏
 	Description string // 规则描述
+	Keywords    []string // 🆕 v4.9: 预过滤关键词（为空时从正则推导）
+	
+	// 🆕 v4.9: Gitleaks/TruffleHog规则的匹配条件
+	SecretGroup int                   // 作为密钥值的捕获组（0表示完整匹配，SecretGroupFirstNonEmpty表示第一个非空捕获组）
+	Entropy     float64               // 密钥值的最低香农熵（0表示不检查）
+	Path        *regexp.Regexp        // 只扫描来源URL匹配的内容
+	Requires    []*regexp.Regexp      // 同一内容中还必须匹配的正则（TruffleHog多regex检测器）
+	Allowlists  []*SensitiveAllowlist // 白名单，放行的匹配不报告
+}
+
+// SecretGroupFirstNonEmpty 取第一个非空捕获组作为密钥值（Gitleaks未指定secretGroup时的行为）
+const SecretGroupFirstNonEmpty = -1
+
+// secretSpan 返回匹配中作为密钥值的区间（指定的捕获组未参与匹配或为空时退回完整匹配）
+func (p *SensitivePattern) secretSpan(match []int) (int, int) {
+	switch group := p.SecretGroup; {
+	case group == SecretGroupFirstNonEmpty:
+		for i := 2; i+1 < len(match); i += 2 {
+			if match[i] >= 0 && match[i] < match[i+1] {
+				return match[i], match[i+1]
+			}
+		}
+	case group > 0 && 2*group+1 < len(match) && match[2*group] < match[2*group+1]:
+		return match[2*group], match[2*group+1]
+	}
+	return match[0], match[1]
 }
 
 // RuleConfig 外部规则配置文件结构
@@ -47,13 +92,15 @@ type RulePattern struct {
 	Severity    string `json:"severity"`
 	Mask        bool   `json:"mask"`
 	Description string `json:"description"`
+	Keywords    []string `json:"keywords"` // 🆕 v4.9: 预过滤关键词（任一出现才执行正则，忽略大小写）
 }
 
 // NewSensitiveInfoDetector 创建敏感信息检测器
 func NewSensitiveInfoDetector() *SensitiveInfoDetector {
 	sid := &SensitiveInfoDetector{
-		patterns: make(map[string]*SensitivePattern),
-		findings: make([]*SensitiveInfo, 0),
+		patterns:  make(map[string]*SensitivePattern),
+		findings:  make([]*SensitiveInfo, 0),
+		ruleStats: make(map[string]*SensitiveRuleStats),
 	}
 	
 	sid.initializePatterns()
@@ -72,12 +119,15 @@ func (sid *SensitiveInfoDetector) initializePatterns() {
 
 // addPattern 添加检测模式
 func (sid *SensitiveInfoDetector) addPattern(name string, pattern *regexp.Regexp, severity string, mask bool) {
+	sid.mutex.Lock()
+	defer sid.mutex.Unlock()
 	sid.patterns[name] = &SensitivePattern{
 		Name:     name,
 		Pattern:  pattern,
 		Severity: severity,
 		Mask:     mask,
 	}
+	sid.prefilter = nil
 }
 
 // LoadRulesFromFile 从外部JSON文件加载规则
@@ -88,6 +138,20 @@ func (sid *SensitiveInfoDetector) LoadRulesFromFile(filename string) error {
 		return fmt.Errorf("读取规则文件失败: %v", err)
 	}
 	
+	// 🆕 v4.9: Gitleaks TOML / TruffleHog YAML / RExpository YAML
+	if format := detectRuleFileFormat(filename, data); format != RuleFormatJSON {
+		rules, err := parseExternalRuleFile(filename, format, data)
+		if err != nil {
+			return err
+		}
+		sid.mutex.Lock()
+		sid.patterns = rules
+		sid.prefilter = nil
+		sid.mutex.Unlock()
+		fmt.Printf("[敏感规则] 从 %s 加载了 %d 条%s规则\n", filename, len(rules), format)
+		return nil
+	}
+	
 	// 🔧 修复: 使用map[string]interface{}来处理混合类型的JSON
 	var rawConfig map[string]interface{}
 	if err := json.Unmarshal(data, &rawConfig); err != nil {
@@ -105,6 +169,9 @@ func (sid *SensitiveInfoDetector) LoadRulesFromFile(filename string) error {
 		return fmt.Errorf("'rules'字段格式不正确")
 	}
 	
+	sid.mutex.Lock()
+	defer sid.mutex.Unlock()
+	
 	// 清空现有规则
 	sid.patterns = make(map[string]*SensitivePattern)
 	
@@ -133,6 +200,7 @@ func (sid *SensitiveInfoDetector) LoadRulesFromFile(filename string) error {
 		severity, _ := ruleMap["severity"].(string)
 		mask, _ := ruleMap["mask"].(bool)
 		description, _ := ruleMap["description"].(string)
+		keywords := ruleKeywords(ruleMap["keywords"])
 		
 		if pattern == "" {
 			fmt.Printf("警告: 规则 '%s' 缺少pattern字段，跳过\n", name)
@@ -153,10 +221,12 @@ func (sid *SensitiveInfoDetector) LoadRulesFromFile(filename string) error {
 			Severity:    severity,
 			Mask:        mask,
 			Description: description,
+			Keywords:    keywords,
 		}
 		loadedCount++
 	}
 	
+	sid.prefilter = nil
 	fmt.Printf("[敏感规则] 从 %s 加载了 %d 条规则\n", filename, loadedCount)
 	return nil
 }
@@ -169,6 +239,23 @@ func (sid *SensitiveInfoDetector) MergeRulesFromFile(filename string) error {
 		return fmt.Errorf("读取规则文件失败: %v", err)
 	}
 	
+	// 🆕 v4.9: Gitleaks TOML / TruffleHog YAML / RExpository YAML
+	if format := detectRuleFileFormat(filename, data); format != RuleFormatJSON {
+		rules, err := parseExternalRuleFile(filename, format, data)
+		if err != nil {
+			return err
+		}
+		sid.mutex.Lock()
+		for name, pattern := range rules {
+			sid.patterns[name] = pattern
+		}
+		sid.prefilter = nil
+		total := len(sid.patterns)
+		sid.mutex.Unlock()
+		fmt.Printf("[敏感规则] 从 %s 合并了 %d 条%s规则，当前共 %d 条规则\n", filename, len(rules), format, total)
+		return nil
+	}
+	
 	// 🔧 修复: 使用map[string]interface{}来处理混合类型的JSON
 	var rawConfig map[string]interface{}
 	if err := json.Unmarshal(data, &rawConfig); err != nil {
@@ -186,6 +273,9 @@ func (sid *SensitiveInfoDetector) MergeRulesFromFile(filename string) error {
 		return fmt.Errorf("'rules'字段格式不正确")
 	}
 	
+	sid.mutex.Lock()
+	defer sid.mutex.Unlock()
+	
 	// 合并规则
 	loadedCount := 0
 	for name, ruleInterface := range rulesMap {
@@ -211,6 +301,7 @@ func (sid *SensitiveInfoDetector) MergeRulesFromFile(filename string) error {
 		severity, _ := ruleMap["severity"].(string)
 		mask, _ := ruleMap["mask"].(bool)
 		description, _ := ruleMap["description"].(string)
+		keywords := ruleKeywords(ruleMap["keywords"])
 		
 		if pattern == "" {
 			fmt.Printf("警告: 规则 '%s' 缺少pattern字段，跳过\n", name)
@@ -231,61 +322,196 @@ func (sid *SensitiveInfoDetector) MergeRulesFromFile(filename string) error {
 			Severity:    severity,
 			Mask:        mask,
 			Description: description,
+			Keywords:    keywords,
 		}
 		loadedCount++
 	}
 	
+	sid.prefilter = nil
 	fmt.Printf("[敏感规则] 从 %s 合并了 %d 条规则，当前共 %d 条规则\n", filename, loadedCount, len(sid.patterns))
 	return nil
 }
 
+// ruleKeywords 解析规则文件中的keywords字段（字符串数组）
+func ruleKeywords(value interface{}) []string {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+	keywords := make([]string, 0, len(items))
+	for _, item := range items {
+		if keyword, ok := item.(string); ok {
+			keywords = append(keywords, keyword)
+		}
+	}
+	return keywords
+}
+
 // Scan 扫描内容
 func (sid *SensitiveInfoDetector) Scan(content string, sourceURL string) []*SensitiveInfo {
-	sid.totalScanned++
+	return sid.ScanContent(content, sourceURL, "")
+}
+
+// ScanContent 扫描内容并记录内容类型（🆕 v4.9）
+// 每个发现带行号、列号和上下文片段
+func (sid *SensitiveInfoDetector) ScanContent(content string, sourceURL string, contentType string) []*SensitiveInfo {
 	findings := make([]*SensitiveInfo, 0)
 	
+	// 🆕 v4.9: 关键词预过滤，只对可能命中的规则执行正则
+	// 预过滤器构建后只读，匹配期间不持锁，统计先记在本地、结束后合并
+	prefilter := sid.currentPrefilter()
+	candidates := prefilter.candidates(content)
+	ruleStats := make([]SensitiveRuleStats, len(prefilter.rules))
+	
 	// 分行处理，记录行号
 	lines := strings.Split(content, "\n")
 	
-	for lineNum, line := range lines {
-		for _, pattern := range sid.patterns {
-			matches := pattern.Pattern.FindAllStringSubmatch(line, -1)
+	for ruleIndex, pattern := range prefilter.rules {
+		stats := &ruleStats[ruleIndex]
+		if !candidates[ruleIndex] || (pattern.Path != nil && !pattern.Path.MatchString(sourceURL)) {
+			stats.Skipped++
+			continue
+		}
+		stats.Candidates++
+		started := time.Now()
+		if !requiresMatched(pattern, content) {
+			stats.duration += time.Since(started)
+			continue
+		}
+		
+		for lineNum, line := range lines {
+			var matches [][]int
+			if pattern.SecretGroup != 0 {
+				matches = pattern.Pattern.FindAllStringSubmatchIndex(line, -1)
+			} else {
+				matches = pattern.Pattern.FindAllStringIndex(line, -1)
+			}
 			
 			for _, match := range matches {
-				if len(match) > 0 {
-					// 🔧 修复: 始终使用match[0]（完整匹配）作为敏感信息的完整值
-					// 如果规则需要提取特定部分，应该在规则设计时使用非捕获组(?:...)
-					fullValue := match[0]
-					
-					// 脱敏处理
-					displayValue := fullValue
-					if pattern.Mask {
-						displayValue = sid.maskValue(fullValue)
-					}
-					
-					info := &SensitiveInfo{
-						Type:       pattern.Name,
-						Value:      displayValue,  // 脱敏后的值
-						FullValue:  fullValue,     // 完整的原始值
-						Location:   fmt.Sprintf("Line %d", lineNum+1),
-						Severity:   pattern.Severity,
-						SourceURL:  sourceURL,
-						LineNumber: lineNum + 1,
-					}
-					
-					findings = append(findings, info)
-					sid.totalFindings++
+				// 🔧 修复: 始终使用完整匹配作为敏感信息的完整值
+				// 如果规则需要提取特定部分，应该在规则设计时使用非捕获组(?:...)
+				// 🆕 v4.9: Gitleaks/TruffleHog规则取secretGroup捕获组（未参与匹配时退回完整匹配）
+				start, end := pattern.secretSpan(match)
+				fullValue := line[start:end]
+				
+				// 🆕 v4.9: 熵阈值和白名单
+				if pattern.Entropy > 0 && shannonEntropy(fullValue) <= pattern.Entropy {
+					stats.Allowlisted++
+					continue
+				}
+				if allowlisted(pattern, fullValue, line[match[0]:match[1]], line, sourceURL) {
+					stats.Allowlisted++
+					continue
+				}
+				
+				// 脱敏处理
+				displayValue := fullValue
+				if pattern.Mask {
+					displayValue = sid.maskValue(fullValue)
 				}
+				
+				column := utf8.RuneCountInString(line[:start]) + 1
+				info := &SensitiveInfo{
+					Type:        pattern.Name,
+					Value:       displayValue, // 脱敏后的值
+					FullValue:   fullValue,    // 完整的原始值
+					Location:    fmt.Sprintf("Line %d, Col %d", lineNum+1, column),
+					Severity:    pattern.Severity,
+					SourceURL:   sourceURL,
+					LineNumber:  lineNum + 1,
+					ContentType: contentType,
+					Column:      column,
+					Snippet:     sensitiveSnippet(line, start, end, displayValue),
+				}
+				
+				findings = append(findings, info)
+				stats.Matches++
 			}
 		}
+		stats.duration += time.Since(started)
 	}
 	
-	// 保存到总findings
+	// 保存到总findings并合并统计
+	sid.mutex.Lock()
+	sid.totalScanned++
+	sid.totalFindings += len(findings)
+	for ruleIndex, pattern := range prefilter.rules {
+		sid.statsFor(pattern.Name).add(&ruleStats[ruleIndex])
+	}
 	sid.findings = append(sid.findings, findings...)
+	sid.mutex.Unlock()
 	
 	return findings
 }
 
+// requiresMatched 检查规则要求同时出现的其他正则
+func requiresMatched(pattern *SensitivePattern, content string) bool {
+	for _, required := range pattern.Requires {
+		if !required.MatchString(content) {
+			return false
+		}
+	}
+	return true
+}
+
+// allowlisted 检查匹配是否被规则的任一白名单放行
+func allowlisted(pattern *SensitivePattern, secret, match, line, sourceURL string) bool {
+	for _, allowlist := range pattern.Allowlists {
+		if allowlist.allows(secret, match, line, sourceURL) {
+			return true
+		}
+	}
+	return false
+}
+
+// currentPrefilter 获取当前规则的预过滤器（规则变化后重建）
+func (sid *SensitiveInfoDetector) currentPrefilter() *keywordPrefilter {
+	sid.mutex.Lock()
+	defer sid.mutex.Unlock()
+	if sid.prefilter == nil {
+		sid.prefilter = newKeywordPrefilter(sid.patterns)
+	}
+	return sid.prefilter
+}
+
+// statsFor 获取规则的统计记录（不存在时创建，调用方需持有sid.mutex）
+func (sid *SensitiveInfoDetector) statsFor(name string) *SensitiveRuleStats {
+	stats, exists := sid.ruleStats[name]
+	if !exists {
+		stats = &SensitiveRuleStats{Name: name}
+		sid.ruleStats[name] = stats
+	}
+	return stats
+}
+
+// GetRuleStats 获取当前规则的扫描统计（🆕 v4.9），按正则总耗时降序
+func (sid *SensitiveInfoDetector) GetRuleStats() []SensitiveRuleStats {
+	prefilter := sid.currentPrefilter()
+	sid.mutex.Lock()
+	defer sid.mutex.Unlock()
+	
+	result := make([]SensitiveRuleStats, 0, len(prefilter.rules))
+	for ruleIndex, pattern := range prefilter.rules {
+		stats := *sid.statsFor(pattern.Name)
+		stats.Keywords = prefilter.keywords[ruleIndex]
+		stats.KeywordSource = prefilter.sources[ruleIndex]
+		stats.DurationMS = float64(stats.duration.Microseconds()) / 1000
+		result = append(result, stats)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].duration > result[j].duration
+	})
+	return result
+}
+
+// AddFinding 记录由其他分析器直接判定的发现（🆕 v4.9，如暴露的Source Map）
+func (sid *SensitiveInfoDetector) AddFinding(info *SensitiveInfo) {
+	sid.mutex.Lock()
+	defer sid.mutex.Unlock()
+	sid.findings = append(sid.findings, info)
+	sid.totalFindings++
+}
+
 // ScanResponse 扫描HTTP响应
 func (sid *SensitiveInfoDetector) ScanResponse(content string, headers map[string][]string, sourceURL string) []*SensitiveInfo {
 	allFindings := make([]*SensitiveInfo, 0)
@@ -306,6 +532,34 @@ func (sid *SensitiveInfoDetector) ScanResponse(content string, headers map[strin
 	return allFindings
 }
 
+// sensitiveSnippet 截取匹配值前后的上下文（按UTF-8字符边界截断，值替换为展示值，换行和制表符替换为空格）
+func sensitiveSnippet(line string, start, end int, displayValue string) string {
+	from := start - sensitiveSnippetRadius
+	if from < 0 {
+		from = 0
+	}
+	for from > 0 && !utf8.RuneStart(line[from]) {
+		from--
+	}
+	to := end + sensitiveSnippetRadius
+	if to > len(line) {
+		to = len(line)
+	}
+	for to < len(line) && !utf8.RuneStart(line[to]) {
+		to++
+	}
+	
+	snippet := line[from:start] + displayValue + line[end:to]
+	snippet = strings.NewReplacer("\r", " ", "\t", " ").Replace(snippet)
+	if from > 0 {
+		snippet = "…" + snippet
+	}
+	if to < len(line) {
+		snippet += "…"
+	}
+	return snippet
+}
+
 // maskValue 脱敏处理
 func (sid *SensitiveInfoDetector) maskValue(value string) string {
 	if len(value) <= 8 {
@@ -318,14 +572,16 @@ func (sid *SensitiveInfoDetector) maskValue(value string) string {
 
 // GetFindings 获取所有发现
 func (sid *SensitiveInfoDetector) GetFindings() []*SensitiveInfo {
-	return sid.findings
+	sid.mutex.Lock()
+	defer sid.mutex.Unlock()
+	return append([]*SensitiveInfo(nil), sid.findings...)
 }
 
 // GetFindingsByType 按类型获取发现
 func (sid *SensitiveInfoDetector) GetFindingsByType(infoType string) []*SensitiveInfo {
 	findings := make([]*SensitiveInfo, 0)
 	
-	for _, finding := range sid.findings {
+	for _, finding := range sid.GetFindings() {
 		if finding.Type == infoType {
 			findings = append(findings, finding)
 		}
@@ -338,7 +594,7 @@ func (sid *SensitiveInfoDetector) GetFindingsByType(infoType string) []*Sensitiv
 func (sid *SensitiveInfoDetector) GetFindingsBySeverity(severity string) []*SensitiveInfo {
 	findings := make([]*SensitiveInfo, 0)
 	
-	for _, finding := range sid.findings {
+	for _, finding := range sid.GetFindings() {
 		if finding.Severity == severity {
 			findings = append(findings, finding)
 		}
@@ -351,8 +607,10 @@ func (sid *SensitiveInfoDetector) GetFindingsBySeverity(severity string) []*Sens
 func (sid *SensitiveInfoDetector) GetStatistics() map[string]interface{} {
 	stats := make(map[string]interface{})
 	
+	sid.mutex.Lock()
 	stats["total_scanned"] = sid.totalScanned
 	stats["total_findings"] = sid.totalFindings
+	sid.mutex.Unlock()
 	
 	// 按严重程度统计
 	highCount := len(sid.GetFindingsBySeverity("HIGH"))
@@ -365,7 +623,7 @@ func (sid *SensitiveInfoDetector) GetStatistics() map[string]interface{} {
 	
 	// 按类型统计
 	typeCount := make(map[string]int)
-	for _, finding := range sid.findings {
+	for _, finding := range sid.GetFindings() {
 		typeCount[finding.Type]++
 	}
 	stats["findings_by_type"] = typeCount
@@ -375,7 +633,7 @@ func (sid *SensitiveInfoDetector) GetStatistics() map[string]interface{} {
 
 // GenerateReport 生成报告
 func (sid *SensitiveInfoDetector) GenerateReport() string {
-	if len(sid.findings) == 0 {
+	if len(sid.GetFindings()) == 0 {
 		return "未发现敏感信息泄露"
 	}
 	
@@ -395,6 +653,9 @@ func (sid *SensitiveInfoDetector) GenerateReport() string {
 			report.WriteString(fmt.Sprintf("  [%d] %s\n", i+1, finding.Type))
 			report.WriteString(fmt.Sprintf("      值: %s\n", finding.Value))
 			report.WriteString(fmt.Sprintf("      位置: %s (%s)\n", finding.SourceURL, finding.Location))
+			if finding.Snippet != "" {
+				report.WriteString(fmt.Sprintf("      上下文: %s\n", finding.Snippet))
+			}
 		}
 		report.WriteString("\n")
 	}
@@ -438,19 +699,25 @@ func (sid *SensitiveInfoDetector) GetSummary() string {
 	mediumCount := len(sid.GetFindingsBySeverity("MEDIUM"))
 	lowCount := len(sid.GetFindingsBySeverity("LOW"))
 	
-	if sid.totalFindings == 0 {
+	sid.mutex.Lock()
+	totalFindings := sid.totalFindings
+	sid.mutex.Unlock()
+	if totalFindings == 0 {
 		return "✅ 未发现敏感信息泄露"
 	}
 	
 	return fmt.Sprintf("⚠️  发现 %d 处敏感信息 (高危:%d, 中危:%d, 低危:%d)", 
-		sid.totalFindings, highCount, mediumCount, lowCount)
+		totalFindings, highCount, mediumCount, lowCount)
 }
 
 // Clear 清空发现记录
 func (sid *SensitiveInfoDetector) Clear() {
+	sid.mutex.Lock()
+	defer sid.mutex.Unlock()
 	sid.findings = make([]*SensitiveInfo, 0)
 	sid.totalScanned = 0
 	sid.totalFindings = 0
+	sid.ruleStats = make(map[string]*SensitiveRuleStats)
 }
 
 // AddCustomPattern 添加自定义检测模式
@@ -468,7 +735,7 @@ func (sid *SensitiveInfoDetector) AddCustomPattern(name string, pattern string,
 func (sid *SensitiveInfoDetector) ExportFindings() []map[string]interface{} {
 	exports := make([]map[string]interface{}, 0)
 	
-	for _, finding := range sid.findings {
+	for _, finding := range sid.GetFindings() {
 		export := make(map[string]interface{})
 		export["type"] = finding.Type
 		export["value"] = finding.Value          // 脱敏后的值
@@ -477,6 +744,9 @@ func (sid *SensitiveInfoDetector) ExportFindings() []map[string]interface{} {
 		export["severity"] = finding.Severity
 		export["source_url"] = finding.SourceURL
 		export["line_number"] = finding.LineNumber
+		export["column"] = finding.Column
+		export["content_type"] = finding.ContentType
+		export["snippet"] = finding.Snippet
 		
 		exports = append(exports, export)
 	}
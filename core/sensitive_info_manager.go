@@ -258,10 +258,16 @@ func (sim *SensitiveInfoManager) writeDetailsByServerity(file *os.File, severity
 		file.WriteString(fmt.Sprintf("[%d] %s\n", i+1, finding.Type))
 		file.WriteString(fmt.Sprintf("    来源URL: %s\n", finding.SourceURL))
 		file.WriteString(fmt.Sprintf("    位置: %s\n", finding.Location))
+		if finding.ContentType != "" {
+			file.WriteString(fmt.Sprintf("    内容类型: %s\n", finding.ContentType))
+		}
 		file.WriteString(fmt.Sprintf("    值: %s\n", finding.Value))
 		if finding.FullValue != finding.Value {
 			file.WriteString(fmt.Sprintf("    完整值: %s\n", finding.FullValue))
 		}
+		if finding.Snippet != "" {
+			file.WriteString(fmt.Sprintf("    上下文: %s\n", finding.Snippet))
+		}
 		file.WriteString("\n")
 	}
 	
@@ -326,7 +332,7 @@ func (sim *SensitiveInfoManager) ExportCSV() error {
 	file.Write([]byte{0xEF, 0xBB, 0xBF})
 	
 	// 写入表头
-	headers := []string{"序号", "严重程度", "类型", "来源URL", "位置", "脱敏值", "完整值"}
+	headers := []string{"序号", "严重程度", "类型", "来源URL", "位置", "内容类型", "脱敏值", "完整值", "上下文"}
 	if err := writer.Write(headers); err != nil {
 		return err
 	}
@@ -353,8 +359,10 @@ func (sim *SensitiveInfoManager) ExportCSV() error {
 			finding.Type,
 			finding.SourceURL,
 			finding.Location,
+			finding.ContentType,
 			finding.Value,
 			finding.FullValue,
+			finding.Snippet,
 		}
 		if err := writer.Write(record); err != nil {
 			return err
@@ -520,8 +528,9 @@ func (sim *SensitiveInfoManager) ExportHTML() error {
                 <div class="finding-card">
                     <h3>{{add $index 1}}. {{$finding.Type}}</h3>
                     <div class="finding-detail"><strong>来源URL:</strong> {{$finding.SourceURL}}</div>
-                    <div class="finding-detail"><strong>位置:</strong> {{$finding.Location}}</div>
+                    <div class="finding-detail"><strong>位置:</strong> {{$finding.Location}}{{if $finding.ContentType}} · {{$finding.ContentType}}{{end}}</div>
                     <div class="finding-detail"><strong>值:</strong> <span class="finding-value">{{$finding.Value}}</span></div>
+                    {{if $finding.Snippet}}<div class="finding-detail"><strong>上下文:</strong> <span class="finding-value">{{$finding.Snippet}}</span></div>{{end}}
                 </div>
                 {{end}}
             </div>
@@ -534,8 +543,9 @@ func (sim *SensitiveInfoManager) ExportHTML() error {
                 <div class="finding-card">
                     <h3>{{add $index 1}}. {{$finding.Type}}</h3>
                     <div class="finding-detail"><strong>来源URL:</strong> {{$finding.SourceURL}}</div>
-                    <div class="finding-detail"><strong>位置:</strong> {{$finding.Location}}</div>
+                    <div class="finding-detail"><strong>位置:</strong> {{$finding.Location}}{{if $finding.ContentType}} · {{$finding.ContentType}}{{end}}</div>
                     <div class="finding-detail"><strong>值:</strong> <span class="finding-value">{{$finding.Value}}</span></div>
+                    {{if $finding.Snippet}}<div class="finding-detail"><strong>上下文:</strong> <span class="finding-value">{{$finding.Snippet}}</span></div>{{end}}
                 </div>
                 {{end}}
             </div>
@@ -548,8 +558,9 @@ func (sim *SensitiveInfoManager) ExportHTML() error {
                 <div class="finding-card">
                     <h3>{{add $index 1}}. {{$finding.Type}}</h3>
                     <div class="finding-detail"><strong>来源URL:</strong> {{$finding.SourceURL}}</div>
-                    <div class="finding-detail"><strong>位置:</strong> {{$finding.Location}}</div>
+                    <div class="finding-detail"><strong>位置:</strong> {{$finding.Location}}{{if $finding.ContentType}} · {{$finding.ContentType}}{{end}}</div>
                     <div class="finding-detail"><strong>值:</strong> <span class="finding-value">{{$finding.Value}}</span></div>
+                    {{if $finding.Snippet}}<div class="finding-detail"><strong>上下文:</strong> <span class="finding-value">{{$finding.Snippet}}</span></div>{{end}}
                 </div>
                 {{end}}
             </div>
@@ -0,0 +1,408 @@
+package core
+
+import (
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 🆕 v4.9: 敏感信息规则引擎 - Aho-Corasick关键词预过滤
+//
+// 每条规则声明（或由正则自动推导）若干字面关键词，扫描时先用Aho-Corasick自动机
+// 对内容做一次线性扫描，只有命中关键词的规则才执行正则；无关键词的规则始终执行。
+// 关键词匹配忽略ASCII大小写，因此只会多放行、不会漏掉规则。
+
+const (
+	minPrefilterKeywordLength = 3  // 推导关键词的最短长度（过短的关键词几乎总会命中）
+	maxDerivedKeywords        = 32 // 单条规则最多推导的关键词数（分支过多时不做预过滤）
+)
+
+// SensitiveRuleStats 单条规则的扫描统计
+type SensitiveRuleStats struct {
+	Name          string   `json:"name"`
+	Keywords      []string `json:"keywords,omitempty"`
+	KeywordSource string   `json:"keyword_source"` // declared / derived / none
+	Candidates    int      `json:"candidates"`     // 通过预过滤、实际执行正则的内容数
+	Skipped       int      `json:"skipped"`        // 被预过滤跳过的内容数
+	Matches       int      `json:"matches"`        // 匹配次数
+	Allowlisted   int      `json:"allowlisted"`    // 被白名单或熵阈值丢弃的匹配数
+	DurationMS    float64  `json:"duration_ms"`    // 正则执行总耗时（毫秒）
+
+	duration time.Duration
+}
+
+// add 累加另一份统计（单次扫描的本地统计合并到总统计）
+func (rs *SensitiveRuleStats) add(other *SensitiveRuleStats) {
+	rs.Candidates += other.Candidates
+	rs.Skipped += other.Skipped
+	rs.Matches += other.Matches
+	rs.Allowlisted += other.Allowlisted
+	rs.duration += other.duration
+}
+
+// keywordPrefilter 规则关键词的Aho-Corasick自动机（完全展开为DFA）
+type keywordPrefilter struct {
+	rules    []*SensitivePattern
+	always   []bool     // 无关键词、始终执行的规则
+	keywords [][]string // 每条规则实际使用的关键词
+	sources  []string   // 关键词来源
+
+	classes [256]int32 // 字节 → 字母表下标（0表示不出现在任何关键词中）
+	stride  int32
+	delta   []int32   // state*stride+class → 下一个状态
+	outputs [][]int32 // state → 命中的规则下标（已合并失败链上的输出）
+}
+
+// newKeywordPrefilter 为规则构建预过滤器（规则按名称排序，保证扫描顺序稳定）
+func newKeywordPrefilter(patterns map[string]*SensitivePattern) *keywordPrefilter {
+	kp := &keywordPrefilter{}
+	names := make([]string, 0, len(patterns))
+	for name := range patterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		pattern := patterns[name]
+		source := "declared"
+		keywords := normalizeKeywords(pattern.Keywords)
+		if hasUnicodeCaseKeyword(keywords) {
+			// 声明的关键词按大小写不敏感匹配，而自动机只折叠ASCII，含非ASCII大小写字母时改为始终执行
+			keywords = nil
+		} else if len(keywords) == 0 {
+			source = "derived"
+			keywords = deriveRuleKeywords(pattern.Pattern.String())
+		}
+		if len(keywords) == 0 {
+			source = "none"
+		}
+		kp.rules = append(kp.rules, pattern)
+		kp.keywords = append(kp.keywords, keywords)
+		kp.sources = append(kp.sources, source)
+		kp.always = append(kp.always, len(keywords) == 0)
+	}
+
+	kp.build()
+	return kp
+}
+
+// build 构建字母表、goto表和失败链
+func (kp *keywordPrefilter) build() {
+	// 压缩字母表：只为关键词中出现的字节分配下标
+	next := int32(1)
+	for _, keywords := range kp.keywords {
+		for _, keyword := range keywords {
+			for i := 0; i < len(keyword); i++ {
+				if kp.classes[keyword[i]] == 0 {
+					kp.classes[keyword[i]] = next
+					next++
+				}
+			}
+		}
+	}
+	// 大写ASCII字母与小写共用下标
+	for c := 'A'; c <= 'Z'; c++ {
+		kp.classes[c] = kp.classes[c+'a'-'A']
+	}
+	kp.stride = next
+
+	// 字典树（状态0为根，-1表示尚无转移）
+	kp.delta = make([]int32, kp.stride)
+	for i := range kp.delta {
+		kp.delta[i] = -1
+	}
+	kp.outputs = [][]int32{nil}
+	for ruleIndex, keywords := range kp.keywords {
+		for _, keyword := range keywords {
+			state := int32(0)
+			for i := 0; i < len(keyword); i++ {
+				slot := state*kp.stride + kp.classes[keyword[i]]
+				if kp.delta[slot] < 0 {
+					kp.delta[slot] = int32(len(kp.outputs))
+					kp.outputs = append(kp.outputs, nil)
+					for j := int32(0); j < kp.stride; j++ {
+						kp.delta = append(kp.delta, -1)
+					}
+				}
+				state = kp.delta[slot]
+			}
+			kp.outputs[state] = append(kp.outputs[state], int32(ruleIndex))
+		}
+	}
+
+	// BFS计算失败链，并把缺失的转移补全为失败状态的转移
+	fail := make([]int32, len(kp.outputs))
+	queue := make([]int32, 0, len(kp.outputs))
+	for class := int32(0); class < kp.stride; class++ {
+		if child := kp.delta[class]; child > 0 {
+			fail[child] = 0
+			queue = append(queue, child)
+		} else {
+			kp.delta[class] = 0
+		}
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		kp.outputs[state] = append(kp.outputs[state], kp.outputs[fail[state]]...)
+		for class := int32(0); class < kp.stride; class++ {
+			slot := state*kp.stride + class
+			if child := kp.delta[slot]; child >= 0 {
+				fail[child] = kp.delta[fail[state]*kp.stride+class]
+				queue = append(queue, child)
+			} else {
+				kp.delta[slot] = kp.delta[fail[state]*kp.stride+class]
+			}
+		}
+	}
+}
+
+// candidates 返回需要执行正则的规则（下标与rules一致）
+func (kp *keywordPrefilter) candidates(content string) []bool {
+	hit := make([]bool, len(kp.rules))
+	copy(hit, kp.always)
+	if kp.stride <= 1 {
+		return hit
+	}
+
+	state := int32(0)
+	for i := 0; i < len(content); i++ {
+		state = kp.delta[state*kp.stride+kp.classes[content[i]]]
+		for _, ruleIndex := range kp.outputs[state] {
+			hit[ruleIndex] = true
+		}
+	}
+	return hit
+}
+
+// normalizeKeywords 关键词的ASCII字母转小写并去重（自动机只折叠ASCII大小写，非ASCII字节原样保留）
+func normalizeKeywords(keywords []string) []string {
+	seen := make(map[string]bool, len(keywords))
+	result := make([]string, 0, len(keywords))
+	for _, keyword := range keywords {
+		keyword = asciiLower(strings.TrimSpace(keyword))
+		if keyword == "" || seen[keyword] {
+			continue
+		}
+		seen[keyword] = true
+		result = append(result, keyword)
+	}
+	return result
+}
+
+// deriveRuleKeywords 从正则推导必须出现的字面量集合（任一命中才可能匹配），无法推导时返回nil
+func deriveRuleKeywords(expr string) []string {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	keywords := requiredLiterals(re.Simplify())
+	for _, keyword := range keywords {
+		if len(keyword) < minPrefilterKeywordLength {
+			return nil
+		}
+	}
+	return normalizeKeywords(keywords)
+}
+
+// requiredLiterals 递归求出匹配必经的字面量集合
+func requiredLiterals(re *syntax.Regexp) []string {
+	if literals, ok := exactLiterals(re); ok {
+		return literals
+	}
+	switch re.Op {
+	case syntax.OpCapture, syntax.OpPlus:
+		return requiredLiterals(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min >= 1 {
+			return requiredLiterals(re.Sub[0])
+		}
+		return nil
+	case syntax.OpConcat:
+		// 连续的可枚举子表达式拼成更长的字面量（如 m(?:ysql|ariadb):// → mysql://、mariadb://），
+		// 其余子表达式各自求必经字面量，取最短关键词最长的一组
+		var best []string
+		consider := func(literals []string) {
+			if len(literals) == 0 {
+				return
+			}
+			if len(best) == 0 || shortestLength(literals) > shortestLength(best) ||
+				(shortestLength(literals) == shortestLength(best) && len(literals) < len(best)) {
+				best = literals
+			}
+		}
+		run := []string{""}
+		for _, sub := range re.Sub {
+			if literals, ok := exactLiterals(sub); ok {
+				if product, ok := crossLiterals(run, literals); ok {
+					run = product
+					continue
+				}
+				consider(run)
+				run = literals
+				continue
+			}
+			consider(run)
+			run = []string{""}
+			consider(requiredLiterals(sub))
+		}
+		consider(run)
+		return best
+	case syntax.OpAlternate:
+		var union []string
+		for _, sub := range re.Sub {
+			literals := requiredLiterals(sub)
+			if len(literals) == 0 {
+				return nil
+			}
+			union = append(union, literals...)
+			if len(union) > maxDerivedKeywords {
+				return nil
+			}
+		}
+		return union
+	}
+	return nil
+}
+
+// exactLiterals 子表达式只能匹配有限个字面量时枚举出全部取值（如 gh[ps]_ → ghp_、ghs_）
+func exactLiterals(re *syntax.Regexp) ([]string, bool) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		literal := string(re.Rune)
+		// 预过滤只折叠ASCII大小写，非ASCII的大小写不敏感字面量无法安全使用
+		if re.Flags&syntax.FoldCase != 0 && !isASCII(literal) {
+			return nil, false
+		}
+		return []string{literal}, true
+	case syntax.OpCharClass:
+		// 小字符类（如 [fF]、[ps]）按小写去重后枚举
+		seen := make(map[string]bool)
+		literals := make([]string, 0)
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			if re.Rune[i+1]-re.Rune[i] > 4 || re.Rune[i+1] >= utf8.RuneSelf {
+				return nil, false
+			}
+			for r := re.Rune[i]; r <= re.Rune[i+1]; r++ {
+				literal := strings.ToLower(string(r))
+				if !seen[literal] {
+					seen[literal] = true
+					literals = append(literals, literal)
+				}
+			}
+		}
+		if len(literals) == 0 || len(literals) > 4 {
+			return nil, false
+		}
+		return literals, true
+	case syntax.OpCapture:
+		return exactLiterals(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min != re.Max || re.Min < 1 {
+			return nil, false
+		}
+		sub, ok := exactLiterals(re.Sub[0])
+		if !ok {
+			return nil, false
+		}
+		result := []string{""}
+		for i := 0; i < re.Min; i++ {
+			if result, ok = crossLiterals(result, sub); !ok {
+				return nil, false
+			}
+		}
+		return result, true
+	case syntax.OpConcat:
+		result := []string{""}
+		for _, sub := range re.Sub {
+			literals, ok := exactLiterals(sub)
+			if !ok {
+				return nil, false
+			}
+			if result, ok = crossLiterals(result, literals); !ok {
+				return nil, false
+			}
+		}
+		return result, true
+	case syntax.OpAlternate:
+		var union []string
+		for _, sub := range re.Sub {
+			literals, ok := exactLiterals(sub)
+			if !ok {
+				return nil, false
+			}
+			union = append(union, literals...)
+		}
+		if len(union) > maxDerivedKeywords {
+			return nil, false
+		}
+		return union, true
+	}
+	return nil, false
+}
+
+// crossLiterals 两组字面量做笛卡尔积拼接，超过上限时放弃
+func crossLiterals(prefixes, suffixes []string) ([]string, bool) {
+	if len(prefixes)*len(suffixes) > maxDerivedKeywords {
+		return nil, false
+	}
+	result := make([]string, 0, len(prefixes)*len(suffixes))
+	for _, prefix := range prefixes {
+		for _, suffix := range suffixes {
+			result = append(result, prefix+suffix)
+		}
+	}
+	return result, true
+}
+
+// shortestLength 最短字面量的长度
+func shortestLength(literals []string) int {
+	shortest := len(literals[0])
+	for _, literal := range literals[1:] {
+		if len(literal) < shortest {
+			shortest = len(literal)
+		}
+	}
+	return shortest
+}
+
+// asciiLower 只把ASCII大写字母转为小写
+func asciiLower(s string) string {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 'A' && s[i] <= 'Z' {
+			b := []byte(s)
+			for j := i; j < len(b); j++ {
+				if b[j] >= 'A' && b[j] <= 'Z' {
+					b[j] += 'a' - 'A'
+				}
+			}
+			return string(b)
+		}
+	}
+	return s
+}
+
+// hasUnicodeCaseKeyword 是否有关键词包含存在大小写变体的非ASCII字符
+func hasUnicodeCaseKeyword(keywords []string) bool {
+	for _, keyword := range keywords {
+		for _, r := range keyword {
+			if r >= utf8.RuneSelf && unicode.SimpleFold(r) != r {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isASCII 是否只包含ASCII字符
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,428 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// 🆕 v4.9: 外部密钥规则格式导入
+//
+// 支持通过 -sensitive-rules 直接加载：
+//   - Gitleaks TOML（[[rules]] 的 regex/secretGroup/entropy/keywords/path，规则级和全局 allowlist）
+//   - TruffleHog 自定义检测器 YAML（detectors 的 keywords/regex/entropy/exclude_*）
+//   - RExpository YAML（regular_expressions）
+//
+// 白名单中的 paths 对应来源URL；commits 等仓库相关条件在爬虫中没有意义，忽略。
+
+// 规则文件格式
+const (
+	RuleFormatJSON        = "json"
+	RuleFormatGitleaks    = "gitleaks"
+	RuleFormatTruffleHog  = "trufflehog"
+	RuleFormatRExpository = "rexpository"
+)
+
+// 白名单正则的匹配目标
+const (
+	AllowlistTargetSecret = "secret" // 密钥值（secretGroup，默认）
+	AllowlistTargetMatch  = "match"  // 完整匹配
+	AllowlistTargetLine   = "line"   // 匹配所在行
+)
+
+// SensitiveAllowlist 规则白名单（Gitleaks allowlist / TruffleHog exclude_*）
+type SensitiveAllowlist struct {
+	Description string
+	Regexes     []*regexp.Regexp
+	RegexTarget string           // secret / match / line
+	Stopwords   []string         // 密钥值包含任一停用词（忽略大小写）即放行
+	Paths       []*regexp.Regexp // 匹配来源URL
+	MatchAll    bool             // condition = AND：所有已配置的条件都满足才放行
+}
+
+// allows 判断一次匹配是否被白名单放行
+func (a *SensitiveAllowlist) allows(secret, match, line, sourceURL string) bool {
+	checks, hits := 0, 0
+	if len(a.Paths) > 0 {
+		checks++
+		if anyRegexMatch(a.Paths, sourceURL) {
+			hits++
+		}
+	}
+	if len(a.Regexes) > 0 {
+		checks++
+		target := secret
+		switch a.RegexTarget {
+		case AllowlistTargetMatch:
+			target = match
+		case AllowlistTargetLine:
+			target = line
+		}
+		if anyRegexMatch(a.Regexes, target) {
+			hits++
+		}
+	}
+	if len(a.Stopwords) > 0 {
+		checks++
+		lowerSecret := strings.ToLower(secret)
+		for _, stopword := range a.Stopwords {
+			if strings.Contains(lowerSecret, stopword) {
+				hits++
+				break
+			}
+		}
+	}
+
+	if checks == 0 {
+		return false
+	}
+	if a.MatchAll {
+		return hits == checks
+	}
+	return hits > 0
+}
+
+// anyRegexMatch 任一正则匹配
+func anyRegexMatch(patterns []*regexp.Regexp, s string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// shannonEntropy 计算字符串的香农熵（按字节，单位bit）
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	var counts [256]int
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+	entropy := 0.0
+	length := float64(len(s))
+	for _, count := range counts {
+		if count == 0 {
+			continue
+		}
+		p := float64(count) / length
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// detectRuleFileFormat 根据扩展名和内容识别规则文件格式
+func detectRuleFileFormat(filename string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".toml":
+		return RuleFormatGitleaks
+	case ".yaml", ".yml":
+		if bytes.Contains(data, []byte("regular_expressions:")) {
+			return RuleFormatRExpository
+		}
+		return RuleFormatTruffleHog
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		return RuleFormatJSON
+	}
+	if bytes.Contains(data, []byte("[[rules]]")) {
+		return RuleFormatGitleaks
+	}
+	if bytes.Contains(data, []byte("detectors:")) {
+		return RuleFormatTruffleHog
+	}
+	return RuleFormatJSON
+}
+
+// parseExternalRuleFile 解析非JSON格式的规则文件
+func parseExternalRuleFile(filename string, format string, data []byte) (map[string]*SensitivePattern, error) {
+	switch format {
+	case RuleFormatGitleaks:
+		return parseGitleaksRules(data)
+	case RuleFormatTruffleHog:
+		return parseTruffleHogRules(data)
+	case RuleFormatRExpository:
+		adapter := NewRExRepositoryAdapter(filename)
+		if err := adapter.LoadFromYAML(); err != nil {
+			return nil, err
+		}
+		return adapter.ConvertToGogoSpiderRules()
+	}
+	return nil, fmt.Errorf("不支持的规则格式: %s", format)
+}
+
+// ========== Gitleaks ==========
+
+// gitleaksConfig Gitleaks配置（v8）
+type gitleaksConfig struct {
+	Title      string              `toml:"title"`
+	Extend     map[string]any      `toml:"extend"`
+	Allowlist  *gitleaksAllowlist  `toml:"allowlist"`  // 旧版全局白名单
+	Allowlists []gitleaksAllowlist `toml:"allowlists"` // v8.25+ 全局白名单（可用targetRules限定规则）
+	Rules      []gitleaksRule      `toml:"rules"`
+}
+
+// gitleaksRule Gitleaks规则
+type gitleaksRule struct {
+	ID          string              `toml:"id"`
+	Description string              `toml:"description"`
+	Regex       string              `toml:"regex"`
+	SecretGroup int                 `toml:"secretGroup"`
+	Entropy     float64             `toml:"entropy"`
+	Keywords    []string            `toml:"keywords"`
+	Path        string              `toml:"path"`
+	Tags        []string            `toml:"tags"`
+	Allowlist   *gitleaksAllowlist  `toml:"allowlist"`  // 旧版 [rules.allowlist]
+	Allowlists  []gitleaksAllowlist `toml:"allowlists"` // 新版 [[rules.allowlists]]
+}
+
+// gitleaksAllowlist Gitleaks白名单
+type gitleaksAllowlist struct {
+	Description string   `toml:"description"`
+	Condition   string   `toml:"condition"`
+	Regexes     []string `toml:"regexes"`
+	RegexTarget string   `toml:"regexTarget"`
+	Paths       []string `toml:"paths"`
+	StopWords   []string `toml:"stopwords"`
+	TargetRules []string `toml:"targetRules"`
+}
+
+// parseGitleaksRules 解析Gitleaks TOML规则
+func parseGitleaksRules(data []byte) (map[string]*SensitivePattern, error) {
+	var cfg gitleaksConfig
+	if _, err := toml.Decode(string(data), &cfg); err != nil {
+		return nil, fmt.Errorf("解析Gitleaks规则失败: %v", err)
+	}
+	if len(cfg.Extend) > 0 {
+		fmt.Println("警告: Gitleaks [extend] 不会自动加载默认/外部配置，只导入本文件中的规则")
+	}
+
+	// 全局白名单（targetRules为空时作用于所有规则）
+	type globalAllowlist struct {
+		allowlist *SensitiveAllowlist
+		targets   map[string]bool
+	}
+	globals := make([]globalAllowlist, 0)
+	rawGlobals := cfg.Allowlists
+	if cfg.Allowlist != nil {
+		rawGlobals = append([]gitleaksAllowlist{*cfg.Allowlist}, rawGlobals...)
+	}
+	for _, raw := range rawGlobals {
+		allowlist, err := raw.compile()
+		if err != nil {
+			return nil, fmt.Errorf("全局白名单: %v", err)
+		}
+		var targets map[string]bool
+		if len(raw.TargetRules) > 0 {
+			targets = make(map[string]bool, len(raw.TargetRules))
+			for _, id := range raw.TargetRules {
+				targets[id] = true
+			}
+		}
+		globals = append(globals, globalAllowlist{allowlist, targets})
+	}
+
+	rules := make(map[string]*SensitivePattern)
+	skipped := 0
+	for _, rule := range cfg.Rules {
+		// 只按文件路径匹配的规则（无regex）不适用于爬虫
+		if rule.ID == "" || rule.Regex == "" {
+			skipped++
+			continue
+		}
+		compiled, err := regexp.Compile(rule.Regex)
+		if err != nil {
+			fmt.Printf("警告: Gitleaks规则 '%s' 的正则表达式编译失败: %v\n", rule.ID, err)
+			continue
+		}
+
+		pattern := &SensitivePattern{
+			Name:        rule.ID,
+			Pattern:     compiled,
+			Severity:    "HIGH",
+			Mask:        true,
+			Description: rule.Description,
+			Keywords:    rule.Keywords,
+			SecretGroup: rule.SecretGroup,
+			Entropy:     rule.Entropy,
+		}
+		// 未指定secretGroup时与Gitleaks一致：有捕获组则取第一个非空捕获组
+		if pattern.SecretGroup == 0 && compiled.NumSubexp() > 0 {
+			pattern.SecretGroup = SecretGroupFirstNonEmpty
+		}
+		if rule.Path != "" {
+			if pattern.Path, err = regexp.Compile(rule.Path); err != nil {
+				fmt.Printf("警告: Gitleaks规则 '%s' 的path编译失败: %v\n", rule.ID, err)
+				continue
+			}
+		}
+
+		rawAllowlists := rule.Allowlists
+		if rule.Allowlist != nil {
+			rawAllowlists = append([]gitleaksAllowlist{*rule.Allowlist}, rawAllowlists...)
+		}
+		for _, raw := range rawAllowlists {
+			allowlist, err := raw.compile()
+			if err != nil {
+				return nil, fmt.Errorf("规则 '%s' 的白名单: %v", rule.ID, err)
+			}
+			pattern.Allowlists = append(pattern.Allowlists, allowlist)
+		}
+		for _, global := range globals {
+			if global.targets == nil || global.targets[rule.ID] {
+				pattern.Allowlists = append(pattern.Allowlists, global.allowlist)
+			}
+		}
+
+		rules[rule.ID] = pattern
+	}
+
+	if skipped > 0 {
+		fmt.Printf("[敏感规则] 跳过 %d 条只匹配文件路径的Gitleaks规则\n", skipped)
+	}
+	return rules, nil
+}
+
+// compile 编译Gitleaks白名单
+func (raw gitleaksAllowlist) compile() (*SensitiveAllowlist, error) {
+	allowlist := &SensitiveAllowlist{
+		Description: raw.Description,
+		RegexTarget: strings.ToLower(raw.RegexTarget),
+		MatchAll:    strings.EqualFold(strings.TrimSpace(raw.Condition), "AND"),
+	}
+	if allowlist.RegexTarget == "" {
+		allowlist.RegexTarget = AllowlistTargetSecret
+	}
+	var err error
+	if allowlist.Regexes, err = compileRegexList(raw.Regexes); err != nil {
+		return nil, err
+	}
+	if allowlist.Paths, err = compileRegexList(raw.Paths); err != nil {
+		return nil, err
+	}
+	allowlist.Stopwords = normalizeKeywords(raw.StopWords)
+	return allowlist, nil
+}
+
+// compileRegexList 编译正则列表
+func compileRegexList(exprs []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(exprs))
+	for _, expr := range exprs {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("正则 %q 编译失败: %v", expr, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// ========== TruffleHog ==========
+
+// truffleHogConfig TruffleHog自定义检测器配置
+type truffleHogConfig struct {
+	Detectors []truffleHogDetector `yaml:"detectors"`
+}
+
+// truffleHogDetector TruffleHog自定义检测器（verify等在线验证字段忽略）
+type truffleHogDetector struct {
+	Name                  string            `yaml:"name"`
+	Keywords              []string          `yaml:"keywords"`
+	Regex                 map[string]string `yaml:"regex"`
+	Entropy               float64           `yaml:"entropy"`
+	ExcludeWords          []string          `yaml:"exclude_words"`
+	ExcludeRegexesMatch   []string          `yaml:"exclude_regexes_match"`
+	ExcludeRegexesCapture []string          `yaml:"exclude_regexes_capture"`
+}
+
+// parseTruffleHogRules 解析TruffleHog自定义检测器YAML
+// 检测器的多个regex需要在同一内容中同时出现：按名称排序后第一个作为报告的主正则，其余作为必须同时匹配的条件
+func parseTruffleHogRules(data []byte) (map[string]*SensitivePattern, error) {
+	var cfg truffleHogConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("解析TruffleHog检测器失败: %v", err)
+	}
+	if len(cfg.Detectors) == 0 {
+		return nil, fmt.Errorf("TruffleHog检测器文件中未找到'detectors'")
+	}
+
+	rules := make(map[string]*SensitivePattern)
+	for _, detector := range cfg.Detectors {
+		if detector.Name == "" || len(detector.Regex) == 0 {
+			fmt.Printf("警告: TruffleHog检测器 '%s' 缺少name或regex，跳过\n", detector.Name)
+			continue
+		}
+
+		names := make([]string, 0, len(detector.Regex))
+		for name := range detector.Regex {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		exprs := make([]string, len(names))
+		for i, name := range names {
+			exprs[i] = detector.Regex[name]
+		}
+		compiled, err := compileRegexList(exprs)
+		if err != nil {
+			fmt.Printf("警告: TruffleHog检测器 '%s' %v，跳过\n", detector.Name, err)
+			continue
+		}
+
+		pattern := &SensitivePattern{
+			Name:        detector.Name,
+			Pattern:     compiled[0],
+			Severity:    "HIGH",
+			Mask:        true,
+			Description: "[TruffleHog] " + detector.Name,
+			Keywords:    detector.Keywords,
+			Entropy:     detector.Entropy,
+			Requires:    compiled[1:],
+		}
+		if compiled[0].NumSubexp() > 0 {
+			pattern.SecretGroup = 1
+		}
+
+		if len(detector.ExcludeWords) > 0 {
+			pattern.Allowlists = append(pattern.Allowlists, &SensitiveAllowlist{
+				Description: "exclude_words",
+				RegexTarget: AllowlistTargetSecret,
+				Stopwords:   normalizeKeywords(detector.ExcludeWords),
+			})
+		}
+		for _, exclude := range []struct {
+			name   string
+			exprs  []string
+			target string
+		}{
+			{"exclude_regexes_match", detector.ExcludeRegexesMatch, AllowlistTargetMatch},
+			{"exclude_regexes_capture", detector.ExcludeRegexesCapture, AllowlistTargetSecret},
+		} {
+			if len(exclude.exprs) == 0 {
+				continue
+			}
+			regexes, err := compileRegexList(exclude.exprs)
+			if err != nil {
+				return nil, fmt.Errorf("检测器 '%s' 的排除规则: %v", detector.Name, err)
+			}
+			pattern.Allowlists = append(pattern.Allowlists, &SensitiveAllowlist{
+				Description: exclude.name,
+				Regexes:     regexes,
+				RegexTarget: exclude.target,
+			})
+		}
+
+		rules[detector.Name] = pattern
+	}
+	return rules, nil
+}
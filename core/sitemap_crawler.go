@@ -65,6 +65,20 @@ func NewSitemapCrawler() *SitemapCrawler {
 	}
 }
 
+// SetAuthManager 设置认证管理器（v4.9新增）
+func (sc *SitemapCrawler) SetAuthManager(am *AuthManager) {
+	if am != nil {
+		am.ApplyToClient(sc.client)
+	}
+}
+
+// SetHARRecorder 设置HAR流量记录器（v4.9新增）（需在SetAuthManager之后调用）
+func (sc *SitemapCrawler) SetHARRecorder(hr *HARRecorder) {
+	if hr != nil {
+		hr.ApplyToClient(sc.client, HARSourceProbe)
+	}
+}
+
 // CrawlSitemap 爬取sitemap.xml
 func (sc *SitemapCrawler) CrawlSitemap(baseURL string) []string {
 	allURLs := make([]string, 0)
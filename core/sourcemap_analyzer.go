@@ -0,0 +1,526 @@
+package core
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"spider-golang/config"
+)
+
+// Source Map发现方式
+const (
+	SourceMapDiscoveryHeader  = "header"  // SourceMap / X-SourceMap 响应头
+	SourceMapDiscoveryComment = "comment" // 脚本末尾的 //# sourceMappingURL=
+	SourceMapDiscoveryInline  = "inline"  // sourceMappingURL=data:...（内联）
+	SourceMapDiscoveryProbe   = "probe"   // 探测 <脚本URL>.map
+)
+
+// LinkSourceSourceMap 从Source Map还原的源码中提取的链接
+const LinkSourceSourceMap = "sourcemap"
+
+// SourceMapAnalyzer Source Map还原器（v4.9新增）
+// 收集目标脚本引用的Source Map，下载后按 sources/sourcesContent 还原原始文件树，
+// 还原的源码交给JS分析器和敏感信息检测器
+type SourceMapAnalyzer struct {
+	settings config.SourceMapSettings
+	client   *http.Client
+
+	candidates  []*sourceMapCandidate
+	seenScripts map[string]bool
+	seenMaps    map[string]bool
+
+	analyses []*SourceMapAnalysis
+	mutex    sync.Mutex
+}
+
+// sourceMapCandidate 待下载的Source Map
+type sourceMapCandidate struct {
+	scriptURL string
+	mapURL    string
+	discovery string
+	inline    []byte // 内联Source Map的内容
+}
+
+// SourceMapAnalysis 单个Source Map的还原结果
+type SourceMapAnalysis struct {
+	ScriptURL  string             `json:"script_url"`
+	MapURL     string             `json:"map_url,omitempty"` // 内联Source Map为空
+	Discovery  string             `json:"discovery"`         // header / comment / inline / probe
+	Size       int64              `json:"size"`
+	SourceRoot string             `json:"source_root,omitempty"`
+	Sources    []*RecoveredSource `json:"sources"`
+	Recovered  int                `json:"recovered"`            // 含原始内容的源文件数
+	OutputDir  string             `json:"output_dir,omitempty"` // 源码保存目录
+	Error      string             `json:"error,omitempty"`
+}
+
+// RecoveredSource 还原的源文件
+type RecoveredSource struct {
+	Path    string `json:"path"`           // sources[] 中的原始路径
+	File    string `json:"file,omitempty"` // 保存到磁盘的文件（相对输出目录）
+	Size    int    `json:"size"`
+	Vendor  bool   `json:"vendor,omitempty"` // 第三方依赖（node_modules等）
+	Content string `json:"-"`
+}
+
+// sourceMapV3 Source Map v3格式（含索引映射sections）
+type sourceMapV3 struct {
+	Version        int                `json:"version"`
+	File           string             `json:"file"`
+	SourceRoot     string             `json:"sourceRoot"`
+	Sources        []string           `json:"sources"`
+	SourcesContent []*string          `json:"sourcesContent"`
+	Sections       []sourceMapSection `json:"sections"`
+}
+
+type sourceMapSection struct {
+	Map *sourceMapV3 `json:"map"`
+}
+
+// sourceMappingURLPattern 匹配 //# sourceMappingURL= 和 /*# sourceMappingURL= */（兼容旧的 //@ 写法）
+var sourceMappingURLPattern = regexp.MustCompile(`(?:/\*|//)[#@][ \t]*sourceMappingURL[ \t]*=[ \t]*([^\s'"*]+)`)
+
+// NewSourceMapAnalyzer 创建Source Map还原器
+func NewSourceMapAnalyzer(settings config.SourceMapSettings, insecureSkipVerify bool) *SourceMapAnalyzer {
+	if settings.MaxMapSize <= 0 {
+		settings.MaxMapSize = 20 * 1024 * 1024
+	}
+	if settings.MaxMaps <= 0 {
+		settings.MaxMaps = 100
+	}
+	if settings.Timeout <= 0 {
+		settings.Timeout = 30
+	}
+	if settings.Concurrency <= 0 {
+		settings.Concurrency = 5
+	}
+
+	transport := &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: insecureSkipVerify},
+	}
+
+	return &SourceMapAnalyzer{
+		settings: settings,
+		client: &http.Client{
+			Timeout:   time.Duration(settings.Timeout) * time.Second,
+			Transport: transport,
+		},
+		candidates:  make([]*sourceMapCandidate, 0),
+		seenScripts: make(map[string]bool),
+		seenMaps:    make(map[string]bool),
+		analyses:    make([]*SourceMapAnalysis, 0),
+	}
+}
+
+// SetAuthManager 设置认证管理器
+func (sma *SourceMapAnalyzer) SetAuthManager(am *AuthManager) {
+	if am != nil {
+		am.ApplyToClient(sma.client)
+	}
+}
+
+// SetHARRecorder 设置HAR流量记录器（需在SetAuthManager之后调用）
+func (sma *SourceMapAnalyzer) SetHARRecorder(hr *HARRecorder) {
+	if hr != nil {
+		hr.ApplyToClient(sma.client, HARSourceProbe)
+	}
+}
+
+// Register 登记一个脚本，从响应头或脚本末尾的注释中找出Source Map地址
+// sourceMapHeader 为 SourceMap / X-SourceMap 响应头的值（可为空）
+func (sma *SourceMapAnalyzer) Register(scriptURL, jsCode, sourceMapHeader string) {
+	sma.mutex.Lock()
+	defer sma.mutex.Unlock()
+
+	if scriptURL == "" || sma.seenScripts[scriptURL] {
+		return
+	}
+	sma.seenScripts[scriptURL] = true
+
+	candidate := &sourceMapCandidate{scriptURL: scriptURL}
+	reference := strings.TrimSpace(sourceMapHeader)
+	candidate.discovery = SourceMapDiscoveryHeader
+	if reference == "" {
+		reference = findSourceMappingURL(jsCode)
+		candidate.discovery = SourceMapDiscoveryComment
+	}
+
+	switch {
+	case strings.HasPrefix(strings.ToLower(reference), "data:"):
+		data, err := decodeDataURL(reference)
+		if err != nil {
+			return
+		}
+		candidate.discovery = SourceMapDiscoveryInline
+		candidate.inline = data
+	case reference != "":
+		mapURL, ok := resolveAgainst(scriptURL, reference)
+		if !ok {
+			return
+		}
+		candidate.mapURL = mapURL
+	case sma.settings.ProbeMapSuffix:
+		u, err := url.Parse(scriptURL)
+		if err != nil {
+			return
+		}
+		u.RawQuery = ""
+		u.Fragment = ""
+		candidate.mapURL = u.String() + ".map"
+		candidate.discovery = SourceMapDiscoveryProbe
+	default:
+		return
+	}
+
+	if candidate.mapURL != "" {
+		if sma.seenMaps[candidate.mapURL] {
+			return
+		}
+		sma.seenMaps[candidate.mapURL] = true
+	}
+	sma.candidates = append(sma.candidates, candidate)
+}
+
+// AnalyzeAll 并发下载并解析已登记的Source Map，返回本次新增的结果
+// 探测（probe）失败的脚本不计入结果
+func (sma *SourceMapAnalyzer) AnalyzeAll() []*SourceMapAnalysis {
+	sma.mutex.Lock()
+	candidates := sma.candidates
+	sma.candidates = make([]*sourceMapCandidate, 0)
+	sma.mutex.Unlock()
+
+	if len(candidates) > sma.settings.MaxMaps {
+		fmt.Printf("  [Source Map] 发现 %d 个Source Map，只处理前 %d 个\n", len(candidates), sma.settings.MaxMaps)
+		candidates = candidates[:sma.settings.MaxMaps]
+	}
+
+	results := make([]*SourceMapAnalysis, len(candidates))
+	sem := make(chan struct{}, sma.settings.Concurrency)
+	var wg sync.WaitGroup
+	for i, candidate := range candidates {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, candidate *sourceMapCandidate) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = sma.analyze(candidate)
+		}(i, candidate)
+	}
+	wg.Wait()
+
+	analyses := make([]*SourceMapAnalysis, 0, len(results))
+	for _, analysis := range results {
+		if analysis.Discovery == SourceMapDiscoveryProbe && analysis.Error != "" {
+			continue
+		}
+		analyses = append(analyses, analysis)
+	}
+
+	sma.mutex.Lock()
+	sma.analyses = append(sma.analyses, analyses...)
+	sma.mutex.Unlock()
+	return analyses
+}
+
+// analyze 下载并解析单个Source Map
+func (sma *SourceMapAnalyzer) analyze(candidate *sourceMapCandidate) *SourceMapAnalysis {
+	analysis := &SourceMapAnalysis{
+		ScriptURL: candidate.scriptURL,
+		MapURL:    candidate.mapURL,
+		Discovery: candidate.discovery,
+		Sources:   make([]*RecoveredSource, 0),
+	}
+
+	data := candidate.inline
+	if data == nil {
+		var err error
+		data, err = sma.download(candidate.mapURL)
+		if err != nil {
+			analysis.Error = err.Error()
+			return analysis
+		}
+	}
+	analysis.Size = int64(len(data))
+
+	// 部分服务器在Source Map前加 )]}' 防止JSON劫持
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte(")]}")) {
+		if idx := bytes.IndexByte(data, '\n'); idx >= 0 {
+			data = data[idx+1:]
+		}
+	}
+
+	var sm sourceMapV3
+	if err := json.Unmarshal(data, &sm); err != nil || (sm.Version == 0 && len(sm.Sources) == 0 && len(sm.Sections) == 0) {
+		analysis.Error = "不是有效的Source Map"
+		return analysis
+	}
+	analysis.SourceRoot = sm.SourceRoot
+
+	sma.collectSources(analysis, &sm)
+	for _, source := range analysis.Sources {
+		if source.Content != "" {
+			analysis.Recovered++
+		}
+	}
+	return analysis
+}
+
+// collectSources 收集源文件（索引映射递归展开sections）
+func (sma *SourceMapAnalyzer) collectSources(analysis *SourceMapAnalysis, sm *sourceMapV3) {
+	for i, source := range sm.Sources {
+		sourcePath := source
+		if sm.SourceRoot != "" && !strings.Contains(source, "://") && !strings.HasPrefix(source, "/") {
+			sourcePath = strings.TrimRight(sm.SourceRoot, "/") + "/" + source
+		}
+		recovered := &RecoveredSource{
+			Path:   sourcePath,
+			Vendor: isVendorSource(sourcePath),
+		}
+		if i < len(sm.SourcesContent) && sm.SourcesContent[i] != nil {
+			recovered.Content = *sm.SourcesContent[i]
+			recovered.Size = len(recovered.Content)
+		}
+		analysis.Sources = append(analysis.Sources, recovered)
+	}
+	for _, section := range sm.Sections {
+		if section.Map != nil {
+			sma.collectSources(analysis, section.Map)
+		}
+	}
+}
+
+// download 下载Source Map（限制大小）
+func (sma *SourceMapAnalyzer) download(mapURL string) ([]byte, error) {
+	req, err := http.NewRequest("GET", mapURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+
+	resp, err := sma.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	if resp.ContentLength > sma.settings.MaxMapSize {
+		return nil, fmt.Errorf("文件过大: %d 字节（上限 %d）", resp.ContentLength, sma.settings.MaxMapSize)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, sma.settings.MaxMapSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > sma.settings.MaxMapSize {
+		return nil, fmt.Errorf("文件过大: 超过 %d 字节", sma.settings.MaxMapSize)
+	}
+	return data, nil
+}
+
+// SaveSources 将还原的源码按原始目录结构写入 dir/<主机名>/，返回写入的文件数
+// 单个源文件写入失败（如不同Source Map的路径冲突、文件名过长）时跳过该文件继续写入，
+// 所有失败合并为一个error返回
+func (sma *SourceMapAnalyzer) SaveSources(dir string) (int, error) {
+	sma.mutex.Lock()
+	defer sma.mutex.Unlock()
+
+	written := 0
+	var errs []error
+	contents := make(map[string]string) // 已写入文件 → 内容（多个bundle常包含相同源文件）
+	for _, analysis := range sma.analyses {
+		if analysis.Recovered == 0 {
+			continue
+		}
+		host := "unknown"
+		if u, err := url.Parse(analysis.ScriptURL); err == nil && u.Host != "" {
+			host = sanitizePathSegment(u.Host)
+		}
+		analysis.OutputDir = filepath.Join(dir, host)
+
+		for i, source := range analysis.Sources {
+			if source.Content == "" {
+				continue
+			}
+			rel := path.Join(host, sanitizeSourcePath(source.Path, i))
+			if existing, ok := contents[rel]; ok {
+				if existing == source.Content {
+					source.File = rel
+					continue
+				}
+				rel = uniqueSourcePath(rel, contents)
+			}
+
+			target := filepath.Join(dir, filepath.FromSlash(rel))
+			err := os.MkdirAll(filepath.Dir(target), 0755)
+			if err == nil {
+				err = os.WriteFile(target, []byte(source.Content), 0644)
+			}
+			if err != nil {
+				fmt.Printf("  [SourceMap] 跳过源文件 %s: %v\n", source.Path, err)
+				errs = append(errs, fmt.Errorf("%s: %w", source.Path, err))
+				continue
+			}
+			contents[rel] = source.Content
+			source.File = rel
+			written++
+		}
+	}
+	return written, errors.Join(errs...)
+}
+
+// GetAnalyses 获取所有还原结果
+func (sma *SourceMapAnalyzer) GetAnalyses() []*SourceMapAnalysis {
+	sma.mutex.Lock()
+	defer sma.mutex.Unlock()
+	return sma.analyses
+}
+
+// findSourceMappingURL 取脚本中最后一个sourceMappingURL（规范要求位于末尾）
+func findSourceMappingURL(jsCode string) string {
+	// 只检查末尾部分，避免在大文件中全文匹配
+	const tailSize = 4096
+	tail := jsCode
+	if len(tail) > tailSize {
+		tail = tail[len(tail)-tailSize:]
+	}
+	matches := sourceMappingURLPattern.FindAllStringSubmatch(tail, -1)
+	if len(matches) == 0 {
+		// 内联Source Map可能远超末尾窗口
+		if idx := strings.LastIndex(jsCode, "sourceMappingURL=data:"); idx >= 0 {
+			matches = sourceMappingURLPattern.FindAllStringSubmatch(jsCode[max(0, idx-4):], -1)
+		}
+	}
+	if len(matches) == 0 {
+		return ""
+	}
+	return matches[len(matches)-1][1]
+}
+
+// decodeDataURL 解码 data: URL（支持base64和百分号编码）
+func decodeDataURL(dataURL string) ([]byte, error) {
+	comma := strings.IndexByte(dataURL, ',')
+	if comma < 0 {
+		return nil, fmt.Errorf("无效的data URL")
+	}
+	meta, payload := dataURL[len("data:"):comma], dataURL[comma+1:]
+	if strings.HasSuffix(strings.ToLower(meta), ";base64") {
+		if data, err := base64.StdEncoding.DecodeString(payload); err == nil {
+			return data, nil
+		}
+		return base64.RawStdEncoding.DecodeString(strings.TrimRight(payload, "="))
+	}
+	decoded, err := url.PathUnescape(payload)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(decoded), nil
+}
+
+// resolveAgainst 将引用解析为相对于脚本URL的绝对地址
+func resolveAgainst(baseURL, reference string) (string, bool) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", false
+	}
+	ref, err := url.Parse(reference)
+	if err != nil {
+		return "", false
+	}
+	resolved := base.ResolveReference(ref)
+	if resolved.Scheme != "http" && resolved.Scheme != "https" {
+		return "", false
+	}
+	return resolved.String(), true
+}
+
+// isVendorSource 判断源文件是否为第三方依赖或打包器运行时
+func isVendorSource(sourcePath string) bool {
+	lower := strings.ToLower(sourcePath)
+	return strings.Contains(lower, "node_modules/") ||
+		strings.Contains(lower, "/~/") ||
+		strings.Contains(lower, "(webpack)") ||
+		strings.HasPrefix(lower, "webpack/") ||
+		strings.HasPrefix(lower, "webpack:///webpack/") ||
+		strings.Contains(lower, "/bower_components/")
+}
+
+// sanitizeSourcePath 将Source Map中的源路径转换为安全的相对文件路径
+// 去掉 webpack:// 等协议前缀和查询串，折叠 ../ 使文件不会写到输出目录之外
+func sanitizeSourcePath(sourcePath string, index int) string {
+	p := strings.ReplaceAll(sourcePath, "\\", "/")
+	if idx := strings.Index(p, "://"); idx >= 0 {
+		p = p[idx+3:]
+	}
+	if idx := strings.IndexAny(p, "?#"); idx >= 0 {
+		p = p[:idx]
+	}
+
+	segments := make([]string, 0)
+	for _, segment := range strings.Split(path.Clean("/"+p), "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			continue
+		}
+		segments = append(segments, sanitizePathSegment(segment))
+	}
+	if len(segments) == 0 {
+		return fmt.Sprintf("source_%d.js", index)
+	}
+	return strings.Join(segments, "/")
+}
+
+// sanitizePathSegment 替换文件名中不可移植的字符
+func sanitizePathSegment(segment string) string {
+	segment = strings.Map(func(r rune) rune {
+		switch r {
+		case ':', '*', '?', '"', '<', '>', '|', 0:
+			return '_'
+		}
+		return r
+	}, segment)
+	if len(segment) > 200 {
+		segment = segment[:200]
+	}
+	return segment
+}
+
+// uniqueSourcePath 为同名但内容不同的源文件生成新文件名（name~2.js）
+func uniqueSourcePath(rel string, used map[string]string) string {
+	ext := path.Ext(rel)
+	stem := strings.TrimSuffix(rel, ext)
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s~%d%s", stem, n, ext)
+		if _, ok := used[candidate]; !ok {
+			return candidate
+		}
+	}
+}
+
+// SourceMapHeader 从响应头中取SourceMap地址（键名大小写不敏感）
+func SourceMapHeader(headers map[string]string) string {
+	for key, value := range headers {
+		switch strings.ToLower(key) {
+		case "sourcemap", "x-sourcemap":
+			return value
+		}
+	}
+	return ""
+}
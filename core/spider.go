@@ -1,11 +1,13 @@
 package core
 
 import (
+	"crypto/sha1"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"log/slog"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -42,6 +44,8 @@ type Spider struct {
 	staticCrawler       StaticCrawler
 	dynamicCrawler      DynamicCrawler
 	jsAnalyzer          *JSAnalyzer
+	jsSandbox           *JSSandbox       // 🆕 v4.9: 反混淆沙箱（未启用时为nil，Close时结束工作进程）
+	chunkEnumerator     *ChunkEnumerator // 🆕 v4.9: Webpack/Vite分块枚举
 	paramHandler        *ParamHandler
 	duplicateHandler    *DuplicateHandler
 	smartDeduplication  *SmartDeduplication
@@ -63,6 +67,8 @@ type Spider struct {
 	sensitiveDetector  *SensitiveInfoDetector // 敏感信息检测器
 	sensitiveManager   *SensitiveInfoManager  // 🆕 v4.2: 敏感信息统一管理器
 	passiveCrawler     *PassiveCrawler        // 被动爬取器
+	trafficImports     []trafficImport        // 🆕 v4.9: 待导入的流量文件
+	importedSeeds      []string               // 🆕 v4.9: 导入流量中没有响应的URL（递归爬取种子）
 	subdomainExtractor *SubdomainExtractor    // 子域名提取器
 	domSimilarity      *DOMSimilarityDetector // DOM相似度检测器
 	sitemapCrawler     *SitemapCrawler        // Sitemap爬取器
@@ -144,10 +150,50 @@ type Spider struct {
 	
 	// 🆕 v4.4: 请求日志记录器
 	requestLogger *RequestLogger // 请求日志记录器（用于调试优化）
+	
+	// 🆕 v4.9: HTTP认证管理器
+	authManager *AuthManager // Basic/Digest/Bearer/OAuth2/mTLS认证
+	
+	// 🆕 v4.9: 文档分析器（PDF/Office）
+	documentAnalyzer *DocumentAnalyzer
+	documentResults  map[string]*Result // 已分析的文档 → 文档结果（正在分析时为nil）
+	
+	// 🆕 v4.9: Source Map还原器
+	sourceMapAnalyzer *SourceMapAnalyzer
+	
+	// 🆕 v4.9: 前端组件漏洞扫描器
+	jsLibScanner *JSLibraryScanner
+	
+	// 🆕 v4.9: 已解析的API定义（Swagger/OpenAPI/WSDL/WADL）
+	apiDefinitions    []*APIDefinition
+	apiDefinitionSeen map[string]bool // 已处理的定义URL和内容哈希
+	
+	// 🆕 v4.9: GraphQL端点和操作收集器
+	graphqlDiscovery *GraphQLDiscovery
+	
+	// 🆕 v4.9: SOAP/gRPC-Web服务检测器
+	serviceDetector *APIServiceDetector
+	
+	// 🆕 v4.9: HAR流量记录器（静态爬虫、无头浏览器和探测请求共用）
+	harRecorder *HARRecorder
+	
+	// 🆕 v4.9: 爬取结束后构建的被动API分析结果（OpenAPI/Schema导出共用）
+	observedAPIs    *APIAnalyzer
+	apiAnalyzerOnce sync.Once
 }
 
 // NewSpider 创建爬虫实例
-func NewSpider(cfg *config.Config) *Spider {
+// 🆕 v4.9: 启用认证但认证管理器初始化失败时返回错误（避免在未认证状态下爬取需要认证的目标）
+func NewSpider(cfg *config.Config) (*Spider, error) {
+	var authManager *AuthManager
+	if cfg.AuthSettings.Enabled {
+		am, err := NewAuthManager(cfg.AuthSettings, cfg.AntiDetectionSettings.InsecureSkipVerify)
+		if err != nil {
+			return nil, fmt.Errorf("认证管理器初始化失败: %v", err)
+		}
+		authManager = am
+	}
+
 	// v2.6: 创建日志记录器
 	var logOutput io.Writer = os.Stdout
 	if cfg.LogSettings.OutputFile != "" {
@@ -181,6 +227,7 @@ func NewSpider(cfg *config.Config) *Spider {
 		staticCrawler:      NewStaticCrawler(cfg, resultChan, stopChan),
 		dynamicCrawler:     NewDynamicCrawler(),
 		jsAnalyzer:         NewJSAnalyzer(),
+		chunkEnumerator:    NewChunkEnumerator(),
 		paramHandler:       NewParamHandler(),
 		duplicateHandler:   NewDuplicateHandler(cfg.DeduplicationSettings.SimilarityThreshold),
 		smartDeduplication: NewSmartDeduplication(),                                                                                                             // 初始化智能去重
@@ -327,9 +374,88 @@ func NewSpider(cfg *config.Config) *Spider {
 	if staticCrawlerImpl, ok := spider.staticCrawler.(*StaticCrawlerImpl); ok {
 		staticCrawlerImpl.SetCookieManager(spider.cookieManager)
 		staticCrawlerImpl.SetRedirectManager(spider.redirectManager)
+		staticCrawlerImpl.SetJSASTAnalyzer(spider.jsAnalyzer.ASTAnalyzer())
+	}
+	
+	// 🆕 v4.9: 初始化HTTP认证（作用于静态爬虫、无头浏览器和辅助探测器）
+	if authManager != nil {
+		spider.authManager = authManager
+		if staticCrawlerImpl, ok := spider.staticCrawler.(*StaticCrawlerImpl); ok {
+			staticCrawlerImpl.SetAuthManager(authManager)
+		}
+		if dynamicCrawlerImpl, ok := spider.dynamicCrawler.(*DynamicCrawlerImpl); ok {
+			dynamicCrawlerImpl.SetAuthManager(authManager)
+		}
+		spider.sitemapCrawler.SetAuthManager(authManager)
+		spider.perfOptimizer.SetAuthManager(authManager)
+	}
+	
+	// 🆕 v4.9: 文档分析器（下载PDF/Office文档提取链接、文本和元数据）
+	if cfg.DocumentAnalysisSettings.Enabled {
+		spider.documentAnalyzer = NewDocumentAnalyzer(cfg.DocumentAnalysisSettings, cfg.AntiDetectionSettings.InsecureSkipVerify)
+		spider.documentAnalyzer.SetAuthManager(spider.authManager)
+		spider.documentResults = make(map[string]*Result)
+	}
+	
+	// 🆕 v4.9: Source Map还原器（还原原始前端源码）
+	if cfg.SourceMapSettings.Enabled {
+		spider.sourceMapAnalyzer = NewSourceMapAnalyzer(cfg.SourceMapSettings, cfg.AntiDetectionSettings.InsecureSkipVerify)
+		spider.sourceMapAnalyzer.SetAuthManager(spider.authManager)
+	}
+	
+	// 🆕 v4.9: 前端组件漏洞扫描（离线漏洞库加载失败时禁用）
+	if cfg.JSVulnerabilitySettings.Enabled {
+		scanner := NewJSLibraryScanner()
+		if err := scanner.LoadDatabase(cfg.JSVulnerabilitySettings.DatabaseFile); err != nil {
+			spider.logger.Warn("前端组件漏洞库加载失败，已禁用组件漏洞检测", "file", cfg.JSVulnerabilitySettings.DatabaseFile, "error", err)
+		} else {
+			spider.jsLibScanner = scanner
+		}
+	}
+	
+	// 🆕 v4.9: HAR流量记录（包装在认证之后，记录实际发出的请求头）
+	if cfg.HARSettings.Enabled {
+		recorder := NewHARRecorder(cfg.HARSettings.MaxEntries, cfg.HARSettings.IncludeBodies, cfg.HARSettings.MaxBodySize)
+		spider.harRecorder = recorder
+		if staticCrawlerImpl, ok := spider.staticCrawler.(*StaticCrawlerImpl); ok {
+			staticCrawlerImpl.SetHARRecorder(recorder)
+		}
+		if dynamicCrawlerImpl, ok := spider.dynamicCrawler.(*DynamicCrawlerImpl); ok {
+			dynamicCrawlerImpl.SetHARRecorder(recorder)
+		}
+		spider.sitemapCrawler.SetHARRecorder(recorder)
+		spider.perfOptimizer.SetHARRecorder(recorder)
+		if spider.documentAnalyzer != nil {
+			spider.documentAnalyzer.SetHARRecorder(recorder)
+		}
+		if spider.sourceMapAnalyzer != nil {
+			spider.sourceMapAnalyzer.SetHARRecorder(recorder)
+		}
+	}
+	
+	// 🆕 v4.9: GraphQL检测（端点发现、introspection、操作收集）
+	if cfg.AdvancedSettings.EnableGraphQLDetection {
+		spider.graphqlDiscovery = NewGraphQLDiscovery(cfg.GraphQLSettings.MaxOperations)
+	}
+	
+	// 🆕 v4.9: SOAP/gRPC-Web服务发现
+	if cfg.APIServiceSettings.Enabled {
+		spider.serviceDetector = NewAPIServiceDetector()
+	}
+	
+	// 🆕 v4.9: JS自动反混淆（静态爬虫与外部JS分析共用同一沙箱）
+	if cfg.JSDeobfuscationSettings.Enabled {
+		sandbox := NewJSSandbox(
+			time.Duration(cfg.JSDeobfuscationSettings.SandboxTimeoutMs)*time.Millisecond,
+			uint64(cfg.JSDeobfuscationSettings.SandboxMemoryMB)*1024*1024)
+		spider.jsSandbox = sandbox
+		spider.jsAnalyzer.EnableDeobfuscation(sandbox, cfg.JSDeobfuscationSettings.MaxScriptSize)
+		if staticCrawlerImpl, ok := spider.staticCrawler.(*StaticCrawlerImpl); ok {
+			staticCrawlerImpl.SetJSSandbox(sandbox)
+		}
 	}
 
-	return spider
+	return spider, nil
 }
 
 // parseLogLevel 解析日志级别字符串为 slog.Level
@@ -380,92 +506,80 @@ func (s *Spider) LoadCookieFromString(cookieString string) error {
 	return s.cookieManager.LoadFromString(cookieString)
 }
 
-// GetCookieManager 获取Cookie管理器
-func (s *Spider) GetCookieManager() *CookieManager {
-	return s.cookieManager
+// GetAuthManager 获取认证管理器（未启用认证时返回nil）
+func (s *Spider) GetAuthManager() *AuthManager {
+	return s.authManager
 }
 
-// Start 开始爬取
-func (s *Spider) Start(targetURL string) error {
-	// 确保资源清理（优化：防止泄漏）
-	defer s.cleanup()
+// GetHARRecorder 获取HAR流量记录器（未启用HAR导出时返回nil）
+func (s *Spider) GetHARRecorder() *HARRecorder {
+	return s.harRecorder
+}
 
-	// 解析目标URL并提取域名
-	parsedURL, err := url.Parse(targetURL)
-	if err != nil {
-		return fmt.Errorf("无效的URL: %v", err)
+// GetDocumentAnalyses 获取文档分析结果（未启用文档分析时返回nil）
+func (s *Spider) GetDocumentAnalyses() []*DocumentAnalysis {
+	if s.documentAnalyzer == nil {
+		return nil
 	}
-	s.targetDomain = parsedURL.Host
-	
-	// 🆕 v4.8: 初始化JS特殊处理器（需要目标URL）
-	jsHandler, err := NewJSSpecialHandler(targetURL, s.config.BlacklistSettings.Domains)
-	if err != nil {
-		s.logger.Warn("JS处理器初始化失败", "error", err)
-	} else {
-		s.jsHandler = jsHandler
-		s.logger.Info("JS特殊处理器已启用", "target", targetURL)
+	return s.documentAnalyzer.GetAnalyses()
+}
+
+// GetSourceMapAnalyses 获取Source Map还原结果（未启用时返回nil）
+func (s *Spider) GetSourceMapAnalyses() []*SourceMapAnalysis {
+	if s.sourceMapAnalyzer == nil {
+		return nil
 	}
-	
-	// 🆕 v4.2: 初始化URL过滤管理器（在targetDomain设置后）
-	if s.config.FilterSettings.Enabled && s.filterManager == nil {
-		s.filterManager = s.initializeFilterManager(s.config)
+	return s.sourceMapAnalyzer.GetAnalyses()
+}
+
+// GetJSLibraryFindings 获取识别出的前端组件及其已知漏洞（未启用时返回nil）
+func (s *Spider) GetJSLibraryFindings() []*JSLibraryFinding {
+	if s.jsLibScanner == nil {
+		return nil
 	}
+	return s.jsLibScanner.GetFindings()
+}
 
-	// 设置JS分析器的目标域名
-	s.jsAnalyzer.SetTargetDomain(s.targetDomain)
-	
-	// 设置CSS分析器的目标域名
-	s.cssAnalyzer.SetTargetDomain(s.targetDomain)
-	
-	// 初始化资源分类器
-	s.resourceClassifier = NewResourceClassifier(s.targetDomain)
-	
-	// 🆕 v3.7: 为爬虫注入Spider引用（用于实时记录URL）
-	s.staticCrawler.SetSpider(s)
-	s.dynamicCrawler.SetSpider(s)
-	
-	// 🆕 初始化优先级调度器（如果配置启用）
-	// 可以通过配置文件控制是否使用优先级队列模式
-	s.priorityScheduler = NewURLPriorityScheduler(s.targetDomain)
+// PrintJSLibraryReport 打印前端组件漏洞报告
+func (s *Spider) PrintJSLibraryReport() {
+	if s.jsLibScanner != nil {
+		s.jsLibScanner.PrintReport()
+	}
+}
 
-	// 初始化高级作用域控制
-	s.advancedScope = NewAdvancedScope(s.targetDomain)
-	s.advancedScope.SetMode(ScopeRDN)         // 根域名模式
-	s.advancedScope.PresetStaticFilterScope() // 过滤静态资源
-	
-	// 🆕 v3.1: 初始化作用域控制器
-	// 🔧 修复: 如果IncludeDomains为空，自动添加目标域名/IP地址
-	includeDomains := s.config.ScopeSettings.IncludeDomains
-	if len(includeDomains) == 0 {
-		// 自动添加目标域名（支持IP地址）
-		includeDomains = []string{s.targetDomain}
+// GetDeobfuscations 获取JS反混淆结果
+func (s *Spider) GetDeobfuscations() []*DeobfuscationReport {
+	return s.jsAnalyzer.GetDeobfuscations()
+}
+
+// SaveRecoveredSources 将还原的源码写入目录（配置了output_dir时优先使用），返回实际目录和文件数
+func (s *Spider) SaveRecoveredSources(defaultDir string) (string, int, error) {
+	if s.sourceMapAnalyzer == nil {
+		return "", 0, nil
 	}
-	
-	scopeConfig := ScopeConfig{
-		IncludeDomains:    includeDomains,
-		ExcludeDomains:    s.config.ScopeSettings.ExcludeDomains,
-		IncludePaths:      s.config.ScopeSettings.IncludePaths,
-		ExcludePaths:      s.config.ScopeSettings.ExcludePaths,
-		IncludeRegex:      s.config.ScopeSettings.IncludeRegex,
-		ExcludeRegex:      s.config.ScopeSettings.ExcludeRegex,
-		IncludeExtensions: s.config.ScopeSettings.IncludeExtensions,
-		ExcludeExtensions: s.config.ScopeSettings.ExcludeExtensions,
-		IncludeParams:     []string{}, // 暂不支持参数过滤
-		ExcludeParams:     []string{}, // 暂不支持参数过滤
-		MaxDepth:          s.config.DepthSettings.MaxDepth,
-		AllowSubdomains:   s.config.ScopeSettings.AllowSubdomains,
-		StayInDomain:      s.config.ScopeSettings.StayInDomain,
-		AllowHTTP:         s.config.ScopeSettings.AllowHTTP,
-		AllowHTTPS:        s.config.ScopeSettings.AllowHTTPS,
+	dir := defaultDir
+	if s.config.SourceMapSettings.OutputDir != "" {
+		dir = s.config.SourceMapSettings.OutputDir
 	}
-	s.scopeController, err = NewScopeController(scopeConfig)
+	written, err := s.sourceMapAnalyzer.SaveSources(dir)
+	return dir, written, err
+}
+
+// GetCookieManager 获取Cookie管理器
+func (s *Spider) GetCookieManager() *CookieManager {
+	return s.cookieManager
+}
+
+// Start 开始爬取
+func (s *Spider) Start(targetURL string) error {
+	// 确保资源清理（优化：防止泄漏）
+	defer s.cleanup()
+
+	parsedURL, err := s.initTarget(targetURL)
 	if err != nil {
-		return fmt.Errorf("初始化作用域控制器失败: %v", err)
+		return err
 	}
 
-	// 初始化子域名提取器
-	s.subdomainExtractor = NewSubdomainExtractor(targetURL)
-
 	// 检查是否重复
 	if s.duplicateHandler.IsDuplicateURL(targetURL) {
 		return fmt.Errorf("URL已处理过: %s", targetURL)
@@ -504,6 +618,8 @@ func (s *Spider) Start(targetURL string) error {
 		userAgent = s.config.AntiDetectionSettings.UserAgents[0]
 	}
 	s.hiddenPathDiscovery = NewHiddenPathDiscovery(targetURL, userAgent)
+	s.hiddenPathDiscovery.SetAuthManager(s.authManager)
+	s.hiddenPathDiscovery.SetHARRecorder(s.harRecorder)
 
 	// === 优化：先爬取sitemap.xml和robots.txt ===
 	s.logger.Info("开始爬取sitemap和robots.txt", "target", targetURL)
@@ -576,6 +692,19 @@ func (s *Spider) Start(targetURL string) error {
 	// 参数爆破功能已移除，专注于纯爬虫
 	// 不再生成参数爆破URL，只爬取真实发现的链接
 
+	// 🆕 v4.9: 导入Burp/HAR流量（响应进入分析，URL和表单作为递归爬取的种子）
+	if len(s.trafficImports) > 0 {
+		s.importQueuedTraffic()
+	}
+
+	// 🆕 v4.9: 探测构建清单（asset-manifest.json / .vite/manifest.json 等）中的分块
+	s.probeBuildManifests()
+
+	// 🆕 v4.9: 探测并解析已发布的API定义（Swagger/OpenAPI/WSDL/WADL）
+	if s.config.APIDefinitionSettings.Enabled {
+		s.probeAPIDefinitions()
+	}
+
 	// 分析跨域JS文件（在递归爬取之前）
 	s.processCrossDomainJS()
 
@@ -635,104 +764,388 @@ func (s *Spider) Start(targetURL string) error {
 		}
 	}
 
-	// 🆕 打印去重器统计信息（调试用）
-	if s.duplicateHandler != nil {
-		s.duplicateHandler.PrintStats()
-	}
+	s.finishCrawl()
 
 	return nil
 }
 
-// shouldUseDynamicCrawler 判断是否需要使用动态爬虫
-func (s *Spider) shouldUseDynamicCrawler() bool {
-	// 如果没有发现足够的链接或API，可能需要动态爬虫
-	if len(s.results) == 0 {
-		return true
-	}
-
-	// 检查最近的结果
-	lastResult := s.results[len(s.results)-1]
-	// 降低触发动态爬虫的阈值（更容易触发）
-	if len(lastResult.Links) < 20 && len(lastResult.APIs) < 10 {
-		return true
+// initTarget 解析目标URL并初始化依赖目标域名的组件（主动爬取和被动代理共用）
+func (s *Spider) initTarget(targetURL string) (*url.URL, error) {
+	// 解析目标URL并提取域名
+	parsedURL, err := url.Parse(targetURL)
+	if err != nil {
+		return nil, fmt.Errorf("无效的URL: %v", err)
 	}
-
-	return false
-}
-
-// isInTargetDomain 检查URL是否属于目标域名
-func (s *Spider) isInTargetDomain(urlStr string) bool {
-	// 忽略特殊协议
-	if strings.HasPrefix(urlStr, "mailto:") || 
-	   strings.HasPrefix(urlStr, "tel:") ||
-	   strings.HasPrefix(urlStr, "javascript:") ||
-	   strings.HasPrefix(urlStr, "data:") {
-		return false
+	s.targetDomain = parsedURL.Host
+	
+	// 🆕 v4.9: 认证凭据默认只发送到目标域名
+	if s.authManager != nil {
+		s.authManager.SetTargetDomain(s.targetDomain)
+		s.authManager.PrintSummary()
 	}
 	
-	// 解析URL提取域名
-	parsedURL, err := url.Parse(urlStr)
+	// 🆕 v4.8: 初始化JS特殊处理器（需要目标URL）
+	jsHandler, err := NewJSSpecialHandler(targetURL, s.config.BlacklistSettings.Domains)
 	if err != nil {
-		return false
+		s.logger.Warn("JS处理器初始化失败", "error", err)
+	} else {
+		s.jsHandler = jsHandler
+		s.logger.Info("JS特殊处理器已启用", "target", targetURL)
 	}
 	
-	// 获取URL的域名（不含端口）
-	urlHost := parsedURL.Hostname()
-	if urlHost == "" {
-		return false
+	// 🆕 v4.2: 初始化URL过滤管理器（在targetDomain设置后）
+	if s.config.FilterSettings.Enabled && s.filterManager == nil {
+		s.filterManager = s.initializeFilterManager(s.config)
 	}
+
+	// 设置JS分析器的目标域名
+	s.jsAnalyzer.SetTargetDomain(s.targetDomain)
 	
-	// 目标域名（不含端口）
-	targetHost := s.targetDomain
+	// 设置CSS分析器的目标域名
+	s.cssAnalyzer.SetTargetDomain(s.targetDomain)
 	
-	// 完全匹配
-	if urlHost == targetHost {
-		return true
-	}
+	// 初始化资源分类器
+	s.resourceClassifier = NewResourceClassifier(s.targetDomain)
 	
-	// 子域名匹配（例如：api.example.com 匹配 example.com）
-	if strings.HasSuffix(urlHost, "."+targetHost) {
-		return true
-	}
+	// 🆕 v3.7: 为爬虫注入Spider引用（用于实时记录URL）
+	s.staticCrawler.SetSpider(s)
+	s.dynamicCrawler.SetSpider(s)
 	
-	return false
-}
+	// 🆕 初始化优先级调度器（如果配置启用）
+	// 可以通过配置文件控制是否使用优先级队列模式
+	s.priorityScheduler = NewURLPriorityScheduler(s.targetDomain)
 
-// addResult 添加爬取结果（增强版：包含DOM相似度检测、技术栈检测、敏感信息检测和登录墙检测）
-func (s *Spider) addResult(result *Result) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
+	// 初始化高级作用域控制
+	s.advancedScope = NewAdvancedScope(s.targetDomain)
+	s.advancedScope.SetMode(ScopeRDN)         // 根域名模式
+	s.advancedScope.PresetStaticFilterScope() // 过滤静态资源
 	
-	// 🆕 v3.2 登录墙检测
-	if s.loginWallDetector != nil && result != nil {
-		s.loginWallDetector.RecordPage(result.URL, result.HTMLContent)
-		
-		// 如果登录墙占比过高，发出警告
-		if s.loginWallDetector.ShouldWarn() && len(s.results) % 100 == 0 {
-			// 每100个页面检查一次
-			s.loginWallDetector.PrintWarning()
-		}
+	// 🆕 v3.1: 初始化作用域控制器
+	// 🔧 修复: 如果IncludeDomains为空，自动添加目标域名/IP地址
+	includeDomains := s.config.ScopeSettings.IncludeDomains
+	if len(includeDomains) == 0 {
+		// 自动添加目标域名（支持IP地址）
+		includeDomains = []string{s.targetDomain}
 	}
 	
-	// 🆕 v3.5 POST请求检测（增强版）
-	if s.postDetector != nil && result != nil && result.HTMLContent != "" {
-		detectedPOST := s.postDetector.DetectFromHTML(result.HTMLContent, result.URL)
-		if len(detectedPOST) > 0 {
-			// 转换为Result中的POSTRequest格式
-			for _, dp := range detectedPOST {
-				postReq := POSTRequest{
-					URL:         dp.URL,
-					Method:      dp.Method,
-					Parameters:  dp.Parameters,
-					ContentType: dp.ContentType,
-					Body:        "",
-					FromForm:    dp.Source == "html-form",
-					FormAction:  dp.URL,
-				}
-				
-				// 🔧 修复v3.6.1：先收集所有POST，在保存时统一去重
-				// 不在这里去重，避免遗漏来自其他来源的POST
-				result.POSTRequests = append(result.POSTRequests, postReq)
+	scopeConfig := ScopeConfig{
+		IncludeDomains:    includeDomains,
+		ExcludeDomains:    s.config.ScopeSettings.ExcludeDomains,
+		IncludePaths:      s.config.ScopeSettings.IncludePaths,
+		ExcludePaths:      s.config.ScopeSettings.ExcludePaths,
+		IncludeRegex:      s.config.ScopeSettings.IncludeRegex,
+		ExcludeRegex:      s.config.ScopeSettings.ExcludeRegex,
+		IncludeExtensions: s.config.ScopeSettings.IncludeExtensions,
+		ExcludeExtensions: s.config.ScopeSettings.ExcludeExtensions,
+		IncludeParams:     []string{}, // 暂不支持参数过滤
+		ExcludeParams:     []string{}, // 暂不支持参数过滤
+		MaxDepth:          s.config.DepthSettings.MaxDepth,
+		AllowSubdomains:   s.config.ScopeSettings.AllowSubdomains,
+		StayInDomain:      s.config.ScopeSettings.StayInDomain,
+		AllowHTTP:         s.config.ScopeSettings.AllowHTTP,
+		AllowHTTPS:        s.config.ScopeSettings.AllowHTTPS,
+	}
+	s.scopeController, err = NewScopeController(scopeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("初始化作用域控制器失败: %v", err)
+	}
+
+	// 初始化子域名提取器
+	s.subdomainExtractor = NewSubdomainExtractor(targetURL)
+
+	return parsedURL, nil
+}
+
+// finishCrawl 爬取结束后的汇总分析（主动爬取和被动代理共用）
+func (s *Spider) finishCrawl() {
+	// 🆕 v4.9: 递归爬取中遇到的API定义
+	if s.config.APIDefinitionSettings.Enabled {
+		s.ingestCrawledAPIDefinitions()
+	}
+
+	// 🆕 v4.9: GraphQL端点确认、introspection和操作收集
+	if s.graphqlDiscovery != nil {
+		s.analyzeGraphQL()
+	}
+
+	// 🆕 v4.9: SOAP/gRPC-Web服务识别
+	if s.serviceDetector != nil {
+		s.analyzeAPIServices()
+	}
+
+	// 🆕 v4.9: 文档分析（PDF/Office中的链接、文本和元数据）
+	if s.documentAnalyzer != nil {
+		s.analyzeDocuments()
+	}
+
+	// 🆕 v4.9: Source Map还原（原始源码的端点和敏感信息）
+	if s.sourceMapAnalyzer != nil {
+		s.analyzeSourceMaps()
+	}
+
+	// 🆕 v4.9: 反混淆还原的字符串进入敏感信息扫描
+	s.scanDeobfuscatedStrings()
+
+	// 🆕 v4.9: 前端组件版本与离线漏洞库比对
+	if s.jsLibScanner != nil {
+		s.scanJSLibraries()
+	}
+
+	// 🆕 打印去重器统计信息（调试用）
+	if s.duplicateHandler != nil {
+		s.duplicateHandler.PrintStats()
+	}
+}
+
+// StartPassive 被动代理模式（v4.9新增）
+// 在listenAddr启动可解密HTTPS的代理，测试人员手动浏览时，目标域名的请求和响应
+// 与主动爬取一样进入链接提取、技术栈、敏感信息和API分析；stop关闭后停止代理并完成汇总分析
+func (s *Spider) StartPassive(targetURL, listenAddr string, stop <-chan struct{}) error {
+	defer s.cleanup()
+
+	if _, err := s.initTarget(targetURL); err != nil {
+		return err
+	}
+
+	settings := s.config.PassiveProxySettings
+	if settings.CACertFile == "" && settings.CAKeyFile == "" {
+		// 配置文件中没有passive_proxy_settings时使用默认配置
+		settings = config.NewDefaultConfig().PassiveProxySettings
+	}
+	ca, created, err := LoadOrCreateProxyCA(settings.CACertFile, settings.CAKeyFile)
+	if err != nil {
+		return fmt.Errorf("加载代理CA失败: %v", err)
+	}
+
+	// 🆕 v4.9: 先导入登记的流量文件
+	if len(s.trafficImports) > 0 {
+		s.importQueuedTraffic()
+	}
+
+	proxy := NewProxyServer(listenAddr, s.targetDomain)
+	proxy.SetSpider(s)
+	proxy.EnableMITM(ca, settings.DecryptInScopeOnly)
+	proxy.SetInsecureSkipVerify(s.config.AntiDetectionSettings.InsecureSkipVerify)
+	proxy.SetHARRecorder(s.harRecorder)
+	if err := proxy.Start(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n【被动代理模式】\n")
+	fmt.Printf("  代理地址: http://%s\n", listenAddr)
+	fmt.Printf("  目标域名: %s\n", s.targetDomain)
+	if created {
+		fmt.Printf("  CA证书: %s（新生成，需导入浏览器并信任后才能解密HTTPS）\n", settings.CACertFile)
+	} else {
+		fmt.Printf("  CA证书: %s\n", settings.CACertFile)
+	}
+	fmt.Printf("  CA指纹: SHA256 %s\n", ca.Fingerprint())
+	fmt.Printf("  浏览器设置代理后手动访问目标，按 Ctrl+C 结束并生成报告\n\n")
+
+	<-stop
+
+	proxy.Stop()
+	proxy.PrintStatistics()
+
+	s.mutex.Lock()
+	recorded := len(s.results)
+	s.mutex.Unlock()
+	s.logger.Info("被动代理已停止", "results", recorded)
+
+	s.finishCrawl()
+
+	return nil
+}
+
+// AnalyzePassiveTraffic 分析被动代理拦截的一次请求（v4.9新增）
+// 图片、音视频、字体、文档和压缩包只记录为静态资源（文档由文档分析器处理），其余响应经静态爬虫的回调解析后加入结果；
+// 非GET请求同时记录为POSTRequest（含实际请求体和响应），供API分析使用
+func (s *Spider) AnalyzePassiveTraffic(req *InterceptedRequest, resp *http.Response, body []byte) {
+	if result := s.buildTrafficResult(req, resp, body); result != nil {
+		s.addResult(result)
+	}
+}
+
+// buildTrafficResult 把一次请求/响应转换为爬取结果（被动代理和流量导入共用）
+// 域外请求、HEAD/OPTIONS和静态资源返回nil
+func (s *Spider) buildTrafficResult(req *InterceptedRequest, resp *http.Response, body []byte) *Result {
+	if req == nil || resp == nil || !s.isInTargetDomain(req.URL) {
+		return nil
+	}
+	method := strings.ToUpper(req.Method)
+	if method == http.MethodHead || method == http.MethodOptions {
+		return nil
+	}
+	target, err := url.Parse(req.URL)
+	if err != nil {
+		return nil
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if resourceType, isStatic := passiveStaticResourceType(contentType); isStatic {
+		s.RecordStaticResource(req.URL, resourceType)
+		return nil
+	}
+
+	result, err := s.staticCrawler.AnalyzeResponse(method, target, resp, body)
+	if err != nil {
+		s.logger.Warn("流量分析失败", "url", req.URL, "error", err)
+		return nil
+	}
+
+	if method != http.MethodGet {
+		result.Method = method
+		headers := make(map[string]string, len(resp.Header))
+		for key, values := range resp.Header {
+			if len(values) > 0 {
+				headers[key] = values[0]
+			}
+		}
+		post := POSTRequest{
+			URL:         req.URL,
+			Method:      method,
+			Parameters:  make(map[string]string),
+			Body:        req.Body,
+			ContentType: req.ContentType,
+			Headers:     req.Headers,
+			Response: &POSTResponse{
+				StatusCode:  resp.StatusCode,
+				Headers:     headers,
+				Body:        string(body),
+				NewURLs:     result.Links,
+				RedirectURL: resp.Header.Get("Location"),
+			},
+		}
+		// 表单编码的请求体解析为参数
+		if strings.HasPrefix(strings.ToLower(req.ContentType), "application/x-www-form-urlencoded") {
+			if values, err := url.ParseQuery(req.Body); err == nil {
+				for key := range values {
+					post.Parameters[key] = values.Get(key)
+				}
+			}
+		}
+		result.POSTRequests = append(result.POSTRequests, post)
+	}
+
+	return result
+}
+
+// passiveStaticResourceType 按响应的Content-Type判断不需要解析的静态资源
+func passiveStaticResourceType(contentType string) (ResourceType, bool) {
+	mediaType := parseMediaType(contentType)
+	switch {
+	case strings.HasPrefix(mediaType, "image/"):
+		return ResourceTypeImage, true
+	case strings.HasPrefix(mediaType, "video/"):
+		return ResourceTypeVideo, true
+	case strings.HasPrefix(mediaType, "audio/"):
+		return ResourceTypeAudio, true
+	case strings.HasPrefix(mediaType, "font/"), strings.Contains(mediaType, "font-"):
+		return ResourceTypeFont, true
+	case mediaType == "application/pdf", strings.Contains(mediaType, "msword"),
+		strings.Contains(mediaType, "officedocument"), strings.Contains(mediaType, "ms-excel"),
+		strings.Contains(mediaType, "ms-powerpoint"):
+		return ResourceTypeDocument, true
+	case strings.Contains(mediaType, "zip"), strings.Contains(mediaType, "compressed"),
+		strings.Contains(mediaType, "x-tar"), strings.Contains(mediaType, "x-rar"):
+		return ResourceTypeArchive, true
+	}
+	return "", false
+}
+
+// shouldUseDynamicCrawler 判断是否需要使用动态爬虫
+func (s *Spider) shouldUseDynamicCrawler() bool {
+	// 如果没有发现足够的链接或API，可能需要动态爬虫
+	if len(s.results) == 0 {
+		return true
+	}
+
+	// 检查最近的结果
+	lastResult := s.results[len(s.results)-1]
+	// 降低触发动态爬虫的阈值（更容易触发）
+	if len(lastResult.Links) < 20 && len(lastResult.APIs) < 10 {
+		return true
+	}
+
+	return false
+}
+
+// isInTargetDomain 检查URL是否属于目标域名
+func (s *Spider) isInTargetDomain(urlStr string) bool {
+	// 忽略特殊协议
+	if strings.HasPrefix(urlStr, "mailto:") || 
+	   strings.HasPrefix(urlStr, "tel:") ||
+	   strings.HasPrefix(urlStr, "javascript:") ||
+	   strings.HasPrefix(urlStr, "data:") {
+		return false
+	}
+	
+	// 解析URL提取域名
+	parsedURL, err := url.Parse(urlStr)
+	if err != nil {
+		return false
+	}
+	
+	// 获取URL的域名（不含端口）
+	urlHost := parsedURL.Hostname()
+	if urlHost == "" {
+		return false
+	}
+	
+	// 目标域名（不含端口）
+	targetHost := s.targetDomain
+	if host, _, err := net.SplitHostPort(targetHost); err == nil {
+		targetHost = host
+	}
+	
+	// 完全匹配
+	if urlHost == targetHost {
+		return true
+	}
+	
+	// 子域名匹配（例如：api.example.com 匹配 example.com）
+	if strings.HasSuffix(urlHost, "."+targetHost) {
+		return true
+	}
+	
+	return false
+}
+
+// addResult 添加爬取结果（增强版：包含DOM相似度检测、技术栈检测、敏感信息检测和登录墙检测）
+func (s *Spider) addResult(result *Result) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	
+	// 🆕 v3.2 登录墙检测
+	if s.loginWallDetector != nil && result != nil {
+		s.loginWallDetector.RecordPage(result.URL, result.HTMLContent)
+		
+		// 如果登录墙占比过高，发出警告
+		if s.loginWallDetector.ShouldWarn() && len(s.results) % 100 == 0 {
+			// 每100个页面检查一次
+			s.loginWallDetector.PrintWarning()
+		}
+	}
+	
+	// 🆕 v3.5 POST请求检测（增强版）
+	if s.postDetector != nil && result != nil && result.HTMLContent != "" {
+		detectedPOST := s.postDetector.DetectFromHTML(result.HTMLContent, result.URL)
+		if len(detectedPOST) > 0 {
+			// 转换为Result中的POSTRequest格式
+			for _, dp := range detectedPOST {
+				postReq := POSTRequest{
+					URL:         dp.URL,
+					Method:      dp.Method,
+					Parameters:  dp.Parameters,
+					ContentType: dp.ContentType,
+					Body:        "",
+					FromForm:    dp.Source == "html-form",
+					FormAction:  dp.URL,
+				}
+				
+				// 🔧 修复v3.6.1：先收集所有POST，在保存时统一去重
+				// 不在这里去重，避免遗漏来自其他来源的POST
+				result.POSTRequests = append(result.POSTRequests, postReq)
 			}
 			
 			s.logger.Info("检测到POST请求",
@@ -813,12 +1226,21 @@ func (s *Spider) addResult(result *Result) {
 		if s.config.SensitiveDetectionSettings.Enabled && s.sensitiveDetector != nil {
 			findings := make([]*SensitiveInfo, 0)
 			
-			// 扫描HTML内容（根据配置）
-			if s.config.SensitiveDetectionSettings.ScanResponseBody {
-				bodyFindings := s.sensitiveDetector.Scan(result.HTMLContent, result.URL)
+			// 扫描响应内容（根据配置，🆕 v4.9: 跳过图片等二进制响应，记录内容类型）
+			if s.config.SensitiveDetectionSettings.ScanResponseBody && IsTextualContentType(result.ContentType) {
+				bodyFindings := s.sensitiveDetector.ScanContent(result.HTMLContent, result.URL, result.ContentType)
 				findings = append(findings, bodyFindings...)
 				s.sensitiveFindings = append(s.sensitiveFindings, bodyFindings...)
 			}
+			
+			// 🆕 v4.9: 扫描浏览器捕获的XHR/Fetch响应（JSON接口等）
+			if s.config.SensitiveDetectionSettings.ScanResponseBody {
+				for _, captured := range result.CapturedResponses {
+					capturedFindings := s.sensitiveDetector.ScanContent(captured.Body, captured.URL+" (XHR)", captured.ContentType)
+					findings = append(findings, capturedFindings...)
+					s.sensitiveFindings = append(s.sensitiveFindings, capturedFindings...)
+				}
+			}
 
 			// 扫描HTTP头（根据配置）
 			if s.config.SensitiveDetectionSettings.ScanResponseHeaders && len(result.Headers) > 0 {
@@ -826,7 +1248,7 @@ func (s *Spider) addResult(result *Result) {
 				for key, value := range result.Headers {
 					headerContent += key + ": " + value + "\n"
 				}
-				headerFindings := s.sensitiveDetector.Scan(headerContent, result.URL+" (Headers)")
+				headerFindings := s.sensitiveDetector.ScanContent(headerContent, result.URL+" (Headers)", "message/http")
 				s.sensitiveFindings = append(s.sensitiveFindings, headerFindings...)
 				findings = append(findings, headerFindings...)
 			}
@@ -905,7 +1327,7 @@ func (s *Spider) addResultWithDetection(result *Result, response *http.Response,
 
 	// 敏感信息检测
 	if s.sensitiveDetector != nil {
-		findings := s.sensitiveDetector.Scan(htmlContent, result.URL)
+		findings := s.sensitiveDetector.ScanContent(htmlContent, result.URL, result.ContentType)
 		s.sensitiveFindings = append(s.sensitiveFindings, findings...)
 
 		if len(findings) > 0 {
@@ -932,100 +1354,1162 @@ func (s *Spider) addResultWithDetection(result *Result, response *http.Response,
 			fmt.Printf("  [子域名] 发现 %d 个新子域名\n", len(subdomains))
 		}
 
-		// 从URL本身提取
-		s.subdomainExtractor.ExtractFromURL(result.URL)
+		// 从URL本身提取
+		s.subdomainExtractor.ExtractFromURL(result.URL)
+	}
+}
+
+// GetResults 获取所有爬取结果
+func (s *Spider) GetResults() []*Result {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// v2.6.1: 打印智能参数值去重统计
+	if s.config.DeduplicationSettings.EnableSmartParamDedup && s.smartParamDedup != nil {
+		s.smartParamDedup.PrintStatistics()
+	}
+
+	// 返回结果副本
+	results := make([]*Result, len(s.results))
+	copy(results, s.results)
+	return results
+}
+
+// GetExternalLinks 获取外部链接列表
+func (s *Spider) GetExternalLinks() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.externalLinks
+}
+
+// GetStaticResources 获取静态资源列表
+func (s *Spider) GetStaticResources() StaticResources {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.staticResources
+}
+
+// GetBlacklistedURLs 获取黑名单URL列表
+func (s *Spider) GetBlacklistedURLs() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.blacklistedURLs
+}
+
+// GetSpecialProtocolLinks 获取特殊协议链接
+func (s *Spider) GetSpecialProtocolLinks() SpecialLinks {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.specialLinks
+}
+
+// RecordStaticResource 记录静态资源
+func (s *Spider) RecordStaticResource(url string, resourceType ResourceType) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	
+	switch resourceType {
+	case ResourceTypeImage:
+		s.staticResources.Images = append(s.staticResources.Images, url)
+	case ResourceTypeVideo:
+		s.staticResources.Videos = append(s.staticResources.Videos, url)
+	case ResourceTypeAudio:
+		s.staticResources.Audios = append(s.staticResources.Audios, url)
+	case ResourceTypeFont:
+		s.staticResources.Fonts = append(s.staticResources.Fonts, url)
+	case ResourceTypeDocument:
+		s.staticResources.Documents = append(s.staticResources.Documents, url)
+	case ResourceTypeArchive:
+		s.staticResources.Archives = append(s.staticResources.Archives, url)
+	}
+}
+
+// analyzeDocuments 分析爬取结束时仍未分析的文档（v4.9新增）
+// 递归爬取期间文档随引用页面一起分析（见 analyzeLinkedDocuments），
+// 这里补充最后一层页面、静态资源记录和被动代理中发现的文档，并汇总结果
+func (s *Spider) analyzeDocuments() {
+	s.mutex.Lock()
+	documents := s.pendingDocuments(s.results, true)
+	s.mutex.Unlock()
+
+	if len(documents) > 0 {
+		fmt.Printf("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		fmt.Printf("【文档分析】发现 %d 个待分析文档，开始下载分析...\n", len(documents))
+		fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		s.analyzeDocumentSet(documents)
+	}
+
+	analyses := s.documentAnalyzer.GetAnalyses()
+	if len(analyses) == 0 {
+		return
+	}
+	analyzed := 0
+	totalLinks := 0
+	for _, analysis := range analyses {
+		if analysis.Error == "" {
+			analyzed++
+			totalLinks += len(analysis.Links)
+		}
+	}
+	fmt.Printf("文档分析完成：成功 %d/%d，提取链接 %d 个\n", analyzed, len(analyses), totalLinks)
+}
+
+// analyzeCrawledDocuments 分析已有结果和静态资源记录中的文档（收集下一层链接前调用，
+// 文档中的链接作为结果加入s.results，从而进入下一层）
+func (s *Spider) analyzeCrawledDocuments() {
+	s.mutex.Lock()
+	documents := s.pendingDocuments(s.results, true)
+	s.mutex.Unlock()
+
+	if len(documents) > 0 {
+		fmt.Printf("  [文档分析] 已爬取页面引用了 %d 个文档，分析后其链接进入下一层\n", len(documents))
+		s.analyzeDocumentSet(documents)
+	}
+}
+
+// analyzeLinkedDocuments 分析单个页面引用的新文档（递归爬取中每个URL完成后调用）
+func (s *Spider) analyzeLinkedDocuments(result *Result) {
+	s.mutex.Lock()
+	documents := s.pendingDocuments([]*Result{result}, false)
+	s.mutex.Unlock()
+
+	if len(documents) > 0 {
+		fmt.Printf("  [文档分析] %s 引用了 %d 个文档\n", result.URL, len(documents))
+		s.analyzeDocumentSet(documents)
+	}
+}
+
+// linkedDocumentResults 页面引用的、已完成分析的文档结果
+func (s *Spider) linkedDocumentResults(result *Result) []*Result {
+	if s.documentAnalyzer == nil {
+		return nil
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	docResults := make([]*Result, 0)
+	for _, link := range append(append([]string{}, result.Links...), result.Assets...) {
+		if docResult := s.documentResults[link]; docResult != nil {
+			docResults = append(docResults, docResult)
+		}
+	}
+	return docResults
+}
+
+// pendingDocuments 收集结果中引用的、尚未分析的文档（文档URL → 来源页面），
+// 并将其标记为正在分析，调用方需持有s.mutex
+func (s *Spider) pendingDocuments(results []*Result, includeStatic bool) map[string][]string {
+	documents := make(map[string][]string)
+	addDocument := func(docURL, sourcePage string) {
+		if _, analyzed := s.documentResults[docURL]; analyzed {
+			return
+		}
+		if !s.documentAnalyzer.IsDocumentURL(docURL) {
+			return
+		}
+		if s.config.DocumentAnalysisSettings.InScopeOnly && !s.isInTargetDomain(docURL) {
+			return
+		}
+		sources := documents[docURL]
+		if sourcePage != "" {
+			for _, existing := range sources {
+				if existing == sourcePage {
+					return
+				}
+			}
+			sources = append(sources, sourcePage)
+		}
+		documents[docURL] = sources
+	}
+
+	for _, result := range results {
+		for _, link := range result.Links {
+			addDocument(link, result.URL)
+		}
+		for _, asset := range result.Assets {
+			addDocument(asset, result.URL)
+		}
+	}
+	if includeStatic {
+		for _, doc := range s.staticResources.Documents {
+			addDocument(doc, "")
+		}
+	}
+
+	for docURL := range documents {
+		s.documentResults[docURL] = nil
+	}
+	return documents
+}
+
+// analyzeDocumentSet 下载并分析一批文档，返回文档结果
+// 文档中的超链接作为该文档的结果加入results，文本进入敏感信息扫描（扫描不持有s.mutex）
+func (s *Spider) analyzeDocumentSet(documents map[string][]string) []*Result {
+	analyses := s.documentAnalyzer.AnalyzeAll(documents)
+	scanSensitive := s.config.DocumentAnalysisSettings.ScanSensitive && s.config.SensitiveDetectionSettings.Enabled && s.sensitiveDetector != nil
+
+	docResults := make([]*Result, 0, len(analyses))
+	for _, analysis := range analyses {
+		if analysis.Error != "" {
+			fmt.Printf("  ✗ %s: %s\n", analysis.URL, analysis.Error)
+			continue
+		}
+
+		meta := analysis.Metadata
+		fmt.Printf("  ✓ %s [%s, %d字节] 链接: %d, 邮箱: %d, 内部路径: %d\n",
+			analysis.URL, analysis.Type, analysis.Size,
+			len(analysis.Links), len(analysis.Emails), len(analysis.InternalPaths))
+		if meta.Author != "" || meta.Creator != "" || meta.Producer != "" {
+			fmt.Printf("      作者: %s | 创建工具: %s | 生成器: %s\n", meta.Author, meta.Creator, meta.Producer)
+		}
+		if analysis.Truncated {
+			fmt.Printf("      ⚠️  解压数据超过文档级上限，剩余部分未解析\n")
+		}
+
+		// 文档作为一条结果，内嵌链接进入后续URL处理和输出
+		docResult := &Result{
+			URL:          analysis.URL,
+			StatusCode:   200,
+			ContentType:  analysis.ContentType,
+			Links:        analysis.Links,
+			LinkSources:  make(map[string]string, len(analysis.Links)),
+			Assets:       make([]string, 0),
+			Forms:        make([]Form, 0),
+			APIs:         make([]string, 0),
+			POSTRequests: make([]POSTRequest, 0),
+			Headers:      make(map[string]string),
+			Crawled:      true,
+		}
+		for _, link := range analysis.Links {
+			docResult.LinkSources[link] = LinkSourceDocument
+		}
+
+		// 敏感信息扫描（文本 + 元数据）
+		var findings []*SensitiveInfo
+		if scanSensitive {
+			content := analysis.Text + "\n" + strings.Join([]string{
+				meta.Title, meta.Subject, meta.Author, meta.LastModifiedBy,
+				meta.Company, meta.Template, meta.Keywords,
+			}, "\n")
+			findings = s.sensitiveDetector.ScanContent(content, analysis.URL+" (文档)", analysis.ContentType)
+			if len(findings) > 0 {
+				fmt.Printf("      ⚠️  敏感信息: %d 处\n", len(findings))
+			}
+		}
+
+		s.mutex.Lock()
+		s.results = append(s.results, docResult)
+		s.documentResults[analysis.URL] = docResult
+		s.sensitiveFindings = append(s.sensitiveFindings, findings...)
+		s.mutex.Unlock()
+		docResults = append(docResults, docResult)
+	}
+	return docResults
+}
+
+// scanDeobfuscatedStrings 汇报反混淆结果，并对解密出的字符串做敏感信息扫描（v4.9新增）
+// 混淆脚本的原文中看不到这些字符串，爬取时的响应体扫描无法覆盖
+func (s *Spider) scanDeobfuscatedStrings() {
+	reports := s.jsAnalyzer.GetDeobfuscations()
+	if len(reports) == 0 {
+		return
+	}
+
+	fmt.Printf("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	fmt.Printf("【JS反混淆】检测到 %d 个混淆脚本\n", len(reports))
+	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+
+	scanSensitive := s.config.JSDeobfuscationSettings.ScanSensitive && s.config.SensitiveDetectionSettings.Enabled && s.sensitiveDetector != nil
+	totalStrings := 0
+	for _, report := range reports {
+		totalStrings += len(report.DecodedStrings)
+		fmt.Printf("  %s [%s] 沙箱还原: %d, 解密字符串: %d, 隐藏URL: %d\n",
+			report.Source, report.Type, report.SandboxDecoded, len(report.DecodedStrings), len(report.HiddenURLs))
+		if report.SandboxError != "" {
+			fmt.Printf("      沙箱中断: %s\n", report.SandboxError)
+		}
+
+		if !scanSensitive || len(report.DecodedStrings) == 0 {
+			continue
+		}
+		s.mutex.Lock()
+		findings := s.sensitiveDetector.ScanContent(strings.Join(report.DecodedStrings, "\n"), report.Source+" (反混淆)", "application/javascript")
+		s.sensitiveFindings = append(s.sensitiveFindings, findings...)
+		s.mutex.Unlock()
+		if len(findings) > 0 {
+			fmt.Printf("      ⚠️  敏感信息: %d 处\n", len(findings))
+		}
+	}
+	fmt.Printf("JS反混淆完成：解密字符串 %d 个\n", totalStrings)
+}
+
+// scanJSLibraries 识别页面引用的前端组件版本并比对已知漏洞（v4.9新增）
+// 已下载的脚本按内容识别，只出现在页面引用中的脚本按URL/文件名识别，
+// 技术栈检测给出版本号的组件一并比对
+func (s *Spider) scanJSLibraries() {
+	s.mutex.Lock()
+	scripts := make([]*Result, 0)
+	scriptURLs := make([]string, 0)
+	for _, result := range s.results {
+		if result.HTMLContent != "" && isJavaScriptMediaType(strings.ToLower(result.ContentType)) {
+			scripts = append(scripts, result)
+		}
+		scriptURLs = append(scriptURLs, result.Assets...)
+		scriptURLs = append(scriptURLs, result.Links...)
+	}
+	techs := make([]*TechInfo, len(s.detectedTechs))
+	copy(techs, s.detectedTechs)
+	s.mutex.Unlock()
+
+	for _, script := range scripts {
+		s.jsLibScanner.ScanContent(script.URL, script.HTMLContent)
+		s.jsLibScanner.ScanURL(script.URL)
+	}
+	seen := make(map[string]bool)
+	for _, scriptURL := range scriptURLs {
+		if seen[scriptURL] {
+			continue
+		}
+		seen[scriptURL] = true
+		if u, err := url.Parse(scriptURL); err == nil && strings.HasSuffix(strings.ToLower(u.Path), ".js") {
+			s.jsLibScanner.ScanURL(scriptURL)
+		}
+	}
+	for _, tech := range techs {
+		if tech.Version != "" {
+			s.jsLibScanner.ScanComponent(tech.Name, tech.Version, s.config.TargetURL)
+		}
+	}
+
+	stats := s.jsLibScanner.GetStatistics()
+	if stats["components"] > 0 {
+		fmt.Printf("\n前端组件识别完成：%d 个组件，%d 个存在已知漏洞\n", stats["components"], stats["vulnerable"])
+	}
+}
+
+// analyzeGraphQL 汇总GraphQL端点和操作（v4.9新增）
+// 端点来自链接、JS端点、捕获的请求和常见路径，用最小查询确认后按配置执行introspection；
+// 操作来自introspection、JS包、页面内联脚本、.graphql文件和捕获的请求体
+func (s *Spider) analyzeGraphQL() {
+	settings := s.config.GraphQLSettings
+	discovery := s.graphqlDiscovery
+
+	s.mutex.Lock()
+	results := make([]*Result, len(s.results))
+	copy(results, s.results)
+	s.mutex.Unlock()
+
+	for _, result := range results {
+		if IsGraphQLURL(result.URL) {
+			discovery.HarvestRequest("GET", result.URL, "", "")
+			discovery.AddEndpoint(result.URL, GraphQLSourceLink)
+		}
+		for _, link := range append(append([]string{}, result.Links...), result.APIs...) {
+			if IsGraphQLURL(link) {
+				discovery.HarvestRequest("GET", link, "", "")
+				discovery.AddEndpoint(link, GraphQLSourceLink)
+			}
+		}
+		for _, post := range result.POSTRequests {
+			discovery.HarvestRequest(post.Method, post.URL, post.ContentType, post.Body)
+		}
+
+		if result.HTMLContent == "" {
+			continue
+		}
+		contentType := strings.ToLower(result.ContentType)
+		path := strings.ToLower(strings.Split(result.URL, "?")[0])
+		switch {
+		case strings.HasSuffix(path, ".graphql") || strings.HasSuffix(path, ".gql"):
+			discovery.HarvestDocument(result.HTMLContent, result.URL, "", GraphQLOriginDocument)
+		case isJavaScriptMediaType(contentType):
+			discovery.HarvestScript(result.HTMLContent, result.URL)
+		case strings.Contains(contentType, "html"):
+			discovery.HarvestPage(result.HTMLContent, result.URL)
+		}
+	}
+
+	for _, endpoint := range s.GetJSEndpoints() {
+		crawlURL := endpoint.CrawlURL()
+		if crawlURL == "" || !IsGraphQLURL(crawlURL) {
+			continue
+		}
+		base, err := url.Parse(endpoint.Source)
+		if err != nil || base.Host == "" {
+			base, err = url.Parse(s.config.TargetURL)
+		}
+		ref, refErr := url.Parse(crawlURL)
+		if err != nil || refErr != nil {
+			continue
+		}
+		discovery.AddEndpoint(base.ResolveReference(ref).String(), GraphQLSourceJS)
+	}
+
+	// 已发现的端点 + 常见路径，逐个确认
+	candidates := make(map[string]string)
+	order := make([]string, 0)
+	addCandidate := func(candidate, source string) {
+		if _, exists := candidates[candidate]; exists {
+			return
+		}
+		if settings.InScopeOnly && !s.isInTargetDomain(candidate) {
+			return
+		}
+		candidates[candidate] = source
+		order = append(order, candidate)
+	}
+	for _, endpoint := range discovery.GetEndpoints() {
+		addCandidate(endpoint.URL, "")
+	}
+	base := &url.URL{Scheme: "https", Host: s.targetDomain}
+	if parsed, err := url.Parse(s.config.TargetURL); err == nil && parsed.Scheme != "" {
+		base.Scheme = parsed.Scheme
+	}
+	if settings.ProbeCommonPaths {
+		for _, path := range GraphQLCommonPaths {
+			addCandidate(base.String()+path, GraphQLSourceCommonPath)
+		}
+	}
+	for _, path := range settings.ExtraPaths {
+		addCandidate(base.String()+"/"+strings.TrimLeft(path, "/"), GraphQLSourceCommonPath)
+	}
+
+	for _, candidate := range order {
+		if !s.probeGraphQLEndpoint(candidate) {
+			continue
+		}
+		if source := candidates[candidate]; source != "" {
+			discovery.AddEndpoint(candidate, source)
+		}
+		discovery.MarkConfirmed(candidate)
+
+		if settings.Introspection {
+			analyzer := NewGraphQLAnalyzer(candidate)
+			analyzer.SetRequestFunc(s.perfOptimizer.DoRequest)
+			schema, err := analyzer.Analyze()
+			if added := discovery.SetSchema(candidate, schema, err); added > 0 {
+				fmt.Printf("  [GraphQL] %s introspection 生成 %d 个操作\n", candidate, added)
+			} else if err != nil {
+				fmt.Printf("  [GraphQL] %s introspection失败，改用JS和请求中收集的操作: %v\n", candidate, err)
+			}
+		}
+	}
+
+	discovery.AssignDefaultEndpoint()
+	discovery.PrintReport()
+}
+
+// probeGraphQLEndpoint 发送 {__typename} 查询确认端点（先POST JSON，失败时尝试GET）
+func (s *Spider) probeGraphQLEndpoint(endpointURL string) bool {
+	userAgent := "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36"
+	if len(s.config.AntiDetectionSettings.UserAgents) > 0 {
+		userAgent = s.config.AntiDetectionSettings.UserAgents[0]
+	}
+	attempts := []func() (*http.Request, error){
+		func() (*http.Request, error) {
+			req, err := http.NewRequest("POST", endpointURL, strings.NewReader(GraphQLProbeQuery))
+			if err == nil {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			return req, err
+		},
+		func() (*http.Request, error) {
+			return http.NewRequest("GET", endpointURL+"?query="+url.QueryEscape("query{__typename}"), nil)
+		},
+	}
+	for _, attempt := range attempts {
+		req, err := attempt()
+		if err != nil {
+			return false
+		}
+		req.Header.Set("User-Agent", userAgent)
+		req.Header.Set("Accept", "application/json")
+		resp, err := s.perfOptimizer.DoRequest(req)
+		if err != nil {
+			return false
+		}
+		body, _ := io.ReadAll(&io.LimitedReader{R: resp.Body, N: 64 * 1024})
+		resp.Body.Close()
+		if IsGraphQLResponse(body) {
+			return true
+		}
+	}
+	return false
+}
+
+// analyzeAPIServices 识别SOAP/gRPC-Web服务（v4.9新增）
+// 来源：捕获的请求（SOAPAction头、SOAP信封、application/grpc-web*）、gRPC-Web响应、
+// WSDL定义和链接、JS端点的请求头，以及JS包中的服务描述
+func (s *Spider) analyzeAPIServices() {
+	detector := s.serviceDetector
+	scanJS := s.config.APIServiceSettings.ScanJS
+	baseURL := s.serviceBaseURL()
+
+	s.mutex.Lock()
+	results := make([]*Result, len(s.results))
+	copy(results, s.results)
+	s.mutex.Unlock()
+
+	// WSDL展开的示例请求不算作捕获的流量
+	definitionURLs := make(map[string]bool)
+	for _, def := range s.GetAPIDefinitions() {
+		detector.AddWSDL(def)
+		definitionURLs[def.URL] = true
+	}
+	for _, result := range results {
+		if definitionURLs[result.URL] {
+			continue
+		}
+		detector.ObserveResponse(result.URL, result.ContentType)
+		detector.ObserveWSDLLink(result.URL)
+		for _, link := range result.Links {
+			detector.ObserveWSDLLink(link)
+		}
+		for _, post := range result.POSTRequests {
+			detector.ObserveRequest(post.Method, post.URL, post.ContentType, post.Headers, post.Body, "traffic")
+		}
+		if scanJS && result.HTMLContent != "" && isJavaScriptMediaType(strings.ToLower(result.ContentType)) {
+			detector.ScanScript(result.HTMLContent, result.URL, baseURL)
+		}
+	}
+
+	// JS中带SOAPAction或gRPC-Web请求头的调用
+	for _, endpoint := range s.GetJSEndpoints() {
+		crawlURL := endpoint.CrawlURL()
+		if crawlURL == "" || len(endpoint.Headers) == 0 {
+			continue
+		}
+		base, err := url.Parse(endpoint.Source)
+		if err != nil || base.Host == "" {
+			base, err = url.Parse(s.config.TargetURL)
+		}
+		ref, refErr := url.Parse(crawlURL)
+		if err != nil || refErr != nil {
+			continue
+		}
+		contentType := ""
+		for name, value := range endpoint.Headers {
+			if strings.EqualFold(name, "Content-Type") {
+				contentType = value
+			}
+		}
+		method := endpoint.Method
+		if method == "" {
+			method = "POST"
+		}
+		detector.ObserveRequest(method, base.ResolveReference(ref).String(), contentType, endpoint.Headers, "", "js")
+	}
+
+	detector.PrintReport()
+}
+
+// serviceBaseURL JS中gRPC-Web方法路径的调用前缀（目标站点根地址）
+func (s *Spider) serviceBaseURL() string {
+	base := &url.URL{Scheme: "https", Host: s.targetDomain}
+	if parsed, err := url.Parse(s.config.TargetURL); err == nil && parsed.Scheme != "" && parsed.Host != "" {
+		base.Scheme, base.Host = parsed.Scheme, parsed.Host
+	}
+	return base.String()
+}
+
+// GetAPIServices 获取发现的SOAP/gRPC-Web服务（🆕 v4.9）
+func (s *Spider) GetAPIServices() []*APIService {
+	if s.serviceDetector == nil {
+		return nil
+	}
+	return s.serviceDetector.GetServices()
+}
+
+// GetGraphQLDiscovery 获取GraphQL收集器（未启用时为nil）（🆕 v4.9）
+func (s *Spider) GetGraphQLDiscovery() *GraphQLDiscovery {
+	return s.graphqlDiscovery
+}
+
+// registerSourceMap 登记脚本，稍后下载其Source Map（未启用或不在范围内时忽略）
+func (s *Spider) registerSourceMap(scriptURL, jsCode, sourceMapHeader string) {
+	if s.sourceMapAnalyzer == nil {
+		return
+	}
+	if s.config.SourceMapSettings.InScopeOnly && !s.isInTargetDomain(scriptURL) {
+		return
+	}
+	s.sourceMapAnalyzer.Register(scriptURL, jsCode, sourceMapHeader)
+}
+
+// analyzeSourceMaps 下载并还原目标脚本的Source Map（v4.9新增）
+// 暴露的Source Map本身作为敏感信息上报；还原的源码做端点提取和敏感信息扫描，
+// 提取到的链接作为该Source Map的结果加入results
+func (s *Spider) analyzeSourceMaps() {
+	// 爬取过程中直接下载的脚本（静态爬虫保留了响应体和响应头）
+	s.mutex.Lock()
+	scripts := make([]*Result, 0)
+	for _, result := range s.results {
+		if result.HTMLContent != "" && isJavaScriptMediaType(strings.ToLower(result.ContentType)) {
+			scripts = append(scripts, result)
+		}
+	}
+	s.mutex.Unlock()
+	for _, script := range scripts {
+		s.registerSourceMap(script.URL, script.HTMLContent, SourceMapHeader(script.Headers))
+	}
+
+	analyses := s.sourceMapAnalyzer.AnalyzeAll()
+	if len(analyses) == 0 {
+		return
+	}
+
+	fmt.Printf("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	fmt.Printf("【Source Map还原】发现 %d 个Source Map\n", len(analyses))
+	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+
+	var baseURL *url.URL
+	if parsed, err := url.Parse(s.config.TargetURL); err == nil {
+		baseURL = parsed
+	}
+	scanSensitive := s.config.SourceMapSettings.ScanSensitive && s.config.SensitiveDetectionSettings.Enabled && s.sensitiveDetector != nil
+
+	recoveredTotal := 0
+	totalLinks := 0
+	for _, analysis := range analyses {
+		mapLabel := analysis.MapURL
+		if mapLabel == "" {
+			mapLabel = analysis.ScriptURL + " (内联Source Map)"
+		}
+		if analysis.Error != "" {
+			fmt.Printf("  ✗ %s: %s\n", mapLabel, analysis.Error)
+			continue
+		}
+		recoveredTotal += analysis.Recovered
+		fmt.Printf("  ✓ %s [%s] 源文件: %d, 含原始内容: %d\n",
+			mapLabel, analysis.Discovery, len(analysis.Sources), analysis.Recovered)
+
+		// 暴露的Source Map本身就是一处信息泄露
+		severity := "MEDIUM"
+		if analysis.Recovered == 0 {
+			severity = "LOW"
+		}
+		exposure := &SensitiveInfo{
+			Type:      "Source Map泄露",
+			Value:     mapLabel,
+			FullValue: mapLabel,
+			Location:  fmt.Sprintf("%d 个源文件（%d 个含原始内容）", len(analysis.Sources), analysis.Recovered),
+			Severity:  severity,
+			SourceURL: analysis.ScriptURL,
+		}
+
+		mapResult := &Result{
+			URL:          mapLabel,
+			StatusCode:   200,
+			ContentType:  "application/json",
+			Links:        make([]string, 0),
+			LinkSources:  make(map[string]string),
+			Assets:       make([]string, 0),
+			Forms:        make([]Form, 0),
+			APIs:         make([]string, 0),
+			POSTRequests: make([]POSTRequest, 0),
+			Headers:      make(map[string]string),
+			Crawled:      true,
+		}
+		if analysis.MapURL == "" {
+			mapResult.URL = analysis.ScriptURL
+		}
+
+		findings := make([]*SensitiveInfo, 0)
+		for _, source := range analysis.Sources {
+			if source.Content == "" || (source.Vendor && s.config.SourceMapSettings.SkipVendor) {
+				continue
+			}
+			sourceLabel := mapLabel + "#" + source.Path
+
+			for _, categoryURLs := range s.jsAnalyzer.EnhancedAnalyzeSource(source.Content, sourceLabel) {
+				for _, discovered := range categoryURLs {
+					link := discovered
+					if isRelativeURL(link) {
+						if baseURL == nil {
+							continue
+						}
+						link = resolveURL(baseURL, link)
+					}
+					if link == "" || mapResult.LinkSources[link] != "" {
+						continue
+					}
+					mapResult.LinkSources[link] = LinkSourceSourceMap
+					mapResult.Links = append(mapResult.Links, link)
+				}
+			}
+
+			if scanSensitive {
+				s.mutex.Lock()
+				findings = append(findings, s.sensitiveDetector.ScanContent(source.Content, sourceLabel, "application/javascript")...)
+				s.mutex.Unlock()
+			}
+		}
+		totalLinks += len(mapResult.Links)
+
+		s.mutex.Lock()
+		s.results = append(s.results, mapResult)
+		if s.sensitiveDetector != nil {
+			s.sensitiveDetector.AddFinding(exposure)
+			s.sensitiveFindings = append(s.sensitiveFindings, exposure)
+		}
+		s.sensitiveFindings = append(s.sensitiveFindings, findings...)
+		s.mutex.Unlock()
+
+		if len(mapResult.Links) > 0 {
+			fmt.Printf("      提取链接: %d\n", len(mapResult.Links))
+		}
+		if len(findings) > 0 {
+			fmt.Printf("      ⚠️  敏感信息: %d 处\n", len(findings))
+		}
+	}
+
+	fmt.Printf("Source Map还原完成：还原源文件 %d 个，提取链接 %d 个\n", recoveredTotal, totalLinks)
+}
+
+// RecordSpecialLink 记录特殊协议链接
+func (s *Spider) RecordSpecialLink(url string, protocol string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	
+	switch protocol {
+	case "mailto":
+		s.specialLinks.Mailto = append(s.specialLinks.Mailto, url)
+	case "tel":
+		s.specialLinks.Tel = append(s.specialLinks.Tel, url)
+	case "ws", "wss":
+		s.specialLinks.WebSocket = append(s.specialLinks.WebSocket, url)
+	case "ftp":
+		s.specialLinks.FTP = append(s.specialLinks.FTP, url)
+	case "data":
+		s.specialLinks.Data = append(s.specialLinks.Data, url)
+	}
+}
+
+// RecordBlacklistedURL 记录黑名单URL
+func (s *Spider) RecordBlacklistedURL(url string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.blacklistedURLs = append(s.blacklistedURLs, url)
+}
+
+// RecordDeobfuscation 记录静态爬虫中脚本的反混淆结果（🆕 v4.9，实现SpiderRecorder接口）
+func (s *Spider) RecordDeobfuscation(report *DeobfuscationReport) {
+	s.jsAnalyzer.RecordDeobfuscation(report)
+}
+
+// RecordJSEndpoints 记录JS分析发现的结构化端点（🆕 v4.9，实现SpiderRecorder接口）
+func (s *Spider) RecordJSEndpoints(endpoints []*JSEndpoint) {
+	s.jsAnalyzer.RecordEndpoints(endpoints)
+}
+
+// GetJSEndpoints 获取JS分析发现的结构化端点（🆕 v4.9）
+func (s *Spider) GetJSEndpoints() []*JSEndpoint {
+	return s.jsAnalyzer.GetEndpoints()
+}
+
+// GetJSASTStats 获取JS语法树解析统计（🆕 v4.9）
+func (s *Spider) GetJSASTStats() JSASTStats {
+	return s.jsAnalyzer.ASTAnalyzer().GetStats()
+}
+
+// BuildOpenAPIGenerator 由爬取结果构建OpenAPI生成器（🆕 v4.9）
+// 没有可导出的端点时返回nil
+func (s *Spider) BuildOpenAPIGenerator() *OpenAPIGenerator {
+	if !s.config.OpenAPISettings.Enabled {
+		return nil
+	}
+	analyzer := s.BuildAPIAnalyzer()
+	if analyzer == nil {
+		return nil
+	}
+	return NewOpenAPIGenerator(analyzer)
+}
+
+// BuildAPIAnalyzer 由爬取结果构建被动API分析器（🆕 v4.9）
+// 被动汇总API链接、POST请求、带查询参数的URL、JSON响应、JS端点和已发布API定义，不发送任何请求；
+// 结果在爬取结束后构建一次并缓存，供OpenAPI文档、端点Schema等导出共用；没有观察到端点时返回nil
+func (s *Spider) BuildAPIAnalyzer() *APIAnalyzer {
+	s.apiAnalyzerOnce.Do(func() {
+		s.observedAPIs = s.buildAPIAnalyzer()
+	})
+	return s.observedAPIs
+}
+
+// buildAPIAnalyzer 汇总爬取结果中的API观察
+func (s *Spider) buildAPIAnalyzer() *APIAnalyzer {
+	settings := s.config.OpenAPISettings
+
+	analyzer := NewAPIAnalyzer(s.targetDomain)
+	analyzer.SetMaxExamples(settings.MaxExamples)
+	analyzer.SetEnumLimits(settings.EnumMaxValues, settings.EnumMinSamples)
+	targetURL, err := url.Parse(s.config.TargetURL)
+	if err == nil && targetURL.Scheme != "" && targetURL.Host != "" {
+		analyzer.SetServerURL(targetURL.Scheme + "://" + targetURL.Host)
+	}
+
+	inScope := func(rawURL string) bool {
+		return !settings.InScopeOnly || s.isInTargetDomain(rawURL)
+	}
+	observed := make(map[string]bool)
+	count := 0
+	observe := func(obs APIObservation) {
+		if inScope(obs.URL) && analyzer.Observe(obs) != nil {
+			count++
+		}
+	}
+
+	s.mutex.Lock()
+	results := make([]*Result, len(s.results))
+	copy(results, s.results)
+	s.mutex.Unlock()
+
+	// API定义文件本身及其展开的端点不按普通结果处理（见第5步）
+	definitions := s.GetAPIDefinitions()
+	definitionURLs := make(map[string]bool, len(definitions))
+	for _, def := range definitions {
+		definitionURLs[def.URL] = true
+	}
+
+	// 1. 实际请求过的URL：JSON响应、API链接或带查询参数的页面
+	apiLinks := make(map[string]bool)
+	for _, result := range results {
+		if definitionURLs[result.URL] {
+			continue
+		}
+		for _, api := range result.APIs {
+			apiLinks[api] = true
+		}
+	}
+	for _, result := range results {
+		// 非GET请求由第3步按POSTRequest处理
+		if result.StatusCode == 0 || definitionURLs[result.URL] || (result.Method != "" && result.Method != "GET") {
+			continue
+		}
+		contentType := strings.ToLower(result.ContentType)
+		isJSON := strings.Contains(contentType, "json") && !strings.HasSuffix(strings.Split(result.URL, "?")[0], ".map")
+		hasQuery := strings.Contains(result.URL, "?")
+		if !isJSON && !apiLinks[result.URL] && !(hasQuery && isDocumentMediaType(contentType)) {
+			continue
+		}
+		obs := APIObservation{
+			URL:         result.URL,
+			StatusCode:  result.StatusCode,
+			ContentType: result.ContentType,
+			Source:      "result",
+		}
+		if isJSON {
+			obs.ResponseBody = result.HTMLContent
+		}
+		observe(obs)
+		observed[result.URL] = true
+	}
+
+	// 2. 只发现未请求的API链接
+	for api := range apiLinks {
+		if !observed[api] {
+			observe(APIObservation{URL: api, Source: "api"})
+		}
+	}
+
+	// 3. POST请求（表单和捕获的请求）
+	for _, result := range results {
+		if definitionURLs[result.URL] {
+			continue
+		}
+		for _, post := range result.POSTRequests {
+			obs := APIObservation{
+				Method:             post.Method,
+				URL:                post.URL,
+				RequestContentType: post.ContentType,
+				RequestBody:        post.Body,
+				BodyParams:         post.Parameters,
+				Source:             "post",
+			}
+			if obs.Method == "" {
+				obs.Method = "POST"
+			}
+			if post.Response != nil {
+				obs.StatusCode = post.Response.StatusCode
+				obs.ContentType = post.Response.Headers["Content-Type"]
+				obs.ResponseBody = post.Response.Body
+			}
+			observe(obs)
+		}
+	}
+
+	// 4. JS中静态分析出的端点（占位符按路径参数模板化）
+	if settings.IncludeJSEndpoints {
+		for _, endpoint := range s.GetJSEndpoints() {
+			crawlURL := endpoint.CrawlURL()
+			if crawlURL == "" || endpoint.Kind == JSEndpointWebSocket || endpoint.Kind == JSEndpointEventSource {
+				continue
+			}
+			base := targetURL
+			if source, err := url.Parse(endpoint.Source); err == nil && source.Host != "" {
+				base = source
+			}
+			ref, err := url.Parse(crawlURL)
+			if err != nil || base == nil {
+				continue
+			}
+			resolved := base.ResolveReference(ref)
+			query := resolved.Query()
+			for _, name := range endpoint.QueryParams {
+				if _, exists := query[name]; !exists {
+					query.Set(name, "")
+				}
+			}
+			resolved.RawQuery = query.Encode()
+
+			bodyParams := make(map[string]string)
+			for _, key := range endpoint.BodyKeys {
+				bodyParams[key] = ""
+			}
+			contentType := ""
+			for name, value := range endpoint.Headers {
+				if strings.EqualFold(name, "Content-Type") {
+					contentType = value
+				}
+			}
+			if contentType == "" && len(bodyParams) > 0 && endpoint.Kind != JSEndpointJQuery {
+				contentType = "application/json" // fetch/axios请求体通常为JSON，jQuery默认表单编码
+			}
+			observe(APIObservation{
+				Method:             endpoint.Method,
+				URL:                resolved.String(),
+				RequestContentType: contentType,
+				BodyParams:         bodyParams,
+				Source:             "js",
+			})
+		}
+	}
+
+	// 5. 已发布API定义中的端点（示例请求体来自定义的Schema）
+	for _, def := range definitions {
+		for _, ep := range def.Endpoints {
+			observe(APIObservation{
+				Method:             ep.Method,
+				URL:                ep.URL,
+				RequestContentType: ep.ContentType,
+				RequestBody:        ep.Body,
+				BodyParams:         ep.BodyParams(),
+				Source:             "definition",
+			})
+		}
+	}
+
+	// 6. SOAP/gRPC-Web服务的操作（记录服务类型、操作名和消息名）
+	for _, service := range s.GetAPIServices() {
+		for _, op := range service.Operations {
+			if op.URL == "" {
+				continue
+			}
+			operation := *op
+			observe(APIObservation{
+				Method:             "POST",
+				URL:                op.URL,
+				RequestContentType: op.ContentType,
+				Source:             "service",
+				APIType:            service.Type,
+				Operation:          &operation,
+			})
+		}
+	}
+
+	if count == 0 {
+		return nil
+	}
+	return analyzer
+}
+
+// BuildCollectionExporter 由爬取结果构建Postman/Insomnia导出器（🆕 v4.9）
+// 导出带查询参数的GET链接、API链接、表单、POST请求（含浏览器捕获的请求）；
+// 配置的Cookie和认证头附加到目标域名下的请求并提取为变量；没有可导出的请求时返回nil
+func (s *Spider) BuildCollectionExporter() *CollectionExporter {
+	settings := s.config.CollectionExportSettings
+	if !settings.Postman && !settings.Insomnia {
+		return nil
+	}
+
+	exporter := NewCollectionExporter(s.targetDomain, settings.MaxRequests)
+	if cookie := s.cookieManager.GetCookieHeader(); cookie != "" {
+		exporter.SetDefaultHeader("Cookie", cookie, s.isInTargetDomain)
+	}
+	if s.authManager != nil {
+		if authorization, err := s.authManager.AuthorizationHeader(); err == nil && authorization != "" {
+			exporter.SetDefaultHeader("Authorization", authorization, s.isInTargetDomain)
+		}
+	}
+	if analyzer := s.BuildAPIAnalyzer(); analyzer != nil {
+		exporter.SetSchemaLookup(analyzer.LookupEndpointSchema)
+	}
+
+	add := func(req CollectionRequest) {
+		if settings.InScopeOnly && !s.isInTargetDomain(req.URL) {
+			return
+		}
+		exporter.AddRequest(req)
+	}
+
+	s.mutex.Lock()
+	results := make([]*Result, len(s.results))
+	copy(results, s.results)
+	s.mutex.Unlock()
+
+	for _, result := range results {
+		// 实际请求过的带参数URL和API链接
+		if result.Crawled && strings.Contains(result.URL, "?") {
+			add(CollectionRequest{Method: "GET", URL: result.URL, Source: CollectionSourceLink})
+		}
+		for _, api := range result.APIs {
+			add(CollectionRequest{Method: "GET", URL: api, Source: CollectionSourceAPI})
+		}
+		if settings.IncludeLinks {
+			for _, link := range result.Links {
+				if strings.Contains(link, "?") {
+					add(CollectionRequest{Method: "GET", URL: link, Source: CollectionSourceLink})
+				}
+			}
+		}
+
+		// POST请求（已提交的表单、浏览器捕获的请求、API定义中的示例）
+		for _, post := range result.POSTRequests {
+			add(CollectionRequest{
+				Method:      post.Method,
+				URL:         post.URL,
+				Headers:     post.Headers,
+				Body:        post.Body,
+				ContentType: post.ContentType,
+				Params:      rawPOSTParams(post),
+				Source:      CollectionSourcePOST,
+			})
+		}
+
+		// 表单：GET表单的字段作为查询参数，其他方法作为请求体
+		base, err := url.Parse(result.URL)
+		if err != nil {
+			continue
+		}
+		for _, form := range result.Forms {
+			if req, ok := formSubmissionRequest(base, form); ok {
+				add(req)
+			}
+		}
+	}
+
+	if len(exporter.GetRequests()) == 0 {
+		return nil
 	}
+	return exporter
 }
 
-// GetResults 获取所有爬取结果
-func (s *Spider) GetResults() []*Result {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	// v2.6.1: 打印智能参数值去重统计
-	if s.config.DeduplicationSettings.EnableSmartParamDedup && s.smartParamDedup != nil {
-		s.smartParamDedup.PrintStatistics()
+// formSubmissionRequest 表单提交对应的请求：GET表单的字段作为查询参数，其他方法作为请求体（🆕 v4.9）
+func formSubmissionRequest(base *url.URL, form Form) (CollectionRequest, bool) {
+	action, err := base.Parse(form.Action)
+	if err != nil {
+		return CollectionRequest{}, false
 	}
-
-	// 返回结果副本
-	results := make([]*Result, len(s.results))
-	copy(results, s.results)
-	return results
+	params := make([]CollectionParam, 0, len(form.Fields))
+	contentType := "application/x-www-form-urlencoded"
+	for _, field := range form.Fields {
+		if field.Name == "" {
+			continue
+		}
+		if strings.EqualFold(field.Type, "file") {
+			contentType = "multipart/form-data"
+		}
+		params = append(params, CollectionParam{Name: field.Name, Value: field.Value})
+	}
+	method := strings.ToUpper(form.Method)
+	if method == "" || method == "GET" {
+		query := action.Query()
+		for _, param := range params {
+			query.Set(param.Name, param.Value)
+		}
+		action.RawQuery = query.Encode()
+		return CollectionRequest{Method: "GET", URL: action.String(), Source: CollectionSourceForm}, true
+	}
+	return CollectionRequest{
+		Method:      method,
+		URL:         action.String(),
+		ContentType: contentType,
+		Params:      params,
+		Source:      CollectionSourceForm,
+	}, true
 }
 
-// GetExternalLinks 获取外部链接列表
-func (s *Spider) GetExternalLinks() []string {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	return s.externalLinks
-}
+// BuildRawRequestExporter 由爬取结果构建原始HTTP请求导出器（🆕 v4.9）
+// 导出POST请求（含浏览器捕获的请求）和非GET表单提交，附加配置的Cookie和认证头；没有可导出的请求时返回nil
+func (s *Spider) BuildRawRequestExporter() *RawRequestExporter {
+	settings := s.config.RequestExportSettings
+	if !settings.RawRequests && !settings.CurlScript {
+		return nil
+	}
 
-// GetStaticResources 获取静态资源列表
-func (s *Spider) GetStaticResources() StaticResources {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	return s.staticResources
-}
+	exporter := NewRawRequestExporter(settings.MaxRequests)
+	exporter.SetPlaceholder(settings.Placeholder, strings.EqualFold(settings.PlaceholderMode, "replace"))
+	if cookie := s.cookieManager.GetCookieHeader(); cookie != "" {
+		exporter.SetDefaultHeader("Cookie", cookie, s.isInTargetDomain)
+	}
+	if s.authManager != nil {
+		if authorization, err := s.authManager.AuthorizationHeader(); err == nil && authorization != "" {
+			exporter.SetDefaultHeader("Authorization", authorization, s.isInTargetDomain)
+		}
+	}
 
-// GetBlacklistedURLs 获取黑名单URL列表
-func (s *Spider) GetBlacklistedURLs() []string {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	return s.blacklistedURLs
-}
+	add := func(req CollectionRequest) {
+		if settings.InScopeOnly && !s.isInTargetDomain(req.URL) {
+			return
+		}
+		exporter.AddRequest(req)
+	}
 
-// GetSpecialProtocolLinks 获取特殊协议链接
-func (s *Spider) GetSpecialProtocolLinks() SpecialLinks {
 	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	return s.specialLinks
-}
+	results := make([]*Result, len(s.results))
+	copy(results, s.results)
+	s.mutex.Unlock()
 
-// RecordStaticResource 记录静态资源
-func (s *Spider) RecordStaticResource(url string, resourceType ResourceType) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	
-	switch resourceType {
-	case ResourceTypeImage:
-		s.staticResources.Images = append(s.staticResources.Images, url)
-	case ResourceTypeVideo:
-		s.staticResources.Videos = append(s.staticResources.Videos, url)
-	case ResourceTypeAudio:
-		s.staticResources.Audios = append(s.staticResources.Audios, url)
-	case ResourceTypeFont:
-		s.staticResources.Fonts = append(s.staticResources.Fonts, url)
-	case ResourceTypeDocument:
-		s.staticResources.Documents = append(s.staticResources.Documents, url)
-	case ResourceTypeArchive:
-		s.staticResources.Archives = append(s.staticResources.Archives, url)
+	for _, result := range results {
+		for _, post := range result.POSTRequests {
+			add(CollectionRequest{
+				Method:      post.Method,
+				URL:         post.URL,
+				Headers:     post.Headers,
+				Body:        post.Body,
+				ContentType: post.ContentType,
+				Params:      rawPOSTParams(post),
+				Source:      CollectionSourcePOST,
+			})
+		}
+
+		base, err := url.Parse(result.URL)
+		if err != nil {
+			continue
+		}
+		for _, form := range result.Forms {
+			if req, ok := formSubmissionRequest(base, form); ok && req.Method != "GET" {
+				add(req)
+			}
+		}
 	}
-}
 
-// RecordSpecialLink 记录特殊协议链接
-func (s *Spider) RecordSpecialLink(url string, protocol string) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	
-	switch protocol {
-	case "mailto":
-		s.specialLinks.Mailto = append(s.specialLinks.Mailto, url)
-	case "tel":
-		s.specialLinks.Tel = append(s.specialLinks.Tel, url)
-	case "ws", "wss":
-		s.specialLinks.WebSocket = append(s.specialLinks.WebSocket, url)
-	case "ftp":
-		s.specialLinks.FTP = append(s.specialLinks.FTP, url)
-	case "data":
-		s.specialLinks.Data = append(s.specialLinks.Data, url)
+	if len(exporter.GetRequests()) == 0 {
+		return nil
 	}
+	return exporter
 }
 
-// RecordBlacklistedURL 记录黑名单URL
-func (s *Spider) RecordBlacklistedURL(url string) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	s.blacklistedURLs = append(s.blacklistedURLs, url)
+// isDocumentMediaType 判断响应是否为页面/数据类型（排除脚本、样式、图片等静态资源）
+func isDocumentMediaType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	for _, prefix := range []string{"text/html", "application/xhtml", "text/plain", "application/xml", "text/xml"} {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
 }
 
 // GetResourceClassifier 获取资源分类器（实现SpiderRecorder接口）
@@ -1305,26 +2789,52 @@ func (s *Spider) processFormsOLD(targetURL string) {
 }
 */
 
+// 🆕 v4.9: 本域脚本分析的上限
+const (
+	maxSameDomainScriptsPerPage = 10  // 每个页面最多分析的本域脚本数
+	maxEnumeratedChunkScripts   = 200 // 运行时枚举出的分块最多追加分析的数量
+	crossDomainJSWorkers        = 8   // 并发下载分析脚本的worker数
+)
+
 // processCrossDomainJS 处理跨域JS文件
 func (s *Spider) processCrossDomainJS() {
 	fmt.Println("\n开始分析跨域JS文件...")
 
 	// 收集所有资源链接
+	// 🆕 v4.9: 本域脚本每个页面最多取前 maxSameDomainScriptsPerPage 个未爬取的（入口/运行时脚本通常最先引用）
 	allAssets := make(map[string]bool)
+	skippedSameDomain := 0
 
 	s.mutex.Lock()
 	for _, result := range s.results {
-		for _, asset := range result.Assets {
+		sameDomainScripts := 0
+		addAsset := func(asset string) {
+			if allAssets[asset] {
+				return
+			}
+			if s.isSameDomainScript(asset) && !s.visitedURLs[asset] {
+				if sameDomainScripts >= maxSameDomainScriptsPerPage {
+					skippedSameDomain++
+					return
+				}
+				sameDomainScripts++
+			}
 			allAssets[asset] = true
 		}
+		for _, asset := range result.Assets {
+			addAsset(asset)
+		}
 		// 也检查Links中的JS文件
 		for _, link := range result.Links {
 			if strings.HasSuffix(strings.ToLower(link), ".js") {
-				allAssets[link] = true
+				addAsset(link)
 			}
 		}
 	}
 	s.mutex.Unlock()
+	if skippedSameDomain > 0 {
+		fmt.Printf("  [本域JS] 超过每页 %d 个的上限，跳过 %d 个脚本\n", maxSameDomainScriptsPerPage, skippedSameDomain)
+	}
 
 	// 过滤出需要分析的JS文件
 	jsToAnalyze := make([]string, 0)
@@ -1349,8 +2859,16 @@ func (s *Spider) processCrossDomainJS() {
 		shouldAnalyze := false
 		reason := ""
 
-		// 1. 是目标域名 - 已经正常爬取了，不需要特殊处理
+		// 1. 是目标域名 - <script src>只记录为资源、不会进入递归爬取
+		// 🆕 v4.9: 本域脚本同样需要分析（webpack运行时/懒加载分块只能从脚本中发现）
 		if domain == s.targetDomain {
+			s.mutex.Lock()
+			visited := s.visitedURLs[asset]
+			s.mutex.Unlock()
+			if visited {
+				continue
+			}
+			jsToAnalyze = append(jsToAnalyze, asset)
 			continue
 		}
 
@@ -1373,69 +2891,407 @@ func (s *Spider) processCrossDomainJS() {
 		}
 	}
 
-	if len(jsToAnalyze) == 0 {
-		fmt.Println("未发现需要分析的跨域JS文件")
-		return
+	if len(jsToAnalyze) == 0 {
+		fmt.Println("未发现需要分析的跨域JS文件")
+		return
+	}
+
+	fmt.Printf("准备分析 %d 个JS文件（跨域+本域）...\n", len(jsToAnalyze))
+
+	// 分析每个JS文件（🆕 v4.9: 有界worker池并发下载分析，结果在当前协程中合并）
+	// 运行时中枚举出的分块按JSSpecialHandler规则追加到待分析列表（最多 maxEnumeratedChunkScripts 个），已分析的脚本不再递归爬取
+	totalURLsFound := 0
+	queued := make(map[string]bool, len(jsToAnalyze))
+	for _, jsURL := range jsToAnalyze {
+		queued[jsURL] = true
+	}
+	defer func() { s.markVisited(jsToAnalyze) }()
+
+	type externalJSResult struct {
+		jsURL  string
+		urls   []string
+		chunks []string
+	}
+	jobs := make(chan string)
+	analyzed := make(chan externalJSResult)
+	var workers sync.WaitGroup
+	for i := 0; i < crossDomainJSWorkers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for jsURL := range jobs {
+				urls, chunks := s.analyzeExternalJS(jsURL)
+				analyzed <- externalJSResult{jsURL: jsURL, urls: urls, chunks: chunks}
+			}
+		}()
+	}
+
+	enumeratedChunks := 0
+	next, inFlight := 0, 0
+	for next < len(jsToAnalyze) || inFlight > 0 {
+		var send chan string
+		var pending string
+		if next < len(jsToAnalyze) {
+			send = jobs
+			pending = jsToAnalyze[next]
+		}
+		select {
+		case send <- pending:
+			next++
+			inFlight++
+			continue
+		case res := <-analyzed:
+			inFlight--
+			jsURL, urls := res.jsURL, res.urls
+			for _, chunk := range res.chunks {
+				if enumeratedChunks >= maxEnumeratedChunkScripts {
+					break
+				}
+				if processedURL, ok := s.AdmitChunk(chunk); ok && !queued[processedURL] {
+					queued[processedURL] = true
+					jsToAnalyze = append(jsToAnalyze, processedURL)
+					enumeratedChunks++
+				}
+			}
+			if len(urls) == 0 {
+				continue
+			}
+			fmt.Printf("  从 %s 提取了 %d 个URL\n", jsURL, len(urls))
+			totalURLsFound += len(urls)
+
+			// 添加到跨域JS发现列表
+			s.mutex.Lock()
+			s.crossDomainJS = append(s.crossDomainJS, urls...)
+			s.mutex.Unlock()
+
+			// 添加到爬取队列（如果启用递归爬取）
+			if s.config.DepthSettings.MaxDepth > 1 && len(s.results) > 0 {
+				// 🔥 URL验证器过滤已禁用：保存所有从JS提取的URL（统一过滤在addLinkWithFilterToResult中进行）
+				addedCount := 0
+				base := &url.URL{Scheme: "https", Host: s.targetDomain}
+				for _, u := range urls {
+					if s.addLinkWithFilterToResult(s.results[0], base, u) {
+						addedCount++
+					}
+				}
+				if addedCount > 0 {
+					fmt.Printf("    [跨域JS] ✅ 添加了 %d 个URL到结果（已禁用过滤，保存所有URL）\n", addedCount)
+				}
+			}
+		}
+	}
+	close(jobs)
+	workers.Wait()
+
+	fmt.Printf("跨域JS分析完成！共从 %d 个JS文件中提取了 %d 个目标域名URL\n\n", len(jsToAnalyze), totalURLsFound)
+}
+
+// isSameDomainScript 是否为目标域名下的JS文件
+func (s *Spider) isSameDomainScript(asset string) bool {
+	if !strings.HasSuffix(strings.ToLower(asset), ".js") {
+		return false
+	}
+	parsedURL, err := url.Parse(asset)
+	return err == nil && parsedURL.Host == s.targetDomain
+}
+
+// AdmitChunk 按JSSpecialHandler规则（黑名单、路径拼接）判断分块是否可分析（🆕 v4.9，实现SpiderRecorder接口）
+func (s *Spider) AdmitChunk(chunkURL string) (string, bool) {
+	if s.jsHandler == nil {
+		return chunkURL, true
+	}
+	shouldProcess, processedURL, _ := s.jsHandler.ShouldProcessJS(chunkURL)
+	return processedURL, shouldProcess
+}
+
+// probeBuildManifests 探测常见构建清单并将其中的分块加入第1层链接（🆕 v4.9）
+// 本域分块进入递归爬取（由静态爬虫做AST分析），跨域分块作为资源交给跨域JS分析
+func (s *Spider) probeBuildManifests() {
+	base := &url.URL{Scheme: "https", Host: s.targetDomain}
+	if parsed, err := url.Parse(s.config.TargetURL); err == nil && parsed.Scheme != "" {
+		base.Scheme = parsed.Scheme
+	}
+
+	total := 0
+	for _, manifestPath := range BuildManifestPaths {
+		manifestURL := base.String() + manifestPath
+		body, err := s.fetchText(manifestURL, 2*1024*1024)
+		if err != nil {
+			continue
+		}
+		chunks := s.chunkEnumerator.FromManifest(body, manifestURL)
+		if len(chunks) == 0 {
+			continue
+		}
+		fmt.Printf("  [构建清单] %s 包含 %d 个分块\n", manifestURL, len(chunks))
+		total += s.addChunksToSeed(chunks)
+	}
+	if total > 0 {
+		fmt.Printf("  [构建清单] 共加入 %d 个分块脚本\n", total)
+	}
+}
+
+// addChunksToSeed 将分块加入第一个结果（本域→Links，跨域→Assets），返回加入数量
+func (s *Spider) addChunksToSeed(chunks []string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(s.results) == 0 {
+		return 0
+	}
+	seed := s.results[0]
+	added := 0
+	for _, chunk := range chunks {
+		processedURL, ok := s.AdmitChunk(chunk)
+		if !ok {
+			continue
+		}
+		parsed, err := url.Parse(processedURL)
+		if err != nil {
+			continue
+		}
+		if parsed.Host == s.targetDomain {
+			seed.Links = append(seed.Links, processedURL)
+			if seed.LinkSources == nil {
+				seed.LinkSources = make(map[string]string)
+			}
+			seed.LinkSources[processedURL] = LinkSourceChunk
+		} else {
+			seed.Assets = append(seed.Assets, processedURL)
+		}
+		added++
+	}
+	return added
+}
+
+// probeAPIDefinitions 探测常见路径、已发现链接和SOAP服务的?wsdl，解析API定义并将端点加入第1层链接（🆕 v4.9）
+func (s *Spider) probeAPIDefinitions() {
+	settings := s.config.APIDefinitionSettings
+	base := &url.URL{Scheme: "https", Host: s.targetDomain}
+	if parsed, err := url.Parse(s.config.TargetURL); err == nil && parsed.Scheme != "" {
+		base.Scheme = parsed.Scheme
+	}
+
+	candidates := make([]string, 0)
+	if settings.ProbeWellKnown {
+		for _, path := range APIDefinitionPaths {
+			candidates = append(candidates, base.String()+path)
+		}
+	}
+	for _, path := range settings.ExtraPaths {
+		candidates = append(candidates, base.String()+"/"+strings.TrimLeft(path, "/"))
+	}
+	s.mutex.Lock()
+	for _, result := range s.results {
+		for _, link := range append(append([]string{}, result.Links...), result.APIs...) {
+			if IsAPIDefinitionURL(link) {
+				candidates = append(candidates, link)
+			} else if wsdlURL := WSDLCandidateURL(link); wsdlURL != "" {
+				candidates = append(candidates, wsdlURL)
+			}
+		}
+	}
+	s.mutex.Unlock()
+
+	found := 0
+	for _, candidate := range candidates {
+		found += s.loadAPIDefinition(candidate, 0)
+	}
+	if found > 0 {
+		fmt.Printf("  [API定义] 共解析 %d 个API定义\n", found)
+	}
+}
+
+// loadAPIDefinition 下载并解析API定义（Swagger UI资源列表会展开为其中的定义），返回解析成功的数量
+func (s *Spider) loadAPIDefinition(definitionURL string, depth int) int {
+	if !s.claimAPIDefinition(definitionURL) {
+		return 0
+	}
+	body, err := s.fetchText(definitionURL, 10*1024*1024)
+	if err != nil {
+		return 0
+	}
+	def, err := ParseAPIDefinition(body, definitionURL)
+	if err != nil {
+		if depth > 0 {
+			return 0
+		}
+		loaded := 0
+		for _, location := range APIDefinitionLocations(body, definitionURL) {
+			loaded += s.loadAPIDefinition(location, depth+1)
+		}
+		return loaded
+	}
+	if s.ingestAPIDefinition(def, body) {
+		return 1
+	}
+	return 0
+}
+
+// ingestCrawledAPIDefinitions 解析递归爬取中下载到的API定义（端点只进入输出和OpenAPI文档，不再爬取）
+func (s *Spider) ingestCrawledAPIDefinitions() {
+	s.mutex.Lock()
+	candidates := make(map[string]string)
+	for _, result := range s.results {
+		if result.HTMLContent == "" || s.apiDefinitionSeen[result.URL] {
+			continue
+		}
+		contentType := strings.ToLower(result.ContentType)
+		if !IsAPIDefinitionURL(result.URL) && !strings.Contains(contentType, "json") &&
+			!strings.Contains(contentType, "yaml") && !strings.Contains(contentType, "xml") {
+			continue
+		}
+		head := strings.ToLower(result.HTMLContent)
+		if len(head) > 4096 {
+			head = head[:4096]
+		}
+		for _, marker := range []string{"swagger", "openapi", "wsdl", "wadl"} {
+			if strings.Contains(head, marker) {
+				candidates[result.URL] = result.HTMLContent
+				break
+			}
+		}
+	}
+	s.mutex.Unlock()
+
+	for definitionURL, body := range candidates {
+		def, err := ParseAPIDefinition([]byte(body), definitionURL)
+		if err == nil && s.claimAPIDefinition(definitionURL) {
+			s.ingestAPIDefinition(def, []byte(body))
+		}
+	}
+}
+
+// claimAPIDefinition 标记定义URL为已处理，超过数量上限或已处理时返回false
+func (s *Spider) claimAPIDefinition(definitionURL string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.apiDefinitionSeen == nil {
+		s.apiDefinitionSeen = make(map[string]bool)
+	}
+	maxDefinitions := s.config.APIDefinitionSettings.MaxDefinitions
+	if s.apiDefinitionSeen[definitionURL] || (maxDefinitions > 0 && len(s.apiDefinitions) >= maxDefinitions) {
+		return false
+	}
+	s.apiDefinitionSeen[definitionURL] = true
+	return true
+}
+
+// ingestAPIDefinition 解析定义并作为一条结果加入：GET端点进入Links（递归爬取），
+// 全部端点进入APIs，非GET端点记录为POST请求；内容重复的定义（同一文档多个路径）只处理一次
+func (s *Spider) ingestAPIDefinition(def *APIDefinition, body []byte) bool {
+	definitionURL := def.URL
+	settings := s.config.APIDefinitionSettings
+	if settings.MaxEndpoints > 0 && len(def.Endpoints) > settings.MaxEndpoints {
+		def.Endpoints = def.Endpoints[:settings.MaxEndpoints]
+	}
+
+	defResult := &Result{
+		URL:          definitionURL,
+		StatusCode:   200,
+		ContentType:  "application/json",
+		Links:        make([]string, 0),
+		LinkSources:  make(map[string]string),
+		Assets:       make([]string, 0),
+		Forms:        make([]Form, 0),
+		APIs:         make([]string, 0),
+		POSTRequests: make([]POSTRequest, 0),
+		Headers:      make(map[string]string),
+		Crawled:      true,
+	}
+	if def.Type == APIDefinitionWSDL11 || def.Type == APIDefinitionWSDL20 || def.Type == APIDefinitionWADL {
+		defResult.ContentType = "application/xml"
+	}
+	for _, ep := range def.Endpoints {
+		defResult.APIs = appendUniqueString(defResult.APIs, ep.URL)
+		if ep.Method != "GET" {
+			defResult.POSTRequests = append(defResult.POSTRequests, POSTRequest{
+				URL:         ep.URL,
+				Method:      ep.Method,
+				Parameters:  ep.BodyParams(),
+				Body:        ep.Body,
+				ContentType: ep.ContentType,
+				Headers:     ep.Headers,
+			})
+			continue
+		}
+		if settings.InScopeOnly && !s.isInTargetDomain(ep.URL) {
+			continue
+		}
+		if _, exists := defResult.LinkSources[ep.URL]; !exists {
+			defResult.Links = append(defResult.Links, ep.URL)
+			defResult.LinkSources[ep.URL] = LinkSourceAPIDefinition
+		}
+	}
+
+	hash := fmt.Sprintf("sha1:%x", sha1.Sum(body))
+	s.mutex.Lock()
+	if s.apiDefinitionSeen[hash] {
+		s.mutex.Unlock()
+		return false
 	}
+	s.apiDefinitionSeen[hash] = true
+	s.apiDefinitions = append(s.apiDefinitions, def)
+	s.results = append(s.results, defResult)
+	s.visitedURLs[definitionURL] = true
+	s.mutex.Unlock()
 
-	fmt.Printf("准备分析 %d 个跨域JS文件...\n", len(jsToAnalyze))
-
-	// 分析每个JS文件
-	totalURLsFound := 0
-	for _, jsURL := range jsToAnalyze {
-		urls := s.analyzeExternalJS(jsURL)
-		if len(urls) > 0 {
-			fmt.Printf("  从 %s 提取了 %d 个URL\n", jsURL, len(urls))
-			totalURLsFound += len(urls)
+	title := def.Title
+	if title == "" {
+		title = "-"
+	}
+	fmt.Printf("  [API定义] %s [%s, %s] 端点: %d\n", definitionURL, def.Type, title, len(def.Endpoints))
+	return true
+}
 
-			// 添加到跨域JS发现列表
-			s.mutex.Lock()
-			s.crossDomainJS = append(s.crossDomainJS, urls...)
-			s.mutex.Unlock()
+// GetAPIDefinitions 获取已解析的API定义（🆕 v4.9）
+func (s *Spider) GetAPIDefinitions() []*APIDefinition {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	definitions := make([]*APIDefinition, len(s.apiDefinitions))
+	copy(definitions, s.apiDefinitions)
+	return definitions
+}
 
-		// 添加到爬取队列（如果启用递归爬取）
-		if s.config.DepthSettings.MaxDepth > 1 {
-			addedCount := 0
-			// filteredCount := 0  // 🔥 禁用过滤计数
-			for _, u := range urls {
-				// 🔥🔥🔥 关键修复：禁用URL验证器过滤 🔥🔥🔥
-				// 原因：从14074个JS提取的URL中，只有110个通过验证(0.8%)
-				//      被过滤的13964个URL(99.2%)完全丢失，用户反馈需要保存所有URL
-				// 修复：临时禁用验证器，保存所有从JS提取的URL
-				//
-				// if s.urlValidator != nil && !s.urlValidator.IsValidBusinessURL(u) {
-				// 	filteredCount++
-				// 	continue
-				// }
-				
-            // 添加到结果中（作为发现的链接，统一过滤）
-            if len(s.results) > 0 {
-                base := &url.URL{Scheme: "https", Host: s.targetDomain}
-                if s.addLinkWithFilterToResult(s.results[0], base, u) {
-                    addedCount++
-                }
-            }
-			}
-			// if filteredCount > 0 {
-			// 	fmt.Printf("    [跨域JS过滤] 过滤了 %d 个无效URL，保留 %d 个有效URL\n", filteredCount, addedCount)
-			// }
-			if addedCount > 0 {
-				fmt.Printf("    [跨域JS] ✅ 添加了 %d 个URL到结果（已禁用过滤，保存所有URL）\n", addedCount)
-			}
-		}
-		}
+// fetchText 使用性能优化的HTTP客户端下载文本内容（仅2xx，限制大小）
+func (s *Spider) fetchText(rawURL string, maxSize int64) ([]byte, error) {
+	req, err := http.NewRequest("GET", rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.config.AntiDetectionSettings.UserAgents) > 0 {
+		req.Header.Set("User-Agent", s.config.AntiDetectionSettings.UserAgents[0])
+	} else {
+		req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+	}
+	resp, err := s.perfOptimizer.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
 	}
+	return io.ReadAll(&io.LimitedReader{R: resp.Body, N: maxSize})
+}
 
-	fmt.Printf("跨域JS分析完成！共从 %d 个JS文件中提取了 %d 个目标域名URL\n\n", len(jsToAnalyze), totalURLsFound)
+// GetChunkEnumerator 获取分块枚举器（🆕 v4.9，实现SpiderRecorder接口）
+func (s *Spider) GetChunkEnumerator() *ChunkEnumerator {
+	return s.chunkEnumerator
+}
+
+// PrintChunkEnumerationReport 打印分块枚举报告（🆕 v4.9）
+func (s *Spider) PrintChunkEnumerationReport() {
+	s.chunkEnumerator.PrintReport()
 }
 
 // analyzeExternalJS 下载并分析外部JS文件（使用性能优化）
-func (s *Spider) analyzeExternalJS(jsURL string) []string {
+// 🆕 v4.9: 同时返回运行时/清单中枚举出的分块URL
+func (s *Spider) analyzeExternalJS(jsURL string) ([]string, []string) {
 	// 使用性能优化的HTTP客户端
 	req, err := http.NewRequest("GET", jsURL, nil)
 	if err != nil {
 		fmt.Printf("    创建请求失败: %v\n", err)
-		return []string{}
+		return []string{}, nil
 	}
 
 	// 设置User-Agent
@@ -1449,14 +3305,14 @@ func (s *Spider) analyzeExternalJS(jsURL string) []string {
 	resp, err := s.perfOptimizer.DoRequest(req)
 	if err != nil {
 		fmt.Printf("    下载失败: %v\n", err)
-		return []string{}
+		return []string{}, nil
 	}
 	defer resp.Body.Close()
 
 	// 检查状态码
 	if resp.StatusCode != 200 {
 		fmt.Printf("    HTTP %d\n", resp.StatusCode)
-		return []string{}
+		return []string{}, nil
 	}
 
 	// 使用Buffer池读取内容
@@ -1470,14 +3326,58 @@ func (s *Spider) analyzeExternalJS(jsURL string) []string {
 	_, err = buf.ReadFrom(limitedReader)
 	if err != nil {
 		fmt.Printf("    读取内容失败: %v\n", err)
-		return []string{}
+		return []string{}, nil
 	}
 
 	// 使用增强的JS分析器提取URL
 	jsCode := buf.String()
 
-	// 使用增强分析
-	enhancedResult := s.jsAnalyzer.EnhancedAnalyze(jsCode)
+	// 🆕 v4.9: 登记脚本的Source Map（爬取结束后统一下载还原）
+	sourceMapHeader := resp.Header.Get("SourceMap")
+	if sourceMapHeader == "" {
+		sourceMapHeader = resp.Header.Get("X-SourceMap")
+	}
+	s.registerSourceMap(jsURL, jsCode, sourceMapHeader)
+
+	// 🆕 v4.9: 识别前端组件版本（横幅/内容哈希）
+	if s.jsLibScanner != nil {
+		s.jsLibScanner.ScanContent(jsURL, jsCode)
+	}
+
+	// 🆕 v4.9: 收集JS包中的GraphQL操作
+	if s.graphqlDiscovery != nil {
+		s.graphqlDiscovery.HarvestScript(jsCode, jsURL)
+	}
+
+	// 🆕 v4.9: 识别JS包中的gRPC-Web服务
+	if s.serviceDetector != nil && s.config.APIServiceSettings.ScanJS {
+		s.serviceDetector.ScanScript(jsCode, jsURL, s.serviceBaseURL())
+	}
+
+	// 🆕 v4.9: 扫描脚本中的敏感信息（本域/跨域脚本只做资源下载，不经过addResult）
+	if s.config.SensitiveDetectionSettings.Enabled && s.config.SensitiveDetectionSettings.ScanResponseBody && s.sensitiveDetector != nil {
+		contentType := resp.Header.Get("Content-Type")
+		if contentType == "" {
+			contentType = "application/javascript"
+		}
+		// 大型脚本包扫描耗时较长，只在追加结果时持有s.mutex
+		findings := s.sensitiveDetector.ScanContent(jsCode, jsURL, contentType)
+		s.mutex.Lock()
+		s.sensitiveFindings = append(s.sensitiveFindings, findings...)
+		s.mutex.Unlock()
+		if len(findings) > 0 {
+			fmt.Printf("    [敏感信息] 发现 %d 处\n", len(findings))
+		}
+	}
+
+	// 🆕 v4.9: 枚举webpack/Vite运行时中的分块
+	chunks := s.chunkEnumerator.FromScript(jsCode, jsURL)
+	if len(chunks) > 0 {
+		fmt.Printf("    [分块枚举] 发现 %d 个分块脚本\n", len(chunks))
+	}
+
+	// 使用增强分析（🆕 v4.9: AST优先，记录端点出处）
+	enhancedResult := s.jsAnalyzer.EnhancedAnalyzeSource(jsCode, jsURL)
 
 	// 合并所有发现的URL
 	urls := make([]string, 0)
@@ -1521,7 +3421,7 @@ func (s *Spider) analyzeExternalJS(jsURL string) []string {
 		}
 	}
 
-	return urls
+	return urls, chunks
 }
 
 // crawlRecursively 递归爬取发现的链接（单层爬取，已废弃）
@@ -1531,60 +3431,166 @@ func (s *Spider) crawlRecursively() {
 }
 
 // crawlRecursivelyMultiLayer 真正的多层递归爬取（修复深度问题）
+// 🆕 v4.9: 改为流水线调度，URL完成后立即展开下一层链接，不再等待整层结束
 func (s *Spider) crawlRecursivelyMultiLayer() {
-	fmt.Println("开始多层递归爬取...")
+	fmt.Println("开始多层递归爬取（流水线调度）...")
 
-	currentDepth := 1
-	totalCrawled := 0
+	maxDepth := s.config.DepthSettings.MaxDepth
+	if maxDepth < 2 {
+		fmt.Println("最大深度小于2，跳过递归爬取")
+		return
+	}
 
-	// 循环爬取每一层，直到达到最大深度
-	for currentDepth < s.config.DepthSettings.MaxDepth {
-		currentDepth++
+	// 🔧 修复：每层URL限制可配置（默认500）
+	maxURLsPerLayer := 500
+	if s.config.SchedulingSettings.HybridConfig.MaxURLsPerLayer > 0 {
+		maxURLsPerLayer = s.config.SchedulingSettings.HybridConfig.MaxURLsPerLayer
+	}
 
-		fmt.Printf("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-		fmt.Printf("【第 %d 层爬取】最大深度: %d\n", currentDepth, s.config.DepthSettings.MaxDepth)
-		fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	frontier := s.newCrawlFrontier(s.config.SchedulingSettings.PerformanceConfig.MaxTotalURLs, maxURLsPerLayer)
+	fmt.Printf("调度器: %s\n", frontier.Describe())
 
-		// 收集当前层需要爬取的链接
-		layerLinks := s.collectLinksForLayer(currentDepth)
+	// 🆕 v4.9: 先分析第1层引用的文档，文档中的链接一并作为种子
+	if s.documentAnalyzer != nil {
+		s.analyzeCrawledDocuments()
+	}
 
-		if len(layerLinks) == 0 {
-			fmt.Printf("第 %d 层没有新链接，递归结束\n", currentDepth)
-			break
-		}
+	// 种子：第1层（起始页+静态/动态爬取结果）中发现的链接
+	seeds := s.collectLinksForLayer(2)
+	if len(seeds) == 0 {
+		fmt.Println("第 2 层没有新链接，递归结束")
+		return
+	}
+	s.markVisited(frontier.Push(seeds, 2, ""))
+	fmt.Printf("第 2 层初始入队 %d 个链接...\n", len(seeds))
 
-		fmt.Printf("第 %d 层准备爬取 %d 个链接...\n", currentDepth, len(layerLinks))
+	frontier.Run(
+		func(task Task) (*Result, error) {
+			return s.crawlURL(task.URL)
+		},
+		func(task Task, result *Result) {
+			s.mutex.Lock()
+			s.results = append(s.results, result)
+			s.mutex.Unlock()
 
-		// 爬取当前层的所有链接
-		newResults := s.crawlLayer(layerLinks, currentDepth)
+			// 🆕 v4.9: 页面引用的文档在展开链接前分析（并发执行，不占用展开锁）
+			if s.documentAnalyzer != nil && task.Depth < maxDepth {
+				s.analyzeLinkedDocuments(result)
+			}
+		},
+		func(task Task, result *Result) {
+			nextDepth := task.Depth + 1
+			if nextDepth > maxDepth {
+				return
+			}
+			// 文档中的链接与页面链接处于同一层
+			candidates := s.collectCandidateLinks(append([]*Result{result}, s.linkedDocumentResults(result)...))
+			if len(candidates) == 0 {
+				return
+			}
+			links := s.selectLinksToCrawl(candidates, nextDepth)
+			s.markVisited(frontier.Push(links, nextDepth, task.URL))
+		},
+	)
 
-		// 合并结果
-		s.mutex.Lock()
-		s.results = append(s.results, newResults...)
-		s.mutex.Unlock()
+	stats := frontier.GetStats()
+	totalCrawled := 0
+	deepest := 1
+	for depth, st := range stats {
+		totalCrawled += st.Completed
+		if st.Completed > 0 && depth > deepest {
+			deepest = depth
+		}
+	}
 
-		totalCrawled += len(layerLinks)
-		fmt.Printf("第 %d 层爬取完成！本层爬取 %d 个URL，累计 %d 个\n",
-			currentDepth, len(layerLinks), totalCrawled)
+	fmt.Printf("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	fmt.Printf("【递归爬取统计】最大深度: %d\n", maxDepth)
+	frontier.PrintStats()
+	fmt.Printf("\n多层递归爬取完成！总共爬取 %d 个URL，深度 %d 层\n", totalCrawled, deepest)
+}
 
-		// 检查是否达到URL限制
-		if totalCrawled >= 500 {
-			fmt.Printf("已达到URL限制(500)，递归结束\n")
-			break
-		}
+// newCrawlFrontier 按配置创建爬取调度器（maxTotal、maxPerDepth 为0表示不限制）
+// worker数取 performance_config.max_concurrent_requests，启用 rate_limit_settings 时按 requests_per_second 限速
+func (s *Spider) newCrawlFrontier(maxTotal, maxPerDepth int) *CrawlFrontier {
+	workers := frontierWorkerCount(s.config)
+	maxQPS := 0
+	if s.config.RateLimitSettings.Enabled {
+		maxQPS = s.config.RateLimitSettings.RequestsPerSecond
 	}
+	return NewCrawlFrontier(workers, maxQPS, maxTotal, maxPerDepth)
+}
+
+// crawlURLsWithFrontier 通过调度器爬取一组URL（不展开链接），按给定顺序出队，返回成功的结果
+// 供按层/按批调度的策略（混合策略、优先级队列）使用，并发和限速与递归爬取一致
+func (s *Spider) crawlURLsWithFrontier(urls []string, depth int) []*Result {
+	s.markVisited(urls)
+
+	frontier := s.newCrawlFrontier(0, 0)
+	frontier.Push(urls, depth, "")
+
+	results := make([]*Result, 0, len(urls))
+	var resultsMutex sync.Mutex
+	frontier.Run(
+		func(task Task) (*Result, error) {
+			return s.crawlURL(task.URL)
+		},
+		func(task Task, result *Result) {
+			resultsMutex.Lock()
+			results = append(results, result)
+			resultsMutex.Unlock()
+		},
+		nil,
+	)
+
+	stats := frontier.GetStats()[depth]
+	fmt.Printf("  本层统计 - 总任务: %d, 成功: %d, 失败: %d\n",
+		stats.Enqueued, stats.Completed-stats.Failed, stats.Failed)
+	return results
+}
 
-	fmt.Printf("\n多层递归爬取完成！总共爬取 %d 个URL，深度 %d 层\n", totalCrawled, currentDepth)
+// markVisited 标记URL为已访问
+func (s *Spider) markVisited(links []string) {
+	if len(links) == 0 {
+		return
+	}
+	s.mutex.Lock()
+	for _, link := range links {
+		s.visitedURLs[link] = true
+	}
+	s.mutex.Unlock()
 }
 
 // collectLinksForLayer 收集指定层需要爬取的链接
 func (s *Spider) collectLinksForLayer(targetDepth int) []string {
+	s.mutex.Lock()
+	results := make([]*Result, len(s.results))
+	copy(results, s.results)
+	s.mutex.Unlock()
+
+	allLinks := s.collectCandidateLinks(results)
+
+	// 🆕 v4.9: 导入流量中没有响应的请求作为第2层种子
+	if targetDepth == 2 {
+		s.mutex.Lock()
+		for _, seed := range s.importedSeeds {
+			if !s.visitedURLs[seed] {
+				allLinks[seed] = true
+			}
+		}
+		s.mutex.Unlock()
+	}
+
+	return s.selectLinksToCrawl(allLinks, targetDepth)
+}
+
+// collectCandidateLinks 从结果中收集未访问的作用域内链接（规范化后），并记录外部链接
+func (s *Spider) collectCandidateLinks(results []*Result) map[string]bool {
 	allLinks := make(map[string]bool)
 	externalLinks := make([]string, 0)
 
 	s.mutex.Lock()
 	// 从所有结果中收集链接
-	for _, result := range s.results {
+	for _, result := range results {
 		for _, link := range result.Links {
 			// 检查是否已访问
 			if s.visitedURLs[link] {
@@ -1603,7 +3609,9 @@ func (s *Spider) collectLinksForLayer(targetDepth int) []string {
 				// 规范化URL
 				normalizedURL, err := s.paramHandler.NormalizeURL(link)
 				if err == nil {
-					allLinks[normalizedURL] = true
+					if !s.visitedURLs[normalizedURL] {
+						allLinks[normalizedURL] = true
+					}
 				} else {
 					allLinks[link] = true
 				}
@@ -1624,6 +3632,11 @@ func (s *Spider) collectLinksForLayer(targetDepth int) []string {
 		fmt.Printf("  发现 %d 个外部链接（已记录但不爬取）\n", len(externalLinks))
 	}
 
+	return allLinks
+}
+
+// selectLinksToCrawl 对候选链接执行过滤管道并按优先级排序
+func (s *Spider) selectLinksToCrawl(allLinks map[string]bool, targetDepth int) []string {
 	// 转换为列表并优先级排序
 	tasksToSubmit := make([]string, 0)
 	skippedBySmart := 0 // 统计智能去重跳过的数量
@@ -1922,55 +3935,6 @@ func (s *Spider) prioritizeURLsWithDepth(urls []string, depth int) []string {
 	return s.prioritizeURLs(urls)
 }
 
-// crawlLayer 爬取一层的所有链接
-func (s *Spider) crawlLayer(links []string, depth int) []*Result {
-	results := make([]*Result, 0)
-
-	// 标记为已访问
-	s.mutex.Lock()
-	for _, link := range links {
-		s.visitedURLs[link] = true
-	}
-	s.mutex.Unlock()
-
-	// 为每层创建新的工作池（修复：避免复用已关闭的工作池）
-	layerWorkerPool := NewWorkerPool(30, 20)
-
-	// 启动工作池
-	layerWorkerPool.Start(func(task Task) (*Result, error) {
-		return s.crawlURL(task.URL)
-	})
-
-	// 提交所有任务
-	for _, link := range links {
-		// 🔧 v4.8: 已废弃 URL模式+DOM去重，使用新的相似URL去重和DOM Embedding替代
-		
-		task := Task{
-			URL:   link,
-			Depth: depth,
-		}
-		if err := layerWorkerPool.Submit(task); err != nil {
-			fmt.Printf("  提交任务失败 %s: %v\n", link, err)
-		}
-	}
-
-	// 等待完成（不显示进度，避免干扰）
-	layerWorkerPool.Wait()
-
-	// 收集结果
-	results = layerWorkerPool.GetResults()
-
-	// 停止工作池
-	layerWorkerPool.Stop()
-
-	// 显示统计
-	stats := layerWorkerPool.GetStats()
-	fmt.Printf("  本层统计 - 总任务: %d, 成功: %d, 失败: %d\n",
-		stats["total"], stats["completed"]-stats["failed"], stats["failed"])
-
-	return results
-}
-
 // crawlURL 爬取单个URL（供工作池使用）
 func (s *Spider) crawlURL(targetURL string) (*Result, error) {
 	// 解析URL
@@ -2022,52 +3986,175 @@ func (s *Spider) showProgress() {
 	}
 }
 
-// ImportFromBurp 从Burp Suite文件导入
-func (s *Spider) ImportFromBurp(filename string) error {
-	fmt.Printf("从Burp Suite导入流量: %s\n", filename)
+// QueueTrafficImport 登记爬取开始时导入的流量文件（v4.9新增）
+// format为burp/har/zap/mitmproxy/raw，为空或auto时按文件内容识别；
+// 导入在起始页爬取之后进行，此时目标域名和作用域已初始化
+func (s *Spider) QueueTrafficImport(format, filename string) {
+	s.trafficImports = append(s.trafficImports, trafficImport{format: format, filename: filename})
+}
 
-	// 创建被动爬取器
-	s.passiveCrawler = NewPassiveCrawler("burp")
+// trafficImport 待导入的流量文件
+type trafficImport struct {
+	format   string
+	filename string
+}
 
-	// 加载Burp文件
-	err := s.passiveCrawler.LoadFromBurp(filename)
-	if err != nil {
-		return err
+// importQueuedTraffic 导入登记的流量文件（导入失败只记录，不中断爬取）
+func (s *Spider) importQueuedTraffic() {
+	for _, item := range s.trafficImports {
+		if err := s.ImportTraffic(item.format, item.filename); err != nil {
+			s.logger.Error("导入流量失败", "file", item.filename, "error", err)
+		}
 	}
+}
 
-	// 过滤目标域名的URL
-	targetURLs := s.passiveCrawler.FilterByDomain(s.targetDomain)
-	fmt.Printf("过滤后得到目标域名URL: %d个\n", len(targetURLs))
+// ImportTraffic 导入流量文件（v4.9新增，format为空或auto时按文件内容识别格式）
+func (s *Spider) ImportTraffic(format, filename string) error {
+	pc := NewPassiveCrawler(format)
+	if targetURL, err := url.Parse(s.config.TargetURL); err == nil && targetURL.Scheme != "" {
+		pc.SetDefaultScheme(targetURL.Scheme)
+	}
 
-	// 将导入的URL和表单加入结果
-	passiveResult := s.passiveCrawler.ExportToResult(s.targetDomain)
-	s.addResult(passiveResult)
+	detected, err := pc.LoadFile(filename, format)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("导入流量: %s（格式: %s）\n", filename, detected)
 
+	s.ingestImportedTraffic(pc)
 	return nil
 }
 
+// ImportFromBurp 从Burp Suite文件导入
+func (s *Spider) ImportFromBurp(filename string) error {
+	return s.ImportTraffic(ImportFormatBurp, filename)
+}
+
 // ImportFromHAR 从HAR文件导入
 func (s *Spider) ImportFromHAR(filename string) error {
-	fmt.Printf("从HAR文件导入流量: %s\n", filename)
+	return s.ImportTraffic(ImportFormatHAR, filename)
+}
 
-	// 创建被动爬取器
-	s.passiveCrawler = NewPassiveCrawler("har")
+// ingestImportedTraffic 把导入的请求加入结果（v4.9新增）
+// 带响应的请求按实际状态码、响应头和响应体经过与爬取相同的提取和检测，URL标记为已访问；
+// 没有响应的GET请求作为递归爬取的种子，其余方法的请求记录为POSTRequest；
+// 从请求体还原的表单附加到对应结果
+func (s *Spider) ingestImportedTraffic(pc *PassiveCrawler) {
+	s.passiveCrawler = pc
 
-	// 加载HAR文件
-	err := s.passiveCrawler.LoadFromHAR(filename)
-	if err != nil {
-		return err
+	// 已有结果的GET请求不重复分析（代理历史中同一页面通常出现多次）
+	s.mutex.Lock()
+	analyzedURLs := make(map[string]bool, len(s.results))
+	for _, result := range s.results {
+		if result.Crawled && (result.Method == "" || result.Method == http.MethodGet) {
+			analyzedURLs[result.URL] = true
+		}
 	}
+	s.mutex.Unlock()
 
-	// 过滤目标域名的URL
-	targetURLs := s.passiveCrawler.FilterByDomain(s.targetDomain)
-	fmt.Printf("过滤后得到目标域名URL: %d个\n", len(targetURLs))
+	analyzed, seeded, requestsOnly, outOfScope := 0, 0, 0, 0
+	for _, exchange := range pc.GetExchanges() {
+		if !s.isInTargetDomain(exchange.URL) {
+			outOfScope++
+			continue
+		}
+		isGET := exchange.Method == "" || exchange.Method == http.MethodGet
+		if isGET && analyzedURLs[exchange.URL] {
+			continue
+		}
+
+		if exchange.StatusCode == 0 {
+			if isGET {
+				analyzedURLs[exchange.URL] = true
+				s.mutex.Lock()
+				if !s.visitedURLs[exchange.URL] {
+					s.importedSeeds = append(s.importedSeeds, exchange.URL)
+					seeded++
+				}
+				s.mutex.Unlock()
+			} else {
+				s.addResult(importedRequestResult(exchange))
+				requestsOnly++
+			}
+			continue
+		}
 
-	// 将导入的URL和表单加入结果
-	passiveResult := s.passiveCrawler.ExportToResult(s.targetDomain)
-	s.addResult(passiveResult)
+		req := &InterceptedRequest{
+			Method:      exchange.Method,
+			URL:         exchange.URL,
+			Headers:     make(map[string]string, len(exchange.RequestHeaders)),
+			Body:        string(exchange.RequestBody),
+			ContentType: exchange.RequestHeaders.Get("Content-Type"),
+		}
+		for key, values := range exchange.RequestHeaders {
+			req.Headers[key] = strings.Join(values, ", ")
+		}
+		resp := &http.Response{
+			StatusCode: exchange.StatusCode,
+			Status:     fmt.Sprintf("%d %s", exchange.StatusCode, http.StatusText(exchange.StatusCode)),
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     exchange.ResponseHeaders,
+		}
 
-	return nil
+		result := s.buildTrafficResult(req, resp, exchange.ResponseBody)
+		if result == nil {
+			continue
+		}
+		if exchange.Form != nil {
+			result.Forms = append(result.Forms, *exchange.Form)
+		}
+		if isGET {
+			analyzedURLs[exchange.URL] = true
+		}
+		s.markVisited([]string{exchange.URL})
+		s.addResult(result)
+		analyzed++
+	}
+
+	fmt.Printf("导入结果: 分析响应 %d 个, 待爬取种子 %d 个, 仅请求 %d 个, 域外跳过 %d 个\n",
+		analyzed, seeded, requestsOnly, outOfScope)
+}
+
+// importedRequestResult 没有响应的非GET请求（如sqlmap -r请求文件）转换为未爬取的结果
+// 请求本身记录为POSTRequest，参与POST请求输出和API分析
+func importedRequestResult(exchange *ImportedExchange) *Result {
+	contentType := exchange.RequestHeaders.Get("Content-Type")
+	headers := make(map[string]string, len(exchange.RequestHeaders))
+	for key, values := range exchange.RequestHeaders {
+		headers[key] = strings.Join(values, ", ")
+	}
+	post := POSTRequest{
+		URL:         exchange.URL,
+		Method:      exchange.Method,
+		Parameters:  make(map[string]string),
+		Body:        string(exchange.RequestBody),
+		ContentType: contentType,
+		Headers:     headers,
+	}
+	if exchange.Form != nil {
+		for _, field := range exchange.Form.Fields {
+			post.Parameters[field.Name] = field.Value
+		}
+	}
+
+	result := &Result{
+		URL:          exchange.URL,
+		Method:       exchange.Method,
+		Links:        make([]string, 0),
+		LinkSources:  make(map[string]string),
+		Assets:       make([]string, 0),
+		Forms:        make([]Form, 0),
+		APIs:         make([]string, 0),
+		POSTRequests: []POSTRequest{post},
+		Headers:      make(map[string]string),
+		SkipReason:   "导入的请求没有响应",
+	}
+	if exchange.Form != nil {
+		result.Forms = append(result.Forms, *exchange.Form)
+	}
+	return result
 }
 
 // Stop 停止爬取
@@ -2100,6 +4187,11 @@ func (s *Spider) Close() error {
 	// 等待所有 goroutine 完成
 	s.wg.Wait()
 
+	// 🆕 v4.9: 结束空闲的JS沙箱工作进程
+	if s.jsSandbox != nil {
+		s.jsSandbox.Close()
+	}
+
 	// 关闭 done channel
 	close(s.done)
 
@@ -2344,7 +4436,13 @@ func (s *Spider) ExportResults() map[string]interface{} {
 		resultData["url"] = result.URL
 		resultData["status_code"] = result.StatusCode
 		resultData["content_type"] = result.ContentType
+		if result.Charset != "" {
+			resultData["charset"] = result.Charset // 🆕 v4.9: 原始字符集
+		}
 		resultData["links"] = result.Links
+		if len(result.LinkSources) > 0 {
+			resultData["link_sources"] = result.LinkSources // 🆕 v4.9: 链接来源提取器
+		}
 		resultData["assets"] = result.Assets
 		resultData["forms"] = result.Forms
 		resultData["apis"] = result.APIs
@@ -2648,7 +4746,13 @@ func (s *Spider) SaveSensitiveInfoToFile(filepath string) error {
 			file.WriteString(fmt.Sprintf("[%d] %s\n", i+1, finding.Type))
 			file.WriteString(fmt.Sprintf("    来源URL: %s\n", finding.SourceURL))
 			file.WriteString(fmt.Sprintf("    位置: %s\n", finding.Location))
+			if finding.ContentType != "" {
+				file.WriteString(fmt.Sprintf("    内容类型: %s\n", finding.ContentType))
+			}
 			file.WriteString(fmt.Sprintf("    值: %s\n", displayValue))
+			if finding.Snippet != "" {
+				file.WriteString(fmt.Sprintf("    上下文: %s\n", finding.Snippet))
+			}
 			file.WriteString("\n")
 		}
 		file.WriteString("\n")
@@ -2668,7 +4772,13 @@ func (s *Spider) SaveSensitiveInfoToFile(filepath string) error {
 			file.WriteString(fmt.Sprintf("[%d] %s\n", i+1, finding.Type))
 			file.WriteString(fmt.Sprintf("    来源URL: %s\n", finding.SourceURL))
 			file.WriteString(fmt.Sprintf("    位置: %s\n", finding.Location))
+			if finding.ContentType != "" {
+				file.WriteString(fmt.Sprintf("    内容类型: %s\n", finding.ContentType))
+			}
 			file.WriteString(fmt.Sprintf("    值: %s\n", displayValue))
+			if finding.Snippet != "" {
+				file.WriteString(fmt.Sprintf("    上下文: %s\n", finding.Snippet))
+			}
 			file.WriteString("\n")
 		}
 		file.WriteString("\n")
@@ -2759,6 +4869,60 @@ func (s *Spider) SaveSensitiveInfoToJSON(filepath string) error {
 	return nil
 }
 
+// SaveSensitiveRuleStats 保存敏感信息规则的扫描统计（🆕 v4.9）
+// 记录每条规则的预过滤关键词、执行/跳过次数、匹配次数和正则耗时，并输出最慢的规则
+func (s *Spider) SaveSensitiveRuleStats(filepath string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	
+	if s.sensitiveDetector == nil {
+		return fmt.Errorf("敏感信息检测器未初始化")
+	}
+	
+	ruleStats := s.sensitiveDetector.GetRuleStats()
+	if len(ruleStats) == 0 {
+		return nil
+	}
+	
+	prefiltered, totalCandidates, totalSkipped := 0, 0, 0
+	for _, stats := range ruleStats {
+		if stats.KeywordSource != "none" {
+			prefiltered++
+		}
+		totalCandidates += stats.Candidates
+		totalSkipped += stats.Skipped
+	}
+	
+	report := map[string]interface{}{
+		"scan_time":         time.Now().Format("2006-01-02 15:04:05"),
+		"total_rules":       len(ruleStats),
+		"prefiltered_rules": prefiltered,
+		"regex_runs":        totalCandidates,
+		"regex_skipped":     totalSkipped,
+		"rules":             ruleStats,
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("JSON编码失败: %v", err)
+	}
+	if err := os.WriteFile(filepath, data, 0644); err != nil {
+		return fmt.Errorf("写入规则统计失败: %v", err)
+	}
+	
+	fmt.Printf("  ✅ 敏感规则统计已保存: %s\n", filepath)
+	fmt.Printf("     %d/%d 条规则启用关键词预过滤，正则执行 %d 次，跳过 %d 次\n",
+		prefiltered, len(ruleStats), totalCandidates, totalSkipped)
+	for i, stats := range ruleStats {
+		if i >= 5 || stats.DurationMS == 0 {
+			break
+		}
+		fmt.Printf("     最慢规则 #%d: %s (%.2fms, 执行 %d 次, 匹配 %d 次)\n",
+			i+1, stats.Name, stats.DurationMS, stats.Candidates, stats.Matches)
+	}
+	
+	return nil
+}
+
 // ExportSensitiveInfoUnified 🆕 v4.2: 统一导出敏感信息（多种格式）
 // 这是推荐的敏感信息导出方法，会自动生成：
 // - TXT格式（详细文本报告）
@@ -3281,6 +5445,11 @@ func (s *Spider) crawlWithHybridStrategy() {
 		fmt.Printf("【第 %d 层爬取】混合策略模式 | 最大深度: %d\n", currentDepth, s.config.DepthSettings.MaxDepth)
 		fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
 		
+		// 🆕 v4.9: 上一层引用的文档先分析，文档中的链接进入本层
+		if s.documentAnalyzer != nil {
+			s.analyzeCrawledDocuments()
+		}
+		
 		// 1. 收集当前层的所有URL（BFS框架）
 		layerURLs := s.collectLinksForLayer(currentDepth)
 		
@@ -3418,15 +5587,8 @@ func (s *Spider) crawlLayerWithPriority(urlsWithPriority []*URLWithPriority, dep
 		urls = append(urls, item.URL)
 	}
 	
-	// 标记为已访问
-	s.mutex.Lock()
-	for _, url := range urls {
-		s.visitedURLs[url] = true
-	}
-	s.mutex.Unlock()
-	
-	// 创建工作池（复用现有的crawlLayer逻辑）
-	return s.crawlLayer(urls, depth)
+	// 🆕 v4.9: 通过调度器按优先级顺序爬取（并发和限速取自配置）
+	return s.crawlURLsWithFrontier(urls, depth)
 }
 
 // crawlWithPriorityQueue 🆕 使用优先级队列模式爬取（实验性）
@@ -3434,6 +5596,11 @@ func (s *Spider) crawlWithPriorityQueue() {
 	fmt.Println("\n开始优先级队列模式爬取...")
 	fmt.Println("算法：纯优先级队列调度（实验性）")
 	
+	// 🆕 v4.9: 第1层引用的文档先分析，文档中的链接一并入队
+	if s.documentAnalyzer != nil {
+		s.analyzeCrawledDocuments()
+	}
+	
 	// 将所有已发现的URL添加到优先级队列
 	s.mutex.Lock()
 	for _, result := range s.results {
@@ -3442,6 +5609,10 @@ func (s *Spider) crawlWithPriorityQueue() {
 			s.priorityScheduler.AddURL(link, 2)
 		}
 	}
+	// 🆕 v4.9: 导入流量中没有响应的请求
+	for _, seed := range s.importedSeeds {
+		s.priorityScheduler.AddURL(seed, 2)
+	}
 	s.mutex.Unlock()
 	
 	fmt.Printf("优先级队列初始化完成，队列大小: %d\n", s.priorityScheduler.Size())
@@ -3476,14 +5647,25 @@ func (s *Spider) crawlWithPriorityQueue() {
 		}
 		
 		// 爬取这批URL
-		newResults := s.crawlLayer(urls, batch[0].Depth)
+		newResults := s.crawlURLsWithFrontier(urls, batch[0].Depth)
 		
 		// 合并结果
 		s.mutex.Lock()
 		s.results = append(s.results, newResults...)
+		s.mutex.Unlock()
+		
+		// 🆕 v4.9: 本批页面引用的文档（已加入s.results），其中的链接与页面链接一起入队
+		linkSources := append([]*Result{}, newResults...)
+		if s.documentAnalyzer != nil {
+			for _, result := range newResults {
+				s.analyzeLinkedDocuments(result)
+				linkSources = append(linkSources, s.linkedDocumentResults(result)...)
+			}
+		}
 		
 		// 将新发现的URL添加到优先级队列
-		for _, result := range newResults {
+		s.mutex.Lock()
+		for _, result := range linkSources {
 			for _, newLink := range result.Links {
 				if !s.priorityScheduler.IsVisited(newLink) {
 					// 新链接的深度 = 当前深度 + 1
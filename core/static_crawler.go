@@ -1,14 +1,19 @@
 package core
 
 import (
+	"bytes"
 	"crypto/tls"
 	"fmt"
+	"io"
 	"math/rand"
+	"net"
 	"net/http"
 	"net/url"
 	"path"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
@@ -18,7 +23,11 @@ import (
 
 // StaticCrawlerImpl 静态爬虫实现
 type StaticCrawlerImpl struct {
-	collector        *colly.Collector
+	collector        *colly.Collector     // 🆕 v4.9：长期复用的collector（首次Crawl时创建）
+	collectorOnce    sync.Once
+	replayCollector  *colly.Collector     // 🆕 v4.9：分析已有响应的collector（不发出网络请求）
+	replayOnce       sync.Once
+	replayTransport  *replayTransport
 	config           *config.Config
 	resultChan       chan<- Result
 	stopChan         chan struct{}
@@ -30,23 +39,16 @@ type StaticCrawlerImpl struct {
 	spider           SpiderRecorder       // Spider引用（v3.7新增，用于实时记录URL）
 	urlNormalizer    *URLNormalizer       // 🆕 v4.0：URL规范化处理器
 	urlQualityFilter *URLQualityFilter    // 🆕 v4.0：URL质量过滤器
+	authManager      *AuthManager         // 🆕 v4.9：HTTP认证管理器
+	contentExtractors *ContentExtractorRegistry // 🆕 v4.9：按Content-Type分派的链接提取器
+	jsASTAnalyzer    *JSASTAnalyzer       // 🆕 v4.9：基于AST的JS端点分析
+	jsSandbox        *JSSandbox           // 🆕 v4.9：反混淆沙箱（nil表示不反混淆）
+	harRecorder      *HARRecorder         // 🆕 v4.9：HAR流量记录器（nil表示不记录）
 }
 
 
 // NewStaticCrawler 创建新的静态爬虫实例
 func NewStaticCrawler(config *config.Config, resultChan chan<- Result, stopChan chan struct{}) StaticCrawler {
-	c := colly.NewCollector(
-		colly.MaxDepth(config.DepthSettings.MaxDepth),
-		colly.Async(true),
-	)
-	
-	// 设置并发限制
-	c.Limit(&colly.LimitRule{
-		DomainGlob:  "*",
-		Parallelism: 5, // 增加并发数
-		Delay:       time.Duration(500) * time.Millisecond, // 减少延迟
-	})
-	
 	// 创建去重处理器
 	duplicateHandler := NewDuplicateHandler(0.9) // 使用默认相似度阈值
 	
@@ -54,7 +56,6 @@ func NewStaticCrawler(config *config.Config, resultChan chan<- Result, stopChan
 	paramHandler := NewParamHandler()
 	
 	return &StaticCrawlerImpl{
-		collector:        c,
 		config:           config,
 		resultChan:       resultChan,
 		stopChan:         stopChan,
@@ -62,6 +63,8 @@ func NewStaticCrawler(config *config.Config, resultChan chan<- Result, stopChan
 		paramHandler:     paramHandler,
 		urlValidator:     NewSmartURLValidatorCompat(), // 🔧 修复：使用v2.0智能验证器
 		urlQualityFilter: NewURLQualityFilter(),        // 🆕 v4.0：URL质量过滤器
+		contentExtractors: NewContentExtractorRegistry(), // 🆕 v4.9：JSON/XML/Feed/文本链接提取
+		jsASTAnalyzer:    NewJSASTAnalyzer(),           // 🆕 v4.9：AST分析内联脚本和JS文件
 	}
 }
 
@@ -69,12 +72,10 @@ func NewStaticCrawler(config *config.Config, resultChan chan<- Result, stopChan
 func (s *StaticCrawlerImpl) Configure(config *config.Config) {
 	s.config = config
 	
-	// 更新并发限制
-	s.collector.Limit(&colly.LimitRule{
-		DomainGlob:  "*",
-		Parallelism: 5, // 增加并发数
-		Delay:       time.Duration(500) * time.Millisecond, // 减少延迟
-	})
+	// 更新并发限制（collector尚未创建时，首次Crawl会按新配置创建）
+	if s.collector != nil {
+		s.applyLimit(s.collector)
+	}
 }
 
 // SetCookieManager 设置Cookie管理器（v3.2新增）
@@ -82,6 +83,26 @@ func (s *StaticCrawlerImpl) SetCookieManager(cm *CookieManager) {
 	s.cookieManager = cm
 }
 
+// SetAuthManager 设置认证管理器（v4.9新增）
+func (s *StaticCrawlerImpl) SetAuthManager(am *AuthManager) {
+	s.authManager = am
+}
+
+// SetHARRecorder 设置HAR流量记录器（v4.9新增）
+func (s *StaticCrawlerImpl) SetHARRecorder(hr *HARRecorder) {
+	s.harRecorder = hr
+}
+
+// SetJSSandbox 设置反混淆沙箱（v4.9新增，与Spider共用同一沙箱）
+func (s *StaticCrawlerImpl) SetJSSandbox(sandbox *JSSandbox) {
+	s.jsSandbox = sandbox
+}
+
+// SetJSASTAnalyzer 设置AST分析器（🆕 v4.9，与Spider的JS分析器共享解析统计）
+func (s *StaticCrawlerImpl) SetJSASTAnalyzer(analyzer *JSASTAnalyzer) {
+	s.jsASTAnalyzer = analyzer
+}
+
 // SetRedirectManager 设置重定向管理器（v3.2新增）
 func (s *StaticCrawlerImpl) SetRedirectManager(rm *RedirectManager) {
 	s.redirectManager = rm
@@ -108,6 +129,7 @@ func (s *StaticCrawlerImpl) Crawl(startURL *url.URL) (*Result, error) {
 		StatusCode:   0,  // 初始值：如果未爬取则保持0
 		ContentType:  "",  // 初始值：如果未爬取则保持空
 		Links:        make([]string, 0),
+		LinkSources:  make(map[string]string),
 		Assets:       make([]string, 0),
 		Forms:        make([]Form, 0),
 		APIs:         make([]string, 0),
@@ -123,28 +145,260 @@ func (s *StaticCrawlerImpl) Crawl(startURL *url.URL) (*Result, error) {
 		ResponseTime: 0,
 	}
 	
-	// 为每次Crawl创建新的collector实例，避免WaitGroup重用问题
-	collector := colly.NewCollector(
-		colly.MaxDepth(s.config.DepthSettings.MaxDepth),
-		colly.Async(true),
-	)
+	ctx := colly.NewContext()
+	task := &staticCrawlTask{result: result}
+	ctx.Put(staticCrawlTaskKey, task)
 	
-	// ✅ 修复5: 配置HTTPS证书验证
-	if s.config.AntiDetectionSettings.InsecureSkipVerify {
-		collector.WithTransport(&http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		})
+	// 🆕 v4.9: 复用长连接collector，同步执行单个URL（并发由调度器控制）
+	if err := s.getCollector().Request("GET", startURL.String(), nil, ctx, nil); err != nil {
+		// 请求已发出但失败（OnError已记录状态），按原逻辑返回结果
+		if result.Crawled || result.Error != nil || result.SkipReason != "" {
+			return result, nil
+		}
+		return nil, fmt.Errorf("访问URL失败 %s: %v", startURL.String(), err)
+	}
+	
+	return result, nil
+}
+
+// AnalyzeResponse 分析已获取的响应（v4.9新增，用于被动代理等外部来源的流量）
+// 响应经replay collector交给与Crawl相同的回调，链接、表单、API和字符集处理保持一致；
+// 不做去重和范围检查，也不会发出网络请求
+func (s *StaticCrawlerImpl) AnalyzeResponse(method string, target *url.URL, resp *http.Response, body []byte) (*Result, error) {
+	if method == "" {
+		method = "GET"
+	}
+	result := &Result{
+		URL:          target.String(),
+		Links:        make([]string, 0),
+		LinkSources:  make(map[string]string),
+		Assets:       make([]string, 0),
+		Forms:        make([]Form, 0),
+		APIs:         make([]string, 0),
+		POSTRequests: make([]POSTRequest, 0),
+		Headers:      make(map[string]string),
 	}
 	
-	// 设置并发限制
+	ctx := colly.NewContext()
+	ctx.Put(staticCrawlTaskKey, &staticCrawlTask{result: result, passive: true})
+	
+	collector := s.getReplayCollector()
+	id := s.replayTransport.store(resp, body)
+	defer s.replayTransport.remove(id)
+	header := http.Header{}
+	header.Set(replayIDHeader, id)
+	
+	if err := collector.Request(method, target.String(), nil, ctx, header); err != nil && !result.Crawled {
+		return nil, fmt.Errorf("分析响应失败 %s: %v", target.String(), err)
+	}
+	return result, nil
+}
+
+// getReplayCollector 获取分析已有响应用的collector（首次调用时创建）
+func (s *StaticCrawlerImpl) getReplayCollector() *colly.Collector {
+	s.replayOnce.Do(func() {
+		collector := colly.NewCollector(colly.AllowURLRevisit())
+		// 错误状态码的响应同样解析（被动流量中的403/500页面也可能包含链接）
+		collector.ParseHTTPErrorResponse = true
+		// 重定向由浏览器自行跟随，这里只分析当前响应
+		collector.SetRedirectHandler(func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		})
+		s.replayTransport = newReplayTransport()
+		collector.WithTransport(s.replayTransport)
+		s.registerCallbacks(collector)
+		s.replayCollector = collector
+	})
+	return s.replayCollector
+}
+
+// replayIDHeader 关联请求与待分析响应的请求头
+const replayIDHeader = "X-Spider-Replay-Id"
+
+// replayTransport 返回预先保存的响应，不发出网络请求
+type replayTransport struct {
+	mutex     sync.Mutex
+	nextID    int64
+	responses map[string]*http.Response
+}
+
+func newReplayTransport() *replayTransport {
+	return &replayTransport{responses: make(map[string]*http.Response)}
+}
+
+// store 保存响应副本（响应体已完整读取并解压），返回关联ID
+func (t *replayTransport) store(resp *http.Response, body []byte) string {
+	stored := &http.Response{
+		Status:     resp.Status,
+		StatusCode: resp.StatusCode,
+		Proto:      resp.Proto,
+		ProtoMajor: resp.ProtoMajor,
+		ProtoMinor: resp.ProtoMinor,
+		Header:     resp.Header.Clone(),
+	}
+	if stored.Header == nil {
+		stored.Header = make(http.Header)
+	}
+	stored.Header.Del("Content-Encoding")
+	stored.Header.Del("Content-Length")
+	stored.ContentLength = int64(len(body))
+	stored.Body = io.NopCloser(bytes.NewReader(body))
+	
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.nextID++
+	id := strconv.FormatInt(t.nextID, 10)
+	t.responses[id] = stored
+	return id
+}
+
+func (t *replayTransport) remove(id string) {
+	t.mutex.Lock()
+	delete(t.responses, id)
+	t.mutex.Unlock()
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mutex.Lock()
+	stored, exists := t.responses[req.Header.Get(replayIDHeader)]
+	t.mutex.Unlock()
+	if !exists {
+		return nil, fmt.Errorf("没有待分析的响应: %s", req.URL)
+	}
+	resp := *stored
+	resp.Request = req
+	return &resp, nil
+}
+
+// staticCrawlTaskKey 请求上下文中保存爬取状态的键
+const staticCrawlTaskKey = "static_crawl_task"
+
+// staticCrawlTask 单个URL的爬取状态（v4.9新增）
+// collector在所有URL间复用，每个请求的结果和统计通过colly上下文传递
+type staticCrawlTask struct {
+	result         *Result
+	passive        bool // 🆕 v4.9: 分析已有响应（AnalyzeResponse），跳过去重和范围检查
+	linkCount      int
+	validCount     int
+	duplicateCount int
+	invalidCount   int
+}
+
+// staticCrawlTaskFrom 从请求上下文取出爬取状态
+func staticCrawlTaskFrom(ctx *colly.Context) *staticCrawlTask {
+	if ctx == nil {
+		return nil
+	}
+	task, _ := ctx.GetAny(staticCrawlTaskKey).(*staticCrawlTask)
+	return task
+}
+
+// getCollector 获取长期复用的collector（首次调用时创建）
+// 所有URL共享同一个collector和Transport，连接通过keep-alive复用
+func (s *StaticCrawlerImpl) getCollector() *colly.Collector {
+	s.collectorOnce.Do(func() {
+		collector := colly.NewCollector(
+			colly.AllowURLRevisit(), // 去重由DuplicateHandler负责
+		)
+		
+		// 持久化Transport（连接池 + keep-alive）
+		transport := &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          200,
+			MaxIdleConnsPerHost:   s.parallelism(),
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		}
+		
+		// ✅ 修复5: 配置HTTPS证书验证
+		if s.config.AntiDetectionSettings.InsecureSkipVerify {
+			transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		}
+		
+		// 🆕 v4.9: 启用认证时包装认证RoundTripper（同样复用连接）
+		var roundTripper http.RoundTripper = transport
+		if s.authManager != nil {
+			roundTripper = s.authManager.WrapTransport(transport)
+		}
+		// 🆕 v4.9: HAR记录放在最外层，记录带认证头的实际请求
+		if s.harRecorder != nil {
+			roundTripper = s.harRecorder.WrapTransport(roundTripper, HARSourceStatic)
+		}
+		collector.WithTransport(roundTripper)
+		
+		if timeout := s.config.SchedulingSettings.PerformanceConfig.RequestTimeout; timeout > 0 {
+			collector.SetRequestTimeout(time.Duration(timeout) * time.Second)
+		}
+		
+		s.applyLimit(collector)
+		s.registerCallbacks(collector)
+		s.collector = collector
+	})
+	return s.collector
+}
+
+// chunkEnumerator 获取Spider共享的分块枚举器（未关联Spider时返回nil）
+func (s *StaticCrawlerImpl) chunkEnumerator() *ChunkEnumerator {
+	if s.spider == nil {
+		return nil
+	}
+	return s.spider.GetChunkEnumerator()
+}
+
+// addChunkLink 分块通过Spider的准入检查（与Spider侧一致）后加入链接
+func (s *StaticCrawlerImpl) addChunkLink(result *Result, chunk string) {
+	processedURL := chunk
+	if s.spider != nil {
+		var ok bool
+		if processedURL, ok = s.spider.AdmitChunk(chunk); !ok {
+			return
+		}
+	}
+	_ = s.addLinkWithSource(result, chunk, processedURL, LinkSourceChunk)
+}
+
+// parallelism 每个域名的最大并发请求数（不低于调度器worker数，否则worker会排队等待colly的并发槽）
+func (s *StaticCrawlerImpl) parallelism() int {
+	parallelism := frontierWorkerCount(s.config)
+	if s.config != nil && s.config.SchedulingSettings.PerformanceConfig.MaxConcurrentRequests > parallelism {
+		parallelism = s.config.SchedulingSettings.PerformanceConfig.MaxConcurrentRequests
+	}
+	return parallelism
+}
+
+// applyLimit 设置并发和请求间隔限制
+// 请求间隔只在启用速率限制时生效（colly在占用并发槽期间等待，未限速时会把并发压成串行）
+func (s *StaticCrawlerImpl) applyLimit(collector *colly.Collector) {
+	var delay time.Duration
+	if s.config != nil && s.config.RateLimitSettings.Enabled {
+		delay = 500 * time.Millisecond
+		if s.config.AntiDetectionSettings.RequestDelay > 0 {
+			delay = s.config.AntiDetectionSettings.RequestDelay
+		}
+	}
 	collector.Limit(&colly.LimitRule{
 		DomainGlob:  "*",
-		Parallelism: 5,
-		Delay:       time.Duration(500) * time.Millisecond,
+		Parallelism: s.parallelism(),
+		Delay:       delay,
 	})
-	
+}
+
+// registerCallbacks 注册所有回调（只执行一次，结果通过请求上下文区分）
+func (s *StaticCrawlerImpl) registerCallbacks(collector *colly.Collector) {
 	// 设置请求前回调，实现User-Agent轮换、域名范围检查和Cookie应用
 	collector.OnRequest(func(r *colly.Request) {
+		task := staticCrawlTaskFrom(r.Ctx)
+		if task == nil || task.passive {
+			return
+		}
+		result := task.result
+		
 		// 🆕 v4.7: 在Colly层面阻止重复请求（关键修复！）
 		// 步骤1：检查URL是否重复
 		if s.duplicateHandler != nil {
@@ -232,35 +486,36 @@ func (s *StaticCrawlerImpl) Crawl(startURL *url.URL) (*Result, error) {
 	
 	// 设置HTML回调 - 提取所有可能包含URL的元素
 	// 1. 提取 <a href> 链接
-	linkCount := 0
-	validCount := 0
-	duplicateCount := 0
-	invalidCount := 0
-	
 	collector.OnHTML("a[href]", func(e *colly.HTMLElement) {
+		task := staticCrawlTaskFrom(e.Request.Ctx)
+		if task == nil {
+			return
+		}
+		result := task.result
+		
 		link := e.Attr("href")
-		linkCount++
+		task.linkCount++
 		
 		// 🆕 v3.7: 检查特殊协议链接并记录
 		if strings.HasPrefix(link, "mailto:") {
 			if s.spider != nil {
 				s.spider.RecordSpecialLink(link, "mailto")
 			}
-			invalidCount++
+			task.invalidCount++
 			return
 		}
 		if strings.HasPrefix(link, "tel:") {
 			if s.spider != nil {
 				s.spider.RecordSpecialLink(link, "tel")
 			}
-			invalidCount++
+			task.invalidCount++
 			return
 		}
 		if strings.HasPrefix(link, "ftp://") {
 			if s.spider != nil {
 				s.spider.RecordSpecialLink(link, "ftp")
 			}
-			invalidCount++
+			task.invalidCount++
 			return
 		}
 		if strings.HasPrefix(link, "ws://") || strings.HasPrefix(link, "wss://") {
@@ -271,14 +526,14 @@ func (s *StaticCrawlerImpl) Crawl(startURL *url.URL) (*Result, error) {
 				}
 				s.spider.RecordSpecialLink(link, protocol)
 			}
-			invalidCount++
+			task.invalidCount++
 			return
 		}
 		if strings.HasPrefix(link, "data:") {
 			if s.spider != nil {
 				s.spider.RecordSpecialLink(link, "data")
 			}
-			invalidCount++
+			task.invalidCount++
 			return
 		}
 		
@@ -299,7 +554,7 @@ func (s *StaticCrawlerImpl) Crawl(startURL *url.URL) (*Result, error) {
 					absURL := e.Request.AbsoluteURL(extractedURL)
 					// ✅ v4.1：使用过滤器
 					if absURL != "" && s.addLinkWithFilter(result, extractedURL, absURL) {
-						validCount++
+						task.validCount++
 						foundAny = true
 						fmt.Printf("    [JS提取] 从javascript:协议提取URL: %s → %s\n", extractedURL, absURL)
 					}
@@ -307,26 +562,26 @@ func (s *StaticCrawlerImpl) Crawl(startURL *url.URL) (*Result, error) {
 			}
 			
 			if !foundAny {
-				invalidCount++
+				task.invalidCount++
 			}
 			return
 		}
 		
 		// 检查URL有效性
 		if !IsValidURL(link) {
-			invalidCount++
+			task.invalidCount++
 			return
 		}
 		
 		absoluteURL := e.Request.AbsoluteURL(link)
 		if absoluteURL == "" {
-			invalidCount++
+			task.invalidCount++
 			return
 		}
 		
 		// ✅ v4.0: 使用统一的过滤函数添加链接
 		if s.addLinkWithFilter(result, link, absoluteURL) {
-			validCount++
+			task.validCount++
 			
 			// ✅ v4.0: 协议相对URL处理 - 生成http和https两个版本
 			if strings.HasPrefix(link, "//") {
@@ -335,13 +590,13 @@ func (s *StaticCrawlerImpl) Crawl(startURL *url.URL) (*Result, error) {
 					if nURL != absoluteURL {
 						// 添加协议变体（也会经过过滤）
 						if s.addLinkWithFilter(result, link, nURL) {
-							validCount++
+							task.validCount++
 						}
 					}
 				}
 			}
 		} else {
-			invalidCount++
+			task.invalidCount++
 			return
 		}
 		
@@ -354,27 +609,39 @@ func (s *StaticCrawlerImpl) Crawl(startURL *url.URL) (*Result, error) {
 				if !shouldRequest {
 					// 静态资源：记录到静态资源列表（但URL已在Links中）
 					s.spider.RecordStaticResource(absoluteURL, resourceType)
-					invalidCount++
+					task.invalidCount++
 					// 这里不return，继续标记validCount，因为URL已成功记录
 				}
 				// 需要请求的资源继续处理
 			}
 		}
 		
-		validCount++
+		task.validCount++
 	})
 	
 	// 添加详细调试日志
 	collector.OnScraped(func(r *colly.Response) {
+		task := staticCrawlTaskFrom(r.Ctx)
+		if task == nil {
+			return
+		}
+		result := task.result
+		
 		fmt.Printf("\n[静态爬虫] 页面爬取完成: %s\n", r.Request.URL)
-		fmt.Printf("[静态爬虫] 发现 %d 个<a>标签\n", linkCount)
+		fmt.Printf("[静态爬虫] 发现 %d 个<a>标签\n", task.linkCount)
 		fmt.Printf("[静态爬虫] 有效链接: %d个 | 重复过滤: %d个 | 无效链接: %d个\n", 
-			validCount, duplicateCount, invalidCount)
+			task.validCount, task.duplicateCount, task.invalidCount)
 		fmt.Printf("[静态爬虫] 最终收集: %d 个链接\n\n", len(result.Links))
 	})
 	
 	// 2. 提取 <form action> 表单提交地址
 	collector.OnHTML("form[action]", func(e *colly.HTMLElement) {
+		task := staticCrawlTaskFrom(e.Request.Ctx)
+		if task == nil {
+			return
+		}
+		result := task.result
+		
 		action := e.Attr("action")
 		if action != "" && !strings.HasPrefix(action, "javascript:") {
 			absoluteURL := e.Request.AbsoluteURL(action)
@@ -392,6 +659,12 @@ func (s *StaticCrawlerImpl) Crawl(startURL *url.URL) (*Result, error) {
 	
 	// 3. 提取 <iframe src> 框架地址
 	collector.OnHTML("iframe[src]", func(e *colly.HTMLElement) {
+		task := staticCrawlTaskFrom(e.Request.Ctx)
+		if task == nil {
+			return
+		}
+		result := task.result
+		
 		src := e.Attr("src")
 		if src != "" && !strings.HasPrefix(src, "javascript:") {
 			absoluteURL := e.Request.AbsoluteURL(src)
@@ -409,6 +682,12 @@ func (s *StaticCrawlerImpl) Crawl(startURL *url.URL) (*Result, error) {
 	
 	// 4. 提取 <frame src> 框架地址
 	collector.OnHTML("frame[src]", func(e *colly.HTMLElement) {
+		task := staticCrawlTaskFrom(e.Request.Ctx)
+		if task == nil {
+			return
+		}
+		result := task.result
+		
 		src := e.Attr("src")
 		if src != "" && !strings.HasPrefix(src, "javascript:") {
 			absoluteURL := e.Request.AbsoluteURL(src)
@@ -421,6 +700,12 @@ func (s *StaticCrawlerImpl) Crawl(startURL *url.URL) (*Result, error) {
 	
 	// 5. 提取 <embed src> 嵌入资源
 	collector.OnHTML("embed[src]", func(e *colly.HTMLElement) {
+		task := staticCrawlTaskFrom(e.Request.Ctx)
+		if task == nil {
+			return
+		}
+		result := task.result
+		
 		src := e.Attr("src")
 		if src != "" {
 			absoluteURL := e.Request.AbsoluteURL(src)
@@ -433,6 +718,12 @@ func (s *StaticCrawlerImpl) Crawl(startURL *url.URL) (*Result, error) {
 	
 	// 6. 提取 <object data> 对象数据
 	collector.OnHTML("object[data]", func(e *colly.HTMLElement) {
+		task := staticCrawlTaskFrom(e.Request.Ctx)
+		if task == nil {
+			return
+		}
+		result := task.result
+		
 		data := e.Attr("data")
 		if data != "" {
 			absoluteURL := e.Request.AbsoluteURL(data)
@@ -445,6 +736,12 @@ func (s *StaticCrawlerImpl) Crawl(startURL *url.URL) (*Result, error) {
 	
 	// 7. 提取 <meta http-equiv="refresh"> 重定向
 	collector.OnHTML("meta[http-equiv='refresh']", func(e *colly.HTMLElement) {
+		task := staticCrawlTaskFrom(e.Request.Ctx)
+		if task == nil {
+			return
+		}
+		result := task.result
+		
 		content := e.Attr("content")
 		if content != "" {
 			// 解析格式: "0;URL='http://example.com'" 或 "0;url=http://example.com"
@@ -467,6 +764,12 @@ func (s *StaticCrawlerImpl) Crawl(startURL *url.URL) (*Result, error) {
 	
 	// 8. 提取 <area href> 图像映射区域
 	collector.OnHTML("area[href]", func(e *colly.HTMLElement) {
+		task := staticCrawlTaskFrom(e.Request.Ctx)
+		if task == nil {
+			return
+		}
+		result := task.result
+		
 		href := e.Attr("href")
 		if href != "" && !strings.HasPrefix(href, "javascript:") {
 			absoluteURL := e.Request.AbsoluteURL(href)
@@ -479,6 +782,12 @@ func (s *StaticCrawlerImpl) Crawl(startURL *url.URL) (*Result, error) {
 	
 	// 9. 提取 <base href> 基础URL（影响相对路径解析）
 	collector.OnHTML("base[href]", func(e *colly.HTMLElement) {
+		task := staticCrawlTaskFrom(e.Request.Ctx)
+		if task == nil {
+			return
+		}
+		result := task.result
+		
 		href := e.Attr("href")
 		if href != "" {
 			absoluteURL := e.Request.AbsoluteURL(href)
@@ -491,6 +800,12 @@ func (s *StaticCrawlerImpl) Crawl(startURL *url.URL) (*Result, error) {
 	
 	// 10. 提取 data-* 属性中的URL（常见于SPA应用）
 	collector.OnHTML("[data-url], [data-href], [data-src], [data-link], [data-ajax], [data-target]", func(e *colly.HTMLElement) {
+		task := staticCrawlTaskFrom(e.Request.Ctx)
+		if task == nil {
+			return
+		}
+		result := task.result
+		
 		for _, attr := range []string{"data-url", "data-href", "data-src", "data-link", "data-ajax", "data-target"} {
 			if val := e.Attr(attr); val != "" && !strings.HasPrefix(val, "javascript:") && !strings.HasPrefix(val, "#") {
 				if strings.HasPrefix(val, "http") || strings.HasPrefix(val, "/") {
@@ -506,6 +821,12 @@ func (s *StaticCrawlerImpl) Crawl(startURL *url.URL) (*Result, error) {
 	
 	// 11. 提取 onclick/onmouseover 等事件处理器中的URL（新增）
 	collector.OnHTML("[onclick], [onmouseover], [onmousedown], [ondblclick]", func(e *colly.HTMLElement) {
+		task := staticCrawlTaskFrom(e.Request.Ctx)
+		if task == nil {
+			return
+		}
+		result := task.result
+		
 		for _, eventAttr := range []string{"onclick", "onmouseover", "onmousedown", "ondblclick"} {
 			if eventCode := e.Attr(eventAttr); eventCode != "" {
 				// 从事件代码中提取URL（已包含质量过滤）
@@ -523,6 +844,12 @@ func (s *StaticCrawlerImpl) Crawl(startURL *url.URL) (*Result, error) {
 	
 	// 12. 提取所有<button>和带role="button"的元素（新增）
 	collector.OnHTML("button, [role='button']", func(e *colly.HTMLElement) {
+		task := staticCrawlTaskFrom(e.Request.Ctx)
+		if task == nil {
+			return
+		}
+		result := task.result
+		
 		// 检查data属性
 		for _, attr := range []string{"data-url", "data-href", "data-target", "data-action"} {
 			if val := e.Attr(attr); val != "" && !strings.HasPrefix(val, "#") {
@@ -539,6 +866,12 @@ func (s *StaticCrawlerImpl) Crawl(startURL *url.URL) (*Result, error) {
 	
 	// 🆕 v3.7: 设置资源回调（增强版：实时分类和记录）
 	collector.OnHTML("link[href], script[src], img[src]", func(e *colly.HTMLElement) {
+		task := staticCrawlTaskFrom(e.Request.Ctx)
+		if task == nil {
+			return
+		}
+		result := task.result
+		
 		var assetURL string
 		if e.Name == "link" {
 			assetURL = e.Attr("href")
@@ -564,6 +897,12 @@ func (s *StaticCrawlerImpl) Crawl(startURL *url.URL) (*Result, error) {
 	
 	// 🆕 提取 srcset 属性（响应式图片）- 新功能
 	collector.OnHTML("img[srcset], source[srcset]", func(e *colly.HTMLElement) {
+		task := staticCrawlTaskFrom(e.Request.Ctx)
+		if task == nil {
+			return
+		}
+		result := task.result
+		
 		srcset := e.Attr("srcset")
 		if srcset == "" {
 			return
@@ -592,6 +931,12 @@ func (s *StaticCrawlerImpl) Crawl(startURL *url.URL) (*Result, error) {
 	
 	// 🆕 提取 picture 标签内的所有源 - 新功能
 	collector.OnHTML("picture", func(e *colly.HTMLElement) {
+		task := staticCrawlTaskFrom(e.Request.Ctx)
+		if task == nil {
+			return
+		}
+		result := task.result
+		
 		// 提取 source 标签
 		e.ForEach("source[srcset]", func(_ int, source *colly.HTMLElement) {
 			srcset := source.Attr("srcset")
@@ -625,6 +970,12 @@ func (s *StaticCrawlerImpl) Crawl(startURL *url.URL) (*Result, error) {
 	
 	// 设置表单回调（增强版：捕获所有表单 + POST请求生成）
 	collector.OnHTML("form", func(e *colly.HTMLElement) {
+		task := staticCrawlTaskFrom(e.Request.Ctx)
+		if task == nil {
+			return
+		}
+		result := task.result
+		
 		action := e.Attr("action")
 		method := strings.ToUpper(e.Attr("method"))
 		enctype := e.Attr("enctype")
@@ -702,6 +1053,12 @@ func (s *StaticCrawlerImpl) Crawl(startURL *url.URL) (*Result, error) {
 	
 	// 设置API端点回调
 	collector.OnHTML("script[src]", func(e *colly.HTMLElement) {
+		task := staticCrawlTaskFrom(e.Request.Ctx)
+		if task == nil {
+			return
+		}
+		result := task.result
+		
 		src := e.Attr("src")
 		absoluteURL := e.Request.AbsoluteURL(src)
 		if absoluteURL != "" && (strings.Contains(absoluteURL, "api") || strings.Contains(absoluteURL, "json")) {
@@ -714,6 +1071,12 @@ func (s *StaticCrawlerImpl) Crawl(startURL *url.URL) (*Result, error) {
 	
 	// 设置响应回调
 	collector.OnResponse(func(r *colly.Response) {
+		task := staticCrawlTaskFrom(r.Ctx)
+		if task == nil {
+			return
+		}
+		result := task.result
+		
 		// 🆕 v4.6: 标记为成功爬取
 		result.Crawled = true
 		result.SkipReason = "" // 清空跳过原因
@@ -775,6 +1138,22 @@ func (s *StaticCrawlerImpl) Crawl(startURL *url.URL) (*Result, error) {
 			}
 		}
 		
+		// 🆕 v4.9: 字符集检测与转码（必须在保存内容和OnHTML解析之前）
+		// colly只会按响应头声明的非UTF-8字符集转码，<meta>声明、BOM和无声明的情况在这里处理
+		if IsTextualContentType(result.ContentType) {
+			declared := CharsetFromContentType(result.ContentType)
+			if declared != "" && !isUTF8Charset(declared) && lookupEncoding(declared) != nil {
+				// 已由colly按响应头转码
+				result.Charset = declared
+				result.CharsetSource = CharsetSourceHeader
+			} else {
+				decoded, detection := DecodeToUTF8(result.ContentType, r.Body)
+				r.Body = decoded // 后续OnHTML回调基于转码后的内容解析
+				result.Charset = detection.Charset
+				result.CharsetSource = detection.Source
+			}
+		}
+		
 		// 保存HTML内容和Headers供高级检测使用
 		result.HTMLContent = string(r.Body)
 		result.Headers = make(map[string]string)
@@ -787,9 +1166,36 @@ func (s *StaticCrawlerImpl) Crawl(startURL *url.URL) (*Result, error) {
         // === 优化1：提取响应头中的URL（统一过滤） ===
         headerURLs := s.extractURLsFromHeaders(r)
         for _, u := range headerURLs {
-            _ = s.addLinkWithFilter(result, u, u)
+            _ = s.addLinkWithSource(result, u, u, LinkSourceHeader)
         }
 		
+		// 🆕 v4.9: 非HTML响应（JSON/RSS/Atom/XML/OpenSearch/纯文本）按类型提取链接
+		if s.contentExtractors != nil && !strings.Contains(result.ContentType, "text/html") {
+			for _, link := range s.contentExtractors.Extract(result.ContentType, r.Body, r.Request.URL) {
+				_ = s.addLinkWithSource(result, link.Raw, link.URL, link.Extractor)
+			}
+		}
+		
+		// 🆕 v4.9: JS文件按AST提取请求端点，并枚举运行时中的懒加载分块
+		if isJavaScriptMediaType(parseMediaType(result.ContentType)) {
+			for _, u := range s.extractURLsFromJSSource(string(r.Body), r.Request.URL.String(), 0) {
+				if absoluteURL := r.Request.AbsoluteURL(u); absoluteURL != "" {
+					_ = s.addLinkWithSource(result, u, absoluteURL, LinkSourceJavaScript)
+				}
+			}
+			if enumerator := s.chunkEnumerator(); enumerator != nil {
+				for _, chunk := range enumerator.FromScript(string(r.Body), r.Request.URL.String()) {
+					s.addChunkLink(result, chunk)
+				}
+			}
+		} else if IsBuildManifestURL(r.Request.URL.String()) {
+			if enumerator := s.chunkEnumerator(); enumerator != nil {
+				for _, chunk := range enumerator.FromManifest(r.Body, r.Request.URL.String()) {
+					s.addChunkLink(result, chunk)
+				}
+			}
+		}
+		
 		// === 优化2：提取内联JavaScript中的URL ===
 		if strings.Contains(result.ContentType, "text/html") {
 			inlineURLs := s.extractURLsFromInlineScripts(string(r.Body), r.Request.URL.String())
@@ -803,7 +1209,7 @@ func (s *StaticCrawlerImpl) Crawl(startURL *url.URL) (*Result, error) {
 						}
 					}
 					
-                    _ = s.addLinkWithFilter(result, u, absoluteURL)
+                    _ = s.addLinkWithSource(result, u, absoluteURL, LinkSourceInlineScript)
 				}
 			}
 			
@@ -838,6 +1244,12 @@ func (s *StaticCrawlerImpl) Crawl(startURL *url.URL) (*Result, error) {
 	
 	// 设置错误回调
 	collector.OnError(func(r *colly.Response, err error) {
+		task := staticCrawlTaskFrom(r.Ctx)
+		if task == nil {
+			return
+		}
+		result := task.result
+		
 		// 🆕 v4.6: 标记为爬取失败并记录错误
 		result.Crawled = true // 已尝试爬取
 		result.Error = err
@@ -871,23 +1283,14 @@ func (s *StaticCrawlerImpl) Crawl(startURL *url.URL) (*Result, error) {
 		}
 	}
 	})
-	
-	// 开始爬取
-	err := collector.Visit(startURL.String())
-	if err != nil {
-		return nil, fmt.Errorf("访问URL失败 %s: %v", startURL.String(), err)
-	}
-	
-	// 等待所有请求完成
-	collector.Wait()
-	
-	return result, nil
 }
 
 // Stop 停止爬取
 func (s *StaticCrawlerImpl) Stop() {
 	// 等待所有请求完成
-	s.collector.Wait()
+	if s.collector != nil {
+		s.collector.Wait()
+	}
 }
 
 // generatePOSTRequestFromForm 从表单生成POST请求数据
@@ -956,6 +1359,11 @@ func (s *StaticCrawlerImpl) generatePOSTRequestFromForm(form *Form, enctype stri
 // addLinkWithFilter 添加链接到结果，应用所有过滤器（v4.0统一入口）
 // 这是添加链接的唯一正确方式，确保所有过滤器都被应用
 func (s *StaticCrawlerImpl) addLinkWithFilter(result *Result, rawURL string, absoluteURL string) bool {
+	return s.addLinkWithSource(result, rawURL, absoluteURL, LinkSourceHTML)
+}
+
+// addLinkWithSource 过滤后添加链接，并记录产生该链接的提取器（v4.9新增）
+func (s *StaticCrawlerImpl) addLinkWithSource(result *Result, rawURL string, absoluteURL string, source string) bool {
 	// 🆕 v4.7: URL模式限流（最高优先级，避免资源浪费）
 	if s.spider != nil {
 		limiter := s.spider.GetURLPatternLimiter()
@@ -993,6 +1401,12 @@ func (s *StaticCrawlerImpl) addLinkWithFilter(result *Result, rawURL string, abs
 	
 	// 添加到结果
 	result.Links = append(result.Links, absoluteURL)
+	if result.LinkSources == nil {
+		result.LinkSources = make(map[string]string)
+	}
+	if _, exists := result.LinkSources[absoluteURL]; !exists {
+		result.LinkSources[absoluteURL] = source
+	}
 	return true
 }
 
@@ -1282,13 +1696,15 @@ func (s *StaticCrawlerImpl) extractURLsFromInlineScripts(htmlContent, baseURL st
 	filteredCount := 0 // 🆕 v3.5: 统计过滤的URL数量
 	
 	// 1. 提取<script>标签内容
+	// 🆕 v4.9: 记录脚本在页面中的起始行，使AST端点行号对应HTML源码
 	scriptPattern := regexp.MustCompile(`(?i)<script[^>]*>([\s\S]*?)</script>`)
-	scripts := scriptPattern.FindAllStringSubmatch(htmlContent, -1)
+	scripts := scriptPattern.FindAllStringSubmatchIndex(htmlContent, -1)
 	
 	for _, script := range scripts {
-		if len(script) > 1 {
-			jsCode := script[1]
-			extractedURLs := s.extractURLsFromJSCode(jsCode)
+		if len(script) > 3 && script[2] >= 0 {
+			jsCode := htmlContent[script[2]:script[3]]
+			lineOffset := strings.Count(htmlContent[:script[2]], "\n")
+			extractedURLs := s.extractURLsFromJSSource(jsCode, baseURL, lineOffset)
 			for _, u := range extractedURLs {
 				if !seen[u] {
 					seen[u] = true
@@ -1345,9 +1761,46 @@ func (s *StaticCrawlerImpl) extractURLsFromInlineScripts(htmlContent, baseURL st
 
 // extractURLsFromJSCode 从JavaScript代码中提取URL（v4.0重写 - 使用专业提取器）
 func (s *StaticCrawlerImpl) extractURLsFromJSCode(jsCode string) []string {
-	// ✅ v4.0修复：使用专业的URL提取器
-	extractor := NewURLExtractorFix()
-	urls := extractor.ExtractFromJSCode(jsCode)
+	return s.extractURLsFromJSSource(jsCode, "", 0)
+}
+
+// extractURLsFromJSSource 从JavaScript代码中提取URL（🆕 v4.9）
+// 优先使用AST分析（source非空时将结构化端点记录到Spider，行号加上lineOffset），
+// 代码无法解析时降级为URL提取器
+func (s *StaticCrawlerImpl) extractURLsFromJSSource(jsCode string, source string, lineOffset int) []string {
+	var urls []string
+	
+	// 🆕 v4.9: 混淆脚本先在沙箱中还原解码调用（替换为字面量，行号不变）
+	seen := make(map[string]bool)
+	if s.jsSandbox != nil && len(jsCode) <= s.config.JSDeobfuscationSettings.MaxScriptSize {
+		if report := DeobfuscateScript(jsCode, source, s.jsSandbox); report != nil {
+			jsCode = report.Code
+			for _, u := range report.HiddenURLs {
+				seen[u] = true
+				urls = append(urls, u)
+			}
+			if s.spider != nil {
+				s.spider.RecordDeobfuscation(report)
+			}
+		}
+	}
+	
+	if endpoints, err := s.jsASTAnalyzer.Analyze(jsCode, source); err == nil {
+		for _, ep := range endpoints {
+			ep.Line += lineOffset
+			if u := ep.CrawlURL(); u != "" && !seen[u] {
+				seen[u] = true
+				urls = append(urls, u)
+			}
+		}
+		if source != "" && s.spider != nil {
+			s.spider.RecordJSEndpoints(endpoints)
+		}
+	} else {
+		// ✅ v4.0修复：使用专业的URL提取器
+		extractor := NewURLExtractorFix()
+		urls = append(urls, extractor.ExtractFromJSCode(jsCode)...)
+	}
 	
 	// ✅ v4.0修复：应用质量过滤器
 	if s.urlQualityFilter != nil {